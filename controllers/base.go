@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"app/db"
+	"app/models"
 	"errors"
 	"fmt"
 	"net/http"
@@ -12,8 +14,35 @@ import (
 var (
 	ErrUnauthorized = "Unauthorized access."
 	ErrNotFound     = "Could not found."
+
+	errEmailNotVerified = "Please verify your email address first."
 )
 
+// shouldRestrictUnverified responds with forbidden if the user hasn't verified
+// the email address, social actions are restricted until then.
+func shouldRestrictUnverified(database *db.MongoDB, uid string, c *gin.Context) bool {
+	userModel := models.NewUserModel(database)
+
+	isVerified, err := userModel.IsUserEmailVerified(uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return true
+	}
+
+	if !isVerified {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": errEmailNotVerified,
+		})
+
+		return true
+	}
+
+	return false
+}
+
 func bindJSONData(data interface{}, c *gin.Context) bool {
 	if err := c.ShouldBindJSON(&data); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -0,0 +1,298 @@
+package controllers
+
+import (
+	"app/db"
+	"app/models"
+	"app/requests"
+	"net/http"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+)
+
+type ConsumptionSessionController struct {
+	Database *db.MongoDB
+}
+
+func NewConsumptionSessionController(mongoDB *db.MongoDB) ConsumptionSessionController {
+	return ConsumptionSessionController{
+		Database: mongoDB,
+	}
+}
+
+// Get Consumption Sessions
+// @Summary Get Consumption Sessions
+// @Description Returns watch/read/play history of a list entry
+// @Tags user_list
+// @Accept application/json
+// @Produce application/json
+// @Param getconsumptionsessions query requests.GetConsumptionSessions true "Get Consumption Sessions"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {array} models.ConsumptionSession
+// @Failure 403 {string} string "Unauthorized access"
+// @Failure 404 {string} string "Could not found"
+// @Failure 500 {string} string
+// @Router /list/session [get]
+func (cs *ConsumptionSessionController) GetConsumptionSessions(c *gin.Context) {
+	var data requests.GetConsumptionSessions
+	if err := c.ShouldBindQuery(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": validatorErrorHandler(err),
+		})
+
+		return
+	}
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+
+	entry, shouldReturn := getOwnedListEntry(c, cs.Database, uid, data.Type, data.ID)
+	if shouldReturn {
+		return
+	}
+
+	sessionModel := models.NewConsumptionSessionModel(cs.Database)
+
+	if err := sessionModel.SeedConsumptionSessions(uid, entry, entry.TimesFinished); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	sessions, err := sessionModel.GetConsumptionSessionsByListID(data.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": sessions})
+}
+
+// Create Consumption Session
+// @Summary Create Consumption Session
+// @Description Adds a past or ongoing session to list entry history
+// @Tags user_list
+// @Accept application/json
+// @Produce application/json
+// @Param createconsumptionsession body requests.CreateConsumptionSession true "Create Consumption Session"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 201 {object} models.ConsumptionSession
+// @Failure 403 {string} string "Unauthorized access"
+// @Failure 404 {string} string "Could not found"
+// @Failure 500 {string} string
+// @Router /list/session [post]
+func (cs *ConsumptionSessionController) CreateConsumptionSession(c *gin.Context) {
+	var data requests.CreateConsumptionSession
+	if shouldReturn := bindJSONData(&data, c); shouldReturn {
+		return
+	}
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+
+	entry, shouldReturn := getOwnedListEntry(c, cs.Database, uid, data.Type, data.ID)
+	if shouldReturn {
+		return
+	}
+
+	sessionModel := models.NewConsumptionSessionModel(cs.Database)
+
+	if err := sessionModel.SeedConsumptionSessions(uid, entry, entry.TimesFinished); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	session, err := sessionModel.CreateConsumptionSession(uid, entry, data)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if session.IsFinished {
+		if err := models.NewUserListModel(cs.Database).IncrementTimesFinishedByID(entry.ContentType, data.ID, 1); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Successfully created.", "data": session})
+}
+
+// Update Consumption Session
+// @Summary Update Consumption Session
+// @Description Updates dates, score or notes of a session
+// @Tags user_list
+// @Accept application/json
+// @Produce application/json
+// @Param updateconsumptionsession body requests.UpdateConsumptionSession true "Update Consumption Session"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {object} models.ConsumptionSession
+// @Failure 403 {string} string "Unauthorized access"
+// @Failure 404 {string} string "Could not found"
+// @Failure 500 {string} string
+// @Router /list/session [patch]
+func (cs *ConsumptionSessionController) UpdateConsumptionSession(c *gin.Context) {
+	var data requests.UpdateConsumptionSession
+	if shouldReturn := bindJSONData(&data, c); shouldReturn {
+		return
+	}
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	sessionModel := models.NewConsumptionSessionModel(cs.Database)
+
+	session, err := sessionModel.GetConsumptionSessionByID(data.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if session.UserID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": ErrNotFound})
+		return
+	}
+
+	if uid != session.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": ErrUnauthorized})
+		return
+	}
+
+	updatedSession, err := sessionModel.UpdateConsumptionSession(session, data)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if updatedSession.IsFinished != session.IsFinished {
+		delta := 1
+		if !updatedSession.IsFinished {
+			delta = -1
+		}
+
+		if err := models.NewUserListModel(cs.Database).IncrementTimesFinishedByID(session.ContentType, session.ListID, delta); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session updated.", "data": updatedSession})
+}
+
+// Delete Consumption Session
+// @Summary Delete Consumption Session
+// @Description Deletes a session from list entry history
+// @Tags user_list
+// @Accept application/json
+// @Produce application/json
+// @Param id body requests.ID true "ID"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /list/session [delete]
+func (cs *ConsumptionSessionController) DeleteConsumptionSession(c *gin.Context) {
+	var data requests.ID
+	if shouldReturn := bindJSONData(&data, c); shouldReturn {
+		return
+	}
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	sessionModel := models.NewConsumptionSessionModel(cs.Database)
+
+	session, _ := sessionModel.GetConsumptionSessionByID(data.ID)
+
+	isDeleted, err := sessionModel.DeleteConsumptionSessionByID(uid, data.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if isDeleted {
+		if session.IsFinished {
+			if err := models.NewUserListModel(cs.Database).IncrementTimesFinishedByID(session.ContentType, session.ListID, -1); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Session deleted successfully."})
+		return
+	}
+
+	c.JSON(http.StatusNotFound, gin.H{"error": ErrNotFound})
+}
+
+func getOwnedListEntry(c *gin.Context, database *db.MongoDB, uid, contentType, listID string) (models.ListEntry, bool) {
+	userListModel := models.NewUserListModel(database)
+
+	entry, err := userListModel.GetListEntryByID(contentType, listID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return models.ListEntry{}, true
+	}
+
+	if entry.UserID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": ErrNotFound})
+		return models.ListEntry{}, true
+	}
+
+	if uid != entry.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": ErrUnauthorized})
+		return models.ListEntry{}, true
+	}
+
+	return entry, false
+}
+
+// recordConsumptionSession starts or finishes a session when the status of a
+// list entry changes and counts the finished session in times_finished, unless
+// the update already incremented it. previousTimesFinished is the counter
+// before the change, used to seed the history of entries created before
+// sessions existed.
+func recordConsumptionSession(
+	database *db.MongoDB, uid, contentType, listID, previousStatus, status string,
+	previousTimesFinished int, score *float32,
+) {
+	if previousStatus == status || (status != "active" && status != "finished") {
+		return
+	}
+
+	userListModel := models.NewUserListModel(database)
+	sessionModel := models.NewConsumptionSessionModel(database)
+
+	entry, err := userListModel.GetListEntryByID(contentType, listID)
+	if err != nil || entry.UserID == "" {
+		return
+	}
+
+	if err := sessionModel.SeedConsumptionSessions(uid, entry, previousTimesFinished); err != nil {
+		return
+	}
+
+	if status == "active" {
+		sessionModel.StartConsumptionSession(uid, entry)
+		return
+	}
+
+	if err := sessionModel.FinishConsumptionSession(uid, entry, score); err != nil {
+		return
+	}
+
+	if entry.TimesFinished <= previousTimesFinished {
+		userListModel.IncrementTimesFinishedByID(contentType, listID, 1)
+	}
+}
+
+// recordCreatedConsumptionSession records the session of a newly created entry,
+// the counter of an entry created as finished already includes that session.
+func recordCreatedConsumptionSession(
+	database *db.MongoDB, uid, contentType, listID, status string,
+	timesFinished int, score *float32,
+) {
+	previousTimesFinished := timesFinished
+	if status == "finished" && timesFinished > 0 {
+		previousTimesFinished--
+	}
+
+	recordConsumptionSession(database, uid, contentType, listID, "", status, previousTimesFinished, score)
+}
@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"app/db"
+	"app/models"
+	"app/requests"
+	"fmt"
+	"net/http"
+	"time"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+)
+
+type DataExportController struct {
+	Database *db.MongoDB
+}
+
+func NewDataExportController(mongoDB *db.MongoDB) DataExportController {
+	return DataExportController{
+		Database: mongoDB,
+	}
+}
+
+const (
+	errDataExportInProgress = "Your data export is still being prepared."
+	errDataExportNotReady   = "Data export is not ready yet."
+	errDataExportExpired    = "Data export has expired, please request a new one."
+)
+
+// Files of the expired exports are deleted with this interval.
+const dataExportCleanupInterval = time.Hour
+
+// StartDataExportCleanup deletes the files of the expired exports, the exports
+// are kept so that their status can still be seen.
+func StartDataExportCleanup(database *db.MongoDB) {
+	go func() {
+		ticker := time.NewTicker(dataExportCleanupInterval)
+		defer ticker.Stop()
+
+		for {
+			models.NewDataExportModel(database).DeleteExpiredDataExportFiles()
+			<-ticker.C
+		}
+	}()
+}
+
+// Create Data Export
+// @Summary Create Data Export
+// @Description Starts generating an archive of all user data, replaces the previous export
+// @Tags export
+// @Accept application/json
+// @Produce application/json
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 202 {object} models.DataExport
+// @Failure 409 {string} string
+// @Failure 500 {string} string
+// @Router /export [post]
+func (de *DataExportController) CreateDataExport(c *gin.Context) {
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	dataExportModel := models.NewDataExportModel(de.Database)
+
+	isInProgress, err := dataExportModel.IsDataExportInProgress(uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	if isInProgress {
+		c.JSON(http.StatusConflict, gin.H{"error": errDataExportInProgress})
+		return
+	}
+
+	dataExportModel.DeleteDataExportsByUserID(uid)
+
+	dataExport, err := dataExportModel.CreateDataExport(uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	go dataExportModel.GenerateDataExport(dataExport)
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Data export started.", "data": dataExport})
+}
+
+// Get Data Export
+// @Summary Get Data Export
+// @Description Returns status of the data export
+// @Tags export
+// @Accept application/json
+// @Produce application/json
+// @Param id query requests.ID true "ID"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {object} models.DataExport
+// @Failure 403 {string} string "Unauthorized access"
+// @Failure 404 {string} string "Could not found"
+// @Failure 500 {string} string
+// @Router /export [get]
+func (de *DataExportController) GetDataExport(c *gin.Context) {
+	dataExport, shouldReturn := de.getOwnedDataExport(c)
+	if shouldReturn {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dataExport})
+}
+
+// Download Data Export
+// @Summary Download Data Export
+// @Description Downloads the zip archive of a completed data export
+// @Tags export
+// @Accept application/json
+// @Produce application/zip
+// @Param id query requests.ID true "ID"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {file} file
+// @Failure 400 {string} string
+// @Failure 403 {string} string "Unauthorized access"
+// @Failure 404 {string} string "Could not found"
+// @Failure 410 {string} string
+// @Failure 500 {string} string
+// @Router /export/download [get]
+func (de *DataExportController) DownloadDataExport(c *gin.Context) {
+	dataExport, shouldReturn := de.getOwnedDataExport(c)
+	if shouldReturn {
+		return
+	}
+
+	if dataExport.ExpiresAt != nil && dataExport.ExpiresAt.Before(time.Now().UTC()) {
+		c.JSON(http.StatusGone, gin.H{"error": errDataExportExpired})
+		return
+	}
+
+	if dataExport.Status != models.CompletedDataExportStatus || dataExport.FileID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errDataExportNotReady})
+		return
+	}
+
+	dataExportModel := models.NewDataExportModel(de.Database)
+
+	downloadStream, err := dataExportModel.OpenDataExportFile(dataExport)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+	defer downloadStream.Close()
+
+	c.DataFromReader(http.StatusOK, downloadStream.GetFile().Length, "application/zip", downloadStream, map[string]string{
+		"Content-Disposition": fmt.Sprintf(
+			"attachment; filename=\"watchlistfy_export_%s.zip\"", dataExport.CreatedAt.Format("2006-01-02"),
+		),
+	})
+}
+
+func (de *DataExportController) getOwnedDataExport(c *gin.Context) (models.DataExport, bool) {
+	var data requests.ID
+	if err := c.ShouldBindQuery(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": validatorErrorHandler(err),
+		})
+
+		return models.DataExport{}, true
+	}
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	dataExportModel := models.NewDataExportModel(de.Database)
+
+	dataExport, err := dataExportModel.GetDataExportByID(data.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return models.DataExport{}, true
+	}
+
+	if dataExport.UserID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": ErrNotFound})
+		return models.DataExport{}, true
+	}
+
+	if uid != dataExport.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": ErrUnauthorized})
+		return models.DataExport{}, true
+	}
+
+	return dataExport, false
+}
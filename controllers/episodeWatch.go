@@ -0,0 +1,476 @@
+package controllers
+
+import (
+	"app/db"
+	"app/models"
+	"app/requests"
+	"app/responses"
+	"net/http"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+)
+
+type EpisodeWatchController struct {
+	Database *db.MongoDB
+}
+
+func NewEpisodeWatchController(mongoDB *db.MongoDB) EpisodeWatchController {
+	return EpisodeWatchController{
+		Database: mongoDB,
+	}
+}
+
+type episodeWatchTarget struct {
+	ContentType     string
+	ContentID       string
+	ContentTitle    string
+	ContentImage    string
+	Status          string
+	Score           *float32
+	TimesFinished   int
+	WatchedEpisodes int
+	Seasons         []models.SeasonEpisodes
+	AnimeList       models.AnimeList
+	Anime           responses.Anime
+	TVList          models.TVSeriesWatchList
+	TVSeries        responses.TVSeries
+}
+
+// Get Episode Watches
+// @Summary Get Watched Episodes
+// @Description Returns watched episodes of anime or tv series list entry
+// @Tags user_list
+// @Accept application/json
+// @Produce application/json
+// @Param getepisodewatches query requests.GetEpisodeWatches true "Get Episode Watches"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {array} models.EpisodeWatch
+// @Failure 403 {string} string "Unauthorized access"
+// @Failure 404 {string} string "Could not found"
+// @Failure 500 {string} string
+// @Router /list/episode [get]
+func (e *EpisodeWatchController) GetEpisodeWatches(c *gin.Context) {
+	var data requests.GetEpisodeWatches
+	if err := c.ShouldBindQuery(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": validatorErrorHandler(err),
+		})
+
+		return
+	}
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+
+	if _, shouldReturn := e.getEpisodeWatchTarget(c, uid, data.ID, data.Type); shouldReturn {
+		return
+	}
+
+	episodeWatchModel := models.NewEpisodeWatchModel(e.Database)
+
+	episodeWatches, err := episodeWatchModel.GetEpisodeWatchesByListID(data.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": episodeWatches})
+}
+
+// Mark Episode
+// @Summary Mark Episode
+// @Description Marks or unmarks single episode of anime or tv series list entry as watched
+// @Tags user_list
+// @Accept application/json
+// @Produce application/json
+// @Param markepisode body requests.MarkEpisode true "Mark Episode"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {string} string
+// @Failure 400 {string} string
+// @Failure 403 {string} string "Unauthorized access"
+// @Failure 404 {string} string "Could not found"
+// @Failure 500 {string} string
+// @Router /list/episode [patch]
+func (e *EpisodeWatchController) MarkEpisode(c *gin.Context) {
+	var data requests.MarkEpisode
+	if shouldReturn := bindJSONData(&data, c); shouldReturn {
+		return
+	}
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+
+	target, shouldReturn := e.getEpisodeWatchTarget(c, uid, data.ID, data.Type)
+	if shouldReturn {
+		return
+	}
+
+	key := models.EpisodeKey{
+		SeasonNumber:  defaultSeasonNumber(data.SeasonNumber),
+		EpisodeNumber: data.EpisodeNumber,
+	}
+
+	if err := models.ValidateEpisodeKey(target.Seasons, key); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	e.handleEpisodeWatches(c, uid, data.ID, target, []models.EpisodeKey{key}, *data.IsWatched, data.Score)
+}
+
+// Mark Episode Season
+// @Summary Mark Season
+// @Description Marks or unmarks every episode of a season of anime or tv series list entry as watched
+// @Tags user_list
+// @Accept application/json
+// @Produce application/json
+// @Param markepisodeseason body requests.MarkEpisodeSeason true "Mark Episode Season"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {string} string
+// @Failure 400 {string} string
+// @Failure 403 {string} string "Unauthorized access"
+// @Failure 404 {string} string "Could not found"
+// @Failure 500 {string} string
+// @Router /list/episode/season [patch]
+func (e *EpisodeWatchController) MarkEpisodeSeason(c *gin.Context) {
+	var data requests.MarkEpisodeSeason
+	if shouldReturn := bindJSONData(&data, c); shouldReturn {
+		return
+	}
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+
+	target, shouldReturn := e.getEpisodeWatchTarget(c, uid, data.ID, data.Type)
+	if shouldReturn {
+		return
+	}
+
+	keys, err := models.SeasonEpisodeKeys(target.Seasons, defaultSeasonNumber(data.SeasonNumber))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	e.handleEpisodeWatches(c, uid, data.ID, target, keys, *data.IsWatched, nil)
+}
+
+// Mark Episode Range
+// @Summary Mark Episode Range
+// @Description Marks or unmarks a range of episodes of anime or tv series list entry as watched
+// @Tags user_list
+// @Accept application/json
+// @Produce application/json
+// @Param markepisoderange body requests.MarkEpisodeRange true "Mark Episode Range"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {string} string
+// @Failure 400 {string} string
+// @Failure 403 {string} string "Unauthorized access"
+// @Failure 404 {string} string "Could not found"
+// @Failure 500 {string} string
+// @Router /list/episode/range [patch]
+func (e *EpisodeWatchController) MarkEpisodeRange(c *gin.Context) {
+	var data requests.MarkEpisodeRange
+	if shouldReturn := bindJSONData(&data, c); shouldReturn {
+		return
+	}
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+
+	target, shouldReturn := e.getEpisodeWatchTarget(c, uid, data.ID, data.Type)
+	if shouldReturn {
+		return
+	}
+
+	keys, err := models.EpisodeRangeKeys(
+		target.Seasons,
+		models.EpisodeKey{
+			SeasonNumber:  defaultSeasonNumber(data.FromSeasonNumber),
+			EpisodeNumber: data.FromEpisodeNumber,
+		},
+		models.EpisodeKey{
+			SeasonNumber:  defaultSeasonNumber(data.ToSeasonNumber),
+			EpisodeNumber: data.ToEpisodeNumber,
+		},
+	)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	e.handleEpisodeWatches(c, uid, data.ID, target, keys, *data.IsWatched, nil)
+}
+
+func (e *EpisodeWatchController) getEpisodeWatchTarget(c *gin.Context, uid, listID, contentType string) (episodeWatchTarget, bool) {
+	userListModel := models.NewUserListModel(e.Database)
+
+	target := episodeWatchTarget{ContentType: contentType}
+
+	var ownerID string
+	switch contentType {
+	case "anime":
+		animeList, err := userListModel.GetBaseAnimeListByID(listID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return episodeWatchTarget{}, true
+		}
+
+		ownerID = animeList.UserID
+		target.AnimeList = animeList
+	case "tv":
+		tvList, err := userListModel.GetBaseTVSeriesListByID(listID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return episodeWatchTarget{}, true
+		}
+
+		ownerID = tvList.UserID
+		target.TVList = tvList
+	}
+
+	if ownerID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": ErrNotFound})
+		return episodeWatchTarget{}, true
+	}
+
+	if uid != ownerID {
+		c.JSON(http.StatusForbidden, gin.H{"error": ErrUnauthorized})
+		return episodeWatchTarget{}, true
+	}
+
+	switch contentType {
+	case "anime":
+		animeModel := models.NewAnimeModel(e.Database)
+		anime, _ := animeModel.GetAnimeDetails(requests.ID{
+			ID: target.AnimeList.AnimeID,
+		})
+
+		target.Anime = anime
+		target.ContentID = target.AnimeList.AnimeID
+		target.ContentTitle = anime.TitleOriginal
+		target.ContentImage = anime.ImageURL
+		target.Status = target.AnimeList.Status
+		target.Score = target.AnimeList.Score
+		target.TimesFinished = target.AnimeList.TimesFinished
+		target.WatchedEpisodes = int(target.AnimeList.WatchedEpisodes)
+		target.Seasons = models.AnimeSeasonEpisodes(anime)
+	case "tv":
+		tvSeriesModel := models.NewTVModel(e.Database)
+		tvSeries, _ := tvSeriesModel.GetTVSeriesDetails(requests.ID{
+			ID: target.TVList.TvID,
+		})
+
+		target.TVSeries = tvSeries
+		target.ContentID = target.TVList.TvID
+		target.ContentTitle = tvSeries.TitleEn
+		target.ContentImage = tvSeries.ImageURL
+		target.Status = target.TVList.Status
+		target.Score = target.TVList.Score
+		target.TimesFinished = target.TVList.TimesFinished
+		target.WatchedEpisodes = target.TVList.WatchedEpisodes
+		target.Seasons = models.TVSeasonEpisodes(tvSeries)
+	}
+
+	if len(target.Seasons) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Episode information is not available for this content."})
+		return episodeWatchTarget{}, true
+	}
+
+	return target, false
+}
+
+func (e *EpisodeWatchController) handleEpisodeWatches(
+	c *gin.Context, uid, listID string, target episodeWatchTarget,
+	keys []models.EpisodeKey, isWatched bool, score *float32,
+) {
+	watchedEpisodes, watchedSeasons, err := applyEpisodeWatches(
+		e.Database, uid, listID, target.ContentID, target.ContentType,
+		target.Seasons, target.WatchedEpisodes, keys, isWatched, score,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	userListModel := models.NewUserListModel(e.Database)
+
+	var (
+		list, updatedList interface{}
+		status            string
+	)
+
+	switch target.ContentType {
+	case "anime":
+		updatedAnimeList, err := userListModel.UpdateAnimeListWatchedEpisodes(target.AnimeList, target.Anime, int64(watchedEpisodes))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		list = target.AnimeList
+		updatedList = updatedAnimeList
+		status = updatedAnimeList.Status
+	case "tv":
+		updatedTVList, err := userListModel.UpdateTVSeriesListWatchedCounters(target.TVList, target.TVSeries, watchedEpisodes, watchedSeasons)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		list = target.TVList
+		updatedList = updatedTVList
+		status = updatedTVList.Status
+	}
+
+	logModel := models.NewLogsModel(e.Database)
+
+	go logModel.CreateLog(uid, requests.CreateLog{
+		LogType:          models.UserListLogType,
+		LogAction:        models.UpdateLogAction,
+		LogActionDetails: status,
+		ContentTitle:     target.ContentTitle,
+		ContentImage:     target.ContentImage,
+		ContentType:      target.ContentType,
+		ContentID:        target.ContentID,
+	})
+
+	// Counters are journaled so undoing an earlier change doesn't restore stale ones.
+	go recordListChange(e.Database, uid, target.ContentType, models.UpdateListChangeAction, list, updatedList)
+
+	go recordConsumptionSession(
+		e.Database, uid, target.ContentType, listID,
+		target.Status, status, target.TimesFinished, target.Score,
+	)
+
+	if target.Status != "finished" && status == "finished" {
+		achievementModel := models.NewAchievementModel(e.Database)
+		achievementModel.CheckAndUnlockAchievements(uid, target.ContentType+"_finished")
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Watched episodes updated.", "data": updatedList})
+}
+
+// applyEpisodeWatches marks or unmarks the episodes and returns the watched
+// episode and season counters derived from the episode records.
+func applyEpisodeWatches(
+	database *db.MongoDB, uid, listID, contentID, contentType string,
+	seasons []models.SeasonEpisodes, watchedEpisodes int,
+	keys []models.EpisodeKey, isWatched bool, score *float32,
+) (int, int, error) {
+	episodeWatchModel := models.NewEpisodeWatchModel(database)
+
+	if err := episodeWatchModel.ReconcileEpisodeWatches(
+		uid, listID, contentID, contentType, seasons, watchedEpisodes,
+	); err != nil {
+		return 0, 0, err
+	}
+
+	if isWatched {
+		if err := episodeWatchModel.MarkEpisodesAsWatched(uid, listID, contentID, contentType, keys, score); err != nil {
+			return 0, 0, err
+		}
+	} else {
+		if err := episodeWatchModel.UnmarkEpisodes(listID, keys); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return episodeWatchModel.CountWatchedEpisodesAndSeasons(listID, seasons)
+}
+
+// incrementEpisodeWatches marks the next unwatched episode, or every episode of
+// the next unwatched season, and returns the derived counters.
+func incrementEpisodeWatches(
+	database *db.MongoDB, uid, listID, contentID, contentType string,
+	seasons []models.SeasonEpisodes, watchedEpisodes int, isEpisode bool,
+) (int, int, error) {
+	episodeWatchModel := models.NewEpisodeWatchModel(database)
+
+	if err := episodeWatchModel.ReconcileEpisodeWatches(
+		uid, listID, contentID, contentType, seasons, watchedEpisodes,
+	); err != nil {
+		return 0, 0, err
+	}
+
+	var keys []models.EpisodeKey
+	if isEpisode {
+		key, ok, err := episodeWatchModel.GetNextUnwatchedEpisode(listID, seasons)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if ok {
+			keys = append(keys, key)
+		}
+	} else {
+		seasonNumber, ok, err := episodeWatchModel.GetNextUnwatchedSeason(listID, seasons)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if ok {
+			if keys, err = models.SeasonEpisodeKeys(seasons, seasonNumber); err != nil {
+				return 0, 0, err
+			}
+		}
+	}
+
+	if err := episodeWatchModel.MarkEpisodesAsWatched(uid, listID, contentID, contentType, keys, nil); err != nil {
+		return 0, 0, err
+	}
+
+	return episodeWatchModel.CountWatchedEpisodesAndSeasons(listID, seasons)
+}
+
+// incrementTVSeriesList marks the episode of the key, or the next unwatched
+// episode or season if key is not set, and updates the counters of the list.
+func incrementTVSeriesList(
+	database *db.MongoDB, uid string, tvList models.TVSeriesWatchList, tvSeries responses.TVSeries,
+	isEpisode bool, key *models.EpisodeKey,
+) (models.TVSeriesWatchList, error) {
+	userListModel := models.NewUserListModel(database)
+
+	seasons := models.TVSeasonEpisodes(tvSeries)
+	if len(seasons) == 0 {
+		return userListModel.IncrementTVSeriesListEpisodeSeasonByID(tvList, tvSeries, requests.IncrementTVSeriesList{
+			ID:        tvList.ID.Hex(),
+			IsEpisode: &isEpisode,
+		})
+	}
+
+	var (
+		watchedEpisodes, watchedSeasons int
+		err                             error
+	)
+
+	// Counters are derived from the episode records when season information is available.
+	if key != nil && models.ValidateEpisodeKey(seasons, *key) == nil {
+		watchedEpisodes, watchedSeasons, err = applyEpisodeWatches(
+			database, uid, tvList.ID.Hex(), tvList.TvID, "tv",
+			seasons, tvList.WatchedEpisodes, []models.EpisodeKey{*key}, true, nil,
+		)
+	} else {
+		watchedEpisodes, watchedSeasons, err = incrementEpisodeWatches(
+			database, uid, tvList.ID.Hex(), tvList.TvID, "tv",
+			seasons, tvList.WatchedEpisodes, isEpisode,
+		)
+	}
+	if err != nil {
+		return models.TVSeriesWatchList{}, err
+	}
+
+	return userListModel.UpdateTVSeriesListWatchedCounters(tvList, tvSeries, watchedEpisodes, watchedSeasons)
+}
+
+func defaultSeasonNumber(seasonNumber int) int {
+	if seasonNumber == 0 {
+		return 1
+	}
+
+	return seasonNumber
+}
@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"app/db"
+	"app/models"
+	"app/requests"
+	"net/http"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+)
+
+type FileImportController struct {
+	Database *db.MongoDB
+}
+
+func NewFileImportController(mongoDB *db.MongoDB) FileImportController {
+	return FileImportController{
+		Database: mongoDB,
+	}
+}
+
+const (
+	errImportFileMissing  = "Please upload the export file."
+	errImportFileTooLarge = "File is too large, maximum size is 20MB."
+	importFileMaxSize     = 20 << 20
+)
+
+var fileImportSources = map[string]string{
+	"mal":        models.MALFileImportSource,
+	"letterboxd": models.LetterboxdImportSource,
+	"imdb":       models.IMDBFileImportSource,
+	"trakt":      models.TraktFileImportSource,
+	"backloggd":  models.BackloggdImportSource,
+	"hltb":       models.HLTBImportSource,
+	"gog":        models.GOGImportSource,
+}
+
+// Import From File
+// @Summary Import lists from an export file
+// @Description Imports the official export file of MyAnimeList (animelist.xml/mangalist.xml), Letterboxd (export zip or diary/ratings/watched/watchlist csv), IMDb (ratings.csv), Trakt (backup zip or json) or the game backlog csv of Backloggd, HowLongToBeat and GOG Galaxy
+// @Tags import
+// @Accept multipart/form-data
+// @Produce application/json
+// @Param source formData string true "Source" Enums(mal, letterboxd, imdb, trakt, backloggd, hltb, gog)
+// @Param file formData file true "Export file"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 202 {object} models.ImportJob
+// @Failure 400 {string} string
+// @Failure 409 {string} string
+// @Failure 413 {string} string
+// @Failure 500 {string} string
+// @Router /import/file [post]
+func (fi *FileImportController) ImportFromFile(c *gin.Context) {
+	var data requests.FileImportRequest
+	if err := c.ShouldBind(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": validatorErrorHandler(err),
+		})
+
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errImportFileMissing})
+		return
+	}
+
+	if fileHeader.Size > importFileMaxSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": errImportFileTooLarge})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errImportFileMissing})
+		return
+	}
+	defer file.Close()
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	importJobModel := models.NewImportJobModel(fi.Database)
+
+	fileID, err := importJobModel.SaveImportFile(uid, fileHeader.Filename, file)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	if isQueued := queueImportJob(c, fi.Database, uid, fileImportSources[data.Source], map[string]string{
+		"file_id":   fileID.Hex(),
+		"file_name": fileHeader.Filename,
+	}, data.ImportOptions); !isQueued {
+		importJobModel.DeleteImportFile(fileID.Hex())
+	}
+}
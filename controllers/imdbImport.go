@@ -60,8 +60,9 @@ func NewTraktImportController(mongoDB *db.MongoDB) TraktImportController {
 // @Param imdbimport body requests.IMDBImportRequest true "IMDB Import Request"
 // @Security BearerAuth
 // @Param Authorization header string true "Authentication header"
-// @Success 200 {object} responses.IMDBImportResponse
+// @Success 202 {object} models.ImportJob
 // @Failure 400 {string} string
+// @Failure 409 {string} string
 // @Failure 500 {string} string
 // @Router /import/imdb [post]
 func (c *IMDBImportController) ImportWatchlist(ctx *gin.Context) {
@@ -78,7 +79,6 @@ func (c *IMDBImportController) ImportWatchlist(ctx *gin.Context) {
 	}
 
 	uid := jwt.ExtractClaims(ctx)["id"].(string)
-	imdbImportModel := models.NewIMDBImportModel(c.Database)
 
 	logrus.WithFields(logrus.Fields{
 		"user_id":      uid,
@@ -86,30 +86,10 @@ func (c *IMDBImportController) ImportWatchlist(ctx *gin.Context) {
 		"imdb_list_id": req.IMDBListID,
 	}).Info("IMDB import request received")
 
-	result, err := imdbImportModel.ImportUserWatchlist(uid, req.IMDBUserID, req.IMDBListID)
-	if err != nil {
-		logrus.WithFields(logrus.Fields{
-			"user_id": uid,
-			"error":   err.Error(),
-		}).Error("IMDB import failed")
-
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
-		return
-	}
-
-	logrus.WithFields(logrus.Fields{
-		"user_id":        uid,
-		"imported_count": result.ImportedCount,
-		"skipped_count":  result.SkippedCount,
-		"error_count":    result.ErrorCount,
-	}).Info("IMDB import completed successfully")
-
-	ctx.JSON(http.StatusOK, gin.H{
-		"message": "IMDB import completed successfully.",
-		"data":    result,
-	})
+	queueImportJob(ctx, c.Database, uid, models.IMDBImportSource, map[string]string{
+		"imdb_user_id": req.IMDBUserID,
+		"imdb_list_id": req.IMDBListID,
+	}, req.ImportOptions)
 }
 
 // Import TMDB Watchlist
@@ -121,8 +101,9 @@ func (c *IMDBImportController) ImportWatchlist(ctx *gin.Context) {
 // @Param tmdbimport body requests.TMDBImportRequest true "TMDB Import Request"
 // @Security BearerAuth
 // @Param Authorization header string true "Authentication header"
-// @Success 200 {object} responses.TMDBImportResponse
+// @Success 202 {object} models.ImportJob
 // @Failure 400 {string} string
+// @Failure 409 {string} string
 // @Failure 500 {string} string
 // @Router /import/tmdb [post]
 func (c *TMDBImportController) ImportUserData(ctx *gin.Context) {
@@ -132,37 +113,15 @@ func (c *TMDBImportController) ImportUserData(ctx *gin.Context) {
 	}
 
 	uid := jwt.ExtractClaims(ctx)["id"].(string)
-	tmdbImportModel := models.NewTMDBImportModel(c.Database)
 
 	logrus.WithFields(logrus.Fields{
 		"user_id":       uid,
 		"tmdb_username": req.TMDBUsername,
 	}).Info("TMDB import request received")
 
-	result, err := tmdbImportModel.ImportUserData(uid, req.TMDBUsername)
-	if err != nil {
-		logrus.WithFields(logrus.Fields{
-			"user_id": uid,
-			"error":   err.Error(),
-		}).Error("TMDB import failed")
-
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
-		return
-	}
-
-	logrus.WithFields(logrus.Fields{
-		"user_id":        uid,
-		"imported_count": result.ImportedCount,
-		"skipped_count":  result.SkippedCount,
-		"error_count":    result.ErrorCount,
-	}).Info("TMDB import completed successfully")
-
-	ctx.JSON(http.StatusOK, gin.H{
-		"message": "TMDB import completed successfully.",
-		"data":    result,
-	})
+	queueImportJob(ctx, c.Database, uid, models.TMDBImportSource, map[string]string{
+		"tmdb_username": req.TMDBUsername,
+	}, req.ImportOptions)
 }
 
 // Import AniList Lists
@@ -174,8 +133,9 @@ func (c *TMDBImportController) ImportUserData(ctx *gin.Context) {
 // @Param anilistimport body requests.AniListImportRequest true "AniList Import Request"
 // @Security BearerAuth
 // @Param Authorization header string true "Authentication header"
-// @Success 200 {object} responses.AniListImportResponse
+// @Success 202 {object} models.ImportJob
 // @Failure 400 {string} string
+// @Failure 409 {string} string
 // @Failure 500 {string} string
 // @Router /import/anilist [post]
 func (c *AniListImportController) ImportUserLists(ctx *gin.Context) {
@@ -185,37 +145,15 @@ func (c *AniListImportController) ImportUserLists(ctx *gin.Context) {
 	}
 
 	uid := jwt.ExtractClaims(ctx)["id"].(string)
-	anilistImportModel := models.NewAniListImportModel(c.Database)
 
 	logrus.WithFields(logrus.Fields{
 		"user_id":          uid,
 		"anilist_username": req.AniListUsername,
 	}).Info("AniList import request received")
 
-	result, err := anilistImportModel.ImportUserLists(uid, req.AniListUsername)
-	if err != nil {
-		logrus.WithFields(logrus.Fields{
-			"user_id": uid,
-			"error":   err.Error(),
-		}).Error("AniList import failed")
-
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
-		return
-	}
-
-	logrus.WithFields(logrus.Fields{
-		"user_id":        uid,
-		"imported_count": result.ImportedCount,
-		"skipped_count":  result.SkippedCount,
-		"error_count":    result.ErrorCount,
-	}).Info("AniList import completed successfully")
-
-	ctx.JSON(http.StatusOK, gin.H{
-		"message": "AniList import completed successfully.",
-		"data":    result,
-	})
+	queueImportJob(ctx, c.Database, uid, models.AniListImportSource, map[string]string{
+		"anilist_username": req.AniListUsername,
+	}, req.ImportOptions)
 }
 
 // Import Trakt Data
@@ -227,8 +165,9 @@ func (c *AniListImportController) ImportUserLists(ctx *gin.Context) {
 // @Param traktimport body requests.TraktImportRequest true "Trakt Import Request"
 // @Security BearerAuth
 // @Param Authorization header string true "Authentication header"
-// @Success 200 {object} responses.TraktImportResponse
+// @Success 202 {object} models.ImportJob
 // @Failure 400 {string} string
+// @Failure 409 {string} string
 // @Failure 500 {string} string
 // @Router /import/trakt [post]
 func (c *TraktImportController) ImportUserData(ctx *gin.Context) {
@@ -238,35 +177,13 @@ func (c *TraktImportController) ImportUserData(ctx *gin.Context) {
 	}
 
 	uid := jwt.ExtractClaims(ctx)["id"].(string)
-	traktImportModel := models.NewTraktImportModel(c.Database)
 
 	logrus.WithFields(logrus.Fields{
 		"user_id":        uid,
 		"trakt_username": req.TraktUsername,
 	}).Info("Trakt import request received")
 
-	result, err := traktImportModel.ImportUserData(uid, req.TraktUsername)
-	if err != nil {
-		logrus.WithFields(logrus.Fields{
-			"user_id": uid,
-			"error":   err.Error(),
-		}).Error("Trakt import failed")
-
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
-		return
-	}
-
-	logrus.WithFields(logrus.Fields{
-		"user_id":        uid,
-		"imported_count": result.ImportedCount,
-		"skipped_count":  result.SkippedCount,
-		"error_count":    result.ErrorCount,
-	}).Info("Trakt import completed successfully")
-
-	ctx.JSON(http.StatusOK, gin.H{
-		"message": "Trakt import completed successfully.",
-		"data":    result,
-	})
+	queueImportJob(ctx, c.Database, uid, models.TraktImportSource, map[string]string{
+		"trakt_username": req.TraktUsername,
+	}, req.ImportOptions)
 }
@@ -0,0 +1,445 @@
+package controllers
+
+import (
+	"app/db"
+	"app/models"
+	"app/requests"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type ImportJobController struct {
+	Database *db.MongoDB
+}
+
+func NewImportJobController(mongoDB *db.MongoDB) ImportJobController {
+	return ImportJobController{
+		Database: mongoDB,
+	}
+}
+
+const (
+	errImportInProgress   = "You already have an import in progress from this source."
+	errImportNotPreviewed = "Import is not waiting for review, only previewed imports can be applied."
+	errImportNotCompleted = "Only completed or failed imports can be rolled back."
+)
+
+const (
+	importWorkerCount = 3
+	// Workers also poll for due jobs, e.g. retries and jobs of other instances.
+	importWorkerPollInterval = 15 * time.Second
+)
+
+// importJobQueue wakes the workers up when a job is created.
+var importJobQueue = make(chan struct{}, importWorkerCount)
+
+// Get Import Job
+// @Summary Get Import Job
+// @Description Returns status and progress of the import
+// @Tags import
+// @Accept application/json
+// @Produce application/json
+// @Param id path string true "Import Job ID"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {object} models.ImportJob
+// @Failure 403 {string} string "Unauthorized access"
+// @Failure 404 {string} string "Could not found"
+// @Failure 500 {string} string
+// @Router /import/jobs/{id} [get]
+func (ij *ImportJobController) GetImportJob(c *gin.Context) {
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	importJobModel := models.NewImportJobModel(ij.Database)
+
+	importJob, err := importJobModel.GetImportJobByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if importJob.UserID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": ErrNotFound})
+		return
+	}
+
+	if uid != importJob.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": ErrUnauthorized})
+		return
+	}
+
+	if err := importJobModel.GetImportPreviewItems(&importJob); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": importJob})
+}
+
+// Apply Import Job
+// @Summary Apply Import Preview
+// @Description Writes the previewed import, conflicts are resolved with the policy or the choices of the items
+// @Tags import
+// @Accept application/json
+// @Produce application/json
+// @Param id path string true "Import Job ID"
+// @Param applyimportjob body requests.ApplyImportJob true "Apply Import Job"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 202 {object} models.ImportJob
+// @Failure 400 {string} string
+// @Failure 403 {string} string "Unauthorized access"
+// @Failure 404 {string} string "Could not found"
+// @Failure 409 {string} string
+// @Failure 500 {string} string
+// @Router /import/jobs/{id}/apply [post]
+func (ij *ImportJobController) ApplyImportJob(c *gin.Context) {
+	var data requests.ApplyImportJob
+	if shouldReturn := bindJSONData(&data, c); shouldReturn {
+		return
+	}
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	importJobModel := models.NewImportJobModel(ij.Database)
+
+	importJob, err := importJobModel.GetImportJobByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if importJob.UserID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": ErrNotFound})
+		return
+	}
+
+	if uid != importJob.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": ErrUnauthorized})
+		return
+	}
+
+	if importJob.Status != models.PreviewedImportJobStatus {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errImportNotPreviewed})
+		return
+	}
+
+	policy := data.Policy
+	if policy == "" {
+		policy = models.KeepMineImportPolicy
+	}
+
+	choices := make(map[string]string, len(data.Choices))
+	for _, choice := range data.Choices {
+		choices[choice.Key] = choice.Choice
+	}
+
+	importJob, isApplied, err := importJobModel.ApplyImportJob(importJob, policy, choices)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !isApplied {
+		if activeImportJob, _ := importJobModel.GetActiveImportJob(uid, importJob.Source); activeImportJob.UserID != "" {
+			c.JSON(http.StatusConflict, gin.H{"error": errImportInProgress, "data": activeImportJob})
+			return
+		}
+
+		c.JSON(http.StatusConflict, gin.H{"error": errImportNotPreviewed})
+		return
+	}
+
+	wakeImportWorkers()
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Import started.", "data": importJob})
+}
+
+// Get Import History
+// @Summary Get Import History
+// @Description Returns the past imports of the user, latest first
+// @Tags import
+// @Accept application/json
+// @Produce application/json
+// @Param getimporthistory query requests.GetImportHistory true "Get Import History"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {array} models.ImportJob
+// @Failure 500 {string} string
+// @Router /import/history [get]
+func (ij *ImportJobController) GetImportHistory(c *gin.Context) {
+	var data requests.GetImportHistory
+	if err := c.ShouldBindQuery(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": validatorErrorHandler(err),
+		})
+
+		return
+	}
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	importJobModel := models.NewImportJobModel(ij.Database)
+
+	importJobs, pagination, err := importJobModel.GetImportJobs(uid, data)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pagination": pagination, "data": importJobs})
+}
+
+// Rollback Import Job
+// @Summary Rollback Import Job
+// @Description Deletes the entries that are added by the import and restores the entries it updated, failed imports roll back the entries they wrote before failing. Entries that are changed after the import are skipped
+// @Tags import
+// @Accept application/json
+// @Produce application/json
+// @Param id path string true "Import Job ID"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {object} models.ImportRollback
+// @Failure 400 {string} string
+// @Failure 403 {string} string "Unauthorized access"
+// @Failure 404 {string} string "Could not found"
+// @Failure 409 {string} string
+// @Failure 500 {string} string
+// @Router /import/jobs/{id}/rollback [post]
+func (ij *ImportJobController) RollbackImportJob(c *gin.Context) {
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	importJobModel := models.NewImportJobModel(ij.Database)
+
+	importJob, err := importJobModel.GetImportJobByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if importJob.UserID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": ErrNotFound})
+		return
+	}
+
+	if uid != importJob.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": ErrUnauthorized})
+		return
+	}
+
+	if importJob.Status != models.CompletedImportJobStatus && importJob.Status != models.FailedImportJobStatus {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errImportNotCompleted})
+		return
+	}
+
+	importHistoryModel := models.NewImportHistoryModel(ij.Database)
+
+	rollback, isRolledBack, err := importHistoryModel.RollbackImportJob(importJob)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !isRolledBack {
+		c.JSON(http.StatusConflict, gin.H{"error": errImportNotCompleted})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Import is rolled back.", "data": rollback})
+}
+
+// StartImportWorkers starts the workers that run the queued imports, jobs that
+// were interrupted by a restart are resumed by them.
+func StartImportWorkers(database *db.MongoDB) {
+	models.NewImportJobModel(database).CreateImportJobIndexes()
+
+	for i := 0; i < importWorkerCount; i++ {
+		go runImportWorker(database)
+	}
+}
+
+func runImportWorker(database *db.MongoDB) {
+	importJobModel := models.NewImportJobModel(database)
+
+	ticker := time.NewTicker(importWorkerPollInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			importJob, err := importJobModel.ClaimNextImportJob()
+			if err != nil || importJob.UserID == "" {
+				break
+			}
+
+			if importJob.Status == models.ProcessingImportJobStatus {
+				processImportJob(database, importJob)
+			}
+		}
+
+		select {
+		case <-importJobQueue:
+		case <-ticker.C:
+		}
+	}
+}
+
+func processImportJob(database *db.MongoDB, importJob models.ImportJob) {
+	importJobModel := models.NewImportJobModel(database)
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		ticker := time.NewTicker(models.ImportJobHeartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				importJobModel.UpdateImportJobHeartbeat(importJob.ID)
+			}
+		}
+	}()
+
+	result, err := runImportJob(database, importJob)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"import_job_id": importJob.ID,
+			"source":        importJob.Source,
+			"attempts":      importJob.Attempts,
+			"is_transient":  models.IsTransientImportError(err),
+		}).Error("import job failed: ", err)
+
+		// Entries that are written before the failure are kept in the history, so
+		// that the failed job can be rolled back and its retry sees them.
+		models.NewImportHistoryModel(database).SaveImportChanges(importJob.ID, result.Changes)
+
+		importJobModel.FailImportJob(importJob, err)
+		recordLinkedAccountSync(database, importJob, models.FailedImportJobStatus)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"import_job_id":  importJob.ID,
+		"source":         importJob.Source,
+		"imported_count": result.ImportedCount,
+		"updated_count":  result.UpdatedCount,
+		"skipped_count":  result.SkippedCount,
+		"limited_count":  result.LimitedCount,
+		"error_count":    result.ErrorCount,
+		"is_preview":     result.Preview != nil,
+	}).Info("import job completed")
+
+	// Changes are saved before the job is completed, so that it can be rolled back
+	// once it's completed.
+	models.NewImportHistoryModel(database).SaveImportChanges(importJob.ID, result.Changes)
+
+	importJobModel.CompleteImportJob(importJob, result)
+	recordLinkedAccountSync(database, importJob, models.CompletedImportJobStatus)
+
+	// Failing to keep the unmatched items doesn't fail the completed import.
+	models.NewUnmatchedImportModel(database).SaveUnmatchedItems(importJob.UserID, importJob.Source, importJob.ID, result.UnmatchedItems)
+}
+
+// runImportJob previews or writes the entries of the source, previewed jobs
+// are applied with their stored items without fetching the source again.
+func runImportJob(database *db.MongoDB, importJob models.ImportJob) (models.ImportJobResult, error) {
+	listImportModel := models.NewListImportModel(database)
+	uid := importJob.UserID
+
+	policy := importJob.Params["policy"]
+	if policy == "" {
+		policy = models.DefaultImportPolicy(importJob.Source)
+	}
+
+	if importJob.Preview != nil {
+		if err := models.NewImportJobModel(database).GetImportPreviewItems(&importJob); err != nil {
+			return models.ImportJobResult{}, err
+		}
+
+		return listImportModel.ApplyPreview(uid, importJob.Preview.Items, policy, importJob.Choices)
+	}
+
+	entries, err := fetchImportEntries(database, importJob)
+	if err != nil {
+		return models.ImportJobResult{}, err
+	}
+
+	if importJob.Params["dry_run"] == "true" {
+		preview, err := listImportModel.PreviewEntries(uid, entries)
+		return models.ImportJobResult{Preview: &preview}, err
+	}
+
+	return listImportModel.ApplyEntries(uid, entries, policy)
+}
+
+func fetchImportEntries(database *db.MongoDB, importJob models.ImportJob) ([]*models.ImportEntry, error) {
+	params := importJob.Params
+
+	switch importJob.Source {
+	case models.MALImportSource:
+		if params["type"] == "manga" {
+			return models.NewMALImportModel(database).FetchMangaListEntries(params["username"])
+		}
+
+		return models.NewMALImportModel(database).FetchAnimeListEntries(params["username"])
+	case models.SteamImportSource:
+		return models.NewSteamImportModel(database).FetchGameLibraryEntries(params["steam_id"])
+	case models.IMDBImportSource:
+		return models.NewIMDBImportModel(database).FetchWatchlistEntries(params["imdb_user_id"], params["imdb_list_id"])
+	case models.TMDBImportSource:
+		return models.NewTMDBImportModel(database).FetchUserEntries(params["tmdb_username"])
+	case models.AniListImportSource:
+		return models.NewAniListImportModel(database).FetchUserEntries(params["anilist_username"])
+	case models.TraktImportSource:
+		return models.NewTraktImportModel(database).FetchUserEntries(importJob.UserID, params["trakt_username"])
+	case models.MALFileImportSource, models.LetterboxdImportSource, models.IMDBFileImportSource, models.TraktFileImportSource,
+		models.BackloggdImportSource, models.HLTBImportSource, models.GOGImportSource:
+		return models.NewFileImportModel(database).FetchFileEntries(importJob.Source, params["file_id"], params["file_name"])
+	}
+
+	return nil, fmt.Errorf("Unknown import source.")
+}
+
+// queueImportJob creates the import job and responds with it, only one import
+// per source can be in progress.
+func queueImportJob(c *gin.Context, database *db.MongoDB, uid, source string, params map[string]string, options requests.ImportOptions) bool {
+	importJobModel := models.NewImportJobModel(database)
+
+	params["dry_run"] = strconv.FormatBool(options.DryRun)
+	params["policy"] = options.Policy
+
+	importJob, isCreated, err := importJobModel.CreateImportJob(uid, source, params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return false
+	}
+
+	if !isCreated {
+		c.JSON(http.StatusConflict, gin.H{"error": errImportInProgress, "data": importJob})
+		return false
+	}
+
+	wakeImportWorkers()
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Import started.", "data": importJob})
+
+	return true
+}
+
+func wakeImportWorkers() {
+	select {
+	case importJobQueue <- struct{}{}:
+	default:
+	}
+}
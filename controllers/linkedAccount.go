@@ -0,0 +1,274 @@
+package controllers
+
+import (
+	"app/db"
+	"app/models"
+	"app/requests"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type LinkedAccountController struct {
+	Database *db.MongoDB
+}
+
+func NewLinkedAccountController(mongoDB *db.MongoDB) LinkedAccountController {
+	return LinkedAccountController{
+		Database: mongoDB,
+	}
+}
+
+const errAccountNotLinked = "Account is not linked."
+
+// Due linked accounts are queued for sync with this interval.
+const linkedAccountSchedulerInterval = 5 * time.Minute
+
+var steamIDRegex = regexp.MustCompile(`^\d{17}$`)
+
+// Get Linked Accounts
+// @Summary Get Linked Accounts
+// @Description Returns the linked external accounts with their last sync
+// @Tags user
+// @Accept application/json
+// @Produce application/json
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {array} models.LinkedAccount
+// @Failure 500 {string} string
+// @Router /user/linked-accounts [get]
+func (la *LinkedAccountController) GetLinkedAccounts(c *gin.Context) {
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	linkedAccountModel := models.NewLinkedAccountModel(la.Database)
+
+	linkedAccounts, err := linkedAccountModel.GetLinkedAccounts(uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": linkedAccounts})
+}
+
+// Link Account
+// @Summary Link External Account
+// @Description Links Steam, Trakt or AniList account, the lists are synced periodically. Previously linked account of the source is replaced
+// @Tags user
+// @Accept application/json
+// @Produce application/json
+// @Param linkaccount body requests.LinkAccount true "Link Account"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 201 {object} models.LinkedAccount
+// @Failure 400 {string} string
+// @Failure 500 {string} string
+// @Router /user/linked-accounts [post]
+func (la *LinkedAccountController) LinkAccount(c *gin.Context) {
+	var data requests.LinkAccount
+	if shouldReturn := bindJSONData(&data, c); shouldReturn {
+		return
+	}
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	linkedAccountModel := models.NewLinkedAccountModel(la.Database)
+
+	accountID := data.AccountID
+	if data.Source == models.SteamImportSource && !steamIDRegex.MatchString(accountID) {
+		steamID, err := models.NewSteamImportModel(la.Database).ResolveSteamUsername(accountID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("Failed to resolve Steam username: %v", err),
+			})
+
+			return
+		}
+
+		accountID = steamID
+	}
+
+	var err error
+	switch data.Source {
+	case models.TraktImportSource:
+		accountID, err = models.NewTraktImportModel(la.Database).ResolveTraktUsername(uid, accountID)
+	case models.AniListImportSource:
+		accountID, err = models.NewAniListImportModel(la.Database).ResolveAniListUsername(accountID)
+	}
+
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("Failed to resolve %s username: %v", data.Source, err),
+		})
+
+		return
+	}
+
+	linkedAccount, err := linkedAccountModel.LinkAccount(uid, data.Source, accountID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Successfully linked.", "data": linkedAccount})
+}
+
+// Unlink Account
+// @Summary Unlink External Account
+// @Description Unlinks the account of the source, imported entries are kept
+// @Tags user
+// @Accept application/json
+// @Produce application/json
+// @Param unlinkaccount body requests.UnlinkAccount true "Unlink Account"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /user/linked-accounts [delete]
+func (la *LinkedAccountController) UnlinkAccount(c *gin.Context) {
+	var data requests.UnlinkAccount
+	if shouldReturn := bindJSONData(&data, c); shouldReturn {
+		return
+	}
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	linkedAccountModel := models.NewLinkedAccountModel(la.Database)
+
+	linkedAccount, err := linkedAccountModel.GetLinkedAccount(uid, data.Source)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	if linkedAccount.Source == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": errAccountNotLinked})
+		return
+	}
+
+	if err := linkedAccountModel.UnlinkAccount(uid, data.Source); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully unlinked."})
+}
+
+// Get Linked Account Syncs
+// @Summary Get Linked Account Sync History
+// @Description Returns the sync jobs of the linked account, the latest first
+// @Tags user
+// @Accept application/json
+// @Produce application/json
+// @Param getlinkedaccountsyncs query requests.GetLinkedAccountSyncs true "Get Linked Account Syncs"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {array} models.ImportJob
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /user/linked-accounts/history [get]
+func (la *LinkedAccountController) GetLinkedAccountSyncs(c *gin.Context) {
+	var data requests.GetLinkedAccountSyncs
+	if err := c.ShouldBindQuery(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": validatorErrorHandler(err),
+		})
+
+		return
+	}
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	linkedAccountModel := models.NewLinkedAccountModel(la.Database)
+
+	linkedAccount, err := linkedAccountModel.GetLinkedAccount(uid, data.Source)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	if linkedAccount.Source == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": errAccountNotLinked})
+		return
+	}
+
+	syncs, pagination, err := linkedAccountModel.GetLinkedAccountSyncs(uid, linkedAccount, data)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pagination": pagination, "data": syncs})
+}
+
+// StartLinkedAccountScheduler queues the sync of the linked accounts that are
+// due, the import workers run them.
+func StartLinkedAccountScheduler(database *db.MongoDB) {
+	go func() {
+		ticker := time.NewTicker(linkedAccountSchedulerInterval)
+		defer ticker.Stop()
+
+		for {
+			queueLinkedAccountSyncs(database)
+			<-ticker.C
+		}
+	}()
+}
+
+func queueLinkedAccountSyncs(database *db.MongoDB) {
+	linkedAccountModel := models.NewLinkedAccountModel(database)
+	importJobModel := models.NewImportJobModel(database)
+
+	for {
+		uid, linkedAccount, err := linkedAccountModel.ClaimNextLinkedAccount()
+		if err != nil || uid == "" {
+			return
+		}
+
+		// Syncs are skipped while an import of the same source is in progress.
+		if _, isCreated, err := importJobModel.CreateImportJob(
+			uid, linkedAccount.Source, linkedAccount.ImportParams(),
+		); err == nil && isCreated {
+			wakeImportWorkers()
+		} else if err == nil {
+			logrus.WithFields(logrus.Fields{
+				"uid":    uid,
+				"source": linkedAccount.Source,
+			}).Info("linked account sync skipped, import in progress")
+		}
+	}
+}
+
+// recordLinkedAccountSync keeps the result of the sync on the linked account.
+func recordLinkedAccountSync(database *db.MongoDB, importJob models.ImportJob, status string) {
+	if importJob.Params[models.LinkedAccountImportParam] != "true" {
+		return
+	}
+
+	models.NewLinkedAccountModel(database).UpdateLinkedAccountSync(
+		importJob.UserID,
+		importJob.Source,
+		importJob.Params[models.LinkedAccountImportParams[importJob.Source]],
+		status,
+	)
+}
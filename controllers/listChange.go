@@ -0,0 +1,356 @@
+package controllers
+
+import (
+	"app/db"
+	"app/models"
+	"app/requests"
+	"net/http"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+)
+
+type ListChangeController struct {
+	Database *db.MongoDB
+}
+
+func NewListChangeController(mongoDB *db.MongoDB) ListChangeController {
+	return ListChangeController{
+		Database: mongoDB,
+	}
+}
+
+const errListEntryExists = "Content is already in your list."
+
+// Get List Changes
+// @Summary Get List Changes
+// @Description Returns change history of a list entry, or of every entry if id is not set
+// @Tags user_list
+// @Accept application/json
+// @Produce application/json
+// @Param getlistchanges query requests.GetListChanges true "Get List Changes"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {array} models.ListChange
+// @Failure 500 {string} string
+// @Router /list/history [get]
+func (lc *ListChangeController) GetListChanges(c *gin.Context) {
+	var data requests.GetListChanges
+	if err := c.ShouldBindQuery(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": validatorErrorHandler(err),
+		})
+
+		return
+	}
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	listChangeModel := models.NewListChangeModel(lc.Database)
+
+	listChanges, pagination, err := listChangeModel.GetListChanges(uid, data)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pagination": pagination, "data": listChanges})
+}
+
+// Undo List Change
+// @Summary Undo List Change
+// @Description Restores the list entry to its state before the last change, deleted entries are restored
+// @Tags user_list
+// @Accept application/json
+// @Produce application/json
+// @Param undolistchange body requests.UndoListChange true "Undo List Change"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {object} models.ListChange
+// @Failure 403 {string} string
+// @Failure 404 {string} string "Could not found"
+// @Failure 409 {string} string
+// @Failure 500 {string} string
+// @Router /list/undo [post]
+func (lc *ListChangeController) UndoListChange(c *gin.Context) {
+	var data requests.UndoListChange
+	if shouldReturn := bindJSONData(&data, c); shouldReturn {
+		return
+	}
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+
+	listChangeModel := models.NewListChangeModel(lc.Database)
+	userListModel := models.NewUserListModel(lc.Database)
+
+	listChange, err := listChangeModel.GetLastListChange(uid, data.Type, data.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if listChange.UserID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": ErrNotFound})
+		return
+	}
+
+	switch listChange.Action {
+	case models.CreateListChangeAction:
+		isDeleted, err := userListModel.DeleteListByUserIDAndType(uid, requests.DeleteList{
+			ID:   data.ID,
+			Type: data.Type,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if isDeleted {
+			if data.Type == "anime" || data.Type == "tv" {
+				episodeWatchModel := models.NewEpisodeWatchModel(lc.Database)
+				go episodeWatchModel.DeleteEpisodeWatchesByListID(data.ID)
+			}
+
+			consumptionSessionModel := models.NewConsumptionSessionModel(lc.Database)
+			go consumptionSessionModel.DeleteConsumptionSessionsByListID(data.ID)
+		}
+	case models.UpdateListChangeAction:
+		if err := userListModel.RevertListEntryFields(uid, data.Type, data.ID, listChange.Changes); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := revertListChangeRecords(lc.Database, uid, listChange); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	case models.DeleteListChangeAction:
+		isInList, err := userListModel.IsContentInUserList(uid, data.Type, listChange.ContentID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if isInList {
+			c.JSON(http.StatusConflict, gin.H{"error": errListEntryExists})
+			return
+		}
+
+		userModel := models.NewUserModel(lc.Database)
+
+		isPremium, _ := userModel.IsUserPremium(uid)
+		count, _ := userListModel.GetUserListCount(uid)
+
+		if !isPremium && count >= models.UserListLimit {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": errUserListPremium,
+			})
+
+			return
+		}
+
+		if err := userListModel.RestoreListEntry(data.Type, listChange.Snapshot); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := restoreListChangeRecords(lc.Database, uid, listChange); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := listChangeModel.MarkListChangeAsUndone(listChange); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	listChange.IsUndone = true
+
+	c.JSON(http.StatusOK, gin.H{"message": "Change undone.", "data": listChange})
+}
+
+// deleteListRecords journals the deleted entry with its episode watches and
+// consumption sessions before deleting them, so that undo can restore them.
+func deleteListRecords(database *db.MongoDB, uid, contentType, listID string, list interface{}, isMovedToConsumeLater bool) {
+	episodeWatchModel := models.NewEpisodeWatchModel(database)
+	consumptionSessionModel := models.NewConsumptionSessionModel(database)
+	listChangeModel := models.NewListChangeModel(database)
+
+	var (
+		records models.ListRecords
+		err     error
+	)
+
+	if contentType == "anime" || contentType == "tv" {
+		if records.EpisodeWatches, err = episodeWatchModel.GetEpisodeWatchesByListID(listID); err != nil {
+			return
+		}
+	}
+
+	if records.ConsumptionSessions, err = consumptionSessionModel.GetConsumptionSessionsByListID(listID); err != nil {
+		return
+	}
+
+	if err := listChangeModel.CreateDeleteListChange(uid, contentType, list, records, isMovedToConsumeLater); err != nil {
+		return
+	}
+
+	if contentType == "anime" || contentType == "tv" {
+		episodeWatchModel.DeleteEpisodeWatchesByListID(listID)
+	}
+
+	consumptionSessionModel.DeleteConsumptionSessionsByListID(listID)
+}
+
+// restoreListChangeRecords restores the records deleted with the entry, entries
+// that were moved to consume later are removed from it.
+func restoreListChangeRecords(database *db.MongoDB, uid string, listChange models.ListChange) error {
+	if listChange.Records != nil {
+		episodeWatchModel := models.NewEpisodeWatchModel(database)
+		if err := episodeWatchModel.RestoreEpisodeWatches(listChange.ListID, listChange.Records.EpisodeWatches); err != nil {
+			return err
+		}
+
+		consumptionSessionModel := models.NewConsumptionSessionModel(database)
+		if err := consumptionSessionModel.RestoreConsumptionSessions(listChange.ListID, listChange.Records.ConsumptionSessions); err != nil {
+			return err
+		}
+	}
+
+	if listChange.IsMovedToConsumeLater {
+		userInteractionModel := models.NewUserInteractionModel(database)
+		return userInteractionModel.DeleteConsumeLaterByContentID(uid, listChange.ContentID, listChange.ContentType)
+	}
+
+	return nil
+}
+
+// revertListChangeRecords reverts what the status change did to the sessions, and
+// reconciles the episode records with the reverted watched episodes.
+func revertListChangeRecords(database *db.MongoDB, uid string, listChange models.ListChange) error {
+	var (
+		previousStatus, status string
+		isCountReverted        bool
+		isWatchedReverted      bool
+	)
+
+	for _, change := range listChange.Changes {
+		switch change.Field {
+		case "status":
+			previousStatus, _ = change.Before.(string)
+			status, _ = change.After.(string)
+		case "times_finished":
+			isCountReverted = true
+		case "watched_episodes":
+			isWatchedReverted = true
+		}
+	}
+
+	consumptionSessionModel := models.NewConsumptionSessionModel(database)
+
+	switch status {
+	case "active":
+		if err := consumptionSessionModel.DeleteOpenConsumptionSessions(listChange.ListID); err != nil {
+			return err
+		}
+	case "finished":
+		var err error
+		if previousStatus == "active" {
+			err = consumptionSessionModel.ReopenLatestConsumptionSession(listChange.ListID)
+		} else {
+			err = consumptionSessionModel.DeleteLatestFinishedConsumptionSession(listChange.ListID)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// The finish increments the counter after the change is journaled.
+		if !isCountReverted {
+			userListModel := models.NewUserListModel(database)
+			if err := userListModel.IncrementTimesFinishedByID(listChange.ContentType, listChange.ListID, -1); err != nil {
+				return err
+			}
+		}
+	}
+
+	if isWatchedReverted {
+		return reconcileListEpisodeWatches(database, uid, listChange.ContentType, listChange.ListID)
+	}
+
+	return nil
+}
+
+// reconcileListEpisodeWatches makes the episode records of the entry match its
+// watched episodes.
+func reconcileListEpisodeWatches(database *db.MongoDB, uid, contentType, listID string) error {
+	userListModel := models.NewUserListModel(database)
+	episodeWatchModel := models.NewEpisodeWatchModel(database)
+
+	switch contentType {
+	case "anime":
+		animeList, err := userListModel.GetBaseAnimeListByID(listID)
+		if err != nil || animeList.UserID == "" {
+			return err
+		}
+
+		animeModel := models.NewAnimeModel(database)
+		anime, _ := animeModel.GetAnimeDetails(requests.ID{
+			ID: animeList.AnimeID,
+		})
+
+		return episodeWatchModel.ReconcileEpisodeWatches(
+			uid, listID, animeList.AnimeID, contentType,
+			models.AnimeSeasonEpisodes(anime), int(animeList.WatchedEpisodes),
+		)
+	case "tv":
+		tvList, err := userListModel.GetBaseTVSeriesListByID(listID)
+		if err != nil || tvList.UserID == "" {
+			return err
+		}
+
+		tvSeriesModel := models.NewTVModel(database)
+		tvSeries, _ := tvSeriesModel.GetTVSeriesDetails(requests.ID{
+			ID: tvList.TvID,
+		})
+
+		return episodeWatchModel.ReconcileEpisodeWatches(
+			uid, listID, tvList.TvID, contentType,
+			models.TVSeasonEpisodes(tvSeries), tvList.WatchedEpisodes,
+		)
+	}
+
+	return nil
+}
+
+// recordListChange journals a list mutation so that it can be listed and undone,
+// movie and tv series changes are pushed to Trakt as well. Episode records are
+// reconciled when the watched episodes are changed.
+func recordListChange(database *db.MongoDB, uid, contentType, action string, before, after interface{}) {
+	listChangeModel := models.NewListChangeModel(database)
+	listChangeModel.CreateListChange(uid, contentType, action, before, after)
+
+	if listID, watchedEpisodes, ok := getListWatchedEpisodes(after); ok {
+		if _, previousWatchedEpisodes, _ := getListWatchedEpisodes(before); watchedEpisodes != previousWatchedEpisodes {
+			reconcileListEpisodeWatches(database, uid, contentType, listID)
+		}
+	}
+
+	pushTraktListChange(database, uid, contentType, after)
+}
+
+// getListWatchedEpisodes returns the list id and watched episodes of anime and
+// tv series entries.
+func getListWatchedEpisodes(entry interface{}) (string, int, bool) {
+	switch list := entry.(type) {
+	case models.AnimeList:
+		return list.ID.Hex(), int(list.WatchedEpisodes), true
+	case models.TVSeriesWatchList:
+		return list.ID.Hex(), list.WatchedEpisodes, true
+	}
+
+	return "", 0, false
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"app/db"
+	"app/models"
+	"app/requests"
+	"fmt"
+	"net/http"
+	"time"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+)
+
+type ListExportController struct {
+	Database *db.MongoDB
+}
+
+func NewListExportController(mongoDB *db.MongoDB) ListExportController {
+	return ListExportController{
+		Database: mongoDB,
+	}
+}
+
+// Export MAL List
+// @Summary Export MAL List
+// @Description Exports anime or manga list as MyAnimeList XML
+// @Tags export
+// @Accept application/json
+// @Produce application/xml
+// @Param exportmallist query requests.ExportMALList true "Export MAL List"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {file} file
+// @Failure 400 {string} string
+// @Failure 500 {string} string
+// @Router /export/mal [get]
+func (le *ListExportController) ExportMALList(c *gin.Context) {
+	var data requests.ExportMALList
+	if err := c.ShouldBindQuery(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": validatorErrorHandler(err),
+		})
+
+		return
+	}
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	listExportModel := models.NewListExportModel(le.Database)
+
+	var (
+		export []byte
+		err    error
+	)
+
+	if data.Type == "anime" {
+		export, err = listExportModel.ExportMALAnimeList(uid)
+	} else {
+		export, err = listExportModel.ExportMALMangaList(uid)
+	}
+
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	sendListExport(c, export, "application/xml", fmt.Sprintf("%s_list.xml", data.Type))
+}
+
+// Export Letterboxd List
+// @Summary Export Letterboxd List
+// @Description Exports finished or plan to watch movies as Letterboxd CSV
+// @Tags export
+// @Accept application/json
+// @Produce text/csv
+// @Param exportletterboxdlist query requests.ExportLetterboxdList true "Export Letterboxd List"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {file} file
+// @Failure 400 {string} string
+// @Failure 500 {string} string
+// @Router /export/letterboxd [get]
+func (le *ListExportController) ExportLetterboxdList(c *gin.Context) {
+	var data requests.ExportLetterboxdList
+	if err := c.ShouldBindQuery(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": validatorErrorHandler(err),
+		})
+
+		return
+	}
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	listExportModel := models.NewListExportModel(le.Database)
+
+	isWatchlist := data.List == "watchlist"
+
+	export, err := listExportModel.ExportLetterboxdMovieList(uid, isWatchlist)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	fileName := "letterboxd_watched.csv"
+	if isWatchlist {
+		fileName = "letterboxd_watchlist.csv"
+	}
+
+	sendListExport(c, export, "text/csv", fileName)
+}
+
+// Export Trakt List
+// @Summary Export Trakt List
+// @Description Exports movie and tv series lists as Trakt sync JSON
+// @Tags export
+// @Accept application/json
+// @Produce application/json
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {object} responses.TraktExport
+// @Failure 500 {string} string
+// @Router /export/trakt [get]
+func (le *ListExportController) ExportTraktList(c *gin.Context) {
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	listExportModel := models.NewListExportModel(le.Database)
+
+	export, err := listExportModel.ExportTraktList(uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	sendListExport(c, export, "application/json", "trakt.json")
+}
+
+func sendListExport(c *gin.Context, export []byte, contentType, fileName string) {
+	c.Header("Content-Disposition", fmt.Sprintf(
+		"attachment; filename=\"watchlistfy_%s_%s\"", time.Now().UTC().Format("2006-01-02"), fileName,
+	))
+	c.Data(http.StatusOK, contentType, export)
+}
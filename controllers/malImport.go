@@ -4,7 +4,6 @@ import (
 	"app/db"
 	"app/models"
 	"app/requests"
-	"net/http"
 
 	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
@@ -21,16 +20,17 @@ func NewMALImportController(mongoDB *db.MongoDB) MALImportController {
 }
 
 // Import MyAnimeList
-// @Summary Import anime list from MyAnimeList
-// @Description Imports user's anime list from MyAnimeList using their username
+// @Summary Import anime or manga list from MyAnimeList
+// @Description Imports user's anime or manga list from MyAnimeList using their username
 // @Tags import
 // @Accept application/json
 // @Produce application/json
 // @Param malimport body requests.MALImportRequest true "MAL Import Request"
 // @Security BearerAuth
 // @Param Authorization header string true "Authentication header"
-// @Success 200 {object} responses.MALImportResponse
+// @Success 202 {object} models.ImportJob
 // @Failure 400 {string} string
+// @Failure 409 {string} string
 // @Failure 500 {string} string
 // @Router /import/mal [post]
 func (m *MALImportController) ImportFromMAL(c *gin.Context) {
@@ -40,18 +40,9 @@ func (m *MALImportController) ImportFromMAL(c *gin.Context) {
 	}
 
 	uid := jwt.ExtractClaims(c)["id"].(string)
-	malImportModel := models.NewMALImportModel(m.Database)
 
-	result, err := malImportModel.ImportUserAnimeList(uid, data.Username)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"message": "MyAnimeList import completed successfully.",
-		"data":    result,
-	})
+	queueImportJob(c, m.Database, uid, models.MALImportSource, map[string]string{
+		"username": data.Username,
+		"type":     data.Type,
+	}, data.ImportOptions)
 }
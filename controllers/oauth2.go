@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"app/db"
+	"app/helpers"
 	"app/models"
 	"app/requests"
 	"app/responses"
@@ -14,16 +15,19 @@ import (
 	"github.com/Timothylock/go-signin-with-apple/apple"
 	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 )
 
 type OAuth2Controller struct {
-	Database *db.MongoDB
+	Database    *db.MongoDB
+	RedisClient *redis.Client
 }
 
-func NewOAuth2Controller(mongoDB *db.MongoDB) OAuth2Controller {
+func NewOAuth2Controller(mongoDB *db.MongoDB, redisClient *redis.Client) OAuth2Controller {
 	return OAuth2Controller{
-		Database: mongoDB,
+		Database:    mongoDB,
+		RedisClient: redisClient,
 	}
 }
 
@@ -33,8 +37,6 @@ var (
 	errWrongLoginMethod = "Failed to login. This email is already registered with different login method."
 )
 
-const tokenExpiration = 259200
-
 // OAuth2 Google Login
 // @Summary OAuth2 Google Login
 // @Description Gets user info from google and creates/finds user and returns token
@@ -113,14 +115,16 @@ func (o *OAuth2Controller) OAuth2GoogleLogin(jwt *jwt.GinJWTMiddleware) gin.Hand
 			}
 		}
 
-		token, _, err := jwt.TokenGenerator(user)
+		sessionModel := models.NewSessionModel(o.Database, o.RedisClient)
+
+		token, refreshToken, err := helpers.IssueSessionTokens(c, jwt, sessionModel, user.ID.Hex(), data.DeviceName, data.FCMToken)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		c.SetCookie("jwt", token, tokenExpiration, "/", os.Getenv("BASE_URI"), true, true)
-		c.JSON(http.StatusOK, gin.H{"access_token": token})
+		helpers.SetSessionCookies(c, token, refreshToken)
+		c.JSON(http.StatusOK, gin.H{"access_token": token, "refresh_token": refreshToken})
 	}
 }
 
@@ -191,14 +195,16 @@ func (o *OAuth2Controller) OAuth2AppleLogin(jwt *jwt.GinJWTMiddleware) gin.Handl
 				return
 			}
 
-			token, _, err := jwt.TokenGenerator(user)
+			sessionModel := models.NewSessionModel(o.Database, o.RedisClient)
+
+			token, refreshToken, err := helpers.IssueSessionTokens(c, jwt, sessionModel, user.ID.Hex(), data.DeviceName, data.FCMToken)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 				return
 			}
 
-			c.SetCookie("jwt", token, tokenExpiration, "/", os.Getenv("BASE_URI"), true, true)
-			c.JSON(http.StatusOK, gin.H{"access_token": token})
+			helpers.SetSessionCookies(c, token, refreshToken)
+			c.JSON(http.StatusOK, gin.H{"access_token": token, "refresh_token": refreshToken})
 
 			return
 		} else {
@@ -256,14 +262,17 @@ func (o *OAuth2Controller) OAuth2AppleLogin(jwt *jwt.GinJWTMiddleware) gin.Handl
 				return
 			}
 
-			token, _, err := jwt.TokenGenerator(user)
+			sessionModel := models.NewSessionModel(o.Database, o.RedisClient)
+
+			token, refreshToken, err := helpers.IssueSessionTokens(c, jwt, sessionModel, user.ID.Hex(), data.DeviceName, data.FCMToken)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 				return
 			}
 
-			c.SetCookie("jwt", token, tokenExpiration, "/", os.Getenv("BASE_URI"), true, true)
-			c.JSON(http.StatusOK, gin.H{"access_token": token, "refresh_token": resp.RefreshToken})
+			// Apple refresh token is used to login again with Apple.
+			helpers.SetSessionCookies(c, token, refreshToken)
+			c.JSON(http.StatusOK, gin.H{"access_token": token, "refresh_token": refreshToken, "apple_refresh_token": resp.RefreshToken})
 		}
 	}
 }
@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"app/db"
+	"app/models"
+	"app/requests"
+	"crypto/subtle"
+	"io"
+	"net/http"
+	"os"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+)
+
+type PurchaseController struct {
+	Database *db.MongoDB
+}
+
+func NewPurchaseController(mongoDB *db.MongoDB) PurchaseController {
+	return PurchaseController{
+		Database: mongoDB,
+	}
+}
+
+const (
+	errPurchaseOwned          = "Purchase belongs to another account."
+	errInvalidWebhookToken    = "Invalid webhook token."
+	purchaseWebhookTokenQuery = "token"
+)
+
+// CreatePurchaseIndexes creates the index that keeps a purchase with a single
+// user, concurrent verifications of the same purchase can't both succeed.
+func CreatePurchaseIndexes(database *db.MongoDB) {
+	models.NewEntitlementModel(database).CreateEntitlementIndexes()
+}
+
+// Get Membership
+// @Summary Get Membership
+// @Description Returns the membership of the user with the verified purchases
+// @Tags user
+// @Accept application/json
+// @Produce application/json
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {array} models.Entitlement
+// @Failure 500 {string} string
+// @Router /user/membership [get]
+func (pc *PurchaseController) GetMembership(c *gin.Context) {
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	userModel := models.NewUserModel(pc.Database)
+	entitlementModel := models.NewEntitlementModel(pc.Database)
+
+	entitlements, err := entitlementModel.GetEntitlementsByUserID(uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	isPremium, membershipType := userModel.IsUserPremium(uid)
+
+	c.JSON(http.StatusOK, gin.H{
+		"is_premium":      isPremium,
+		"membership_type": membershipType,
+		"data":            entitlements,
+	})
+}
+
+// Verify Purchase
+// @Summary Verify Purchase
+// @Description Verifies the App Store receipt or Play Store purchase token, membership is granted while the purchase is active
+// @Tags user
+// @Accept application/json
+// @Produce application/json
+// @Param verifypurchase body requests.VerifyPurchase true "Verify Purchase"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {object} models.Entitlement
+// @Failure 400 {string} string
+// @Failure 409 {string} string "Purchase belongs to another account"
+// @Failure 500 {string} string
+// @Router /user/membership [post]
+func (pc *PurchaseController) VerifyPurchase(c *gin.Context) {
+	var data requests.VerifyPurchase
+	if shouldReturn := bindJSONData(&data, c); shouldReturn {
+		return
+	}
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	entitlementModel := models.NewEntitlementModel(pc.Database)
+
+	entitlement, isOwned, err := entitlementModel.VerifyPurchase(uid, data)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if models.IsInvalidPurchaseError(err) {
+			status = http.StatusBadRequest
+		}
+
+		c.JSON(status, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	if !isOwned {
+		c.JSON(http.StatusConflict, gin.H{"error": errPurchaseOwned})
+		return
+	}
+
+	userModel := models.NewUserModel(pc.Database)
+	isPremium, membershipType := userModel.IsUserPremium(uid)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Successfully verified.",
+		"is_premium":      isPremium,
+		"membership_type": membershipType,
+		"data":            entitlement,
+	})
+}
+
+// App Store Notification
+// @Summary App Store Server Notification
+// @Description Renewals, expirations and refunds of the App Store, the purchase is verified again
+// @Tags purchase
+// @Accept application/json
+// @Produce application/json
+// @Param token query string true "Webhook Token"
+// @Success 200 {string} string
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 500 {string} string
+// @Router /purchase/app-store [post]
+func (pc *PurchaseController) AppStoreNotification(c *gin.Context) {
+	pc.handleStoreNotification(c, models.AppStorePurchaseStore, models.ParseAppStoreNotification)
+}
+
+// Play Store Notification
+// @Summary Play Store Real-time Developer Notification
+// @Description Renewals, expirations and refunds of the Play Store pushed by Pub/Sub, the purchase is verified again
+// @Tags purchase
+// @Accept application/json
+// @Produce application/json
+// @Param token query string true "Webhook Token"
+// @Success 200 {string} string
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 500 {string} string
+// @Router /purchase/play-store [post]
+func (pc *PurchaseController) PlayStoreNotification(c *gin.Context) {
+	pc.handleStoreNotification(c, models.PlayStorePurchaseStore, models.ParsePlayStoreNotification)
+}
+
+// handleStoreNotification refreshes the entitlements of the notified purchase.
+// Stores retry the notifications until they're answered with 200.
+func (pc *PurchaseController) handleStoreNotification(c *gin.Context, store string, parse func([]byte) (string, error)) {
+	webhookToken := os.Getenv("PURCHASE_WEBHOOK_TOKEN")
+	if webhookToken == "" || subtle.ConstantTimeCompare([]byte(c.Query(purchaseWebhookTokenQuery)), []byte(webhookToken)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errInvalidWebhookToken})
+		return
+	}
+
+	payload, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	transactionID, err := parse(payload)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if transactionID == "" {
+		c.JSON(http.StatusOK, gin.H{"message": "Notification is ignored."})
+		return
+	}
+
+	entitlementModel := models.NewEntitlementModel(pc.Database)
+
+	userIDs, err := entitlementModel.RefreshEntitlements(store, transactionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	userModel := models.NewUserModel(pc.Database)
+	for _, uid := range userIDs {
+		userModel.IsUserPremium(uid)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notification is received."})
+}
@@ -43,6 +43,11 @@ func (rn *RecommendationController) CreateRecommendation(c *gin.Context) {
 		return
 	}
 
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	if shouldReturn := shouldRestrictUnverified(rn.Database, uid, c); shouldReturn {
+		return
+	}
+
 	var (
 		contentTitleEn       string
 		contentTitleOriginal string
@@ -232,7 +237,6 @@ func (rn *RecommendationController) CreateRecommendation(c *gin.Context) {
 		recommendationImage = recommendationTVSeries.ImageURL
 	}
 
-	uid := jwt.ExtractClaims(c)["id"].(string)
 	recommendationModel := models.NewRecommendationModel(rn.Database)
 	userModel := models.NewUserModel(rn.Database)
 
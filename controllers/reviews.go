@@ -40,6 +40,11 @@ func (r *ReviewController) CreateReview(c *gin.Context) {
 		return
 	}
 
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	if shouldReturn := shouldRestrictUnverified(r.Database, uid, c); shouldReturn {
+		return
+	}
+
 	var (
 		contentTitle string
 		contentImage string
@@ -132,7 +137,6 @@ func (r *ReviewController) CreateReview(c *gin.Context) {
 		contentImage = tvSeries.ImageURL
 	}
 
-	uid := jwt.ExtractClaims(c)["id"].(string)
 	reviewModel := models.NewReviewModel(r.Database)
 	userModel := models.NewUserModel(r.Database)
 
@@ -0,0 +1,267 @@
+package controllers
+
+import (
+	"app/db"
+	"app/models"
+	"app/requests"
+	"app/responses"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+)
+
+type ScrobbleController struct {
+	Database *db.MongoDB
+}
+
+func NewScrobbleController(mongoDB *db.MongoDB) ScrobbleController {
+	return ScrobbleController{
+		Database: mongoDB,
+	}
+}
+
+const (
+	errInvalidScrobbleToken = "Invalid scrobble token."
+	errScrobbleNotFound     = "Played item is not found."
+)
+
+// Create Scrobble Token
+// @Summary Create Scrobble Token
+// @Description Generates the webhook urls of Plex, Jellyfin and Emby, previous urls stop working
+// @Tags scrobble
+// @Accept application/json
+// @Produce application/json
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 201 {object} responses.ScrobbleWebhooks
+// @Failure 500 {string} string
+// @Router /scrobble/token [post]
+func (s *ScrobbleController) CreateScrobbleToken(c *gin.Context) {
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	scrobbleModel := models.NewScrobbleModel(s.Database)
+
+	token, err := scrobbleModel.CreateScrobbleToken(uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	webhookURL := func(source string) string {
+		return os.Getenv("BASE_URI") + "api/v1/scrobble/" + source + "?token=" + url.QueryEscape(token)
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Successfully created.", "data": responses.ScrobbleWebhooks{
+		Token:       token,
+		PlexURL:     webhookURL(models.PlexScrobbleSource),
+		JellyfinURL: webhookURL(models.JellyfinScrobbleSource),
+		EmbyURL:     webhookURL(models.EmbyScrobbleSource),
+	}})
+}
+
+// Scrobble
+// @Summary Media Server Webhook
+// @Description Marks the played movie as finished or the played episode as watched. Plex sends multipart payload, Jellyfin and Emby send JSON
+// @Tags scrobble
+// @Accept application/json
+// @Produce application/json
+// @Param source path string true "plex, jellyfin or emby"
+// @Param token query string true "Scrobble Token"
+// @Success 200 {string} string
+// @Failure 400 {string} string
+// @Failure 401 {string} string
+// @Failure 403 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /scrobble/{source} [post]
+func (s *ScrobbleController) Scrobble(c *gin.Context) {
+	source := c.Param("source")
+	if source != models.PlexScrobbleSource && source != models.JellyfinScrobbleSource && source != models.EmbyScrobbleSource {
+		c.JSON(http.StatusNotFound, gin.H{"error": ErrNotFound})
+		return
+	}
+
+	scrobbleModel := models.NewScrobbleModel(s.Database)
+
+	uid, err := scrobbleModel.GetUserIDByScrobbleToken(c.Query("token"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if uid == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errInvalidScrobbleToken})
+		return
+	}
+
+	var payload []byte
+	switch {
+	case source == models.PlexScrobbleSource:
+		payload = []byte(c.PostForm("payload"))
+	case c.ContentType() == "multipart/form-data" || c.ContentType() == "application/x-www-form-urlencoded":
+		payload = []byte(c.PostForm("data"))
+	default:
+		if payload, err = io.ReadAll(c.Request.Body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	scrobble, err := models.ParseScrobble(source, payload)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Media servers send every playback event, only finished ones are scrobbled.
+	if !scrobble.IsFinished {
+		c.JSON(http.StatusOK, gin.H{"message": "Event is ignored."})
+		return
+	}
+
+	contentID, err := scrobbleModel.ResolveScrobbleContent(scrobble)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if contentID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": errScrobbleNotFound})
+		return
+	}
+
+	if scrobble.ContentType == "movie" {
+		s.scrobbleMovie(c, uid, contentID, scrobble)
+		return
+	}
+
+	s.scrobbleEpisode(c, uid, contentID, scrobble)
+}
+
+// scrobbleMovie adds the movie as finished, or finishes the entry in the list.
+func (s *ScrobbleController) scrobbleMovie(c *gin.Context, uid, contentID string, scrobble models.Scrobble) {
+	now := time.Now().UTC()
+
+	result, isLimited, err := s.writeScrobbleEntry(uid, contentID, models.ImportEntry{
+		ContentType: "movie",
+		Title:       scrobble.Title,
+		Status:      "finished",
+		FinishedAt:  &now,
+		UpdatedAt:   &now,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if isLimited {
+		c.JSON(http.StatusForbidden, gin.H{"error": errUserListPremium})
+		return
+	}
+
+	if result.ImportedCount == 0 && result.UpdatedCount == 0 {
+		c.JSON(http.StatusOK, gin.H{"message": "Movie is already finished."})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Movie is finished."})
+}
+
+// scrobbleEpisode marks the episode as watched the same way the tv series list
+// is incremented, series that are not in the list are added first.
+func (s *ScrobbleController) scrobbleEpisode(c *gin.Context, uid, contentID string, scrobble models.Scrobble) {
+	userListModel := models.NewUserListModel(s.Database)
+
+	tvList := userListModel.GetTVSeriesListByUserIdAndTVId(uid, contentID)
+	if tvList.UserID == "" {
+		now := time.Now().UTC()
+
+		_, isLimited, err := s.writeScrobbleEntry(uid, contentID, models.ImportEntry{
+			ContentType: "tv",
+			Title:       scrobble.Title,
+			Status:      "active",
+			StartedAt:   &now,
+			UpdatedAt:   &now,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if isLimited {
+			c.JSON(http.StatusForbidden, gin.H{"error": errUserListPremium})
+			return
+		}
+
+		if tvList = userListModel.GetTVSeriesListByUserIdAndTVId(uid, contentID); tvList.UserID == "" {
+			c.JSON(http.StatusNotFound, gin.H{"error": ErrNotFound})
+			return
+		}
+	}
+
+	tvSeriesModel := models.NewTVModel(s.Database)
+	tvSeries, _ := tvSeriesModel.GetTVSeriesDetails(requests.ID{
+		ID: tvList.TvID,
+	})
+
+	var key *models.EpisodeKey
+	if scrobble.SeasonNumber > 0 && scrobble.EpisodeNumber > 0 {
+		key = &models.EpisodeKey{
+			SeasonNumber:  scrobble.SeasonNumber,
+			EpisodeNumber: scrobble.EpisodeNumber,
+		}
+	}
+
+	updatedTVList, err := incrementTVSeriesList(s.Database, uid, tvList, tvSeries, true, key)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	logModel := models.NewLogsModel(s.Database)
+
+	go logModel.CreateLog(uid, requests.CreateLog{
+		LogType:          models.UserListLogType,
+		LogAction:        models.UpdateLogAction,
+		LogActionDetails: updatedTVList.Status,
+		ContentTitle:     tvSeries.TitleEn,
+		ContentImage:     tvSeries.ImageURL,
+		ContentType:      "tv",
+		ContentID:        updatedTVList.TvID,
+	})
+
+	go recordListChange(s.Database, uid, "tv", models.UpdateListChangeAction, tvList, updatedTVList)
+
+	go recordConsumptionSession(
+		s.Database, uid, "tv", tvList.ID.Hex(),
+		tvList.Status, updatedTVList.Status, tvList.TimesFinished, updatedTVList.Score,
+	)
+
+	if tvList.Status != "finished" && updatedTVList.Status == "finished" {
+		achievementModel := models.NewAchievementModel(s.Database)
+		achievementModel.CheckAndUnlockAchievements(uid, "tv_finished")
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Episode is watched."})
+}
+
+// writeScrobbleEntry writes the entry with the list writer, so that the list
+// limit, logs and achievements are the same as imports.
+func (s *ScrobbleController) writeScrobbleEntry(uid, contentID string, entry models.ImportEntry) (models.ImportJobResult, bool, error) {
+	listImportModel := models.NewListImportModel(s.Database)
+
+	result, err := listImportModel.ApplyPreview(uid, []models.ImportItem{{
+		Action:    models.NewImportItemAction,
+		ContentID: contentID,
+		Entry:     entry,
+	}}, models.TakeTheirsImportPolicy, nil)
+
+	return result, result.LimitedCount > 0, err
+}
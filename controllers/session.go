@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"app/db"
+	"app/helpers"
+	"app/models"
+	"app/requests"
+	"net/http"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+type SessionController struct {
+	Database    *db.MongoDB
+	RedisClient *redis.Client
+}
+
+func NewSessionController(mongoDB *db.MongoDB, redisClient *redis.Client) SessionController {
+	return SessionController{
+		Database:    mongoDB,
+		RedisClient: redisClient,
+	}
+}
+
+const errInvalidRefreshToken = "Session is ended, please login again."
+
+// Refresh Session
+// @Summary Refresh Session
+// @Description Returns a new access token with a new refresh token, refresh token can only be used once
+// @Tags auth
+// @Accept application/json
+// @Produce application/json
+// @Param refreshsession body requests.RefreshSession true "Refresh Session"
+// @Success 200 {string} string "Token"
+// @Failure 401 {string} string
+// @Failure 500 {string} string
+// @Router /auth/refresh [post]
+// @Router /auth/refresh [get]
+func (s *SessionController) RefreshSession(jwt *jwt.GinJWTMiddleware) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var data requests.RefreshSession
+		if c.Request.ContentLength != 0 {
+			if shouldReturn := bindJSONData(&data, c); shouldReturn {
+				return
+			}
+		}
+
+		refreshToken := data.RefreshToken
+		if refreshToken == "" {
+			refreshToken = helpers.GetRefreshTokenCookie(c)
+		}
+
+		if refreshToken == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": errInvalidRefreshToken})
+			return
+		}
+
+		sessionModel := models.NewSessionModel(s.Database, s.RedisClient)
+
+		token, newRefreshToken, err := helpers.RefreshSessionTokens(c, jwt, sessionModel, refreshToken)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		if token == "" {
+			helpers.ClearSessionCookies(c)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": errInvalidRefreshToken})
+			return
+		}
+
+		helpers.SetSessionCookies(c, token, newRefreshToken)
+		c.JSON(http.StatusOK, gin.H{"access_token": token, "refresh_token": newRefreshToken})
+	}
+}
+
+// Logout
+// @Summary Logout
+// @Description Ends the session of the access token
+// @Tags auth
+// @Accept application/json
+// @Produce application/json
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {string} string
+// @Failure 500 {string} string
+// @Router /auth/logout [post]
+func (s *SessionController) Logout(jwt *jwt.GinJWTMiddleware) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if sessionID := helpers.GetLogoutSessionID(c, jwt); sessionID != "" {
+			sessionModel := models.NewSessionModel(s.Database, s.RedisClient)
+
+			session, err := sessionModel.GetSessionByID(sessionID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+
+			if session.UserID != "" && session.RevokedAt == nil {
+				if err := sessionModel.RevokeSession(session); err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+					return
+				}
+			}
+		}
+
+		helpers.ClearSessionCookies(c)
+		c.JSON(http.StatusOK, gin.H{"message": "Successfully logged out."})
+	}
+}
+
+// Get Sessions
+// @Summary Get Sessions
+// @Description Returns the active sessions of the user, latest seen first
+// @Tags user
+// @Accept application/json
+// @Produce application/json
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {array} models.Session
+// @Failure 500 {string} string
+// @Router /user/sessions [get]
+func (s *SessionController) GetSessions(c *gin.Context) {
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	sessionModel := models.NewSessionModel(s.Database, s.RedisClient)
+
+	sessions, err := sessionModel.GetActiveSessions(uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	sessionID := helpers.GetSessionID(c)
+	for index := range sessions {
+		sessions[index].IsCurrent = sessions[index].ID.Hex() == sessionID
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": sessions})
+}
+
+// Revoke Session
+// @Summary Revoke Session
+// @Description Ends the session, its device has to login again
+// @Tags user
+// @Accept application/json
+// @Produce application/json
+// @Param id path string true "Session ID"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {string} string
+// @Failure 403 {string} string "Unauthorized access"
+// @Failure 404 {string} string "Could not found"
+// @Failure 500 {string} string
+// @Router /user/sessions/{id} [delete]
+func (s *SessionController) RevokeSession(c *gin.Context) {
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	sessionModel := models.NewSessionModel(s.Database, s.RedisClient)
+
+	session, err := sessionModel.GetSessionByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if session.UserID == "" || session.RevokedAt != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": ErrNotFound})
+		return
+	}
+
+	if uid != session.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": ErrUnauthorized})
+		return
+	}
+
+	if err := sessionModel.RevokeSession(session); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully revoked."})
+}
+
+// Revoke All Sessions
+// @Summary Revoke All Sessions
+// @Description Ends every session of the user including the current one
+// @Tags user
+// @Accept application/json
+// @Produce application/json
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {string} string
+// @Failure 500 {string} string
+// @Router /user/sessions [delete]
+func (s *SessionController) RevokeAllSessions(c *gin.Context) {
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	sessionModel := models.NewSessionModel(s.Database, s.RedisClient)
+
+	if err := sessionModel.RevokeUserSessions(uid, ""); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	helpers.ClearSessionCookies(c)
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully revoked."})
+}
@@ -30,8 +30,9 @@ func NewSteamImportController(mongoDB *db.MongoDB) SteamImportController {
 // @Param steamimport body requests.SteamImportRequest true "Steam Import Request"
 // @Security BearerAuth
 // @Param Authorization header string true "Authentication header"
-// @Success 200 {object} responses.SteamImportResponse
+// @Success 202 {object} models.ImportJob
 // @Failure 400 {string} string
+// @Failure 409 {string} string
 // @Failure 500 {string} string
 // @Router /import/steam [post]
 func (s *SteamImportController) ImportFromSteam(c *gin.Context) {
@@ -73,16 +74,7 @@ func (s *SteamImportController) ImportFromSteam(c *gin.Context) {
 		steamID = data.SteamID
 	}
 
-	result, err := steamImportModel.ImportUserGameLibrary(uid, steamID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Steam import completed successfully.",
-		"data":    result,
-	})
+	queueImportJob(c, s.Database, uid, models.SteamImportSource, map[string]string{
+		"steam_id": steamID,
+	}, data.ImportOptions)
 }
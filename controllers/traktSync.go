@@ -0,0 +1,209 @@
+package controllers
+
+import (
+	"app/db"
+	"app/models"
+	"net/http"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+)
+
+type TraktSyncController struct {
+	Database *db.MongoDB
+}
+
+func NewTraktSyncController(mongoDB *db.MongoDB) TraktSyncController {
+	return TraktSyncController{
+		Database: mongoDB,
+	}
+}
+
+const (
+	errTraktDeviceExpired = "Device code is expired, please request a new one."
+	errTraktDeviceDenied  = "Authorization is denied."
+)
+
+// Get Trakt Connection
+// @Summary Get Trakt Connection
+// @Description Returns the connected Trakt account, connection is null if not connected
+// @Tags user
+// @Accept application/json
+// @Produce application/json
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {object} models.TraktConnection
+// @Failure 500 {string} string
+// @Router /user/trakt [get]
+func (ts *TraktSyncController) GetTraktConnection(c *gin.Context) {
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	traktSyncModel := models.NewTraktSyncModel(ts.Database)
+
+	connection, err := traktSyncModel.GetTraktConnection(uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	if !connection.IsConnected() {
+		c.JSON(http.StatusOK, gin.H{"data": nil})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": connection})
+}
+
+// Request Trakt Device Code
+// @Summary Request Trakt Device Code
+// @Description Starts the device flow, user enters the code on the verification url and the client polls the token with the interval
+// @Tags user
+// @Accept application/json
+// @Produce application/json
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 201 {object} responses.TraktDevice
+// @Failure 500 {string} string
+// @Router /user/trakt/device [post]
+func (ts *TraktSyncController) RequestDeviceCode(c *gin.Context) {
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	traktSyncModel := models.NewTraktSyncModel(ts.Database)
+
+	device, err := traktSyncModel.RequestDeviceCode(uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Enter the code on the verification url.", "data": device})
+}
+
+// Poll Trakt Device Token
+// @Summary Poll Trakt Device Token
+// @Description Connects the Trakt account once the code is authorized, 202 while the authorization is pending. Connected account is linked for the sync
+// @Tags user
+// @Accept application/json
+// @Produce application/json
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {object} models.TraktConnection
+// @Success 202 {string} string
+// @Failure 403 {string} string "Authorization is denied"
+// @Failure 410 {string} string "Device code is expired"
+// @Failure 429 {string} string "Polling too fast"
+// @Failure 500 {string} string
+// @Router /user/trakt/device/token [post]
+func (ts *TraktSyncController) PollDeviceToken(c *gin.Context) {
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	traktSyncModel := models.NewTraktSyncModel(ts.Database)
+
+	connection, status, err := traktSyncModel.PollDeviceToken(uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	switch status {
+	case models.TraktDevicePending:
+		c.JSON(http.StatusAccepted, gin.H{"message": "Authorization is pending."})
+		return
+	case models.TraktDeviceSlowDown:
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Polling too fast, please slow down."})
+		return
+	case models.TraktDeviceExpired:
+		c.JSON(http.StatusGone, gin.H{"error": errTraktDeviceExpired})
+		return
+	case models.TraktDeviceDenied:
+		c.JSON(http.StatusForbidden, gin.H{"error": errTraktDeviceDenied})
+		return
+	}
+
+	// Linked account pulls from Trakt periodically, pushes happen as the list changes.
+	linkedAccountModel := models.NewLinkedAccountModel(ts.Database)
+	if _, err := linkedAccountModel.LinkAccount(uid, models.TraktImportSource, connection.Username); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully connected.", "data": connection})
+}
+
+// Disconnect Trakt
+// @Summary Disconnect Trakt
+// @Description Revokes the access of the app, the linked account is kept and pulls the public data
+// @Tags user
+// @Accept application/json
+// @Produce application/json
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /user/trakt [delete]
+func (ts *TraktSyncController) Disconnect(c *gin.Context) {
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	traktSyncModel := models.NewTraktSyncModel(ts.Database)
+
+	connection, err := traktSyncModel.GetTraktConnection(uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	if !connection.IsConnected() {
+		c.JSON(http.StatusNotFound, gin.H{"error": errAccountNotLinked})
+		return
+	}
+
+	traktSyncModel.Disconnect(uid)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully disconnected."})
+}
+
+// pushTraktListChange sends the movie or tv series list entry to the connected
+// Trakt account, only the changes that Trakt doesn't know are pushed.
+func pushTraktListChange(database *db.MongoDB, uid, contentType string, entry interface{}) {
+	traktSyncModel := models.NewTraktSyncModel(database)
+
+	switch contentType {
+	case "movie":
+		if movieList, ok := entry.(models.MovieWatchList); ok {
+			traktSyncModel.PushMovie(uid, movieList)
+		}
+	case "tv":
+		if tvList, ok := entry.(models.TVSeriesWatchList); ok {
+			episodeWatchModel := models.NewEpisodeWatchModel(database)
+
+			episodeWatches, err := episodeWatchModel.GetEpisodeWatchesByListID(tvList.ID.Hex())
+			if err != nil {
+				return
+			}
+
+			traktSyncModel.PushTVSeries(uid, tvList, episodeWatches)
+		}
+	}
+}
+
+// pushTraktWatchlist sends the consume later addition to the watchlist of the
+// connected Trakt account.
+func pushTraktWatchlist(database *db.MongoDB, uid, contentType string, tmdbID *string) {
+	if (contentType != "movie" && contentType != "tv") || tmdbID == nil {
+		return
+	}
+
+	models.NewTraktSyncModel(database).PushWatchlist(uid, contentType, *tmdbID)
+}
@@ -0,0 +1,212 @@
+package controllers
+
+import (
+	"app/db"
+	"app/models"
+	"app/requests"
+	"net/http"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+)
+
+type UnmatchedImportController struct {
+	Database *db.MongoDB
+}
+
+func NewUnmatchedImportController(mongoDB *db.MongoDB) UnmatchedImportController {
+	return UnmatchedImportController{
+		Database: mongoDB,
+	}
+}
+
+const (
+	errUnmatchedItemLinked = "Item is already linked."
+	errContentNotFound     = "Content is not found."
+)
+
+// Get Unmatched Import Items
+// @Summary Get Unmatched Import Items
+// @Description Returns the imported entries that are not found with their candidates, pending items by default
+// @Tags import
+// @Accept application/json
+// @Produce application/json
+// @Param getunmatchedimportitems query requests.GetUnmatchedImportItems true "Get Unmatched Import Items"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {array} models.UnmatchedImportItem
+// @Failure 500 {string} string
+// @Router /import/unmatched [get]
+func (ui *UnmatchedImportController) GetUnmatchedItems(c *gin.Context) {
+	var data requests.GetUnmatchedImportItems
+	if err := c.ShouldBindQuery(&data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": validatorErrorHandler(err),
+		})
+
+		return
+	}
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	unmatchedImportModel := models.NewUnmatchedImportModel(ui.Database)
+
+	items, pagination, err := unmatchedImportModel.GetUnmatchedItems(uid, data)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pagination": pagination, "data": items})
+}
+
+// Confirm Unmatched Import Item
+// @Summary Confirm Unmatched Import Item
+// @Description Links the item to the content and imports it, content can be one of the candidates or any other content of the same type
+// @Tags import
+// @Accept application/json
+// @Produce application/json
+// @Param id path string true "Unmatched Import Item ID"
+// @Param confirmunmatchedimportitem body requests.ConfirmUnmatchedImportItem true "Confirm Unmatched Import Item"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {object} models.UnmatchedImportItem
+// @Failure 400 {string} string
+// @Failure 403 {string} string "Unauthorized access or list limit"
+// @Failure 404 {string} string "Could not found"
+// @Failure 500 {string} string
+// @Router /import/unmatched/{id}/confirm [post]
+func (ui *UnmatchedImportController) ConfirmUnmatchedItem(c *gin.Context) {
+	var data requests.ConfirmUnmatchedImportItem
+	if shouldReturn := bindJSONData(&data, c); shouldReturn {
+		return
+	}
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	unmatchedImportModel := models.NewUnmatchedImportModel(ui.Database)
+
+	item, shouldReturn := ui.getUserUnmatchedItem(c, unmatchedImportModel, uid)
+	if shouldReturn {
+		return
+	}
+
+	if item.Status == models.LinkedUnmatchedImportStatus {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errUnmatchedItemLinked})
+		return
+	}
+
+	isExists, err := unmatchedImportModel.IsContentExists(item.Entry.ContentType, data.ContentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !isExists {
+		c.JSON(http.StatusNotFound, gin.H{"error": errContentNotFound})
+		return
+	}
+
+	importJob, err := models.NewImportJobModel(ui.Database).GetImportJobByID(item.ImportJobID.Hex())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy := data.Policy
+	if policy == "" {
+		policy = models.DefaultImportPolicy(item.Source)
+	}
+
+	listImportModel := models.NewListImportModel(ui.Database)
+
+	result, err := listImportModel.ApplyPreview(uid, []models.ImportItem{{
+		Key:       item.Key,
+		Action:    models.NewImportItemAction,
+		ContentID: data.ContentID,
+		Entry:     item.Entry,
+	}}, policy, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if result.LimitedCount > 0 {
+		c.JSON(http.StatusForbidden, gin.H{"error": errUserListPremium})
+		return
+	}
+
+	// Linked entry is a part of its import, it's rolled back with it. Once the
+	// import is rolled back the entry is kept as a standalone change.
+	if importJob.Status != models.RolledBackImportJobStatus {
+		models.NewImportHistoryModel(ui.Database).SaveImportChanges(item.ImportJobID, result.Changes)
+	}
+
+	if err := unmatchedImportModel.LinkUnmatchedItem(item, data.ContentID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	item.Status = models.LinkedUnmatchedImportStatus
+	item.ContentID = data.ContentID
+
+	c.JSON(http.StatusOK, gin.H{"message": result.Message, "data": item})
+}
+
+// Dismiss Unmatched Import Item
+// @Summary Dismiss Unmatched Import Item
+// @Description Dismisses the item, it won't be asked again when the same entry is imported
+// @Tags import
+// @Accept application/json
+// @Produce application/json
+// @Param id path string true "Unmatched Import Item ID"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {string} string
+// @Failure 400 {string} string
+// @Failure 403 {string} string "Unauthorized access"
+// @Failure 404 {string} string "Could not found"
+// @Failure 500 {string} string
+// @Router /import/unmatched/{id}/dismiss [post]
+func (ui *UnmatchedImportController) DismissUnmatchedItem(c *gin.Context) {
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	unmatchedImportModel := models.NewUnmatchedImportModel(ui.Database)
+
+	item, shouldReturn := ui.getUserUnmatchedItem(c, unmatchedImportModel, uid)
+	if shouldReturn {
+		return
+	}
+
+	if item.Status == models.LinkedUnmatchedImportStatus {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errUnmatchedItemLinked})
+		return
+	}
+
+	if err := unmatchedImportModel.DismissUnmatchedItem(item); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully dismissed."})
+}
+
+func (ui *UnmatchedImportController) getUserUnmatchedItem(c *gin.Context, unmatchedImportModel *models.UnmatchedImportModel, uid string) (models.UnmatchedImportItem, bool) {
+	item, err := unmatchedImportModel.GetUnmatchedItemByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return models.UnmatchedImportItem{}, true
+	}
+
+	if item.UserID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": ErrNotFound})
+		return models.UnmatchedImportItem{}, true
+	}
+
+	if uid != item.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": ErrUnauthorized})
+		return models.UnmatchedImportItem{}, true
+	}
+
+	return item, false
+}
@@ -9,21 +9,22 @@ import (
 	"app/utils"
 	"math"
 	"net/http"
-	"time"
 
 	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
-	"github.com/sethvargo/go-password/password"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
 )
 
 type UserController struct {
-	Database *db.MongoDB
+	Database    *db.MongoDB
+	RedisClient *redis.Client
 }
 
-func NewUserController(mongoDB *db.MongoDB) UserController {
+func NewUserController(mongoDB *db.MongoDB, redisClient *redis.Client) UserController {
 	return UserController{
-		Database: mongoDB,
+		Database:    mongoDB,
+		RedisClient: redisClient,
 	}
 }
 
@@ -49,6 +50,8 @@ var (
 	errOAuthUser         = "Sorry, you can't do this action."
 	errMailAlreadySent   = "Password reset mail already sent, you have to wait 5 minutes before sending another. Please check spam mails."
 	errPremiumFeature    = "This feature requires premium membership."
+	errEmailVerified     = "Email address is already verified."
+	errVerificationSent  = "Verification mail already sent, you have to wait 2 minutes before sending another. Please check spam mails."
 )
 
 // Register
@@ -97,7 +100,95 @@ func (u *UserController) Register(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "Registered successfully."})
+	// Registration mail is claimed like the resent ones so it's throttled too,
+	// users can request the verification mail again if it fails.
+	go func(user models.User) {
+		if isClaimed, err := userModel.ClaimEmailVerificationMail(user.ID.Hex()); err != nil || !isClaimed {
+			return
+		}
+
+		if err := helpers.SendEmailVerificationEmail(models.CreateEmailVerificationToken(user), user.EmailAddress); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"uid": user.ID,
+			}).Error("failed to send verification mail: ", err)
+		}
+	}(*createdUser)
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Registered successfully, please verify your email address."})
+}
+
+// Resend Email Verification
+// @Summary Resend Email Verification
+// @Description Sends the email verification mail again, can be sent once in 2 minutes
+// @Tags user
+// @Accept application/json
+// @Produce application/json
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {string} string
+// @Failure 400 {string} string
+// @Failure 429 {string} string
+// @Failure 500 {string} string
+// @Router /user/email-verification [post]
+func (u *UserController) ResendEmailVerification(c *gin.Context) {
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	userModel := models.NewUserModel(u.Database)
+
+	user, err := userModel.FindUserByID(uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	if user.HasVerifiedEmail() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": errEmailVerified,
+		})
+
+		return
+	}
+
+	isClaimed, err := userModel.ClaimEmailVerificationMail(uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	if !isClaimed {
+		c.JSON(http.StatusTooManyRequests, gin.H{
+			"error": errVerificationSent,
+		})
+
+		return
+	}
+
+	if err := helpers.SendEmailVerificationEmail(models.CreateEmailVerificationToken(user), user.EmailAddress); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully send verification email."})
+}
+
+func (u *UserController) ConfirmEmail(c *gin.Context) {
+	userModel := models.NewUserModel(u.Database)
+
+	isVerified, err := userModel.VerifyEmail(c.Query("token"))
+	if err != nil || !isVerified {
+		http.ServeFile(c.Writer, c.Request, "assets/error_email_verification.html")
+		return
+	}
+
+	http.ServeFile(c.Writer, c.Request, "assets/confirm_email.html")
 }
 
 // Extra Statistics
@@ -373,7 +464,7 @@ func (u *UserController) GetUserInfo(c *gin.Context) {
 		userStatsCh := make(chan responses.UserStats)
 		go func() {
 			userListModel := models.NewUserListModel(u.Database)
-			userStats, err := userListModel.GetUserListStats(uid)
+			userStats, err := userListModel.GetUserListStats(uid, true)
 			if err != nil {
 				resultCh <- UserInfoResult{Error: err}
 				return
@@ -424,6 +515,7 @@ func (u *UserController) GetUserInfo(c *gin.Context) {
 		userInfo.AnimeWatchedEpisodes = userStats.AnimeWatchedEpisodes
 		userInfo.TVWatchedEpisodes = userStats.TVWatchedEpisodes
 		userInfo.GameTotalHoursPlayed = userStats.GameTotalHoursPlayed
+		userInfo.TagCounts = userStats.TagCounts
 
 		userInfo.MovieTotalScore = calculateTotalScore(userStats.MovieCount, userStats.MovieTotalScore)
 		userInfo.TVTotalScore = calculateTotalScore(userStats.TVCount, userStats.TVTotalScore)
@@ -632,7 +724,7 @@ func (u *UserController) GetUserInfoFromUsername(c *gin.Context) {
 		userStatsCh := make(chan responses.UserStats)
 		go func() {
 			userListModel := models.NewUserListModel(u.Database)
-			userStats, err := userListModel.GetUserListStats(userInfo.ID.Hex())
+			userStats, err := userListModel.GetUserListStats(userInfo.ID.Hex(), false)
 			if err != nil {
 				resultCh <- UserInfoResult{Error: err}
 				return
@@ -887,6 +979,14 @@ func (u *UserController) SendFriendRequest(c *gin.Context) {
 		return
 	}
 
+	if !sender.HasVerifiedEmail() {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": errEmailNotVerified,
+		})
+
+		return
+	}
+
 	friendModel := models.NewFriendModel(u.Database)
 
 	if err := friendModel.CreateFriendRequest(sender.ID.Hex(), sender.Username, receiver.ID.Hex(), sender.Username); err != nil {
@@ -964,38 +1064,6 @@ func (u *UserController) ChangeUsername(c *gin.Context) {
 	}
 }
 
-// Change User Membership
-// @Summary Change User Membership
-// @Description User membership status will be updated depending on subscription status
-// @Tags user
-// @Accept application/json
-// @Produce application/json
-// @Param changemembership body requests.ChangeMembership true "Set Membership"
-// @Security ApiKeyAuth
-// @Param Authorization header string true "Authentication header"
-// @Success 200 {string} string
-// @Failure 500 {string} string
-// @Router /user/membership [patch]
-func (u *UserController) ChangeUserMembership(c *gin.Context) {
-	var data requests.ChangeMembership
-	if shouldReturn := bindJSONData(&data, c); shouldReturn {
-		return
-	}
-
-	uid := jwt.ExtractClaims(c)["id"].(string)
-
-	userModel := models.NewUserModel(u.Database)
-	if err := userModel.UpdateUserMembership(uid, data); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
-
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{"message": "Successfully updated membership."})
-}
-
 // Change App Notification Preference
 // @Summary Change User App Notification Preference
 // @Description Users can change their app notification preference
@@ -1144,6 +1212,15 @@ func (u *UserController) ChangePassword(c *gin.Context) {
 		return
 	}
 
+	sessionModel := models.NewSessionModel(u.Database, u.RedisClient)
+	if err = sessionModel.RevokeUserSessions(uid, helpers.GetSessionID(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Successfully changed password."})
 }
 
@@ -1191,26 +1268,16 @@ func (u *UserController) ForgotPassword(c *gin.Context) {
 		return
 	}
 
-	var resetToken string
-	if user.PasswordResetToken == "" {
-		resetToken = uuid.NewString()
-		user.PasswordResetToken = resetToken
-
-		if err = userModel.UpdateUser(user); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": err.Error(),
-			})
-
-			return
-		}
+	resetToken, isCreated, err := userModel.CreatePasswordResetToken(user.ID.Hex())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
 
-		const scheduleTime = 5 * time.Minute
+		return
+	}
 
-		time.AfterFunc(scheduleTime, func() {
-			user.PasswordResetToken = ""
-			userModel.UpdateUser(user)
-		})
-	} else {
+	if !isCreated {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": errMailAlreadySent,
 		})
@@ -1229,56 +1296,59 @@ func (u *UserController) ForgotPassword(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Successfully send password reset email."})
 }
 
+// ConfirmPasswordReset serves the form to choose the new password, the form is
+// posted to ResetPassword with the token of the link.
 func (u *UserController) ConfirmPasswordReset(c *gin.Context) {
-	token := c.Query("token")
-	email := c.Query("mail")
-
 	userModel := models.NewUserModel(u.Database)
 
-	user, err := userModel.FindUserByResetTokenAndEmail(token, email)
-	if err != nil {
+	user, err := userModel.FindUserByPasswordResetToken(c.Query("token"))
+	if err != nil || user.EmailAddress == "" || user.IsOAuthUser {
 		http.ServeFile(c.Writer, c.Request, "assets/error_password_reset.html")
 		return
 	}
 
-	if user.EmailAddress == "" {
+	http.ServeFile(c.Writer, c.Request, "assets/reset_password.html")
+}
+
+func (u *UserController) ResetPassword(c *gin.Context) {
+	var data requests.ResetPassword
+	if err := c.ShouldBind(&data); err != nil {
 		http.ServeFile(c.Writer, c.Request, "assets/error_password_reset.html")
 		return
 	}
 
-	if user.IsOAuthUser {
+	userModel := models.NewUserModel(u.Database)
+
+	user, err := userModel.FindUserByPasswordResetToken(c.Query("token"))
+	if err != nil || user.EmailAddress == "" || user.IsOAuthUser {
 		http.ServeFile(c.Writer, c.Request, "assets/error_password_reset.html")
 		return
 	}
 
-	const (
-		passwordLength = 10
-		numDigits      = 4
-	)
-
-	generatedPass, err := password.Generate(passwordLength, numDigits, 0, true, false)
-	if err != nil {
-		generatedPass = user.EmailAddress + "_Password"
+	// Sessions are revoked before the password is changed, the link can be used
+	// again if it fails.
+	sessionModel := models.NewSessionModel(u.Database, u.RedisClient)
+	if err = sessionModel.RevokeUserSessions(user.ID.Hex(), ""); err != nil {
+		http.ServeFile(c.Writer, c.Request, "assets/error_password_reset.html")
+		return
 	}
 
-	user.Password = utils.HashPassword(generatedPass)
-	user.PasswordResetToken = ""
-
-	if err = userModel.UpdateUser(user); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
-
+	user, err = userModel.ResetPassword(c.Query("token"), data.NewPassword)
+	if err != nil || user.EmailAddress == "" {
+		http.ServeFile(c.Writer, c.Request, "assets/error_password_reset.html")
 		return
 	}
 
-	if err := helpers.SendPasswordChangedEmail(generatedPass, user.EmailAddress); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": err.Error(),
-		})
+	// Sessions that are started with the old password meanwhile are revoked too.
+	go sessionModel.RevokeUserSessions(user.ID.Hex(), "")
 
-		return
-	}
+	go func() {
+		if err := helpers.SendPasswordChangedEmail(user.EmailAddress); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"uid": user.ID,
+			}).Error("failed to send password changed mail: ", err)
+		}
+	}()
 
 	http.ServeFile(c.Writer, c.Request, "assets/confirm_password.html")
 }
@@ -1315,6 +1385,18 @@ func (u *UserController) DeleteUser(c *gin.Context) {
 	logsModel := models.NewLogsModel(u.Database)
 	recommendationModel := models.NewRecommendationModel(u.Database)
 	achievementModel := models.NewAchievementModel(u.Database)
+	episodeWatchModel := models.NewEpisodeWatchModel(u.Database)
+	consumptionSessionModel := models.NewConsumptionSessionModel(u.Database)
+	userTagModel := models.NewUserTagModel(u.Database)
+	listChangeModel := models.NewListChangeModel(u.Database)
+	dataExportModel := models.NewDataExportModel(u.Database)
+	importJobModel := models.NewImportJobModel(u.Database)
+	importHistoryModel := models.NewImportHistoryModel(u.Database)
+	entitlementModel := models.NewEntitlementModel(u.Database)
+	unmatchedImportModel := models.NewUnmatchedImportModel(u.Database)
+	scrobbleModel := models.NewScrobbleModel(u.Database)
+	traktSyncModel := models.NewTraktSyncModel(u.Database)
+	sessionModel := models.NewSessionModel(u.Database, u.RedisClient)
 
 	go userListModel.DeleteUserListByUserID(uid)
 	go userInteractionModel.DeleteAllConsumeLaterByUserID(uid)
@@ -1325,6 +1407,18 @@ func (u *UserController) DeleteUser(c *gin.Context) {
 	go logsModel.DeleteLogsByUserID(uid)
 	go recommendationModel.DeleteAllRecommendationByUserID(uid)
 	go achievementModel.DeleteUserAchievementsByUserID(uid)
+	go episodeWatchModel.DeleteEpisodeWatchesByUserID(uid)
+	go consumptionSessionModel.DeleteConsumptionSessionsByUserID(uid)
+	go userTagModel.DeleteUserTagsByUserID(uid)
+	go listChangeModel.DeleteListChangesByUserID(uid)
+	go dataExportModel.DeleteDataExportsByUserID(uid)
+	go importJobModel.DeleteImportJobsByUserID(uid)
+	go importHistoryModel.DeleteImportChangesByUserID(uid)
+	go entitlementModel.DeleteEntitlementsByUserID(uid)
+	go unmatchedImportModel.DeleteUnmatchedItemsByUserID(uid)
+	go scrobbleModel.DeleteScrobbleTokenByUserID(uid)
+	go traktSyncModel.Disconnect(uid)
+	go sessionModel.DeleteSessionsByUserID(uid)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Successfully deleted user."})
 }
@@ -161,6 +161,9 @@ func (ui *UserInteractionController) CreateConsumeLater(c *gin.Context) {
 		return
 	}
 
+	go addUserTags(ui.Database, uid, createdConsumeLater.Tags)
+	go pushTraktWatchlist(ui.Database, uid, data.ContentType, createdConsumeLater.ContentExternalID)
+
 	logModel := models.NewLogsModel(ui.Database)
 
 	go logModel.CreateLog(uid, requests.CreateLog{
@@ -279,35 +282,42 @@ func (ui *UserInteractionController) MarkConsumeLaterAsUserList(c *gin.Context)
 				score = *data.Score
 			}
 
-			if _, err := userListModel.UpdateAnimeListByID(animeList, requests.UpdateAnimeList{
+			updatedAnimeList, err := userListModel.UpdateAnimeListByID(animeList, requests.UpdateAnimeList{
 				ID:              consumeLater.ContentID,
 				IsUpdatingScore: true,
 				Score:           &score,
 				TimesFinished:   &timesFinished,
 				Status:          &status,
 				WatchedEpisodes: &episodes,
-			}); err != nil {
+			})
+			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{
 					"error": err.Error(),
 				})
 
 				return
 			}
+
+			go recordListChange(ui.Database, uid, "anime", models.UpdateListChangeAction, animeList, updatedAnimeList)
 		} else {
-			if _, err = userListModel.CreateAnimeList(uid, requests.CreateAnimeList{
+			createdAnimeList, err := userListModel.CreateAnimeList(uid, requests.CreateAnimeList{
 				AnimeID:         consumeLater.ContentID,
 				AnimeMALID:      anime.MalID,
 				Status:          status,
 				TimesFinished:   &timesFinished,
 				WatchedEpisodes: &episodes,
 				Score:           data.Score,
-			}, anime); err != nil {
+				Tags:            consumeLater.Tags,
+			}, anime)
+			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{
 					"error": err.Error(),
 				})
 
 				return
 			}
+
+			go recordListChange(ui.Database, uid, "anime", models.CreateListChangeAction, nil, createdAnimeList)
 		}
 
 		userInteractionModel.DeleteConsumeLaterByID(uid, data.ID)
@@ -370,33 +380,40 @@ func (ui *UserInteractionController) MarkConsumeLaterAsUserList(c *gin.Context)
 				score = *data.Score
 			}
 
-			if _, err := userListModel.UpdateGameListByID(gameList, requests.UpdateGameList{
+			updatedGameList, err := userListModel.UpdateGameListByID(gameList, requests.UpdateGameList{
 				ID:              consumeLater.ContentID,
 				IsUpdatingScore: true,
 				Score:           &score,
 				TimesFinished:   &timesFinished,
 				Status:          &status,
-			}); err != nil {
+			})
+			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{
 					"error": err.Error(),
 				})
 
 				return
 			}
+
+			go recordListChange(ui.Database, uid, "game", models.UpdateListChangeAction, gameList, updatedGameList)
 		} else {
-			if _, err = userListModel.CreateGameList(uid, requests.CreateGameList{
+			createdGameList, err := userListModel.CreateGameList(uid, requests.CreateGameList{
 				GameID:        consumeLater.ContentID,
 				GameRAWGID:    *consumeLater.ContentExternalIntID,
 				Status:        status,
 				Score:         data.Score,
 				TimesFinished: &timesFinished,
-			}, game); err != nil {
+				Tags:          consumeLater.Tags,
+			}, game)
+			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{
 					"error": err.Error(),
 				})
 
 				return
 			}
+
+			go recordListChange(ui.Database, uid, "game", models.CreateListChangeAction, nil, createdGameList)
 		}
 
 		userInteractionModel.DeleteConsumeLaterByID(uid, data.ID)
@@ -460,32 +477,39 @@ func (ui *UserInteractionController) MarkConsumeLaterAsUserList(c *gin.Context)
 				score = *data.Score
 			}
 
-			if _, err := userListModel.UpdateMovieListByID(movieList, requests.UpdateMovieList{
+			updatedMovieList, err := userListModel.UpdateMovieListByID(movieList, requests.UpdateMovieList{
 				ID:              consumeLater.ContentID,
 				IsUpdatingScore: true,
 				Score:           &score,
 				TimesFinished:   &timesFinished,
 				Status:          &status,
-			}); err != nil {
+			})
+			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{
 					"error": err.Error(),
 				})
 
 				return
 			}
+
+			go recordListChange(ui.Database, uid, "movie", models.UpdateListChangeAction, movieList, updatedMovieList)
 		} else {
-			if _, err = userListModel.CreateMovieWatchList(uid, requests.CreateMovieWatchList{
+			createdMovieWatchList, err := userListModel.CreateMovieWatchList(uid, requests.CreateMovieWatchList{
 				MovieID:     consumeLater.ContentID,
 				MovieTmdbID: *consumeLater.ContentExternalID,
 				Status:      "finished",
 				Score:       data.Score,
-			}, movie); err != nil {
+				Tags:        consumeLater.Tags,
+			}, movie)
+			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{
 					"error": err.Error(),
 				})
 
 				return
 			}
+
+			go recordListChange(ui.Database, uid, "movie", models.CreateListChangeAction, nil, createdMovieWatchList)
 		}
 
 		userInteractionModel.DeleteConsumeLaterByID(uid, data.ID)
@@ -551,7 +575,7 @@ func (ui *UserInteractionController) MarkConsumeLaterAsUserList(c *gin.Context)
 				score = *data.Score
 			}
 
-			if _, err := userListModel.UpdateTVSeriesListByID(tvList, requests.UpdateTVSeriesList{
+			updatedTVSeriesList, err := userListModel.UpdateTVSeriesListByID(tvList, requests.UpdateTVSeriesList{
 				ID:              consumeLater.ContentID,
 				IsUpdatingScore: true,
 				Score:           &score,
@@ -559,15 +583,18 @@ func (ui *UserInteractionController) MarkConsumeLaterAsUserList(c *gin.Context)
 				Status:          &status,
 				WatchedEpisodes: &episodes,
 				WatchedSeasons:  &seasons,
-			}); err != nil {
+			})
+			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{
 					"error": err.Error(),
 				})
 
 				return
 			}
+
+			go recordListChange(ui.Database, uid, "tv", models.UpdateListChangeAction, tvList, updatedTVSeriesList)
 		} else {
-			if _, err = userListModel.CreateTVSeriesWatchList(uid, requests.CreateTVSeriesWatchList{
+			createdTVSeriesWatchList, err := userListModel.CreateTVSeriesWatchList(uid, requests.CreateTVSeriesWatchList{
 				TvID:            consumeLater.ContentID,
 				TvTmdbID:        tvSeries.TmdbID,
 				Status:          "finished",
@@ -575,13 +602,17 @@ func (ui *UserInteractionController) MarkConsumeLaterAsUserList(c *gin.Context)
 				WatchedEpisodes: &episodes,
 				WatchedSeasons:  &seasons,
 				Score:           data.Score,
-			}, tvSeries); err != nil {
+				Tags:            consumeLater.Tags,
+			}, tvSeries)
+			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{
 					"error": err.Error(),
 				})
 
 				return
 			}
+
+			go recordListChange(ui.Database, uid, "tv", models.CreateListChangeAction, nil, createdTVSeriesWatchList)
 		}
 
 		userInteractionModel.DeleteConsumeLaterByID(uid, data.ID)
@@ -658,6 +689,48 @@ func (ui *UserInteractionController) GetConsumeLater(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": consumeLaterList})
 }
 
+// Update Consume Later Tags
+// @Summary Update Consume Later Tags
+// @Description Replaces tags of consume later
+// @Tags consume_later
+// @Accept application/json
+// @Produce application/json
+// @Param updateconsumelatertags body requests.UpdateConsumeLaterTags true "Update Consume Later Tags"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {object} models.ConsumeLaterList
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /consume/tags [patch]
+func (ui *UserInteractionController) UpdateConsumeLaterTags(c *gin.Context) {
+	var data requests.UpdateConsumeLaterTags
+	if shouldReturn := bindJSONData(&data, c); shouldReturn {
+		return
+	}
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	userInteractionModel := models.NewUserInteractionModel(ui.Database)
+
+	consumeLater, _ := userInteractionModel.GetBaseConsumeLater(uid, data.ID)
+	if consumeLater.UserID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": ErrNotFound})
+		return
+	}
+
+	updatedConsumeLater, err := userInteractionModel.UpdateConsumeLaterTags(consumeLater, data)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	go addUserTags(ui.Database, uid, updatedConsumeLater.Tags)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully updated.", "data": updatedConsumeLater})
+}
+
 // Delete Consume Later
 // @Summary Delete Consume Later
 // @Description Deletes Consume Later
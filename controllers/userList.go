@@ -4,7 +4,9 @@ import (
 	"app/db"
 	"app/models"
 	"app/requests"
+	"app/responses"
 	"net/http"
+	"time"
 
 	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
@@ -22,6 +24,12 @@ func NewUserListController(mongoDB *db.MongoDB) UserListController {
 
 const errUserListPremium = "Free members can add up to 175 content to their list, you can get premium membership for unlimited access."
 
+const errBulkUserListPremium = "Free members can bulk update up to 175 list entries, entries over the limit can only be deleted or moved to watch later. You can get premium membership for unlimited access."
+
+const errInvalidListDates = "Finished date cannot be before started date."
+
+const errMangaConsumeLater = "Manga can't be added to watch later."
+
 // Create Anime List
 // @Summary Create Anime List
 // @Description Creates Anime List
@@ -41,6 +49,11 @@ func (u *UserListController) CreateAnimeList(c *gin.Context) {
 		return
 	}
 
+	if isListDateRangeInvalid(data.StartedAt, data.FinishedAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidListDates})
+		return
+	}
+
 	var (
 		createdAnimeList models.AnimeList
 		err              error
@@ -98,6 +111,14 @@ func (u *UserListController) CreateAnimeList(c *gin.Context) {
 		ContentID:        createdAnimeList.AnimeID,
 	})
 
+	go addUserTags(u.Database, uid, createdAnimeList.Tags)
+	go recordListChange(u.Database, uid, "anime", models.CreateListChangeAction, nil, createdAnimeList)
+
+	go recordCreatedConsumptionSession(
+		u.Database, uid, "anime", createdAnimeList.ID.Hex(),
+		createdAnimeList.Status, createdAnimeList.TimesFinished, createdAnimeList.Score,
+	)
+
 	// Check achievements in background
 	achievementModel := models.NewAchievementModel(u.Database)
 	if createdAnimeList.Status == "finished" {
@@ -127,6 +148,11 @@ func (u *UserListController) CreateMangaList(c *gin.Context) {
 		return
 	}
 
+	if isListDateRangeInvalid(data.StartedAt, data.FinishedAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidListDates})
+		return
+	}
+
 	var (
 		createdMangaList models.MangaList
 		err              error
@@ -184,8 +210,19 @@ func (u *UserListController) CreateMangaList(c *gin.Context) {
 		ContentID:        createdMangaList.MangaID,
 	})
 
+	go addUserTags(u.Database, uid, createdMangaList.Tags)
+	go recordListChange(u.Database, uid, "manga", models.CreateListChangeAction, nil, createdMangaList)
+
+	go recordCreatedConsumptionSession(
+		u.Database, uid, "manga", createdMangaList.ID.Hex(),
+		createdMangaList.Status, createdMangaList.TimesFinished, createdMangaList.Score,
+	)
+
 	// Check achievements in background
 	achievementModel := models.NewAchievementModel(u.Database)
+	if createdMangaList.Status == "finished" {
+		achievementModel.CheckAndUnlockAchievements(uid, "manga_finished")
+	}
 	achievementModel.CheckAndUnlockAchievements(uid, "first_activity")
 
 	c.JSON(http.StatusCreated, gin.H{"message": "Successfully created.", "data": createdMangaList})
@@ -210,6 +247,11 @@ func (u *UserListController) CreateGameList(c *gin.Context) {
 		return
 	}
 
+	if isListDateRangeInvalid(data.StartedAt, data.FinishedAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidListDates})
+		return
+	}
+
 	var (
 		createdGameList models.GameList
 		err             error
@@ -267,6 +309,14 @@ func (u *UserListController) CreateGameList(c *gin.Context) {
 		ContentID:        createdGameList.GameID,
 	})
 
+	go addUserTags(u.Database, uid, createdGameList.Tags)
+	go recordListChange(u.Database, uid, "game", models.CreateListChangeAction, nil, createdGameList)
+
+	go recordCreatedConsumptionSession(
+		u.Database, uid, "game", createdGameList.ID.Hex(),
+		createdGameList.Status, createdGameList.TimesFinished, createdGameList.Score,
+	)
+
 	// Check achievements in background
 	achievementModel := models.NewAchievementModel(u.Database)
 	if createdGameList.Status == "finished" {
@@ -296,6 +346,11 @@ func (u *UserListController) CreateMovieWatchList(c *gin.Context) {
 		return
 	}
 
+	if isListDateRangeInvalid(data.StartedAt, data.FinishedAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidListDates})
+		return
+	}
+
 	var (
 		createdWatchList models.MovieWatchList
 		err              error
@@ -353,6 +408,14 @@ func (u *UserListController) CreateMovieWatchList(c *gin.Context) {
 		ContentID:        createdWatchList.MovieID,
 	})
 
+	go addUserTags(u.Database, uid, createdWatchList.Tags)
+	go recordListChange(u.Database, uid, "movie", models.CreateListChangeAction, nil, createdWatchList)
+
+	go recordCreatedConsumptionSession(
+		u.Database, uid, "movie", createdWatchList.ID.Hex(),
+		createdWatchList.Status, createdWatchList.TimesFinished, createdWatchList.Score,
+	)
+
 	// Check achievements in background
 	achievementModel := models.NewAchievementModel(u.Database)
 	if createdWatchList.Status == "finished" {
@@ -382,6 +445,11 @@ func (u *UserListController) CreateTVSeriesWatchList(c *gin.Context) {
 		return
 	}
 
+	if isListDateRangeInvalid(data.StartedAt, data.FinishedAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidListDates})
+		return
+	}
+
 	var (
 		createdTVSeriesWatchList models.TVSeriesWatchList
 		err                      error
@@ -439,6 +507,14 @@ func (u *UserListController) CreateTVSeriesWatchList(c *gin.Context) {
 		ContentID:        createdTVSeriesWatchList.TvID,
 	})
 
+	go addUserTags(u.Database, uid, createdTVSeriesWatchList.Tags)
+	go recordListChange(u.Database, uid, "tv", models.CreateListChangeAction, nil, createdTVSeriesWatchList)
+
+	go recordCreatedConsumptionSession(
+		u.Database, uid, "tv", createdTVSeriesWatchList.ID.Hex(),
+		createdTVSeriesWatchList.Status, createdTVSeriesWatchList.TimesFinished, createdTVSeriesWatchList.Score,
+	)
+
 	// Check achievements in background
 	achievementModel := models.NewAchievementModel(u.Database)
 	if createdTVSeriesWatchList.Status == "finished" {
@@ -542,7 +618,19 @@ func (u *UserListController) IncrementAnimeListEpisodeByID(c *gin.Context) {
 		ID: animeList.AnimeID,
 	})
 
-	if updatedAnimeList, err = userListModel.IncrementAnimeListEpisodeByID(animeList, anime, data); err != nil {
+	watchedEpisodes, _, err := incrementEpisodeWatches(
+		u.Database, uid, animeList.ID.Hex(), animeList.AnimeID, "anime",
+		models.AnimeSeasonEpisodes(anime), int(animeList.WatchedEpisodes), true,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	if updatedAnimeList, err = userListModel.UpdateAnimeListWatchedEpisodes(animeList, anime, int64(watchedEpisodes)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(),
 		})
@@ -562,6 +650,13 @@ func (u *UserListController) IncrementAnimeListEpisodeByID(c *gin.Context) {
 		ContentID:        updatedAnimeList.AnimeID,
 	})
 
+	go recordListChange(u.Database, uid, "anime", models.UpdateListChangeAction, animeList, updatedAnimeList)
+
+	go recordConsumptionSession(
+		u.Database, uid, "anime", animeList.ID.Hex(),
+		animeList.Status, updatedAnimeList.Status, animeList.TimesFinished, updatedAnimeList.Score,
+	)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Anime list updated.", "data": updatedAnimeList})
 }
 
@@ -608,6 +703,11 @@ func (u *UserListController) UpdateAnimeListByID(c *gin.Context) {
 		return
 	}
 
+	if isListUpdateDateRangeInvalid(data.IsUpdatingDates, data.StartedAt, data.FinishedAt, animeList.StartedAt, animeList.FinishedAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidListDates})
+		return
+	}
+
 	animeModel := models.NewAnimeModel(u.Database)
 	anime, _ := animeModel.GetAnimeDetails(requests.ID{
 		ID: animeList.AnimeID,
@@ -643,9 +743,204 @@ func (u *UserListController) UpdateAnimeListByID(c *gin.Context) {
 		achievementModel.CheckAndUnlockAchievements(uid, "anime_finished")
 	}
 
+	if data.IsUpdatingTags {
+		go addUserTags(u.Database, uid, updatedAnimeList.Tags)
+	}
+
+	go recordListChange(u.Database, uid, "anime", models.UpdateListChangeAction, animeList, updatedAnimeList)
+
+	go recordConsumptionSession(
+		u.Database, uid, "anime", animeList.ID.Hex(),
+		animeList.Status, updatedAnimeList.Status, animeList.TimesFinished, updatedAnimeList.Score,
+	)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Anime list updated.", "data": updatedAnimeList})
 }
 
+// Increment Manga List
+// @Summary Increment Manga List
+// @Description Increment manga list chapter or volume
+// @Tags user_list
+// @Accept application/json
+// @Produce application/json
+// @Param incrementmangalist body requests.IncrementTVSeriesList true "Increment Manga List"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {object} models.MangaList
+// @Failure 403 {string} string "Unauthorized update"
+// @Failure 404 {string} string "Could not found"
+// @Failure 500 {string} string
+// @Router /list/manga/inc [patch]
+func (u *UserListController) IncrementMangaListChapterVolumeByID(c *gin.Context) {
+	var data requests.IncrementTVSeriesList
+	if shouldReturn := bindJSONData(&data, c); shouldReturn {
+		return
+	}
+
+	var (
+		updatedMangaList models.MangaList
+		err              error
+	)
+
+	userListModel := models.NewUserListModel(u.Database)
+	mangaList, err := userListModel.GetBaseMangaListByID(data.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if mangaList.UserID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": ErrNotFound})
+		return
+	}
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	if uid != mangaList.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": ErrUnauthorized})
+		return
+	}
+
+	mangaModel := models.NewMangaModel(u.Database)
+	manga, _ := mangaModel.GetMangaDetails(requests.ID{
+		ID: mangaList.MangaID,
+	})
+
+	if updatedMangaList, err = userListModel.IncrementMangaListChapterVolumeByID(mangaList, manga, data); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	logModel := models.NewLogsModel(u.Database)
+
+	go logModel.CreateLog(uid, requests.CreateLog{
+		LogType:          models.UserListLogType,
+		LogAction:        models.UpdateLogAction,
+		LogActionDetails: updatedMangaList.Status,
+		ContentTitle:     manga.TitleOriginal,
+		ContentImage:     manga.ImageURL,
+		ContentType:      "manga",
+		ContentID:        updatedMangaList.MangaID,
+	})
+
+	if mangaList.Status != "finished" && updatedMangaList.Status == "finished" {
+		achievementModel := models.NewAchievementModel(u.Database)
+		achievementModel.CheckAndUnlockAchievements(uid, "manga_finished")
+	}
+
+	go recordListChange(u.Database, uid, "manga", models.UpdateListChangeAction, mangaList, updatedMangaList)
+
+	go recordConsumptionSession(
+		u.Database, uid, "manga", mangaList.ID.Hex(),
+		mangaList.Status, updatedMangaList.Status, mangaList.TimesFinished, updatedMangaList.Score,
+	)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Manga list updated.", "data": updatedMangaList})
+}
+
+// Update Manga List
+// @Summary Update Manga List
+// @Description Updates manga list
+// @Tags user_list
+// @Accept application/json
+// @Produce application/json
+// @Param updatemangalist body requests.UpdateMangaList true "Update Manga List"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {object} models.MangaList
+// @Failure 403 {string} string "Unauthorized update"
+// @Failure 404 {string} string "Could not found"
+// @Failure 500 {string} string
+// @Router /list/manga [patch]
+func (u *UserListController) UpdateMangaListByID(c *gin.Context) {
+	var data requests.UpdateMangaList
+	if shouldReturn := bindJSONData(&data, c); shouldReturn {
+		return
+	}
+
+	var (
+		updatedMangaList models.MangaList
+		err              error
+	)
+
+	userListModel := models.NewUserListModel(u.Database)
+	mangaList, err := userListModel.GetBaseMangaListByID(data.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if mangaList.UserID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": ErrNotFound})
+		return
+	}
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	if uid != mangaList.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": ErrUnauthorized})
+		return
+	}
+
+	if isListUpdateDateRangeInvalid(data.IsUpdatingDates, data.StartedAt, data.FinishedAt, mangaList.StartedAt, mangaList.FinishedAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidListDates})
+		return
+	}
+
+	mangaModel := models.NewMangaModel(u.Database)
+	manga, _ := mangaModel.GetMangaDetails(requests.ID{
+		ID: mangaList.MangaID,
+	})
+
+	if data.ReadChapters != nil && (manga.Chapters != nil && *data.ReadChapters > *manga.Chapters) {
+		data.ReadChapters = manga.Chapters
+	}
+
+	if data.ReadVolumes != nil && (manga.Volumes != nil && *data.ReadVolumes > *manga.Volumes) {
+		data.ReadVolumes = manga.Volumes
+	}
+
+	if updatedMangaList, err = userListModel.UpdateMangaListByID(mangaList, data); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	logModel := models.NewLogsModel(u.Database)
+
+	go logModel.CreateLog(uid, requests.CreateLog{
+		LogType:          models.UserListLogType,
+		LogAction:        models.UpdateLogAction,
+		LogActionDetails: updatedMangaList.Status,
+		ContentTitle:     manga.TitleOriginal,
+		ContentImage:     manga.ImageURL,
+		ContentType:      "manga",
+		ContentID:        updatedMangaList.MangaID,
+	})
+
+	// Check achievements if status changed to finished
+	if data.Status != nil && *data.Status == "finished" {
+		achievementModel := models.NewAchievementModel(u.Database)
+		achievementModel.CheckAndUnlockAchievements(uid, "manga_finished")
+	}
+
+	if data.IsUpdatingTags {
+		go addUserTags(u.Database, uid, updatedMangaList.Tags)
+	}
+
+	go recordListChange(u.Database, uid, "manga", models.UpdateListChangeAction, mangaList, updatedMangaList)
+
+	go recordConsumptionSession(
+		u.Database, uid, "manga", mangaList.ID.Hex(),
+		mangaList.Status, updatedMangaList.Status, mangaList.TimesFinished, updatedMangaList.Score,
+	)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Manga list updated.", "data": updatedMangaList})
+}
+
 // Increment Game List
 // @Summary Increment Game List
 // @Description Increment game list hours played
@@ -714,6 +1009,8 @@ func (u *UserListController) IncrementGameListHourByID(c *gin.Context) {
 		ContentID:        updatedGameList.GameID,
 	})
 
+	go recordListChange(u.Database, uid, "game", models.UpdateListChangeAction, gameList, updatedGameList)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Game list updated.", "data": updatedGameList})
 }
 
@@ -760,6 +1057,11 @@ func (u *UserListController) UpdateGameListByID(c *gin.Context) {
 		return
 	}
 
+	if isListUpdateDateRangeInvalid(data.IsUpdatingDates, data.StartedAt, data.FinishedAt, gameList.StartedAt, gameList.FinishedAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidListDates})
+		return
+	}
+
 	if updatedGameList, err = userListModel.UpdateGameListByID(gameList, data); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(),
@@ -791,6 +1093,17 @@ func (u *UserListController) UpdateGameListByID(c *gin.Context) {
 		achievementModel.CheckAndUnlockAchievements(uid, "game_finished")
 	}
 
+	if data.IsUpdatingTags {
+		go addUserTags(u.Database, uid, updatedGameList.Tags)
+	}
+
+	go recordListChange(u.Database, uid, "game", models.UpdateListChangeAction, gameList, updatedGameList)
+
+	go recordConsumptionSession(
+		u.Database, uid, "game", gameList.ID.Hex(),
+		gameList.Status, updatedGameList.Status, gameList.TimesFinished, updatedGameList.Score,
+	)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Game list updated.", "data": updatedGameList})
 }
 
@@ -837,6 +1150,11 @@ func (u *UserListController) UpdateMovieListByID(c *gin.Context) {
 		return
 	}
 
+	if isListUpdateDateRangeInvalid(data.IsUpdatingDates, data.StartedAt, data.FinishedAt, movieList.StartedAt, movieList.FinishedAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidListDates})
+		return
+	}
+
 	if updatedWatchList, err = userListModel.UpdateMovieListByID(movieList, data); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(),
@@ -868,6 +1186,17 @@ func (u *UserListController) UpdateMovieListByID(c *gin.Context) {
 		achievementModel.CheckAndUnlockAchievements(uid, "movie_finished")
 	}
 
+	if data.IsUpdatingTags {
+		go addUserTags(u.Database, uid, updatedWatchList.Tags)
+	}
+
+	go recordListChange(u.Database, uid, "movie", models.UpdateListChangeAction, movieList, updatedWatchList)
+
+	go recordConsumptionSession(
+		u.Database, uid, "movie", movieList.ID.Hex(),
+		movieList.Status, updatedWatchList.Status, movieList.TimesFinished, updatedWatchList.Score,
+	)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Movie list updated.", "data": updatedWatchList})
 }
 
@@ -919,7 +1248,7 @@ func (u *UserListController) IncrementTVSeriesListEpisodeSeasonByID(c *gin.Conte
 		ID: tvList.TvID,
 	})
 
-	if updatedTVList, err = userListModel.IncrementTVSeriesListEpisodeSeasonByID(tvList, tvSeries, data); err != nil {
+	if updatedTVList, err = incrementTVSeriesList(u.Database, uid, tvList, tvSeries, *data.IsEpisode, nil); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error(),
 		})
@@ -939,6 +1268,13 @@ func (u *UserListController) IncrementTVSeriesListEpisodeSeasonByID(c *gin.Conte
 		ContentID:        updatedTVList.TvID,
 	})
 
+	go recordListChange(u.Database, uid, "tv", models.UpdateListChangeAction, tvList, updatedTVList)
+
+	go recordConsumptionSession(
+		u.Database, uid, "tv", tvList.ID.Hex(),
+		tvList.Status, updatedTVList.Status, tvList.TimesFinished, updatedTVList.Score,
+	)
+
 	c.JSON(http.StatusOK, gin.H{"message": "TV series watch list updated.", "data": updatedTVList})
 }
 
@@ -985,6 +1321,11 @@ func (u *UserListController) UpdateTVSeriesListByID(c *gin.Context) {
 		return
 	}
 
+	if isListUpdateDateRangeInvalid(data.IsUpdatingDates, data.StartedAt, data.FinishedAt, tvList.StartedAt, tvList.FinishedAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidListDates})
+		return
+	}
+
 	tvSeriesModel := models.NewTVModel(u.Database)
 	tvSeries, _ := tvSeriesModel.GetTVSeriesDetails(requests.ID{
 		ID: tvList.TvID,
@@ -1020,17 +1361,32 @@ func (u *UserListController) UpdateTVSeriesListByID(c *gin.Context) {
 		achievementModel.CheckAndUnlockAchievements(uid, "tv_finished")
 	}
 
+	if data.IsUpdatingTags {
+		go addUserTags(u.Database, uid, updatedTVList.Tags)
+	}
+
+	go recordListChange(u.Database, uid, "tv", models.UpdateListChangeAction, tvList, updatedTVList)
+
+	go recordConsumptionSession(
+		u.Database, uid, "tv", tvList.ID.Hex(),
+		tvList.Status, updatedTVList.Status, tvList.TimesFinished, updatedTVList.Score,
+	)
+
 	c.JSON(http.StatusOK, gin.H{"message": "TV series watch list updated.", "data": updatedTVList})
 }
 
 // Get User List
 // @Summary Get User List by User ID
-// @Description Returns user list by user id with optional status filtering
+// @Description Returns user list by user id with optional status, priority, tag and finish date filtering
 // @Tags user_list
 // @Accept application/json
 // @Produce application/json
 // @Param sortlist query requests.SortList true "Sort List"
 // @Param status query string false "Status Filter" Enums(active, finished, dropped)
+// @Param priority query int false "Priority Filter"
+// @Param finished_from query string false "Finished From" format(date)
+// @Param finished_to query string false "Finished To" format(date)
+// @Param tag query string false "Tag Filter"
 // @Security BearerAuth
 // @Param Authorization header string true "Authentication header"
 // @Success 200 {object} responses.UserList
@@ -1128,6 +1484,7 @@ func (u *UserListController) DeleteListByUserIDAndType(c *gin.Context) {
 		contentTitle string
 		contentImage string
 		contentID    string
+		deletedList  interface{}
 	)
 
 	switch data.Type {
@@ -1142,6 +1499,19 @@ func (u *UserListController) DeleteListByUserIDAndType(c *gin.Context) {
 		contentTitle = anime.TitleOriginal
 		contentImage = anime.ImageURL
 		contentID = animeList.AnimeID
+		deletedList = animeList
+	case "manga":
+		mangaModel := models.NewMangaModel(u.Database)
+
+		mangaList, _ := userListModel.GetBaseMangaListByID(data.ID)
+		manga, _ := mangaModel.GetMangaDetails(requests.ID{
+			ID: mangaList.MangaID,
+		})
+
+		contentTitle = manga.TitleOriginal
+		contentImage = manga.ImageURL
+		contentID = mangaList.MangaID
+		deletedList = mangaList
 	case "game":
 		gameModel := models.NewGameModel(u.Database)
 
@@ -1153,6 +1523,7 @@ func (u *UserListController) DeleteListByUserIDAndType(c *gin.Context) {
 		contentTitle = game.Title
 		contentImage = game.ImageUrl
 		contentID = gameList.GameID
+		deletedList = gameList
 	case "movie":
 		movieModel := models.NewMovieModel(u.Database)
 
@@ -1164,6 +1535,7 @@ func (u *UserListController) DeleteListByUserIDAndType(c *gin.Context) {
 		contentTitle = movie.TitleEn
 		contentImage = movie.ImageURL
 		contentID = movieList.MovieID
+		deletedList = movieList
 	case "tv":
 		tvSeriesModel := models.NewTVModel(u.Database)
 
@@ -1175,6 +1547,7 @@ func (u *UserListController) DeleteListByUserIDAndType(c *gin.Context) {
 		contentTitle = tvSeries.TitleEn
 		contentImage = tvSeries.ImageURL
 		contentID = tvList.TvID
+		deletedList = tvList
 	}
 
 	isDeleted, err := userListModel.DeleteListByUserIDAndType(uid, data)
@@ -1187,6 +1560,8 @@ func (u *UserListController) DeleteListByUserIDAndType(c *gin.Context) {
 	}
 
 	if isDeleted {
+		go deleteListRecords(u.Database, uid, data.Type, data.ID, deletedList, false)
+
 		logModel := models.NewLogsModel(u.Database)
 
 		go logModel.CreateLog(uid, requests.CreateLog{
@@ -1205,3 +1580,208 @@ func (u *UserListController) DeleteListByUserIDAndType(c *gin.Context) {
 
 	c.JSON(http.StatusNotFound, gin.H{"error": ErrNotFound})
 }
+
+// Bulk Update List
+// @Summary Bulk Update List
+// @Description Sets status or score, deletes or moves to consume later multiple list entries at once
+// @Tags user_list
+// @Accept application/json
+// @Produce application/json
+// @Param bulkupdatelist body requests.BulkUpdateList true "Bulk Update List"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {array} responses.BulkListItemResult
+// @Failure 400 {string} string
+// @Failure 403 {string} string
+// @Failure 500 {string} string
+// @Router /list/bulk [patch]
+func (u *UserListController) BulkUpdateList(c *gin.Context) {
+	var data requests.BulkUpdateList
+	if shouldReturn := bindJSONData(&data, c); shouldReturn {
+		return
+	}
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+
+	userModel := models.NewUserModel(u.Database)
+	userListModel := models.NewUserListModel(u.Database)
+
+	isPremium, _ := userModel.IsUserPremium(uid)
+
+	// Free members can change up to the limit at once. Once the list is over the
+	// limit, e.g. after premium ends, entries can only be removed from it.
+	if !isPremium {
+		count, err := userListModel.GetUserListEntryCount(uid)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": err.Error(),
+			})
+
+			return
+		}
+
+		isRemoving := data.Operation == "delete" || data.Operation == "later"
+		if len(data.Items) > models.UserListLimit || (count > models.UserListLimit && !isRemoving) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": errBulkUserListPremium,
+			})
+
+			return
+		}
+	}
+
+	listIDsByType := make(map[string][]string)
+	for _, item := range data.Items {
+		listIDsByType[item.Type] = append(listIDsByType[item.Type], item.ID)
+	}
+
+	listEntries, err := userListModel.GetListEntriesByIDs(listIDsByType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	consumeLaterCapacity := int64(len(data.Items))
+	if data.Operation == "later" && !isPremium {
+		userInteractionModel := models.NewUserInteractionModel(u.Database)
+		consumeLaterCapacity = models.ConsumeLaterLimit - userInteractionModel.GetConsumeLaterCount(uid)
+	}
+
+	var (
+		entries      []models.ListEntry
+		entryIndexes []int
+	)
+
+	results := make([]responses.BulkListItemResult, len(data.Items))
+	seenItems := make(map[string]bool)
+
+	for index, item := range data.Items {
+		results[index] = responses.BulkListItemResult{
+			ID:   item.ID,
+			Type: item.Type,
+		}
+
+		if seenItems[item.Type+item.ID] {
+			results[index].Error = &[]string{"Duplicate item."}[0]
+			continue
+		}
+		seenItems[item.Type+item.ID] = true
+
+		entry, ok := listEntries[item.Type+item.ID]
+		if !ok {
+			results[index].Error = &[]string{ErrNotFound}[0]
+			continue
+		}
+
+		if entry.UserID != uid {
+			results[index].Error = &[]string{ErrUnauthorized}[0]
+			continue
+		}
+
+		if data.Operation == "later" {
+			if item.Type == "manga" {
+				results[index].Error = &[]string{errMangaConsumeLater}[0]
+				continue
+			}
+
+			if consumeLaterCapacity <= 0 {
+				results[index].Error = &[]string{errConsumeLaterPremium}[0]
+				continue
+			}
+			consumeLaterCapacity--
+		}
+
+		entries = append(entries, entry)
+		entryIndexes = append(entryIndexes, index)
+	}
+
+	if len(entries) > 0 {
+		if err := userListModel.BulkUpdateListEntries(uid, data, entries); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": err.Error(),
+			})
+
+			return
+		}
+	}
+
+	for _, index := range entryIndexes {
+		results[index].IsSuccess = true
+	}
+
+	if len(entries) > 0 {
+		handleBulkUpdateList(u.Database, uid, data, entries)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Bulk operation applied.", "data": results})
+}
+
+// handleBulkUpdateList runs the side effects of single item handlers for every
+// entry, logs of the entries are created at once.
+func handleBulkUpdateList(database *db.MongoDB, uid string, data requests.BulkUpdateList, entries []models.ListEntry) {
+	listChangeModel := models.NewListChangeModel(database)
+	listWriteModel := models.NewListWriteModel(database)
+
+	switch data.Operation {
+	case "status":
+		finishedTypes := make(map[string]bool)
+
+		for index, entry := range entries {
+			go recordConsumptionSession(
+				database, uid, entry.ContentType, entry.ID.Hex(),
+				entry.Status, *data.Status, entry.TimesFinished, entry.Score,
+			)
+
+			go listChangeModel.CreateListFieldChange(uid, entry.ContentType, entry.List, "status", *data.Status)
+
+			if entry.Status != "finished" && *data.Status == "finished" {
+				finishedTypes[entry.ContentType] = true
+			}
+
+			entries[index].Status = *data.Status
+		}
+
+		achievementModel := models.NewAchievementModel(database)
+		for contentType := range finishedTypes {
+			achievementModel.CheckAndUnlockAchievements(uid, contentType+"_finished")
+		}
+
+		go listWriteModel.CreateListEntryLogs(uid, models.UserListLogType, models.UpdateLogAction, entries)
+	case "score":
+		for _, entry := range entries {
+			go listChangeModel.CreateListFieldChange(uid, entry.ContentType, entry.List, "score", data.Score)
+		}
+
+		go listWriteModel.CreateListEntryLogs(uid, models.UserListLogType, models.UpdateLogAction, entries)
+	case "delete", "later":
+		for _, entry := range entries {
+			go deleteListRecords(database, uid, entry.ContentType, entry.ID.Hex(), entry.List, data.Operation == "later")
+		}
+
+		go listWriteModel.CreateListEntryLogs(uid, models.UserListLogType, models.DeleteLogAction, entries)
+
+		if data.Operation == "later" {
+			go listWriteModel.CreateListEntryLogs(uid, models.ConsumeLaterLogType, models.AddLogAction, entries)
+
+			achievementModel := models.NewAchievementModel(database)
+			achievementModel.CheckAndUnlockAchievements(uid, "watch_later")
+		}
+	}
+}
+
+func isListDateRangeInvalid(startedAt, finishedAt *time.Time) bool {
+	return startedAt != nil && finishedAt != nil && finishedAt.Before(*startedAt)
+}
+
+// isListUpdateDateRangeInvalid checks the dates the entry will have after the
+// update instead of only the dates sent in the request.
+func isListUpdateDateRangeInvalid(isUpdatingDates bool, startedAt, finishedAt, storedStartedAt, storedFinishedAt *time.Time) bool {
+	if !isUpdatingDates {
+		return false
+	}
+
+	return isListDateRangeInvalid(models.MergeListDates(startedAt, finishedAt, storedStartedAt, storedFinishedAt))
+}
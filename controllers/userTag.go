@@ -0,0 +1,222 @@
+package controllers
+
+import (
+	"app/db"
+	"app/models"
+	"app/requests"
+	"net/http"
+	"strings"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+)
+
+type UserTagController struct {
+	Database *db.MongoDB
+}
+
+const errEmptyTagName = "Tag name can't be empty."
+
+func NewUserTagController(mongoDB *db.MongoDB) UserTagController {
+	return UserTagController{
+		Database: mongoDB,
+	}
+}
+
+// Get User Tags
+// @Summary Get User Tags
+// @Description Returns tag vocabulary of the user with entry counts
+// @Tags user_tag
+// @Accept application/json
+// @Produce application/json
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {array} responses.UserTag
+// @Failure 500 {string} string
+// @Router /tag [get]
+func (ut *UserTagController) GetUserTags(c *gin.Context) {
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	userTagModel := models.NewUserTagModel(ut.Database)
+
+	userTags, err := userTagModel.GetUserTags(uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": userTags})
+}
+
+// Create User Tag
+// @Summary Create User Tag
+// @Description Adds tag to the tag vocabulary of the user
+// @Tags user_tag
+// @Accept application/json
+// @Produce application/json
+// @Param createusertag body requests.CreateUserTag true "Create User Tag"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 201 {string} string
+// @Failure 500 {string} string
+// @Router /tag [post]
+func (ut *UserTagController) CreateUserTag(c *gin.Context) {
+	var data requests.CreateUserTag
+	if shouldReturn := bindJSONData(&data, c); shouldReturn {
+		return
+	}
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	userTagModel := models.NewUserTagModel(ut.Database)
+
+	if err := userTagModel.AddUserTags(uid, []string{data.Name}); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Successfully created."})
+}
+
+// Rename User Tag
+// @Summary Rename User Tag
+// @Description Renames tag and every entry tagged with it, merges if the new name exists
+// @Tags user_tag
+// @Accept application/json
+// @Produce application/json
+// @Param renameusertag body requests.RenameUserTag true "Rename User Tag"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {string} string
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /tag/rename [patch]
+func (ut *UserTagController) RenameUserTag(c *gin.Context) {
+	var data requests.RenameUserTag
+	if shouldReturn := bindJSONData(&data, c); shouldReturn {
+		return
+	}
+
+	if strings.TrimSpace(data.NewName) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errEmptyTagName})
+		return
+	}
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	userTagModel := models.NewUserTagModel(ut.Database)
+
+	isRenamed, err := userTagModel.MergeUserTags(uid, []string{data.Name}, data.NewName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	if isRenamed {
+		c.JSON(http.StatusOK, gin.H{"message": "Tag renamed successfully."})
+		return
+	}
+
+	c.JSON(http.StatusNotFound, gin.H{"error": ErrNotFound})
+}
+
+// Merge User Tags
+// @Summary Merge User Tags
+// @Description Replaces tags with a single tag on every entry
+// @Tags user_tag
+// @Accept application/json
+// @Produce application/json
+// @Param mergeusertags body requests.MergeUserTags true "Merge User Tags"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {string} string
+// @Failure 400 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /tag/merge [patch]
+func (ut *UserTagController) MergeUserTags(c *gin.Context) {
+	var data requests.MergeUserTags
+	if shouldReturn := bindJSONData(&data, c); shouldReturn {
+		return
+	}
+
+	if strings.TrimSpace(data.Into) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errEmptyTagName})
+		return
+	}
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	userTagModel := models.NewUserTagModel(ut.Database)
+
+	isMerged, err := userTagModel.MergeUserTags(uid, data.Names, data.Into)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	if isMerged {
+		c.JSON(http.StatusOK, gin.H{"message": "Tags merged successfully."})
+		return
+	}
+
+	c.JSON(http.StatusNotFound, gin.H{"error": ErrNotFound})
+}
+
+// Delete User Tag
+// @Summary Delete User Tag
+// @Description Deletes tag and removes it from every entry
+// @Tags user_tag
+// @Accept application/json
+// @Produce application/json
+// @Param deleteusertag body requests.DeleteUserTag true "Delete User Tag"
+// @Security BearerAuth
+// @Param Authorization header string true "Authentication header"
+// @Success 200 {string} string
+// @Failure 404 {string} string
+// @Failure 500 {string} string
+// @Router /tag [delete]
+func (ut *UserTagController) DeleteUserTag(c *gin.Context) {
+	var data requests.DeleteUserTag
+	if shouldReturn := bindJSONData(&data, c); shouldReturn {
+		return
+	}
+
+	uid := jwt.ExtractClaims(c)["id"].(string)
+	userTagModel := models.NewUserTagModel(ut.Database)
+
+	isDeleted, err := userTagModel.DeleteUserTag(uid, data.Name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+
+		return
+	}
+
+	if isDeleted {
+		c.JSON(http.StatusOK, gin.H{"message": "Tag deleted successfully."})
+		return
+	}
+
+	c.JSON(http.StatusNotFound, gin.H{"error": ErrNotFound})
+}
+
+// addUserTags keeps the tag vocabulary in sync with the tags used on entries.
+func addUserTags(database *db.MongoDB, uid string, tags []string) {
+	if len(tags) == 0 {
+		return
+	}
+
+	userTagModel := models.NewUserTagModel(database)
+	userTagModel.AddUserTags(uid, tags)
+}
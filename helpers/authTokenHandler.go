@@ -13,6 +13,8 @@ import (
 
 	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
+	jwtToken "github.com/golang-jwt/jwt/v4"
+	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 )
 
@@ -23,9 +25,13 @@ var (
 	errMissingAuth   = errors.New("Missing email or password")
 	errIncorrectAuth = errors.New("Incorrect email or password")
 	errEmptyPassword = errors.New("Password is empty")
+	errRevokedToken  = errors.New("Session is ended, please login again")
 )
 
-func SetupJWTHandler(mongoDB *db.MongoDB) *jwt.GinJWTMiddleware {
+func SetupJWTHandler(mongoDB *db.MongoDB, redisClient *redis.Client) *jwt.GinJWTMiddleware {
+	key := []byte(os.Getenv("JWT_SECRET_KEY"))
+	sessionModel := models.NewSessionModel(mongoDB, redisClient)
+
 	// port := os.Getenv("PORT")
 	r := gin.New()
 	r.Use(gin.Logger())
@@ -33,10 +39,35 @@ func SetupJWTHandler(mongoDB *db.MongoDB) *jwt.GinJWTMiddleware {
 
 	authMiddleware, err := jwt.New(&jwt.GinJWTMiddleware{
 		Realm:       "project-consumer",
-		Key:         []byte(os.Getenv("JWT_SECRET_KEY")),
-		Timeout:     time.Hour * 730,  // 1 month
-		MaxRefresh:  time.Hour * 2190, // 3 months
+		Key:         key,
+		Timeout:     models.AccessTokenTimeout, // Sessions are refreshed with their refresh token.
 		IdentityKey: identityKey,
+		// Tokens of the revoked sessions are rejected. Tokens issued before the
+		// sessions have no session, they are rejected if the user's sessions are
+		// revoked after they are issued, e.g. the password is changed.
+		KeyFunc: func(token *jwtToken.Token) (interface{}, error) {
+			if token.Method != jwtToken.SigningMethodHS256 {
+				return nil, jwt.ErrInvalidSigningAlgorithm
+			}
+
+			claims, _ := token.Claims.(jwtToken.MapClaims)
+			sessionID, _ := claims[sessionKey].(string)
+			if sessionID != "" {
+				if sessionModel.IsSessionRevoked(sessionID) {
+					return nil, errRevokedToken
+				}
+
+				return key, nil
+			}
+
+			uid, _ := claims[identityKey].(string)
+			issuedAt, _ := claims["orig_iat"].(float64)
+			if uid == "" || sessionModel.IsUserTokenRevoked(uid, time.Unix(int64(issuedAt), 0)) {
+				return nil, errRevokedToken
+			}
+
+			return key, nil
+		},
 		Authenticator: func(c *gin.Context) (interface{}, error) {
 			var data requests.Login
 			if err := c.Bind(&data); err != nil {
@@ -62,17 +93,20 @@ func SetupJWTHandler(mongoDB *db.MongoDB) *jwt.GinJWTMiddleware {
 				return "", errIncorrectAuth
 			}
 
+			fcmToken := user.FCMToken
 			if data.FCMToken != nil && user.FCMToken != *data.FCMToken {
-				user.FCMToken = *data.FCMToken
+				fcmToken = *data.FCMToken
+				user.FCMToken = fcmToken
 				go userModel.UpdateUser(user)
 			}
 
-			return user, nil
+			return startSession(c, sessionModel, user.ID.Hex(), data.DeviceName, fcmToken)
 		},
 		PayloadFunc: func(data interface{}) jwt.MapClaims {
-			if user, ok := data.(models.User); ok {
+			if identity, ok := data.(sessionIdentity); ok {
 				return jwt.MapClaims{
-					identityKey: user.ID,
+					identityKey: identity.UserID,
+					sessionKey:  identity.SessionID,
 				}
 			}
 			return jwt.MapClaims{}
@@ -84,12 +118,9 @@ func SetupJWTHandler(mongoDB *db.MongoDB) *jwt.GinJWTMiddleware {
 			})
 		},
 		LoginResponse: func(c *gin.Context, code int, token string, expire time.Time) {
-			c.SetCookie("access_token", token, 2592000, "/", os.Getenv("BASE_URI"), true, true)
-			c.JSON(http.StatusOK, gin.H{"access_token": token})
-		},
-		RefreshResponse: func(c *gin.Context, code int, token string, expire time.Time) {
-			c.SetCookie("access_token", token, 2592000, "/", os.Getenv("BASE_URI"), true, true)
-			c.JSON(http.StatusOK, gin.H{"access_token": token})
+			refreshToken := c.GetString(refreshTokenKey)
+			SetSessionCookies(c, token, refreshToken)
+			c.JSON(http.StatusOK, gin.H{"access_token": token, "refresh_token": refreshToken, "expire": expire})
 		},
 		TokenLookup:    "header: Authorization, cookie: access_token",
 		TimeFunc:       time.Now,
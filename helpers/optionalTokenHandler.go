@@ -1,59 +1,69 @@
 package helpers
 
 import (
+	"encoding/json"
 	"errors"
-	"strings"
+	"net/http"
 
+	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt"
 )
 
-func ExtractBearerToken(header string) (string, bool) {
-	if header == "" {
-		return "", false
-	}
-
-	jwtToken := strings.Split(header, " ")
-	if len(jwtToken) != 2 {
-		return "", false
-	}
+// OptionalAuthMiddleware identifies the user of the request if it has a token,
+// the token is looked up and verified with the config of the jwt middleware.
+// Requests without a token are anonymous, invalid or expired tokens are
+// rejected as the jwt middleware does.
+func OptionalAuthMiddleware(authMiddleware *jwt.GinJWTMiddleware) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := authMiddleware.GetClaimsFromJWT(c)
+		if errors.Is(err, jwt.ErrEmptyAuthHeader) || errors.Is(err, jwt.ErrEmptyCookieToken) {
+			c.Next()
+			return
+		}
 
-	return jwtToken[1], true
-}
+		if err == nil {
+			err = checkTokenExpiry(authMiddleware, claims)
+		}
 
-func ParseToken(jwtToken string) (*jwt.Token, bool) {
-	token, _ := jwt.Parse(jwtToken, func(token *jwt.Token) (interface{}, error) {
-		if _, OK := token.Method.(*jwt.SigningMethodHMAC); !OK {
-			return nil, errors.New("bad signed method received")
+		if err != nil {
+			c.Abort()
+			authMiddleware.Unauthorized(c, http.StatusUnauthorized, authMiddleware.HTTPStatusMessageFunc(err, c))
+			return
 		}
 
-		return []byte("supersaucysecret"), nil
-	})
+		c.Set("JWT_PAYLOAD", claims)
+		if uid, ok := claims[identityKey].(string); ok {
+			c.Set("uuid", uid)
+		}
 
-	return token, true
+		c.Next()
+	}
 }
 
-func OptionalTokenCheck(c *gin.Context) {
-	jwtToken, isHeaderExist := ExtractBearerToken(c.GetHeader("Authorization"))
-	if isHeaderExist {
+// checkTokenExpiry checks the expiry of the claims the same way jwt middleware
+// does, tokens without expiry are not accepted.
+func checkTokenExpiry(authMiddleware *jwt.GinJWTMiddleware, claims jwt.MapClaims) error {
+	var expiresAt int64
 
-		token, isTokenParsed := ParseToken(jwtToken)
-		if !isTokenParsed {
-			c.Next()
-		} else if token != nil {
-			claims, OK := token.Claims.(jwt.MapClaims)
-			if !OK {
-				c.Next()
-			} else {
-				claimedUID, OK := claims["id"].(string)
-				if !OK {
-					c.Next()
-				} else {
-					c.Set("uuid", claimedUID)
-				}
-			}
+	switch exp := claims["exp"].(type) {
+	case nil:
+		return jwt.ErrMissingExpField
+	case float64:
+		expiresAt = int64(exp)
+	case json.Number:
+		value, err := exp.Int64()
+		if err != nil {
+			return jwt.ErrWrongFormatOfExp
 		}
+
+		expiresAt = value
+	default:
+		return jwt.ErrWrongFormatOfExp
+	}
+
+	if expiresAt < authMiddleware.TimeFunc().Unix() {
+		return jwt.ErrExpiredToken
 	}
 
-	c.Next()
+	return nil
 }
@@ -0,0 +1,121 @@
+package helpers
+
+import (
+	"app/models"
+	"errors"
+	"os"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+	jwtToken "github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	sessionKey      = "sid"
+	refreshTokenKey = "refresh_token"
+)
+
+// sessionIdentity is the payload of the access tokens, tokens are tied to the
+// session so that they can be revoked.
+type sessionIdentity struct {
+	UserID    string
+	SessionID string
+}
+
+// startSession creates the session of the device, the refresh token is kept in
+// the context to be responded with the access token.
+func startSession(c *gin.Context, sessionModel *models.SessionModel, uid string, deviceName *string, fcmToken string) (interface{}, error) {
+	name := c.Request.UserAgent()
+	if deviceName != nil && *deviceName != "" {
+		name = *deviceName
+	}
+
+	session, refreshToken, err := sessionModel.CreateSession(uid, name, fcmToken, c.ClientIP())
+	if err != nil {
+		return nil, err
+	}
+
+	c.Set(refreshTokenKey, refreshToken)
+
+	return sessionIdentity{
+		UserID:    uid,
+		SessionID: session.ID.Hex(),
+	}, nil
+}
+
+// IssueSessionTokens starts a session for the logins that don't go through the
+// login handler, e.g. OAuth, and returns its access and refresh tokens.
+func IssueSessionTokens(c *gin.Context, authMiddleware *jwt.GinJWTMiddleware, sessionModel *models.SessionModel, uid string, deviceName *string, fcmToken string) (string, string, error) {
+	identity, err := startSession(c, sessionModel, uid, deviceName, fcmToken)
+	if err != nil {
+		return "", "", err
+	}
+
+	accessToken, _, err := authMiddleware.TokenGenerator(identity)
+	if err != nil {
+		return "", "", err
+	}
+
+	return accessToken, c.GetString(refreshTokenKey), nil
+}
+
+// RefreshSessionTokens rotates the refresh token and returns the new tokens of
+// the session, tokens are empty if the refresh token is not valid.
+func RefreshSessionTokens(c *gin.Context, authMiddleware *jwt.GinJWTMiddleware, sessionModel *models.SessionModel, refreshToken string) (string, string, error) {
+	session, newRefreshToken, err := sessionModel.RotateRefreshToken(refreshToken, c.ClientIP())
+	if err != nil || session.UserID == "" {
+		return "", "", err
+	}
+
+	accessToken, _, err := authMiddleware.TokenGenerator(sessionIdentity{
+		UserID:    session.UserID,
+		SessionID: session.ID.Hex(),
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return accessToken, newRefreshToken, nil
+}
+
+// GetSessionID returns the session of the access token.
+func GetSessionID(c *gin.Context) string {
+	sessionID, _ := jwt.ExtractClaims(c)[sessionKey].(string)
+	return sessionID
+}
+
+// GetLogoutSessionID returns the session of the access token, expired tokens
+// are accepted so that the session can be ended after its token expires.
+func GetLogoutSessionID(c *gin.Context, authMiddleware *jwt.GinJWTMiddleware) string {
+	token, err := authMiddleware.ParseToken(c)
+	if token == nil {
+		return ""
+	}
+
+	if err != nil {
+		var validationErr *jwtToken.ValidationError
+		if !errors.As(err, &validationErr) || validationErr.Errors != jwtToken.ValidationErrorExpired {
+			return ""
+		}
+	}
+
+	claims, _ := token.Claims.(jwtToken.MapClaims)
+	sessionID, _ := claims[sessionKey].(string)
+	return sessionID
+}
+
+// GetRefreshTokenCookie returns the refresh token of the web clients.
+func GetRefreshTokenCookie(c *gin.Context) string {
+	refreshToken, _ := c.Cookie(refreshTokenKey)
+	return refreshToken
+}
+
+func SetSessionCookies(c *gin.Context, accessToken, refreshToken string) {
+	c.SetCookie("access_token", accessToken, int(models.AccessTokenTimeout.Seconds()), "/", os.Getenv("BASE_URI"), true, true)
+	c.SetCookie(refreshTokenKey, refreshToken, int(models.RefreshTokenTimeout.Seconds()), "/", os.Getenv("BASE_URI"), true, true)
+}
+
+func ClearSessionCookies(c *gin.Context) {
+	c.SetCookie("access_token", "", -1, "/", os.Getenv("BASE_URI"), true, true)
+	c.SetCookie(refreshTokenKey, "", -1, "/", os.Getenv("BASE_URI"), true, true)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"app/controllers"
 	"app/db"
 	"app/docs"
 	"app/helpers"
@@ -63,7 +64,12 @@ func main() {
 
 	utils.InitCipher()
 
-	jwtHandler := helpers.SetupJWTHandler(mongoDB)
+	controllers.StartImportWorkers(mongoDB)
+	controllers.StartLinkedAccountScheduler(mongoDB)
+	controllers.StartDataExportCleanup(mongoDB)
+	controllers.CreatePurchaseIndexes(mongoDB)
+
+	jwtHandler := helpers.SetupJWTHandler(mongoDB, redisClient)
 
 	logrus.SetFormatter(&logrus.JSONFormatter{
 		TimestampFormat: time.RFC822,
@@ -334,15 +334,16 @@ var achievementMap = map[string]struct {
 }
 
 // CheckAndUnlockAchievements checks and unlocks achievements for a user based on their activity
-// This runs asynchronously to avoid impacting response times
-func (achievementModel *AchievementModel) CheckAndUnlockAchievements(uid string, activityType string) {
+// This runs asynchronously to avoid impacting response times, bulk writes e.g. imports pass every
+// activity at once so that achievements are checked once
+func (achievementModel *AchievementModel) CheckAndUnlockAchievements(uid string, activityTypes ...string) {
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {
 				logrus.WithFields(logrus.Fields{
-					"user_id":       uid,
-					"activity_type": activityType,
-					"panic":         r,
+					"user_id":        uid,
+					"activity_types": activityTypes,
+					"panic":          r,
 				}).Error("panic in achievement checker")
 			}
 		}()
@@ -352,24 +353,36 @@ func (achievementModel *AchievementModel) CheckAndUnlockAchievements(uid string,
 
 		// Check relevant achievements based on activity type
 		var achievementsToCheck []string
+		isAdded := map[string]bool{}
+
+		for _, activityType := range activityTypes {
+			var activityAchievements []string
+
+			switch activityType {
+			case "first_activity":
+				activityAchievements = []string{"first_steps"}
+			case "review":
+				activityAchievements = []string{"reviewer", "critic"}
+			case "movie_finished":
+				activityAchievements = []string{"silver_screen_scout", "reel_explorer", "cinema_sage", "devoted_soul"}
+			case "tv_finished":
+				activityAchievements = []string{"pilot_hunter", "seasoned_binger", "episodic_legend", "devoted_soul"}
+			case "game_finished":
+				activityAchievements = []string{"rookie_adventurer", "pixel_challenger", "digital_conqueror", "devoted_soul"}
+			case "anime_finished":
+				activityAchievements = []string{"newcomer_to_nippon", "story_arc_wanderer", "legend_of_the_otaku", "devoted_soul"}
+			case "manga_finished":
+				activityAchievements = []string{"devoted_soul"}
+			case "watch_later":
+				activityAchievements = []string{"future_watcher", "content_collector", "archiver_of_anticipation"}
+			}
 
-		switch activityType {
-		case "first_activity":
-			achievementsToCheck = []string{"first_steps"}
-		case "review":
-			achievementsToCheck = []string{"reviewer", "critic"}
-		case "movie_finished":
-			achievementsToCheck = []string{"silver_screen_scout", "reel_explorer", "cinema_sage", "devoted_soul"}
-		case "tv_finished":
-			achievementsToCheck = []string{"pilot_hunter", "seasoned_binger", "episodic_legend", "devoted_soul"}
-		case "game_finished":
-			achievementsToCheck = []string{"rookie_adventurer", "pixel_challenger", "digital_conqueror", "devoted_soul"}
-		case "anime_finished":
-			achievementsToCheck = []string{"newcomer_to_nippon", "story_arc_wanderer", "legend_of_the_otaku", "devoted_soul"}
-		case "watch_later":
-			achievementsToCheck = []string{"future_watcher", "content_collector", "archiver_of_anticipation"}
-		default:
-			return
+			for _, achievementKey := range activityAchievements {
+				if !isAdded[achievementKey] {
+					isAdded[achievementKey] = true
+					achievementsToCheck = append(achievementsToCheck, achievementKey)
+				}
+			}
 		}
 
 		for _, achievementKey := range achievementsToCheck {
@@ -430,6 +443,8 @@ func (achievementModel *AchievementModel) getUserFinishedCount(uid, contentType
 		collectionName = "game-lists"
 	case "anime":
 		collectionName = "anime-lists"
+	case "manga":
+		collectionName = "manga-lists"
 	default:
 		return 0
 	}
@@ -448,7 +463,7 @@ func (achievementModel *AchievementModel) getUserMaxTimesFinished(uid string) in
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	collections := []string{"movie-watch-lists", "tvseries-watch-lists", "game-lists", "anime-lists"}
+	collections := []string{"movie-watch-lists", "tvseries-watch-lists", "game-lists", "anime-lists", "manga-lists"}
 	maxTimes := int64(0)
 
 	for _, collectionName := range collections {
@@ -474,6 +489,32 @@ func (achievementModel *AchievementModel) getUserMaxTimesFinished(uid string) in
 		cursor.Close(ctx)
 	}
 
+	// Finished sessions are the source of truth for entries with history
+	pipeline := []bson.M{
+		{"$match": bson.M{"user_id": uid, "is_finished": true}},
+		{"$group": bson.M{
+			"_id":   "$list_id",
+			"count": bson.M{"$sum": 1},
+		}},
+		{"$group": bson.M{
+			"_id":       nil,
+			"max_times": bson.M{"$max": "$count"},
+		}},
+	}
+
+	cursor, err := achievementModel.AchievementCollection.Database().Collection("consumption-sessions").Aggregate(ctx, pipeline)
+	if err != nil {
+		return maxTimes
+	}
+	defer cursor.Close(ctx)
+
+	var result []bson.M
+	if err := cursor.All(ctx, &result); err == nil && len(result) > 0 {
+		if times, ok := result[0]["max_times"].(int32); ok && int64(times) > maxTimes {
+			maxTimes = int64(times)
+		}
+	}
+
 	return maxTimes
 }
 
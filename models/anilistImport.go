@@ -22,67 +22,32 @@ import (
 //lint:file-ignore ST1005 Ignore all
 
 type AniListImportModel struct {
-	AnimeCollection     *mongo.Collection
-	MangaCollection     *mongo.Collection
-	AnimeListCollection *mongo.Collection
-	MangaListCollection *mongo.Collection
+	AnimeCollection *mongo.Collection
+	MangaCollection *mongo.Collection
 }
 
 func NewAniListImportModel(mongoDB *db.MongoDB) *AniListImportModel {
 	return &AniListImportModel{
-		AnimeCollection:     mongoDB.Database.Collection("animes"),
-		MangaCollection:     mongoDB.Database.Collection("mangas"),
-		AnimeListCollection: mongoDB.Database.Collection("anime-lists"),
-		MangaListCollection: mongoDB.Database.Collection("manga-lists"),
+		AnimeCollection: mongoDB.Database.Collection("animes"),
+		MangaCollection: mongoDB.Database.Collection("mangas"),
 	}
 }
 
-func (a *AniListImportModel) ImportUserLists(userID, anilistUsername string) (responses.AniListImportResponse, error) {
+func (a *AniListImportModel) FetchUserEntries(anilistUsername string) ([]*ImportEntry, error) {
 	logrus.WithFields(logrus.Fields{
-		"user_id":          userID,
 		"anilist_username": anilistUsername,
 	}).Info("Starting AniList import")
 
-	// Get existing entries in bulk
-	existingAnimeEntries, err := a.getExistingAnimeEntries(userID)
-	if err != nil {
-		return responses.AniListImportResponse{}, fmt.Errorf("failed to get existing anime entries: %v", err)
-	}
-
-	existingMangaEntries, err := a.getExistingMangaEntries(userID)
-	if err != nil {
-		return responses.AniListImportResponse{}, fmt.Errorf("failed to get existing manga entries: %v", err)
-	}
-
-	// Get all AniList IDs from database
-	animeAniListIDs, err := a.getAllAnimeAniListIDs()
-	if err != nil {
-		return responses.AniListImportResponse{}, fmt.Errorf("failed to get anime AniList IDs: %v", err)
-	}
-
-	mangaAniListIDs, err := a.getAllMangaAniListIDs()
-	if err != nil {
-		return responses.AniListImportResponse{}, fmt.Errorf("failed to get manga AniList IDs: %v", err)
-	}
-
-	logrus.WithFields(logrus.Fields{
-		"user_id":             userID,
-		"anime_anilist_count": len(animeAniListIDs),
-		"manga_anilist_count": len(mangaAniListIDs),
-	}).Info("loaded AniList mappings from database")
-
-	var importedCount, skippedCount, errorCount int
-	var animeEntriesToInsert []interface{}
-	var mangaEntriesToInsert []interface{}
-	var importedTitles, skippedTitles []string
+	anilistEntries := newImportEntries()
 
 	// Fetch anime list
 	animeEntries, err := a.fetchUserMediaList(anilistUsername, "ANIME")
 	if err != nil {
 		logrus.WithError(err).Warn("failed to fetch anime list, continuing with manga")
 	} else {
-		a.processAnimeEntries(animeEntries, userID, animeAniListIDs, existingAnimeEntries,
-			&animeEntriesToInsert, &importedCount, &skippedCount, &errorCount, &importedTitles, &skippedTitles)
+		for _, entry := range animeEntries {
+			anilistEntries.add(a.importEntry(entry, "anime"))
+		}
 	}
 
 	// Fetch manga list
@@ -90,54 +55,12 @@ func (a *AniListImportModel) ImportUserLists(userID, anilistUsername string) (re
 	if err != nil {
 		logrus.WithError(err).Warn("failed to fetch manga list")
 	} else {
-		a.processMangaEntries(mangaEntries, userID, mangaAniListIDs, existingMangaEntries,
-			&mangaEntriesToInsert, &importedCount, &skippedCount, &errorCount, &importedTitles, &skippedTitles)
-	}
-
-	// Bulk insert anime entries
-	if len(animeEntriesToInsert) > 0 {
-		_, err = a.AnimeListCollection.InsertMany(context.TODO(), animeEntriesToInsert)
-		if err != nil {
-			logrus.WithFields(logrus.Fields{
-				"user_id": userID,
-				"count":   len(animeEntriesToInsert),
-				"error":   err.Error(),
-			}).Error("failed to bulk insert anime entries")
-			return responses.AniListImportResponse{}, fmt.Errorf("failed to import anime entries: %v", err)
+		for _, entry := range mangaEntries {
+			anilistEntries.add(a.importEntry(entry, "manga"))
 		}
 	}
 
-	// Bulk insert manga entries
-	if len(mangaEntriesToInsert) > 0 {
-		_, err = a.MangaListCollection.InsertMany(context.TODO(), mangaEntriesToInsert)
-		if err != nil {
-			logrus.WithFields(logrus.Fields{
-				"user_id": userID,
-				"count":   len(mangaEntriesToInsert),
-				"error":   err.Error(),
-			}).Error("failed to bulk insert manga entries")
-			return responses.AniListImportResponse{}, fmt.Errorf("failed to import manga entries: %v", err)
-		}
-	}
-
-	message := fmt.Sprintf("Import completed: %d imported, %d skipped, %d errors",
-		importedCount, skippedCount, errorCount)
-
-	logrus.WithFields(logrus.Fields{
-		"user_id":        userID,
-		"imported_count": importedCount,
-		"skipped_count":  skippedCount,
-		"error_count":    errorCount,
-	}).Info("AniList import completed")
-
-	return responses.AniListImportResponse{
-		ImportedCount:  importedCount,
-		SkippedCount:   skippedCount,
-		ErrorCount:     errorCount,
-		Message:        message,
-		ImportedTitles: importedTitles,
-		SkippedTitles:  skippedTitles,
-	}, nil
+	return anilistEntries.entries, nil
 }
 
 func (a *AniListImportModel) fetchUserMediaList(username, mediaType string) ([]responses.AniListMediaListEntry, error) {
@@ -209,11 +132,70 @@ func (a *AniListImportModel) fetchUserMediaList(username, mediaType string) ([]r
 		}
 	`
 
-	variables := map[string]interface{}{
+	body, err := a.makeAniListRequest(query, map[string]interface{}{
 		"username": username,
 		"type":     mediaType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var graphqlResponse responses.AniListGraphQLResponse
+	if err := json.Unmarshal(body, &graphqlResponse); err != nil {
+		return nil, fmt.Errorf("failed to parse GraphQL response: %v", err)
+	}
+
+	if len(graphqlResponse.Errors) > 0 {
+		return nil, fmt.Errorf("GraphQL errors: %s", graphqlResponse.Errors[0].Message)
 	}
 
+	// Flatten all entries from all lists
+	var allEntries []responses.AniListMediaListEntry
+	for _, list := range graphqlResponse.Data.MediaListCollection.Lists {
+		allEntries = append(allEntries, list.Entries...)
+	}
+
+	return allEntries, nil
+}
+
+// ResolveAniListUsername checks that the user exists and returns the username
+// as AniList has it.
+func (a *AniListImportModel) ResolveAniListUsername(username string) (string, error) {
+	query := `
+		query ($name: String) {
+			User(name: $name) {
+				name
+			}
+		}
+	`
+
+	body, err := a.makeAniListRequest(query, map[string]interface{}{
+		"name": username,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var userResponse struct {
+		Data struct {
+			User *struct {
+				Name string `json:"name"`
+			} `json:"User"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &userResponse); err != nil {
+		return "", fmt.Errorf("failed to parse GraphQL response: %v", err)
+	}
+
+	if userResponse.Data.User == nil {
+		return "", fmt.Errorf("AniList username '%s' not found", username)
+	}
+
+	return userResponse.Data.User.Name, nil
+}
+
+func (a *AniListImportModel) makeAniListRequest(query string, variables map[string]interface{}) ([]byte, error) {
 	requestBody := map[string]interface{}{
 		"query":     query,
 		"variables": variables,
@@ -238,7 +220,7 @@ func (a *AniListImportModel) fetchUserMediaList(username, mediaType string) ([]r
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make GraphQL request: %v", err)
+		return nil, importRequestError("failed to make GraphQL request", err)
 	}
 	defer resp.Body.Close()
 
@@ -247,7 +229,7 @@ func (a *AniListImportModel) fetchUserMediaList(username, mediaType string) ([]r
 	}
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("AniList API returned status code: %d", resp.StatusCode)
+		return nil, importStatusError("AniList API returned status code", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -255,145 +237,49 @@ func (a *AniListImportModel) fetchUserMediaList(username, mediaType string) ([]r
 		return nil, fmt.Errorf("failed to read response body: %v", err)
 	}
 
-	var graphqlResponse responses.AniListGraphQLResponse
-	if err := json.Unmarshal(body, &graphqlResponse); err != nil {
-		return nil, fmt.Errorf("failed to parse GraphQL response: %v", err)
-	}
+	return body, nil
+}
 
-	if len(graphqlResponse.Errors) > 0 {
-		return nil, fmt.Errorf("GraphQL errors: %s", graphqlResponse.Errors[0].Message)
+func (a *AniListImportModel) importEntry(entry responses.AniListMediaListEntry, contentType string) ImportEntry {
+	importEntry := ImportEntry{
+		ContentType:   contentType,
+		Title:         a.getPreferredTitle(entry.Media.Title),
+		AniListID:     int64(entry.Media.ID),
+		Status:        a.convertAniListStatus(entry.Status),
+		Progress:      int64(entry.Progress),
+		TimesFinished: entry.Repeat,
+		StartedAt:     a.convertAniListDate(entry.StartedAt),
+		FinishedAt:    a.convertAniListDate(entry.CompletedAt),
 	}
 
-	// Flatten all entries from all lists
-	var allEntries []responses.AniListMediaListEntry
-	for _, list := range graphqlResponse.Data.MediaListCollection.Lists {
-		allEntries = append(allEntries, list.Entries...)
+	if entry.Score > 0 {
+		convertedScore := a.convertAniListScore(entry.Score, "POINT_10") // Assume 10-point for now
+		importEntry.Score = &convertedScore
 	}
 
-	return allEntries, nil
-}
-
-func (a *AniListImportModel) processAnimeEntries(entries []responses.AniListMediaListEntry, userID string,
-	animeAniListIDs map[int64]string, existingAnimeEntries map[int64]bool,
-	animeEntriesToInsert *[]interface{}, importedCount, skippedCount, errorCount *int,
-	importedTitles, skippedTitles *[]string) {
-
-	for _, entry := range entries {
-		title := a.getPreferredTitle(entry.Media.Title)
-
-		logrus.WithFields(logrus.Fields{
-			"anilist_id": entry.Media.ID,
-			"title":      title,
-			"status":     entry.Status,
-		}).Debug("processing AniList anime entry")
-
-		// Check if anime exists in our database
-		animeObjectID, exists := animeAniListIDs[int64(entry.Media.ID)]
-		if !exists {
-			logrus.WithFields(logrus.Fields{
-				"anilist_id": entry.Media.ID,
-				"title":      title,
-			}).Debug("anime not found in database, skipping")
-			*errorCount++
-			continue
-		}
-
-		// Check if already exists in user's list
-		if _, exists := existingAnimeEntries[int64(entry.Media.ID)]; exists {
-			*skippedCount++
-			*skippedTitles = append(*skippedTitles, title)
-			continue
-		}
-
-		// Convert AniList status to application status
-		status := a.convertAniListStatus(entry.Status)
-
-		// Convert score
-		var score *float32
-		if entry.Score > 0 {
-			convertedScore := a.convertAniListScore(entry.Score, "POINT_10") // Assume 10-point for now
-			score = &convertedScore
-		}
-
-		// Create anime list entry
-		animeListEntry := AnimeList{
-			UserID:          userID,
-			AnimeID:         animeObjectID,
-			AnimeMALID:      int64(entry.Media.ID), // Using AniList ID in MAL field for now
-			Status:          status,
-			WatchedEpisodes: int64(entry.Progress),
-			Score:           score,
-			TimesFinished:   entry.Repeat,
-			CreatedAt:       time.Now().UTC(),
-			UpdatedAt:       time.Now().UTC(),
-		}
-
-		*animeEntriesToInsert = append(*animeEntriesToInsert, animeListEntry)
-		*importedCount++
-		*importedTitles = append(*importedTitles, title)
+	if entry.ProgressVolumes != nil {
+		importEntry.Volumes = int64(*entry.ProgressVolumes)
 	}
-}
 
-func (a *AniListImportModel) processMangaEntries(entries []responses.AniListMediaListEntry, userID string,
-	mangaAniListIDs map[int64]string, existingMangaEntries map[int64]bool,
-	mangaEntriesToInsert *[]interface{}, importedCount, skippedCount, errorCount *int,
-	importedTitles, skippedTitles *[]string) {
-
-	for _, entry := range entries {
-		title := a.getPreferredTitle(entry.Media.Title)
-
-		logrus.WithFields(logrus.Fields{
-			"anilist_id": entry.Media.ID,
-			"title":      title,
-			"status":     entry.Status,
-		}).Debug("processing AniList manga entry")
-
-		// Check if manga exists in our database
-		mangaObjectID, exists := mangaAniListIDs[int64(entry.Media.ID)]
-		if !exists {
-			logrus.WithFields(logrus.Fields{
-				"anilist_id": entry.Media.ID,
-				"title":      title,
-			}).Debug("manga not found in database, skipping")
-			*errorCount++
-			continue
-		}
-
-		// Check if already exists in user's list
-		if _, exists := existingMangaEntries[int64(entry.Media.ID)]; exists {
-			*skippedCount++
-			*skippedTitles = append(*skippedTitles, title)
-			continue
-		}
-
-		// Convert AniList status to application status
-		status := a.convertAniListStatus(entry.Status)
+	if importEntry.Status == "finished" {
+		importEntry.TimesFinished++
+	}
 
-		// Convert score
-		var score *float32
-		if entry.Score > 0 {
-			convertedScore := a.convertAniListScore(entry.Score, "POINT_10") // Assume 10-point for now
-			score = &convertedScore
-		}
+	if entry.UpdatedAt > 0 {
+		updatedAt := time.Unix(entry.UpdatedAt, 0).UTC()
+		importEntry.UpdatedAt = &updatedAt
+	}
 
-		// Create manga list entry
-		mangaListEntry := MangaList{
-			UserID:        userID,
-			MangaID:       mangaObjectID,
-			MangaMALID:    int64(entry.Media.ID), // Using AniList ID in MAL field for now
-			Status:        status,
-			ReadChapters:  int64(entry.Progress),
-			ReadVolumes:   0, // AniList progress volumes not always available
-			Score:         score,
-			TimesFinished: entry.Repeat,
-			CreatedAt:     time.Now().UTC(),
-			UpdatedAt:     time.Now().UTC(),
-		}
+	return importEntry
+}
 
-		*mangaEntriesToInsert = append(*mangaEntriesToInsert, mangaListEntry)
-		*importedCount++
-		*importedTitles = append(*importedTitles, title)
+func (a *AniListImportModel) convertAniListDate(date *responses.AniListDate) *time.Time {
+	if date == nil || date.Year == nil || date.Month == nil || date.Day == nil {
+		return nil
 	}
+
+	convertedDate := time.Date(*date.Year, time.Month(*date.Month), *date.Day, 0, 0, 0, 0, time.UTC)
+	return &convertedDate
 }
 
 func (a *AniListImportModel) getPreferredTitle(title struct {
@@ -417,7 +303,7 @@ func (a *AniListImportModel) convertAniListStatus(status string) string {
 	case "COMPLETED":
 		return "finished"
 	case "PLANNING":
-		return "active"
+		return "planto"
 	case "PAUSED":
 		return "active"
 	case "DROPPED":
@@ -456,52 +342,6 @@ func (a *AniListImportModel) convertAniListScore(score int, scoreFormat string)
 	}
 }
 
-func (a *AniListImportModel) getExistingAnimeEntries(userID string) (map[int64]bool, error) {
-	cursor, err := a.AnimeListCollection.Find(context.TODO(), bson.M{
-		"user_id": userID,
-	}, options.Find().SetProjection(bson.M{"anime_anilist_id": 1}))
-	if err != nil {
-		return nil, err
-	}
-	defer cursor.Close(context.TODO())
-
-	existingEntries := make(map[int64]bool)
-	for cursor.Next(context.TODO()) {
-		var entry struct {
-			AnimeAniListID int64 `bson:"anime_anilist_id"`
-		}
-		if err := cursor.Decode(&entry); err != nil {
-			continue
-		}
-		existingEntries[entry.AnimeAniListID] = true
-	}
-
-	return existingEntries, nil
-}
-
-func (a *AniListImportModel) getExistingMangaEntries(userID string) (map[int64]bool, error) {
-	cursor, err := a.MangaListCollection.Find(context.TODO(), bson.M{
-		"user_id": userID,
-	}, options.Find().SetProjection(bson.M{"manga_anilist_id": 1}))
-	if err != nil {
-		return nil, err
-	}
-	defer cursor.Close(context.TODO())
-
-	existingEntries := make(map[int64]bool)
-	for cursor.Next(context.TODO()) {
-		var entry struct {
-			MangaAniListID int64 `bson:"manga_anilist_id"`
-		}
-		if err := cursor.Decode(&entry); err != nil {
-			continue
-		}
-		existingEntries[entry.MangaAniListID] = true
-	}
-
-	return existingEntries, nil
-}
-
 func (a *AniListImportModel) getAllAnimeAniListIDs() (map[int64]string, error) {
 	cursor, err := a.AnimeCollection.Find(context.TODO(), bson.M{
 		"anilist_id": bson.M{"$exists": true, "$ne": nil},
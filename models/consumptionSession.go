@@ -0,0 +1,425 @@
+package models
+
+import (
+	"app/db"
+	"app/requests"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+//lint:file-ignore ST1005 Ignore all
+
+type ConsumptionSessionModel struct {
+	ConsumptionSessionCollection *mongo.Collection
+}
+
+func NewConsumptionSessionModel(mongoDB *db.MongoDB) *ConsumptionSessionModel {
+	return &ConsumptionSessionModel{
+		ConsumptionSessionCollection: mongoDB.Database.Collection("consumption-sessions"),
+	}
+}
+
+// ConsumptionSession is a single watch/read/play through of a list entry.
+// Sessions created from the legacy times_finished counter have no dates.
+type ConsumptionSession struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"_id"`
+	UserID      string             `bson:"user_id" json:"user_id"`
+	ListID      string             `bson:"list_id" json:"list_id"`
+	ContentID   string             `bson:"content_id" json:"content_id"`
+	ContentType string             `bson:"content_type" json:"content_type"`
+	StartedAt   *time.Time         `bson:"started_at" json:"started_at"`
+	FinishedAt  *time.Time         `bson:"finished_at" json:"finished_at"`
+	IsFinished  bool               `bson:"is_finished" json:"is_finished"`
+	Score       *float32           `bson:"score" json:"score"`
+	Notes       string             `bson:"notes" json:"notes"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+}
+
+func createConsumptionSessionObject(
+	userID, listID, contentID, contentType string,
+	startedAt, finishedAt *time.Time, isFinished bool, score *float32, notes string,
+) *ConsumptionSession {
+	return &ConsumptionSession{
+		UserID:      userID,
+		ListID:      listID,
+		ContentID:   contentID,
+		ContentType: contentType,
+		StartedAt:   startedAt,
+		FinishedAt:  finishedAt,
+		IsFinished:  isFinished,
+		Score:       score,
+		Notes:       notes,
+		CreatedAt:   time.Now().UTC(),
+	}
+}
+
+// ! Create
+func (sessionModel *ConsumptionSessionModel) CreateConsumptionSession(uid string, entry ListEntry, data requests.CreateConsumptionSession) (ConsumptionSession, error) {
+	session := createConsumptionSessionObject(
+		uid, entry.ID.Hex(), entry.ContentID, entry.ContentType,
+		data.StartedAt, data.FinishedAt, data.FinishedAt != nil || data.IsFinished,
+		data.Score, data.Notes,
+	)
+
+	insertedID, err := sessionModel.ConsumptionSessionCollection.InsertOne(context.TODO(), session)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid":  uid,
+			"data": data,
+		}).Error("failed to create consumption session: ", err)
+
+		return ConsumptionSession{}, fmt.Errorf("Failed to create session.")
+	}
+
+	session.ID = insertedID.InsertedID.(primitive.ObjectID)
+
+	return *session, nil
+}
+
+// SeedConsumptionSessions creates dateless finished sessions for entries that only
+// have the times_finished counter, so that history and counter stay consistent.
+func (sessionModel *ConsumptionSessionModel) SeedConsumptionSessions(uid string, entry ListEntry, timesFinished int) error {
+	if timesFinished <= 0 {
+		return nil
+	}
+
+	count, err := sessionModel.ConsumptionSessionCollection.CountDocuments(context.TODO(), bson.M{
+		"list_id": entry.ID.Hex(),
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"list_id": entry.ID.Hex(),
+		}).Error("failed to count consumption sessions: ", err)
+
+		return fmt.Errorf("Failed to get sessions.")
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	sessions := make([]interface{}, 0, timesFinished)
+	for i := 0; i < timesFinished; i++ {
+		sessions = append(sessions, createConsumptionSessionObject(
+			uid, entry.ID.Hex(), entry.ContentID, entry.ContentType,
+			nil, nil, true, nil, "",
+		))
+	}
+
+	if _, err := sessionModel.ConsumptionSessionCollection.InsertMany(context.TODO(), sessions); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"list_id": entry.ID.Hex(),
+			"count":   timesFinished,
+		}).Error("failed to seed consumption sessions: ", err)
+
+		return fmt.Errorf("Failed to create sessions.")
+	}
+
+	return nil
+}
+
+// StartConsumptionSession opens a new session unless there is already an unfinished one.
+func (sessionModel *ConsumptionSessionModel) StartConsumptionSession(uid string, entry ListEntry) error {
+	count, err := sessionModel.ConsumptionSessionCollection.CountDocuments(context.TODO(), bson.M{
+		"list_id":     entry.ID.Hex(),
+		"is_finished": false,
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"list_id": entry.ID.Hex(),
+		}).Error("failed to count open consumption sessions: ", err)
+
+		return fmt.Errorf("Failed to get sessions.")
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	startedAt := time.Now().UTC()
+
+	if _, err := sessionModel.ConsumptionSessionCollection.InsertOne(context.TODO(), createConsumptionSessionObject(
+		uid, entry.ID.Hex(), entry.ContentID, entry.ContentType,
+		&startedAt, nil, false, nil, "",
+	)); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"list_id": entry.ID.Hex(),
+		}).Error("failed to start consumption session: ", err)
+
+		return fmt.Errorf("Failed to start session.")
+	}
+
+	return nil
+}
+
+// FinishConsumptionSession closes the latest open session with the current score,
+// or records a finished session without a start date if none is open.
+func (sessionModel *ConsumptionSessionModel) FinishConsumptionSession(uid string, entry ListEntry, score *float32) error {
+	finishedAt := time.Now().UTC()
+
+	result, err := sessionModel.ConsumptionSessionCollection.UpdateOne(context.TODO(), bson.M{
+		"list_id":     entry.ID.Hex(),
+		"is_finished": false,
+	}, bson.M{"$set": bson.M{
+		"finished_at": finishedAt,
+		"is_finished": true,
+		"score":       score,
+	}})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"list_id": entry.ID.Hex(),
+		}).Error("failed to finish consumption session: ", err)
+
+		return fmt.Errorf("Failed to finish session.")
+	}
+
+	if result.MatchedCount > 0 {
+		return nil
+	}
+
+	if _, err := sessionModel.ConsumptionSessionCollection.InsertOne(context.TODO(), createConsumptionSessionObject(
+		uid, entry.ID.Hex(), entry.ContentID, entry.ContentType,
+		nil, &finishedAt, true, score, "",
+	)); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"list_id": entry.ID.Hex(),
+		}).Error("failed to create finished consumption session: ", err)
+
+		return fmt.Errorf("Failed to finish session.")
+	}
+
+	return nil
+}
+
+// RestoreConsumptionSessions inserts the sessions of a restored entry back with
+// their original ids.
+func (sessionModel *ConsumptionSessionModel) RestoreConsumptionSessions(listID string, sessions []ConsumptionSession) error {
+	if len(sessions) == 0 {
+		return nil
+	}
+
+	writeModels := make([]mongo.WriteModel, 0, len(sessions))
+	for _, session := range sessions {
+		writeModels = append(writeModels, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": session.ID}).
+			SetReplacement(session).
+			SetUpsert(true),
+		)
+	}
+
+	if _, err := sessionModel.ConsumptionSessionCollection.BulkWrite(
+		context.TODO(), writeModels, options.BulkWrite().SetOrdered(false),
+	); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"list_id": listID,
+			"count":   len(sessions),
+		}).Error("failed to restore consumption sessions: ", err)
+
+		return fmt.Errorf("Failed to restore sessions.")
+	}
+
+	return nil
+}
+
+// ! Update
+// ReopenLatestConsumptionSession sets the latest finished session back to open,
+// it reverts FinishConsumptionSession when the finish is undone.
+func (sessionModel *ConsumptionSessionModel) ReopenLatestConsumptionSession(listID string) error {
+	if err := sessionModel.ConsumptionSessionCollection.FindOneAndUpdate(context.TODO(), bson.M{
+		"list_id":     listID,
+		"is_finished": true,
+	}, bson.M{"$set": bson.M{
+		"finished_at": nil,
+		"is_finished": false,
+	}}, options.FindOneAndUpdate().SetSort(bson.D{
+		{Key: "finished_at", Value: -1},
+		{Key: "created_at", Value: -1},
+	})).Err(); err != nil && err != mongo.ErrNoDocuments {
+		logrus.WithFields(logrus.Fields{
+			"list_id": listID,
+		}).Error("failed to reopen consumption session: ", err)
+
+		return fmt.Errorf("Failed to update session.")
+	}
+
+	return nil
+}
+
+func (sessionModel *ConsumptionSessionModel) UpdateConsumptionSession(session ConsumptionSession, data requests.UpdateConsumptionSession) (ConsumptionSession, error) {
+	set := bson.M{}
+
+	if data.StartedAt != nil {
+		set["started_at"] = data.StartedAt
+		session.StartedAt = data.StartedAt
+	}
+
+	if data.FinishedAt != nil {
+		set["finished_at"] = data.FinishedAt
+		set["is_finished"] = true
+		session.FinishedAt = data.FinishedAt
+		session.IsFinished = true
+	}
+
+	if data.IsFinished != nil && session.IsFinished != *data.IsFinished {
+		set["is_finished"] = *data.IsFinished
+		session.IsFinished = *data.IsFinished
+
+		if !session.IsFinished {
+			set["finished_at"] = nil
+			session.FinishedAt = nil
+		}
+	}
+
+	if data.IsUpdatingScore {
+		set["score"] = data.Score
+		session.Score = data.Score
+	}
+
+	if data.Notes != nil {
+		set["notes"] = *data.Notes
+		session.Notes = *data.Notes
+	}
+
+	if len(set) == 0 {
+		return session, nil
+	}
+
+	if _, err := sessionModel.ConsumptionSessionCollection.UpdateOne(context.TODO(), bson.M{
+		"_id": session.ID,
+	}, bson.M{"$set": set}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"session_id": session.ID,
+			"data":       data,
+		}).Error("failed to update consumption session: ", err)
+
+		return ConsumptionSession{}, fmt.Errorf("Failed to update session.")
+	}
+
+	return session, nil
+}
+
+// ! Get
+func (sessionModel *ConsumptionSessionModel) GetConsumptionSessionByID(sessionID string) (ConsumptionSession, error) {
+	objectID, _ := primitive.ObjectIDFromHex(sessionID)
+
+	result := sessionModel.ConsumptionSessionCollection.FindOne(context.TODO(), bson.M{"_id": objectID})
+
+	var session ConsumptionSession
+	if err := result.Decode(&session); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"id": sessionID,
+		}).Error("failed to find consumption session by id: ", err)
+
+		return ConsumptionSession{}, fmt.Errorf("Failed to find session by id.")
+	}
+
+	return session, nil
+}
+
+func (sessionModel *ConsumptionSessionModel) GetConsumptionSessionsByListID(listID string) ([]ConsumptionSession, error) {
+	cursor, err := sessionModel.ConsumptionSessionCollection.Find(context.TODO(), bson.M{
+		"list_id": listID,
+	}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"list_id": listID,
+		}).Error("failed to find consumption sessions: ", err)
+
+		return nil, fmt.Errorf("Failed to get sessions.")
+	}
+
+	var sessions []ConsumptionSession
+	if err := cursor.All(context.TODO(), &sessions); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"list_id": listID,
+		}).Error("failed to decode consumption sessions: ", err)
+
+		return nil, fmt.Errorf("Failed to decode sessions.")
+	}
+
+	return sessions, nil
+}
+
+// ! Delete
+func (sessionModel *ConsumptionSessionModel) DeleteConsumptionSessionByID(uid, sessionID string) (bool, error) {
+	objectID, _ := primitive.ObjectIDFromHex(sessionID)
+
+	count, err := sessionModel.ConsumptionSessionCollection.DeleteOne(context.TODO(), bson.M{
+		"_id":     objectID,
+		"user_id": uid,
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid":        uid,
+			"session_id": sessionID,
+		}).Error("failed to delete consumption session: ", err)
+
+		return false, fmt.Errorf("Failed to delete session.")
+	}
+
+	return count.DeletedCount > 0, nil
+}
+
+// DeleteLatestFinishedConsumptionSession reverts the session recorded by a finish
+// that is undone.
+func (sessionModel *ConsumptionSessionModel) DeleteLatestFinishedConsumptionSession(listID string) error {
+	if err := sessionModel.ConsumptionSessionCollection.FindOneAndDelete(context.TODO(), bson.M{
+		"list_id":     listID,
+		"is_finished": true,
+	}, options.FindOneAndDelete().SetSort(bson.D{
+		{Key: "finished_at", Value: -1},
+		{Key: "created_at", Value: -1},
+	})).Err(); err != nil && err != mongo.ErrNoDocuments {
+		logrus.WithFields(logrus.Fields{
+			"list_id": listID,
+		}).Error("failed to delete finished consumption session: ", err)
+
+		return fmt.Errorf("Failed to delete session.")
+	}
+
+	return nil
+}
+
+// DeleteOpenConsumptionSessions reverts the session started by a change to
+// active that is undone.
+func (sessionModel *ConsumptionSessionModel) DeleteOpenConsumptionSessions(listID string) error {
+	if _, err := sessionModel.ConsumptionSessionCollection.DeleteMany(context.TODO(), bson.M{
+		"list_id":     listID,
+		"is_finished": false,
+	}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"list_id": listID,
+		}).Error("failed to delete open consumption sessions: ", err)
+
+		return fmt.Errorf("Failed to delete session.")
+	}
+
+	return nil
+}
+
+func (sessionModel *ConsumptionSessionModel) DeleteConsumptionSessionsByListID(listID string) {
+	if _, err := sessionModel.ConsumptionSessionCollection.DeleteMany(context.TODO(), bson.M{
+		"list_id": listID,
+	}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"list_id": listID,
+		}).Error("failed to delete consumption sessions by list id: ", err)
+	}
+}
+
+func (sessionModel *ConsumptionSessionModel) DeleteConsumptionSessionsByUserID(uid string) {
+	if _, err := sessionModel.ConsumptionSessionCollection.DeleteMany(context.TODO(), bson.M{
+		"user_id": uid,
+	}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to delete consumption sessions by user id: ", err)
+	}
+}
@@ -0,0 +1,588 @@
+package models
+
+import (
+	"app/db"
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+//lint:file-ignore ST1005 Ignore all
+
+type DataExportModel struct {
+	DataExportCollection *mongo.Collection
+	Database             *mongo.Database
+}
+
+func NewDataExportModel(mongoDB *db.MongoDB) *DataExportModel {
+	return &DataExportModel{
+		DataExportCollection: mongoDB.Database.Collection("data-exports"),
+		Database:             mongoDB.Database,
+	}
+}
+
+const (
+	PendingDataExportStatus    = "pending"
+	ProcessingDataExportStatus = "processing"
+	CompletedDataExportStatus  = "completed"
+	FailedDataExportStatus     = "failed"
+)
+
+const (
+	dataExportBucket = "data-export-files"
+	// Exports are kept for a week, a new export replaces the previous one.
+	dataExportExpiration = 7 * 24 * time.Hour
+	// Exports that are not completed in time are considered lost, e.g. restart.
+	dataExportTimeout = time.Hour
+)
+
+type DataExport struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"_id"`
+	UserID      string              `bson:"user_id" json:"user_id"`
+	Status      string              `bson:"status" json:"status"`
+	FileID      *primitive.ObjectID `bson:"file_id" json:"-"`
+	Size        int64               `bson:"size" json:"size"`
+	Error       *string             `bson:"error" json:"error"`
+	CreatedAt   time.Time           `bson:"created_at" json:"created_at"`
+	CompletedAt *time.Time          `bson:"completed_at" json:"completed_at"`
+	ExpiresAt   *time.Time          `bson:"expires_at" json:"expires_at"`
+}
+
+// dataExportPart is a single file set of the archive. Parts with csv columns are
+// exported as csv too, array values are joined with ";".
+type dataExportPart struct {
+	Name       string
+	Collection string
+	Filter     func(uid string) bson.M
+	Projection bson.M
+	CSVColumns []string
+}
+
+func userIDFilter(uid string) bson.M {
+	return bson.M{"user_id": uid}
+}
+
+var listCSVColumns = []string{
+	"status", "score", "times_finished", "started_at", "finished_at",
+	"priority", "private_note", "tags", "created_at",
+}
+
+var dataExportParts = []dataExportPart{
+	{
+		Name:       "profile",
+		Collection: "users",
+		Filter: func(uid string) bson.M {
+			objectUID, _ := primitive.ObjectIDFromHex(uid)
+
+			return bson.M{"_id": objectUID}
+		},
+		Projection: bson.M{
+			"password":               0,
+			"reset_token":            0,
+			"reset_token_hash":       0,
+			"reset_token_expires_at": 0,
+			"refresh_token":          0,
+			"fcm_token":              0,
+		},
+	},
+	{
+		Name:       "anime_list",
+		Collection: "anime-lists",
+		Filter:     userIDFilter,
+		CSVColumns: append([]string{"anime_id", "anime_mal_id", "watched_episodes"}, listCSVColumns...),
+	},
+	{
+		Name:       "manga_list",
+		Collection: "manga-lists",
+		Filter:     userIDFilter,
+		CSVColumns: append([]string{"manga_id", "manga_mal_id", "read_chapters", "read_volumes"}, listCSVColumns...),
+	},
+	{
+		Name:       "game_list",
+		Collection: "game-lists",
+		Filter:     userIDFilter,
+		CSVColumns: append([]string{"game_id", "game_rawg_id", "hours_played", "achievement_status"}, listCSVColumns...),
+	},
+	{
+		Name:       "movie_list",
+		Collection: "movie-watch-lists",
+		Filter:     userIDFilter,
+		CSVColumns: append([]string{"movie_id", "movie_tmdb_id"}, listCSVColumns...),
+	},
+	{
+		Name:       "tv_list",
+		Collection: "tvseries-watch-lists",
+		Filter:     userIDFilter,
+		CSVColumns: append([]string{"tv_id", "tv_tmdb_id", "watched_episodes", "watched_seasons"}, listCSVColumns...),
+	},
+	{
+		Name:       "consume_later",
+		Collection: "consume-laters",
+		Filter:     userIDFilter,
+		CSVColumns: []string{
+			"content_id", "content_type", "content_external_id", "content_external_int_id",
+			"self_note", "tags", "created_at",
+		},
+	},
+	{
+		Name:       "reviews",
+		Collection: "reviews",
+		Filter:     userIDFilter,
+	},
+	{
+		Name:       "recommendations",
+		Collection: "recomendations",
+		Filter:     userIDFilter,
+	},
+	{
+		Name:       "custom_lists",
+		Collection: "custom-lists",
+		Filter:     userIDFilter,
+	},
+	{
+		Name:       "logs",
+		Collection: "logs",
+		Filter:     userIDFilter,
+		CSVColumns: []string{
+			"log_type", "log_action", "log_action_details", "content_title",
+			"content_type", "content_id", "created_at",
+		},
+	},
+	{
+		Name:       "achievements",
+		Collection: "user-achievements",
+		Filter:     userIDFilter,
+	},
+	{
+		Name:       "friend_requests",
+		Collection: "friend-requests",
+		Filter: func(uid string) bson.M {
+			return bson.M{"$or": bson.A{
+				bson.M{"sender_id": uid},
+				bson.M{"receiver_id": uid},
+			}}
+		},
+	},
+	{
+		Name:       "ai_suggestions",
+		Collection: "ai-suggestions",
+		Filter:     userIDFilter,
+	},
+	{
+		Name:       "consumption_sessions",
+		Collection: "consumption-sessions",
+		Filter:     userIDFilter,
+	},
+	{
+		Name:       "episode_watches",
+		Collection: "episode-watches",
+		Filter:     userIDFilter,
+	},
+	{
+		Name:       "tags",
+		Collection: "user-tags",
+		Filter:     userIDFilter,
+	},
+}
+
+func (dataExportModel *DataExportModel) getBucket() (*gridfs.Bucket, error) {
+	return gridfs.NewBucket(dataExportModel.Database, options.GridFSBucket().SetName(dataExportBucket))
+}
+
+// ! Create
+func (dataExportModel *DataExportModel) CreateDataExport(uid string) (DataExport, error) {
+	dataExport := DataExport{
+		UserID:    uid,
+		Status:    PendingDataExportStatus,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	result, err := dataExportModel.DataExportCollection.InsertOne(context.TODO(), dataExport)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to create data export: ", err)
+
+		return DataExport{}, fmt.Errorf("Failed to create data export.")
+	}
+
+	dataExport.ID = result.InsertedID.(primitive.ObjectID)
+
+	return dataExport, nil
+}
+
+// GenerateDataExport writes the archive of the user data to GridFS, it is meant
+// to run in background and records the result on the export.
+func (dataExportModel *DataExportModel) GenerateDataExport(dataExport DataExport) {
+	dataExportModel.updateDataExport(dataExport.ID, bson.M{"status": ProcessingDataExportStatus})
+
+	fileID, size, err := dataExportModel.writeDataExport(dataExport)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid":            dataExport.UserID,
+			"data_export_id": dataExport.ID,
+		}).Error("failed to generate data export: ", err)
+
+		dataExportModel.updateDataExport(dataExport.ID, bson.M{
+			"status": FailedDataExportStatus,
+			"error":  "Failed to generate data export.",
+		})
+
+		return
+	}
+
+	completedAt := time.Now().UTC()
+	dataExportModel.updateDataExport(dataExport.ID, bson.M{
+		"status":       CompletedDataExportStatus,
+		"file_id":      fileID,
+		"size":         size,
+		"completed_at": completedAt,
+		"expires_at":   completedAt.Add(dataExportExpiration),
+	})
+}
+
+func (dataExportModel *DataExportModel) writeDataExport(dataExport DataExport) (primitive.ObjectID, int64, error) {
+	bucket, err := dataExportModel.getBucket()
+	if err != nil {
+		return primitive.NilObjectID, 0, err
+	}
+
+	uploadStream, err := bucket.OpenUploadStream(fmt.Sprintf("watchlistfy_export_%s.zip", dataExport.ID.Hex()))
+	if err != nil {
+		return primitive.NilObjectID, 0, err
+	}
+
+	fileID := uploadStream.FileID.(primitive.ObjectID)
+	counter := &countingWriter{writer: uploadStream}
+	zipWriter := zip.NewWriter(counter)
+
+	for _, part := range dataExportParts {
+		if err := dataExportModel.writeDataExportPart(zipWriter, part, dataExport.UserID); err != nil {
+			uploadStream.Abort()
+			return primitive.NilObjectID, 0, fmt.Errorf("%s: %w", part.Name, err)
+		}
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		uploadStream.Abort()
+		return primitive.NilObjectID, 0, err
+	}
+
+	if err := uploadStream.Close(); err != nil {
+		return primitive.NilObjectID, 0, err
+	}
+
+	return fileID, counter.count, nil
+}
+
+// writeDataExportPart streams the documents to the archive, documents are read
+// twice for csv parts so that the whole collection is never kept in memory.
+func (dataExportModel *DataExportModel) writeDataExportPart(zipWriter *zip.Writer, part dataExportPart, uid string) error {
+	collection := dataExportModel.Database.Collection(part.Collection)
+	findOptions := options.Find().SetProjection(part.Projection)
+
+	cursor, err := collection.Find(context.TODO(), part.Filter(uid), findOptions)
+	if err != nil {
+		return err
+	}
+
+	jsonFile, err := zipWriter.Create(part.Name + ".json")
+	if err != nil {
+		cursor.Close(context.TODO())
+		return err
+	}
+
+	if err := writeDataExportJSON(jsonFile, cursor); err != nil {
+		return err
+	}
+
+	if len(part.CSVColumns) == 0 {
+		return nil
+	}
+
+	cursor, err = collection.Find(context.TODO(), part.Filter(uid), findOptions)
+	if err != nil {
+		return err
+	}
+
+	csvFile, err := zipWriter.Create(part.Name + ".csv")
+	if err != nil {
+		cursor.Close(context.TODO())
+		return err
+	}
+
+	return writeDataExportCSV(csvFile, cursor, part.CSVColumns)
+}
+
+func writeDataExportJSON(writer io.Writer, cursor *mongo.Cursor) error {
+	defer cursor.Close(context.TODO())
+
+	if _, err := io.WriteString(writer, "["); err != nil {
+		return err
+	}
+
+	isFirst := true
+	for cursor.Next(context.TODO()) {
+		var document bson.M
+		if err := cursor.Decode(&document); err != nil {
+			return err
+		}
+
+		data, err := json.MarshalIndent(document, "  ", "  ")
+		if err != nil {
+			return err
+		}
+
+		separator := ",\n  "
+		if isFirst {
+			separator = "\n  "
+			isFirst = false
+		}
+
+		if _, err := io.WriteString(writer, separator); err != nil {
+			return err
+		}
+
+		if _, err := writer.Write(data); err != nil {
+			return err
+		}
+	}
+
+	if _, err := io.WriteString(writer, "\n]\n"); err != nil {
+		return err
+	}
+
+	return cursor.Err()
+}
+
+func writeDataExportCSV(writer io.Writer, cursor *mongo.Cursor, columns []string) error {
+	defer cursor.Close(context.TODO())
+
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write(columns); err != nil {
+		return err
+	}
+
+	for cursor.Next(context.TODO()) {
+		var document bson.M
+		if err := cursor.Decode(&document); err != nil {
+			return err
+		}
+
+		record := make([]string, len(columns))
+		for index, column := range columns {
+			record[index] = dataExportCSVValue(document[column])
+		}
+
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		return err
+	}
+
+	return cursor.Err()
+}
+
+func dataExportCSVValue(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case primitive.DateTime:
+		return value.Time().UTC().Format(time.RFC3339)
+	case primitive.ObjectID:
+		return value.Hex()
+	case primitive.A:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			values = append(values, dataExportCSVValue(item))
+		}
+
+		return strings.Join(values, ";")
+	}
+
+	return fmt.Sprint(value)
+}
+
+type countingWriter struct {
+	writer io.Writer
+	count  int64
+}
+
+func (countingWriter *countingWriter) Write(data []byte) (int, error) {
+	n, err := countingWriter.writer.Write(data)
+	countingWriter.count += int64(n)
+
+	return n, err
+}
+
+// ! Update
+func (dataExportModel *DataExportModel) updateDataExport(dataExportID primitive.ObjectID, set bson.M) {
+	if _, err := dataExportModel.DataExportCollection.UpdateOne(context.TODO(), bson.M{
+		"_id": dataExportID,
+	}, bson.M{"$set": set}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"data_export_id": dataExportID,
+			"set":            set,
+		}).Error("failed to update data export: ", err)
+	}
+}
+
+// ! Get
+func (dataExportModel *DataExportModel) GetDataExportByID(dataExportID string) (DataExport, error) {
+	objectID, _ := primitive.ObjectIDFromHex(dataExportID)
+
+	result := dataExportModel.DataExportCollection.FindOne(context.TODO(), bson.M{
+		"_id": objectID,
+	})
+
+	var dataExport DataExport
+	if err := result.Decode(&dataExport); err != nil && err != mongo.ErrNoDocuments {
+		logrus.WithFields(logrus.Fields{
+			"data_export_id": dataExportID,
+		}).Error("failed to find data export by id: ", err)
+
+		return DataExport{}, fmt.Errorf("Failed to find data export.")
+	}
+
+	return dataExport, nil
+}
+
+// IsDataExportInProgress checks whether the user has an export that is still
+// being generated, exports that exceeded the timeout don't count.
+func (dataExportModel *DataExportModel) IsDataExportInProgress(uid string) (bool, error) {
+	count, err := dataExportModel.DataExportCollection.CountDocuments(context.TODO(), bson.M{
+		"user_id":    uid,
+		"status":     bson.M{"$in": bson.A{PendingDataExportStatus, ProcessingDataExportStatus}},
+		"created_at": bson.M{"$gte": time.Now().UTC().Add(-dataExportTimeout)},
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to count data exports in progress: ", err)
+
+		return false, fmt.Errorf("Failed to find data export.")
+	}
+
+	return count > 0, nil
+}
+
+func (dataExportModel *DataExportModel) OpenDataExportFile(dataExport DataExport) (*gridfs.DownloadStream, error) {
+	bucket, err := dataExportModel.getBucket()
+	if err == nil {
+		var downloadStream *gridfs.DownloadStream
+		if downloadStream, err = bucket.OpenDownloadStream(*dataExport.FileID); err == nil {
+			return downloadStream, nil
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"data_export_id": dataExport.ID,
+	}).Error("failed to open data export file: ", err)
+
+	return nil, fmt.Errorf("Failed to download data export.")
+}
+
+// ! Delete
+// DeleteExpiredDataExportFiles deletes the files and chunks of the expired
+// exports from GridFS, the exports are kept without their files.
+func (dataExportModel *DataExportModel) DeleteExpiredDataExportFiles() {
+	cursor, err := dataExportModel.DataExportCollection.Find(context.TODO(), bson.M{
+		"file_id":    bson.M{"$ne": nil},
+		"expires_at": bson.M{"$lte": time.Now().UTC()},
+	})
+	if err != nil {
+		logrus.Error("failed to find expired data exports: ", err)
+
+		return
+	}
+
+	var dataExports []DataExport
+	if err := cursor.All(context.TODO(), &dataExports); err != nil {
+		logrus.Error("failed to decode expired data exports: ", err)
+
+		return
+	}
+
+	if len(dataExports) == 0 {
+		return
+	}
+
+	bucket, err := dataExportModel.getBucket()
+	if err != nil {
+		return
+	}
+
+	for _, dataExport := range dataExports {
+		if err := bucket.Delete(*dataExport.FileID); err != nil && err != gridfs.ErrFileNotFound {
+			logrus.WithFields(logrus.Fields{
+				"data_export_id": dataExport.ID,
+			}).Error("failed to delete expired data export file: ", err)
+
+			continue
+		}
+
+		dataExportModel.updateDataExport(dataExport.ID, bson.M{"file_id": nil})
+	}
+}
+
+// DeleteDataExportsByUserID deletes the exports of the user with their files.
+func (dataExportModel *DataExportModel) DeleteDataExportsByUserID(uid string) {
+	cursor, err := dataExportModel.DataExportCollection.Find(context.TODO(), bson.M{
+		"user_id": uid,
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to find data exports by user id: ", err)
+
+		return
+	}
+
+	var dataExports []DataExport
+	if err := cursor.All(context.TODO(), &dataExports); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to decode data exports: ", err)
+
+		return
+	}
+
+	bucket, err := dataExportModel.getBucket()
+	if err != nil {
+		return
+	}
+
+	for _, dataExport := range dataExports {
+		if dataExport.FileID != nil {
+			if err := bucket.Delete(*dataExport.FileID); err != nil && err != gridfs.ErrFileNotFound {
+				logrus.WithFields(logrus.Fields{
+					"data_export_id": dataExport.ID,
+				}).Error("failed to delete data export file: ", err)
+			}
+		}
+	}
+
+	if _, err := dataExportModel.DataExportCollection.DeleteMany(context.TODO(), bson.M{
+		"user_id": uid,
+	}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to delete data exports by user id: ", err)
+	}
+}
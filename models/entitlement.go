@@ -0,0 +1,254 @@
+package models
+
+import (
+	"app/db"
+	"app/requests"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+//lint:file-ignore ST1005 Ignore all
+
+// EntitlementModel keeps the verified purchases of the users, premium is
+// derived from the entitlements that are active.
+type EntitlementModel struct {
+	EntitlementCollection *mongo.Collection
+}
+
+func NewEntitlementModel(mongoDB *db.MongoDB) *EntitlementModel {
+	return &EntitlementModel{
+		EntitlementCollection: mongoDB.Database.Collection("entitlements"),
+	}
+}
+
+const (
+	BasicMembershipType     = 0
+	PremiumMembershipType   = 1
+	SupporterMembershipType = 2
+)
+
+type membershipProduct struct {
+	MembershipType int
+	IsSubscription bool
+}
+
+// membershipProducts are the products of the stores by their ids, products
+// that are not subscriptions don't expire.
+var membershipProducts = map[string]membershipProduct{
+	"premium_monthly":           {MembershipType: PremiumMembershipType, IsSubscription: true},
+	"premium_yearly":            {MembershipType: PremiumMembershipType, IsSubscription: true},
+	"premium_lifetime":          {MembershipType: PremiumMembershipType, IsSubscription: false},
+	"premium_supporter_monthly": {MembershipType: SupporterMembershipType, IsSubscription: true},
+	"premium_supporter_yearly":  {MembershipType: SupporterMembershipType, IsSubscription: true},
+}
+
+// Entitlement is the membership of a verified purchase, purchase token is
+// kept to verify it again when the store notifies a renewal or refund.
+type Entitlement struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"_id"`
+	UserID         string             `bson:"user_id" json:"user_id"`
+	Store          string             `bson:"store" json:"store"`
+	ProductID      string             `bson:"product_id" json:"product_id"`
+	TransactionID  string             `bson:"transaction_id" json:"-"`
+	PurchaseToken  string             `bson:"purchase_token" json:"-"`
+	MembershipType int                `bson:"membership_type" json:"membership_type"`
+	ExpiresAt      *time.Time         `bson:"expires_at" json:"expires_at"`
+	RefundedAt     *time.Time         `bson:"refunded_at" json:"refunded_at"`
+	VerifiedAt     time.Time          `bson:"verified_at" json:"verified_at"`
+	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
+}
+
+func (entitlement Entitlement) IsActive() bool {
+	return entitlement.RefundedAt == nil &&
+		(entitlement.ExpiresAt == nil || entitlement.ExpiresAt.After(time.Now().UTC()))
+}
+
+// CreateEntitlementIndexes creates the unique index that allows a purchase to
+// be the entitlement of a single user.
+func (entitlementModel *EntitlementModel) CreateEntitlementIndexes() {
+	if _, err := entitlementModel.EntitlementCollection.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys: bson.D{{Key: "store", Value: 1}, {Key: "transaction_id", Value: 1}},
+		Options: options.Index().
+			SetName("store_transaction").
+			SetUnique(true),
+	}); err != nil {
+		logrus.Error("failed to create entitlement transaction index: ", err)
+	}
+}
+
+// ! Create
+// VerifyPurchase verifies the purchase with its store and saves it as the
+// entitlement of the user. isOwned is false if the purchase is already used by
+// another user, the entitlement of another user doesn't match the filter and
+// inserting it fails with the unique index.
+func (entitlementModel *EntitlementModel) VerifyPurchase(uid string, data requests.VerifyPurchase) (Entitlement, bool, error) {
+	product, ok := membershipProducts[data.ProductID]
+	if !ok {
+		return Entitlement{}, false, invalidPurchaseError("Unknown product.")
+	}
+
+	purchase, err := getPurchaseVerifier(data.Store).VerifyPurchase(data.ProductID, data.PurchaseToken, product.IsSubscription)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid":        uid,
+			"store":      data.Store,
+			"product_id": data.ProductID,
+		}).Error("failed to verify purchase: ", err)
+
+		if IsInvalidPurchaseError(err) {
+			return Entitlement{}, false, err
+		}
+
+		return Entitlement{}, false, fmt.Errorf("Failed to verify purchase, please try again later.")
+	}
+
+	now := time.Now().UTC()
+
+	result := entitlementModel.EntitlementCollection.FindOneAndUpdate(context.TODO(), bson.M{
+		"store":          data.Store,
+		"transaction_id": purchase.TransactionID,
+		"user_id":        bson.M{"$in": bson.A{uid, "", nil}},
+	}, bson.M{
+		"$set": bson.M{
+			"user_id":         uid,
+			"product_id":      purchase.ProductID,
+			"purchase_token":  data.PurchaseToken,
+			"membership_type": product.MembershipType,
+			"expires_at":      purchase.ExpiresAt,
+			"refunded_at":     purchase.RefundedAt,
+			"verified_at":     now,
+		},
+		"$setOnInsert": bson.M{
+			"created_at": now,
+		},
+	}, options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After))
+
+	var entitlement Entitlement
+	if err := result.Decode(&entitlement); mongo.IsDuplicateKeyError(err) {
+		return Entitlement{}, false, nil
+	} else if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid":   uid,
+			"store": data.Store,
+		}).Error("failed to save entitlement: ", err)
+
+		return Entitlement{}, false, fmt.Errorf("Failed to save purchase.")
+	}
+
+	return entitlement, true, nil
+}
+
+// ! Update
+// RefreshEntitlements verifies the purchase of the store notification again,
+// notifications aren't trusted and only tell which purchase has changed. User
+// ids of the refreshed entitlements are returned.
+func (entitlementModel *EntitlementModel) RefreshEntitlements(store, transactionID string) ([]string, error) {
+	cursor, err := entitlementModel.EntitlementCollection.Find(context.TODO(), bson.M{
+		"store":          store,
+		"transaction_id": transactionID,
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"store": store,
+		}).Error("failed to find entitlements to refresh: ", err)
+
+		return nil, fmt.Errorf("Failed to find purchase.")
+	}
+
+	var entitlements []Entitlement
+	if err := cursor.All(context.TODO(), &entitlements); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"store": store,
+		}).Error("failed to decode entitlements to refresh: ", err)
+
+		return nil, fmt.Errorf("Failed to find purchase.")
+	}
+
+	userIDs := []string{}
+	for _, entitlement := range entitlements {
+		product := membershipProducts[entitlement.ProductID]
+
+		purchase, err := getPurchaseVerifier(store).VerifyPurchase(entitlement.ProductID, entitlement.PurchaseToken, product.IsSubscription)
+		if err != nil && !IsInvalidPurchaseError(err) {
+			logrus.WithFields(logrus.Fields{
+				"entitlement_id": entitlement.ID,
+				"store":          store,
+			}).Error("failed to refresh entitlement: ", err)
+
+			return nil, fmt.Errorf("Failed to verify purchase.")
+		}
+
+		now := time.Now().UTC()
+
+		set := bson.M{
+			"expires_at":  purchase.ExpiresAt,
+			"refunded_at": purchase.RefundedAt,
+			"verified_at": now,
+		}
+
+		// Purchases that the store doesn't accept anymore are revoked.
+		if err != nil {
+			set = bson.M{
+				"refunded_at": now,
+				"verified_at": now,
+			}
+		}
+
+		if _, err := entitlementModel.EntitlementCollection.UpdateOne(context.TODO(), bson.M{
+			"_id": entitlement.ID,
+		}, bson.M{"$set": set}); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"entitlement_id": entitlement.ID,
+			}).Error("failed to update entitlement: ", err)
+
+			return nil, fmt.Errorf("Failed to update purchase.")
+		}
+
+		userIDs = append(userIDs, entitlement.UserID)
+	}
+
+	return userIDs, nil
+}
+
+// ! Get
+func (entitlementModel *EntitlementModel) GetEntitlementsByUserID(uid string) ([]Entitlement, error) {
+	cursor, err := entitlementModel.EntitlementCollection.Find(context.TODO(), bson.M{
+		"user_id": uid,
+	}, options.Find().SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to find entitlements: ", err)
+
+		return nil, fmt.Errorf("Failed to find purchases.")
+	}
+
+	entitlements := []Entitlement{}
+	if err := cursor.All(context.TODO(), &entitlements); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to decode entitlements: ", err)
+
+		return nil, fmt.Errorf("Failed to find purchases.")
+	}
+
+	return entitlements, nil
+}
+
+// ! Delete
+func (entitlementModel *EntitlementModel) DeleteEntitlementsByUserID(uid string) {
+	if _, err := entitlementModel.EntitlementCollection.DeleteMany(context.TODO(), bson.M{
+		"user_id": uid,
+	}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to delete entitlements: ", err)
+	}
+}
@@ -0,0 +1,471 @@
+package models
+
+import (
+	"app/db"
+	"app/responses"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+//lint:file-ignore ST1005 Ignore all
+
+type EpisodeWatchModel struct {
+	EpisodeWatchCollection *mongo.Collection
+}
+
+func NewEpisodeWatchModel(mongoDB *db.MongoDB) *EpisodeWatchModel {
+	return &EpisodeWatchModel{
+		EpisodeWatchCollection: mongoDB.Database.Collection("episode-watches"),
+	}
+}
+
+type EpisodeWatch struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"_id"`
+	UserID        string             `bson:"user_id" json:"user_id"`
+	ListID        string             `bson:"list_id" json:"list_id"`
+	ContentID     string             `bson:"content_id" json:"content_id"`
+	ContentType   string             `bson:"content_type" json:"content_type"`
+	SeasonNumber  int                `bson:"season_number" json:"season_number"`
+	EpisodeNumber int                `bson:"episode_number" json:"episode_number"`
+	Score         *float32           `bson:"score" json:"score"`
+	WatchedAt     time.Time          `bson:"watched_at" json:"watched_at"`
+}
+
+type EpisodeKey struct {
+	SeasonNumber  int
+	EpisodeNumber int
+}
+
+// SeasonEpisodes is the episode count of a single season, 0 means unknown.
+type SeasonEpisodes struct {
+	SeasonNumber int
+	EpisodeCount int
+}
+
+// TVSeasonEpisodes returns the regular seasons of a tv series ordered by season number.
+// Specials (season 0) are ignored so that counters match total_episodes.
+func TVSeasonEpisodes(tvSeries responses.TVSeries) []SeasonEpisodes {
+	var seasons []SeasonEpisodes
+	for _, season := range tvSeries.Seasons {
+		if season.SeasonNum <= 0 {
+			continue
+		}
+
+		seasons = append(seasons, SeasonEpisodes{
+			SeasonNumber: season.SeasonNum,
+			EpisodeCount: season.EpisodeCount,
+		})
+	}
+
+	sort.Slice(seasons, func(i, j int) bool {
+		return seasons[i].SeasonNumber < seasons[j].SeasonNumber
+	})
+
+	return seasons
+}
+
+// AnimeSeasonEpisodes returns anime episodes as a single season.
+func AnimeSeasonEpisodes(anime responses.Anime) []SeasonEpisodes {
+	var episodeCount int
+	if anime.Episodes != nil {
+		episodeCount = int(*anime.Episodes)
+	}
+
+	return []SeasonEpisodes{{
+		SeasonNumber: 1,
+		EpisodeCount: episodeCount,
+	}}
+}
+
+func findSeason(seasons []SeasonEpisodes, seasonNumber int) (SeasonEpisodes, bool) {
+	for _, season := range seasons {
+		if season.SeasonNumber == seasonNumber {
+			return season, true
+		}
+	}
+
+	return SeasonEpisodes{}, false
+}
+
+func ValidateEpisodeKey(seasons []SeasonEpisodes, key EpisodeKey) error {
+	season, ok := findSeason(seasons, key.SeasonNumber)
+	if !ok {
+		return fmt.Errorf("Season %d does not exist.", key.SeasonNumber)
+	}
+
+	if key.EpisodeNumber < 1 || (season.EpisodeCount > 0 && key.EpisodeNumber > season.EpisodeCount) {
+		return fmt.Errorf("Episode %d does not exist in season %d.", key.EpisodeNumber, key.SeasonNumber)
+	}
+
+	return nil
+}
+
+// SeasonEpisodeKeys returns every episode of the given season.
+func SeasonEpisodeKeys(seasons []SeasonEpisodes, seasonNumber int) ([]EpisodeKey, error) {
+	season, ok := findSeason(seasons, seasonNumber)
+	if !ok {
+		return nil, fmt.Errorf("Season %d does not exist.", seasonNumber)
+	}
+
+	if season.EpisodeCount <= 0 {
+		return nil, fmt.Errorf("Episode count of season %d is unknown.", seasonNumber)
+	}
+
+	keys := make([]EpisodeKey, 0, season.EpisodeCount)
+	for episode := 1; episode <= season.EpisodeCount; episode++ {
+		keys = append(keys, EpisodeKey{SeasonNumber: seasonNumber, EpisodeNumber: episode})
+	}
+
+	return keys, nil
+}
+
+// EpisodeRangeKeys returns every episode between from and to, both inclusive.
+func EpisodeRangeKeys(seasons []SeasonEpisodes, from, to EpisodeKey) ([]EpisodeKey, error) {
+	if err := ValidateEpisodeKey(seasons, from); err != nil {
+		return nil, err
+	}
+
+	if err := ValidateEpisodeKey(seasons, to); err != nil {
+		return nil, err
+	}
+
+	if from.SeasonNumber > to.SeasonNumber ||
+		(from.SeasonNumber == to.SeasonNumber && from.EpisodeNumber > to.EpisodeNumber) {
+		return nil, fmt.Errorf("Invalid episode range.")
+	}
+
+	var keys []EpisodeKey
+	for _, season := range seasons {
+		if season.SeasonNumber < from.SeasonNumber || season.SeasonNumber > to.SeasonNumber {
+			continue
+		}
+
+		firstEpisode, lastEpisode := 1, season.EpisodeCount
+		if season.SeasonNumber == from.SeasonNumber {
+			firstEpisode = from.EpisodeNumber
+		}
+
+		if season.SeasonNumber == to.SeasonNumber {
+			lastEpisode = to.EpisodeNumber
+		} else if season.EpisodeCount <= 0 {
+			return nil, fmt.Errorf("Episode count of season %d is unknown.", season.SeasonNumber)
+		}
+
+		for episode := firstEpisode; episode <= lastEpisode; episode++ {
+			keys = append(keys, EpisodeKey{SeasonNumber: season.SeasonNumber, EpisodeNumber: episode})
+		}
+	}
+
+	return keys, nil
+}
+
+func episodeKeyFilters(keys []EpisodeKey) bson.A {
+	filters := bson.A{}
+	for _, key := range keys {
+		filters = append(filters, bson.M{
+			"season_number":  key.SeasonNumber,
+			"episode_number": key.EpisodeNumber,
+		})
+	}
+
+	return filters
+}
+
+// ! Create
+func (episodeWatchModel *EpisodeWatchModel) MarkEpisodesAsWatched(
+	uid, listID, contentID, contentType string, keys []EpisodeKey, score *float32,
+) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	watchedAt := time.Now().UTC()
+
+	writeModels := make([]mongo.WriteModel, 0, len(keys))
+	for _, key := range keys {
+		set := bson.M{
+			"user_id":      uid,
+			"content_id":   contentID,
+			"content_type": contentType,
+			"watched_at":   watchedAt,
+		}
+
+		if score != nil {
+			set["score"] = score
+		}
+
+		writeModels = append(writeModels, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{
+				"list_id":        listID,
+				"season_number":  key.SeasonNumber,
+				"episode_number": key.EpisodeNumber,
+			}).
+			SetUpdate(bson.M{"$set": set}).
+			SetUpsert(true),
+		)
+	}
+
+	if _, err := episodeWatchModel.EpisodeWatchCollection.BulkWrite(
+		context.TODO(), writeModels, options.BulkWrite().SetOrdered(false),
+	); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid":     uid,
+			"list_id": listID,
+			"count":   len(keys),
+		}).Error("failed to mark episodes as watched: ", err)
+
+		return fmt.Errorf("Failed to mark episodes as watched.")
+	}
+
+	return nil
+}
+
+// RestoreEpisodeWatches inserts the episode records of a restored entry back with
+// their original ids.
+func (episodeWatchModel *EpisodeWatchModel) RestoreEpisodeWatches(listID string, episodeWatches []EpisodeWatch) error {
+	if len(episodeWatches) == 0 {
+		return nil
+	}
+
+	writeModels := make([]mongo.WriteModel, 0, len(episodeWatches))
+	for _, episodeWatch := range episodeWatches {
+		writeModels = append(writeModels, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": episodeWatch.ID}).
+			SetReplacement(episodeWatch).
+			SetUpsert(true),
+		)
+	}
+
+	if _, err := episodeWatchModel.EpisodeWatchCollection.BulkWrite(
+		context.TODO(), writeModels, options.BulkWrite().SetOrdered(false),
+	); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"list_id": listID,
+			"count":   len(episodeWatches),
+		}).Error("failed to restore episode watches: ", err)
+
+		return fmt.Errorf("Failed to restore watched episodes.")
+	}
+
+	return nil
+}
+
+// ReconcileEpisodeWatches makes the episode records match the watched_episodes
+// counter, the counter is also set directly e.g. by the list update, undo and
+// imports. Missing episodes are marked from the first one and extra ones are
+// unmarked from the last one, in season and episode order.
+func (episodeWatchModel *EpisodeWatchModel) ReconcileEpisodeWatches(
+	uid, listID, contentID, contentType string, seasons []SeasonEpisodes, watchedEpisodes int,
+) error {
+	episodeWatches, err := episodeWatchModel.GetEpisodeWatchesByListID(listID)
+	if err != nil {
+		return err
+	}
+
+	watched := make(map[EpisodeKey]bool, len(episodeWatches))
+	for _, episodeWatch := range episodeWatches {
+		watched[EpisodeKey{SeasonNumber: episodeWatch.SeasonNumber, EpisodeNumber: episodeWatch.EpisodeNumber}] = true
+	}
+
+	// Only the records of the seasons are counted, e.g. specials are not.
+	var watchedKeys []EpisodeKey
+	for _, episodeWatch := range episodeWatches {
+		key := EpisodeKey{SeasonNumber: episodeWatch.SeasonNumber, EpisodeNumber: episodeWatch.EpisodeNumber}
+		if season, ok := findSeason(seasons, key.SeasonNumber); ok &&
+			(season.EpisodeCount <= 0 || key.EpisodeNumber <= season.EpisodeCount) {
+			watchedKeys = append(watchedKeys, key)
+		}
+	}
+
+	if len(watchedKeys) > watchedEpisodes {
+		return episodeWatchModel.UnmarkEpisodes(listID, watchedKeys[max(watchedEpisodes, 0):])
+	}
+
+	var missingKeys []EpisodeKey
+	for _, season := range seasons {
+		for episode := 1; len(watchedKeys)+len(missingKeys) < watchedEpisodes &&
+			(season.EpisodeCount <= 0 || episode <= season.EpisodeCount); episode++ {
+			key := EpisodeKey{SeasonNumber: season.SeasonNumber, EpisodeNumber: episode}
+			if !watched[key] {
+				missingKeys = append(missingKeys, key)
+			}
+		}
+	}
+
+	return episodeWatchModel.MarkEpisodesAsWatched(uid, listID, contentID, contentType, missingKeys, nil)
+}
+
+// ! Get
+func (episodeWatchModel *EpisodeWatchModel) GetEpisodeWatchesByListID(listID string) ([]EpisodeWatch, error) {
+	cursor, err := episodeWatchModel.EpisodeWatchCollection.Find(context.TODO(), bson.M{
+		"list_id": listID,
+	}, options.Find().SetSort(bson.D{{Key: "season_number", Value: 1}, {Key: "episode_number", Value: 1}}))
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"list_id": listID,
+		}).Error("failed to find episode watches: ", err)
+
+		return nil, fmt.Errorf("Failed to get watched episodes.")
+	}
+
+	var episodeWatches []EpisodeWatch
+	if err := cursor.All(context.TODO(), &episodeWatches); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"list_id": listID,
+		}).Error("failed to decode episode watches: ", err)
+
+		return nil, fmt.Errorf("Failed to decode watched episodes.")
+	}
+
+	return episodeWatches, nil
+}
+
+// GetNextUnwatchedEpisode returns the first episode in watch order that has no record.
+func (episodeWatchModel *EpisodeWatchModel) GetNextUnwatchedEpisode(listID string, seasons []SeasonEpisodes) (EpisodeKey, bool, error) {
+	episodeWatches, err := episodeWatchModel.GetEpisodeWatchesByListID(listID)
+	if err != nil {
+		return EpisodeKey{}, false, err
+	}
+
+	watched := make(map[EpisodeKey]bool, len(episodeWatches))
+	for _, episodeWatch := range episodeWatches {
+		watched[EpisodeKey{SeasonNumber: episodeWatch.SeasonNumber, EpisodeNumber: episodeWatch.EpisodeNumber}] = true
+	}
+
+	for _, season := range seasons {
+		for episode := 1; season.EpisodeCount <= 0 || episode <= season.EpisodeCount; episode++ {
+			key := EpisodeKey{SeasonNumber: season.SeasonNumber, EpisodeNumber: episode}
+			if !watched[key] {
+				return key, true, nil
+			}
+		}
+	}
+
+	return EpisodeKey{}, false, nil
+}
+
+// GetNextUnwatchedSeason returns the first season in order that is not fully watched.
+func (episodeWatchModel *EpisodeWatchModel) GetNextUnwatchedSeason(listID string, seasons []SeasonEpisodes) (int, bool, error) {
+	watchedBySeason, err := episodeWatchModel.countEpisodeWatchesBySeason(listID)
+	if err != nil {
+		return 0, false, err
+	}
+
+	for _, season := range seasons {
+		if season.EpisodeCount <= 0 || watchedBySeason[season.SeasonNumber] < season.EpisodeCount {
+			return season.SeasonNumber, true, nil
+		}
+	}
+
+	return 0, false, nil
+}
+
+// CountWatchedEpisodesAndSeasons derives the watched_episodes and watched_seasons
+// counters of a list entry from its episode records.
+func (episodeWatchModel *EpisodeWatchModel) CountWatchedEpisodesAndSeasons(listID string, seasons []SeasonEpisodes) (int, int, error) {
+	watchedBySeason, err := episodeWatchModel.countEpisodeWatchesBySeason(listID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var watchedEpisodes, watchedSeasons int
+	for _, season := range seasons {
+		watchedEpisodes += watchedBySeason[season.SeasonNumber]
+
+		if season.EpisodeCount > 0 && watchedBySeason[season.SeasonNumber] >= season.EpisodeCount {
+			watchedSeasons++
+		}
+	}
+
+	return watchedEpisodes, watchedSeasons, nil
+}
+
+func (episodeWatchModel *EpisodeWatchModel) countEpisodeWatchesBySeason(listID string) (map[int]int, error) {
+	match := bson.M{"$match": bson.M{
+		"list_id": listID,
+	}}
+
+	group := bson.M{"$group": bson.M{
+		"_id": "$season_number",
+		"count": bson.M{
+			"$sum": 1,
+		},
+	}}
+
+	cursor, err := episodeWatchModel.EpisodeWatchCollection.Aggregate(context.TODO(), bson.A{match, group})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"list_id": listID,
+		}).Error("failed to aggregate episode watches: ", err)
+
+		return nil, fmt.Errorf("Failed to count watched episodes.")
+	}
+
+	var results []struct {
+		SeasonNumber int `bson:"_id"`
+		Count        int `bson:"count"`
+	}
+	if err := cursor.All(context.TODO(), &results); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"list_id": listID,
+		}).Error("failed to decode episode watch counts: ", err)
+
+		return nil, fmt.Errorf("Failed to count watched episodes.")
+	}
+
+	watchedBySeason := make(map[int]int, len(results))
+	for _, result := range results {
+		watchedBySeason[result.SeasonNumber] = result.Count
+	}
+
+	return watchedBySeason, nil
+}
+
+// ! Delete
+func (episodeWatchModel *EpisodeWatchModel) UnmarkEpisodes(listID string, keys []EpisodeKey) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if _, err := episodeWatchModel.EpisodeWatchCollection.DeleteMany(context.TODO(), bson.M{
+		"list_id": listID,
+		"$or":     episodeKeyFilters(keys),
+	}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"list_id": listID,
+			"count":   len(keys),
+		}).Error("failed to unmark episodes: ", err)
+
+		return fmt.Errorf("Failed to unmark episodes.")
+	}
+
+	return nil
+}
+
+func (episodeWatchModel *EpisodeWatchModel) DeleteEpisodeWatchesByListID(listID string) {
+	if _, err := episodeWatchModel.EpisodeWatchCollection.DeleteMany(context.TODO(), bson.M{
+		"list_id": listID,
+	}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"list_id": listID,
+		}).Error("failed to delete episode watches by list id: ", err)
+	}
+}
+
+func (episodeWatchModel *EpisodeWatchModel) DeleteEpisodeWatchesByUserID(uid string) {
+	if _, err := episodeWatchModel.EpisodeWatchCollection.DeleteMany(context.TODO(), bson.M{
+		"user_id": uid,
+	}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to delete episode watches by user id: ", err)
+	}
+}
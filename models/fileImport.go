@@ -0,0 +1,499 @@
+package models
+
+import (
+	"app/db"
+	"app/responses"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+//lint:file-ignore ST1005 Ignore all
+
+// importFileMaxExtractedSize limits the decompressed size of the uploads, only
+// the compressed file is limited on upload.
+const importFileMaxExtractedSize = 100 << 20
+
+var errImportFileTooLarge = fmt.Errorf("Export file is too large, maximum size is 100MB once it's extracted.")
+
+// FileImportModel reads the official export files of other services.
+type FileImportModel struct {
+	ImportJobModel *ImportJobModel
+}
+
+func NewFileImportModel(mongoDB *db.MongoDB) *FileImportModel {
+	return &FileImportModel{
+		ImportJobModel: NewImportJobModel(mongoDB),
+	}
+}
+
+// ! Fetch
+// FetchFileEntries reads the entries of the uploaded export file.
+func (fileImportModel *FileImportModel) FetchFileEntries(source, fileID, fileName string) ([]*ImportEntry, error) {
+	data, err := fileImportModel.ImportJobModel.ReadImportFile(fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*ImportEntry
+	switch source {
+	case MALFileImportSource:
+		entries, err = parseMALExportFile(data)
+	case LetterboxdImportSource:
+		entries, err = parseLetterboxdExportFile(fileName, data)
+	case IMDBFileImportSource:
+		entries, err = parseIMDBRatingsFile(data)
+	case TraktFileImportSource:
+		entries, err = parseTraktExportFile(data)
+	case BackloggdImportSource, HLTBImportSource, GOGImportSource:
+		entries, err = parseGameBacklogFile(source, data)
+	default:
+		err = fmt.Errorf("Unknown import source.")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("No entries found in the file, please check the file.")
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"source":        source,
+		"total_entries": len(entries),
+	}).Info("Starting file import")
+
+	return entries, nil
+}
+
+// ! Parse
+// readImportArchive returns the files of a zip archive by their lower case base
+// names. Files in sub folders are only used if there is no such file at the root,
+// e.g. Letterboxd keeps deleted entries in a folder with the same file names.
+func readImportArchive(data []byte) (map[string][]byte, error) {
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read zip archive.")
+	}
+
+	files := map[string][]byte{}
+	nestedFiles := map[string][]byte{}
+	remainingSize := int64(importFileMaxExtractedSize)
+	for _, zipFile := range zipReader.File {
+		if zipFile.FileInfo().IsDir() {
+			continue
+		}
+
+		dir := strings.ToLower(path.Dir(zipFile.Name))
+		if strings.Contains(dir, "deleted") || strings.Contains(dir, "orphaned") {
+			continue
+		}
+
+		// Size in the header can be forged, the content is limited while it's read too.
+		if zipFile.UncompressedSize64 > uint64(remainingSize) {
+			return nil, errImportFileTooLarge
+		}
+
+		reader, err := zipFile.Open()
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read zip archive.")
+		}
+
+		content, err := readImportLimited(reader, remainingSize)
+		reader.Close()
+		if err != nil {
+			return nil, err
+		}
+		remainingSize -= int64(len(content))
+
+		name := strings.ToLower(path.Base(zipFile.Name))
+		if dir == "." {
+			files[name] = content
+		} else {
+			nestedFiles[name] = content
+		}
+	}
+
+	for name, content := range nestedFiles {
+		if _, exists := files[name]; !exists {
+			files[name] = content
+		}
+	}
+
+	return files, nil
+}
+
+// readImportLimited reads the decompressed content up to the limit, so that
+// a zip or gzip bomb fails instead of filling the memory.
+func readImportLimited(reader io.Reader, limit int64) ([]byte, error) {
+	content, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read compressed file.")
+	}
+
+	if int64(len(content)) > limit {
+		return nil, errImportFileTooLarge
+	}
+
+	return content, nil
+}
+
+func isZipFile(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("PK\x03\x04"))
+}
+
+// readImportCSV returns the rows as maps of the header columns.
+func readImportCSV(data []byte) ([]map[string]string, error) {
+	csvReader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read csv file.")
+	}
+
+	if len(records) == 0 {
+		return []map[string]string{}, nil
+	}
+
+	header := records[0]
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := map[string]string{}
+		for index, column := range header {
+			if index < len(record) {
+				row[strings.ToLower(strings.TrimSpace(column))] = strings.TrimSpace(record[index])
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func parseImportDate(date string) *time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if parsedDate, err := time.Parse(layout, date); err == nil {
+			parsedDate = parsedDate.UTC()
+			return &parsedDate
+		}
+	}
+
+	return nil
+}
+
+func parseImportScore(score string, multiplier float64) *float32 {
+	value, err := strconv.ParseFloat(score, 64)
+	if err != nil || value <= 0 {
+		return nil
+	}
+
+	converted := float32(value * multiplier)
+	return &converted
+}
+
+// parseMALExportFile parses animelist.xml or mangalist.xml, gzipped exports of
+// MyAnimeList are accepted as is.
+func parseMALExportFile(data []byte) ([]*ImportEntry, error) {
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read MyAnimeList export.")
+		}
+		defer gzipReader.Close()
+
+		if data, err = readImportLimited(gzipReader, importFileMaxExtractedSize); err != nil {
+			return nil, err
+		}
+	}
+
+	var malExport responses.MALExport
+	if err := xml.Unmarshal(data, &malExport); err != nil {
+		return nil, fmt.Errorf("Failed to parse MyAnimeList export.")
+	}
+
+	fileEntries := newImportEntries()
+
+	for _, anime := range malExport.Anime {
+		status := parseMALExportStatus(anime.MyStatus)
+		fileEntries.add(ImportEntry{
+			ContentType:   "anime",
+			Title:         anime.SeriesTitle.Value,
+			MALID:         anime.SeriesAnimeDBID,
+			Status:        status,
+			Score:         parseImportScore(strconv.Itoa(anime.MyScore), 1),
+			Progress:      anime.MyWatchedEpisodes,
+			TimesFinished: parseMALExportTimesFinished(status, anime.MyTimesWatched),
+			StartedAt:     parseImportDate(anime.MyStartDate),
+			FinishedAt:    parseImportDate(anime.MyFinishDate),
+		})
+	}
+
+	for _, manga := range malExport.Manga {
+		status := parseMALExportStatus(manga.MyStatus)
+		fileEntries.add(ImportEntry{
+			ContentType:   "manga",
+			Title:         manga.MangaTitle.Value,
+			MALID:         manga.MangaMangaDBID,
+			Status:        status,
+			Score:         parseImportScore(strconv.Itoa(manga.MyScore), 1),
+			Progress:      manga.MyReadChapters,
+			Volumes:       manga.MyReadVolumes,
+			TimesFinished: parseMALExportTimesFinished(status, manga.MyTimesRead),
+			StartedAt:     parseImportDate(manga.MyStartDate),
+			FinishedAt:    parseImportDate(manga.MyFinishDate),
+		})
+	}
+
+	return fileEntries.entries, nil
+}
+
+func parseMALExportStatus(status string) string {
+	switch status {
+	case "Watching", "Reading", "On-Hold":
+		return "active"
+	case "Completed":
+		return "finished"
+	case "Dropped":
+		return "dropped"
+	}
+
+	return "planto"
+}
+
+// parseMALExportTimesFinished converts MyAnimeList rewatch/reread count to times finished.
+func parseMALExportTimesFinished(status string, timesRepeated int) int {
+	if status == "finished" {
+		return timesRepeated + 1
+	}
+
+	return timesRepeated
+}
+
+// parseLetterboxdExportFile parses the Letterboxd export archive, or one of its
+// diary.csv, ratings.csv, watched.csv and watchlist.csv files. Letterboxd ratings
+// are 0.5-5 and every diary row is a watch.
+func parseLetterboxdExportFile(fileName string, data []byte) ([]*ImportEntry, error) {
+	files := map[string][]byte{strings.ToLower(path.Base(fileName)): data}
+	if isZipFile(data) {
+		var err error
+		if files, err = readImportArchive(data); err != nil {
+			return nil, err
+		}
+	}
+
+	fileEntries := newImportEntries()
+
+	for _, name := range []string{"watchlist.csv", "watched.csv", "ratings.csv", "diary.csv"} {
+		fileData, ok := files[name]
+		if !ok {
+			continue
+		}
+
+		rows, err := readImportCSV(fileData)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			year, _ := strconv.Atoi(row["year"])
+			entry := ImportEntry{
+				ContentType: "movie",
+				Title:       row["name"],
+				Year:        year,
+				IMDBID:      row["imdbid"],
+				TMDBID:      row["tmdbid"],
+				Status:      "finished",
+			}
+
+			if entry.Title == "" {
+				entry.Title = row["title"]
+			}
+
+			switch name {
+			case "watchlist.csv":
+				entry.Status = "planto"
+			case "ratings.csv":
+				entry.Score = parseImportScore(row["rating"], 2)
+			case "diary.csv":
+				entry.Score = parseImportScore(row["rating"], 2)
+				entry.FinishedAt = parseImportDate(row["watched date"])
+				entry.TimesFinished = 1
+			}
+
+			fileEntries.add(entry)
+		}
+	}
+
+	if len(fileEntries.entries) == 0 && len(files) > 0 && !isZipFile(data) {
+		return nil, fmt.Errorf("Unsupported Letterboxd file, please upload the export archive or one of its csv files.")
+	}
+
+	return fileEntries.entries, nil
+}
+
+// parseIMDBRatingsFile parses ratings.csv of IMDb, rated titles are finished.
+func parseIMDBRatingsFile(data []byte) ([]*ImportEntry, error) {
+	rows, err := readImportCSV(data)
+	if err != nil {
+		return nil, err
+	}
+
+	fileEntries := newImportEntries()
+
+	for _, row := range rows {
+		contentType := "movie"
+		switch row["title type"] {
+		case "tvSeries", "tvMiniSeries", "TV Series", "TV Mini Series":
+			contentType = "tv"
+		case "tvEpisode", "TV Episode", "videoGame", "Video Game":
+			continue
+		}
+
+		if row["const"] == "" {
+			continue
+		}
+
+		year, _ := strconv.Atoi(row["year"])
+		fileEntries.add(ImportEntry{
+			ContentType: contentType,
+			Title:       row["title"],
+			Year:        year,
+			IMDBID:      row["const"],
+			Status:      "finished",
+			Score:       parseImportScore(row["your rating"], 1),
+			FinishedAt:  parseImportDate(row["date rated"]),
+		})
+	}
+
+	return fileEntries.entries, nil
+}
+
+// parseTraktExportFile parses the Trakt backup archive with watched, ratings and
+// watchlist json files, or a json file in the format of our Trakt export.
+func parseTraktExportFile(data []byte) ([]*ImportEntry, error) {
+	fileEntries := newImportEntries()
+
+	if !isZipFile(data) {
+		var traktExport responses.TraktExport
+		if err := json.Unmarshal(data, &traktExport); err != nil {
+			return nil, fmt.Errorf("Failed to parse Trakt export.")
+		}
+
+		sections := []struct {
+			section responses.TraktExportSection
+			status  string
+		}{
+			{traktExport.Watchlist, "planto"},
+			{traktExport.History, "finished"},
+			{traktExport.Ratings, "finished"},
+		}
+
+		for _, section := range sections {
+			for _, item := range section.section.Movies {
+				fileEntries.add(traktExportFileEntry("movie", item, section.status))
+			}
+
+			for _, item := range section.section.Shows {
+				fileEntries.add(traktExportFileEntry("tv", item, section.status))
+			}
+		}
+
+		return fileEntries.entries, nil
+	}
+
+	files, err := readImportArchive(data)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, fileData := range files {
+		switch {
+		case strings.HasPrefix(name, "watchlist") && strings.HasSuffix(name, ".json"):
+			var items []responses.TraktWatchlistItem
+			if err := json.Unmarshal(fileData, &items); err != nil {
+				return nil, fmt.Errorf("Failed to parse %s.", name)
+			}
+
+			for _, item := range items {
+				fileEntries.add(traktImportEntry(item.Movie, item.Show, "planto", nil))
+			}
+		case name == "watched-movies.json":
+			var movies []responses.TraktWatchedMovie
+			if err := json.Unmarshal(fileData, &movies); err != nil {
+				return nil, fmt.Errorf("Failed to parse %s.", name)
+			}
+
+			for _, movie := range movies {
+				fileEntries.add(traktWatchedMovieEntry(movie))
+			}
+		case name == "watched-shows.json":
+			var shows []responses.TraktWatchedShow
+			if err := json.Unmarshal(fileData, &shows); err != nil {
+				return nil, fmt.Errorf("Failed to parse %s.", name)
+			}
+
+			for _, show := range shows {
+				fileEntries.add(traktWatchedShowEntry(show))
+			}
+		case strings.HasPrefix(name, "ratings-movies") || strings.HasPrefix(name, "ratings-shows"):
+			var items []responses.TraktRatingItem
+			if err := json.Unmarshal(fileData, &items); err != nil {
+				return nil, fmt.Errorf("Failed to parse %s.", name)
+			}
+
+			for _, item := range items {
+				fileEntries.add(traktImportEntry(item.Movie, item.Show, "finished", parseImportScore(strconv.Itoa(item.Rating), 1)))
+			}
+		}
+	}
+
+	return fileEntries.entries, nil
+}
+
+func traktExportFileEntry(contentType string, item responses.TraktExportItem, status string) ImportEntry {
+	entry := ImportEntry{
+		ContentType: contentType,
+		Title:       item.Title,
+		Status:      status,
+	}
+
+	if item.Year != nil {
+		entry.Year = *item.Year
+	}
+
+	if item.IDs.Imdb != nil {
+		entry.IMDBID = *item.IDs.Imdb
+	}
+
+	if item.IDs.Trakt != nil {
+		entry.TraktID = *item.IDs.Trakt
+	}
+
+	if item.IDs.Tmdb != nil {
+		entry.TMDBID = strconv.FormatInt(*item.IDs.Tmdb, 10)
+	}
+
+	if item.WatchedAt != nil {
+		entry.FinishedAt = parseImportDate(*item.WatchedAt)
+	}
+
+	if item.Rating != nil {
+		entry.Score = parseImportScore(strconv.Itoa(*item.Rating), 1)
+	}
+
+	return entry
+}
@@ -0,0 +1,434 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+//lint:file-ignore ST1005 Ignore all
+
+// Game backlog files don't have ids, games are matched by title, release year
+// and platform. Columns are looked up by their known names in every format.
+var (
+	gameFileTitleColumns      = []string{"title", "name", "game", "game title", "game name"}
+	gameFilePlatformColumns   = []string{"platform", "platforms", "platformlist", "platform list", "console", "storefront"}
+	gameFileStatusColumns     = []string{"status", "completion status", "list", "state", "progress status"}
+	gameFileYearColumns       = []string{"release date", "releasedate", "release year", "released", "year"}
+	gameFileRatingColumns     = []string{"rating", "my rating", "myrating", "score", "review"}
+	gameFileCompletionColumns = []string{"completion", "completion %", "achievements", "achievement progress", "achievementcompletion", "percentage"}
+	gameFileStartedColumns    = []string{"started", "start date", "started on", "date started"}
+	gameFileFinishedColumns   = []string{"completed on", "finished", "finish date", "finished on", "date completed", "completion date"}
+	gameFileHourColumns       = []string{"time played", "playtime", "hours played", "hours", "progress", "played time"}
+	gameFileMinuteColumns     = []string{"gamemins", "gameminutes", "minutes played", "time played (minutes)", "playtime (minutes)"}
+)
+
+// Ratings are converted to 10 point scores, HowLongToBeat reviews are 0-100
+// while the others are 0.5-5 stars.
+var gameFileScoreMultipliers = map[string]float64{
+	BackloggdImportSource: 2,
+	HLTBImportSource:      0.1,
+	GOGImportSource:       2,
+}
+
+var (
+	gameFileYearRegex     = regexp.MustCompile(`\b(19|20)\d{2}\b`)
+	gameFileDurationRegex = regexp.MustCompile(`(?i)(\d+(?:\.\d+)?)\s*([hm])`)
+	gameFileFractionRegex = regexp.MustCompile(`^(\d+)\s*/\s*(\d+)$`)
+	gamePlatformRegex     = regexp.MustCompile(`[^a-z0-9]`)
+)
+
+// Platform aliases of the sources, they are normalized to our platform names
+// without spaces and punctuation.
+var gamePlatformAliases = map[string]string{
+	"pc":                 "pc",
+	"windows":            "pc",
+	"windowspc":          "pc",
+	"microsoftwindows":   "pc",
+	"pcmicrosoftwindows": "pc",
+	"steam":              "pc",
+	"epic":               "pc",
+	"epicgamesstore":     "pc",
+	"gog":                "pc",
+	"uplay":              "pc",
+	"ubisoftconnect":     "pc",
+	"origin":             "pc",
+	"ea":                 "pc",
+	"battlenet":          "pc",
+	"mac":                "macos",
+	"ps5":                "playstation5",
+	"ps4":                "playstation4",
+	"ps3":                "playstation3",
+	"ps2":                "playstation2",
+	"ps1":                "playstation",
+	"psx":                "playstation",
+	"playstationvita":    "psvita",
+	"vita":               "psvita",
+	"switch":             "nintendoswitch",
+	"nswitch":            "nintendoswitch",
+	"3ds":                "nintendo3ds",
+	"xboxseriesx":        "xboxseriessx",
+	"xboxseriess":        "xboxseriessx",
+	"xboxseriesxs":       "xboxseriessx",
+	"xboxseriessx":       "xboxseriessx",
+	"xbox":               "xbox",
+	"xbox1":              "xboxone",
+}
+
+// Platform families of the sources that don't tell the exact platform, e.g. GOG
+// Galaxy has "psn" for every PlayStation.
+var gamePlatformFamilies = map[string][]string{
+	"psn": {"playstation", "playstation2", "playstation3", "playstation4", "playstation5", "psp", "psvita"},
+}
+
+// parseGameBacklogFile parses the csv exports of Backloggd, HowLongToBeat and
+// GOG Galaxy, GOG Galaxy includes the games of Epic, PlayStation, Xbox etc.
+func parseGameBacklogFile(source string, data []byte) ([]*ImportEntry, error) {
+	rows, err := readImportCSV(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) > 0 && gameFileValue(rows[0], gameFileTitleColumns...) == "" {
+		return nil, fmt.Errorf("Title column is not found, please upload the csv export.")
+	}
+
+	fileEntries := newImportEntries()
+
+	for _, row := range rows {
+		title := gameFileValue(row, gameFileTitleColumns...)
+		if title == "" {
+			continue
+		}
+
+		year := 0
+		if yearText := gameFileYearRegex.FindString(gameFileValue(row, gameFileYearColumns...)); yearText != "" {
+			year, _ = strconv.Atoi(yearText)
+		}
+
+		entry := ImportEntry{
+			ContentType:       "game",
+			Title:             title,
+			Year:              year,
+			Platform:          gameFileValue(row, gameFilePlatformColumns...),
+			Score:             parseGameFileScore(source, gameFileValue(row, gameFileRatingColumns...)),
+			HoursPlayed:       parseGameFilePlaytime(row),
+			AchievementStatus: parseGameFileCompletion(row),
+			StartedAt:         parseImportDate(gameFileValue(row, gameFileStartedColumns...)),
+			FinishedAt:        parseImportDate(gameFileValue(row, gameFileFinishedColumns...)),
+		}
+
+		entry.Status = parseGameFileStatus(row, entry)
+
+		// Mastered games are completed with every achievement.
+		if strings.Contains(strings.ToLower(gameFileValue(row, gameFileStatusColumns...)), "master") {
+			completion := float32(100)
+			entry.AchievementStatus = &completion
+		}
+
+		if entry.Status == "finished" {
+			entry.TimesFinished = 1
+		}
+
+		fileEntries.add(entry)
+	}
+
+	return fileEntries.entries, nil
+}
+
+// gameFileValue returns the first non empty value of the columns.
+func gameFileValue(row map[string]string, columns ...string) string {
+	for _, column := range columns {
+		if value := row[column]; value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// isGameFileFlag reports whether the column is checked, HowLongToBeat marks the
+// lists of the game with separate columns.
+func isGameFileFlag(row map[string]string, column string) bool {
+	switch strings.ToLower(row[column]) {
+	case "", "0", "false", "no", "n":
+		return false
+	}
+
+	return true
+}
+
+// parseGameFileStatus converts the status, or the list flags if there is no
+// status. Games without both are active if they are played, planned otherwise.
+func parseGameFileStatus(row map[string]string, entry ImportEntry) string {
+	status := strings.ToLower(gameFileValue(row, gameFileStatusColumns...))
+
+	switch {
+	case status == "":
+	case strings.Contains(status, "unplayed"), strings.Contains(status, "not played"):
+		return "planto"
+	case strings.Contains(status, "playing"), strings.Contains(status, "replay"),
+		strings.Contains(status, "shelved"), strings.Contains(status, "hold"),
+		strings.Contains(status, "paused"), strings.Contains(status, "in progress"):
+		return "active"
+	case strings.Contains(status, "master"), strings.Contains(status, "complet"),
+		strings.Contains(status, "beaten"), strings.Contains(status, "finished"),
+		strings.Contains(status, "played"):
+		return "finished"
+	case strings.Contains(status, "abandon"), strings.Contains(status, "retired"),
+		strings.Contains(status, "dropped"):
+		return "dropped"
+	case strings.Contains(status, "backlog"), strings.Contains(status, "wishlist"),
+		strings.Contains(status, "plan"), strings.Contains(status, "want"):
+		return "planto"
+	}
+
+	switch {
+	case isGameFileFlag(row, "completed"):
+		return "finished"
+	case isGameFileFlag(row, "retired"):
+		return "dropped"
+	case isGameFileFlag(row, "playing"), isGameFileFlag(row, "replay"):
+		return "active"
+	case isGameFileFlag(row, "backlog"), isGameFileFlag(row, "wishlist"):
+		return "planto"
+	case entry.FinishedAt != nil:
+		return "finished"
+	case entry.HoursPlayed != nil:
+		return "active"
+	}
+
+	return "planto"
+}
+
+func parseGameFileScore(source, rating string) *float32 {
+	score := parseImportScore(strings.TrimSuffix(rating, "%"), gameFileScoreMultipliers[source])
+	if score == nil || *score > 10 {
+		return nil
+	}
+
+	return score
+}
+
+// parseGameFilePlaytime converts the playtime to hours, playtime can be hours,
+// hh:mm(:ss), "12h 30m" or minutes in the minute columns.
+func parseGameFilePlaytime(row map[string]string) *int {
+	var minutes float64
+
+	if value := gameFileValue(row, gameFileMinuteColumns...); value != "" {
+		minutes, _ = strconv.ParseFloat(value, 64)
+	} else if value := gameFileValue(row, gameFileHourColumns...); value != "" {
+		minutes = parseGameFileDuration(value)
+	}
+
+	if minutes <= 0 {
+		return nil
+	}
+
+	hours := int(math.Max(1, math.Round(minutes/60)))
+	return &hours
+}
+
+func parseGameFileDuration(value string) float64 {
+	if hours, err := strconv.ParseFloat(value, 64); err == nil {
+		return hours * 60
+	}
+
+	if parts := strings.Split(value, ":"); len(parts) >= 2 {
+		hours, hourErr := strconv.Atoi(parts[0])
+		minutes, minuteErr := strconv.Atoi(parts[1])
+		if hourErr == nil && minuteErr == nil {
+			return float64(hours*60 + minutes)
+		}
+	}
+
+	var minutes float64
+	for _, match := range gameFileDurationRegex.FindAllStringSubmatch(value, -1) {
+		amount, _ := strconv.ParseFloat(match[1], 64)
+		if strings.ToLower(match[2]) == "h" {
+			amount *= 60
+		}
+		minutes += amount
+	}
+
+	return minutes
+}
+
+// parseGameFileCompletion converts the completion to percentage, completion can
+// be a percentage or unlocked/total achievements. HowLongToBeat has the time of
+// the completionist run instead.
+func parseGameFileCompletion(row map[string]string) *float32 {
+	value := strings.TrimSpace(strings.TrimSuffix(gameFileValue(row, gameFileCompletionColumns...), "%"))
+
+	var completion float64
+	if match := gameFileFractionRegex.FindStringSubmatch(value); match != nil {
+		unlocked, _ := strconv.ParseFloat(match[1], 64)
+		total, _ := strconv.ParseFloat(match[2], 64)
+		if total == 0 {
+			return nil
+		}
+
+		completion = unlocked / total * 100
+	} else if parsedValue, err := strconv.ParseFloat(value, 64); err == nil {
+		completion = parsedValue
+		if completion > 0 && completion <= 1 && strings.Contains(value, ".") {
+			completion *= 100
+		}
+	} else if row["completionist"] != "" && parseGameFileDuration(row["completionist"]) > 0 {
+		completion = 100
+	} else {
+		return nil
+	}
+
+	if completion < 0 || completion > 100 {
+		return nil
+	}
+
+	converted := float32(math.Round(completion*10) / 10)
+	return &converted
+}
+
+// normalizeGamePlatform converts the platform name to our platform names in
+// lower case without spaces and punctuation.
+func normalizeGamePlatform(platform string) string {
+	normalized := gamePlatformRegex.ReplaceAllString(strings.ToLower(platform), "")
+	if alias, ok := gamePlatformAliases[normalized]; ok {
+		return alias
+	}
+
+	return normalized
+}
+
+// isGamePlatformMatch reports whether one of the platforms of the entry is one
+// of the game's platforms, platforms are compared exactly except the families
+// e.g. "psn" matches every PlayStation.
+func isGamePlatformMatch(entryPlatform string, platforms []string) bool {
+	for _, entryPlatform := range strings.FieldsFunc(entryPlatform, func(r rune) bool {
+		return r == ',' || r == ';' || r == '|' || r == '/'
+	}) {
+		entryPlatform = normalizeGamePlatform(entryPlatform)
+		if entryPlatform == "" {
+			continue
+		}
+
+		entryPlatforms, ok := gamePlatformFamilies[entryPlatform]
+		if !ok {
+			entryPlatforms = []string{entryPlatform}
+		}
+
+		for _, platform := range platforms {
+			platform = normalizeGamePlatform(platform)
+			for _, entryPlatform := range entryPlatforms {
+				if platform == entryPlatform {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+type gameImportContent struct {
+	ID            primitive.ObjectID `bson:"_id"`
+	Title         string             `bson:"title"`
+	TitleOriginal string             `bson:"title_original"`
+	ReleaseDate   string             `bson:"release_date"`
+	Platforms     []string           `bson:"platforms"`
+}
+
+// matchRank ranks the game for the entry, remasters and ports share the title
+// so the games of the same release year and platform are preferred. Games of
+// another year on another platform are not matched.
+func (content gameImportContent) matchRank(entry *ImportEntry) int {
+	yearRank := 1
+	if entry.Year != 0 && len(content.ReleaseDate) >= 4 {
+		year, _ := strconv.Atoi(content.ReleaseDate[:4])
+		switch {
+		case year == entry.Year:
+			yearRank = 2
+		case year != 0 && (year-entry.Year > 1 || entry.Year-year > 1):
+			yearRank = 0
+		}
+	}
+
+	platformRank := 0
+	if entry.Platform != "" && isGamePlatformMatch(entry.Platform, content.Platforms) {
+		platformRank = 1
+	}
+
+	if yearRank == 0 && platformRank == 0 {
+		return 0
+	}
+
+	return yearRank*2 + platformRank
+}
+
+// matchGamesByTitles matches the games of the entries without store ids by their
+// title case insensitively, the best ranked game is chosen. Entries without year
+// and platform are left unmatched if more than one game has the title.
+func (listImportModel *ListImportModel) matchGamesByTitles(entries []*ImportEntry, indexes []int, contentIDs []string) error {
+	if len(indexes) == 0 {
+		return nil
+	}
+
+	titleList := make([]string, 0, len(indexes))
+	for _, index := range indexes {
+		titleList = append(titleList, entries[index].Title)
+	}
+
+	cursor, err := listImportModel.GameCollection.Find(context.TODO(), bson.M{"$or": bson.A{
+		bson.M{"title": bson.M{"$in": titleList}},
+		bson.M{"title_original": bson.M{"$in": titleList}},
+	}}, options.Find().SetProjection(bson.M{
+		"_id":            1,
+		"title":          1,
+		"title_original": 1,
+		"release_date":   1,
+		"platforms":      1,
+	}).SetCollation(&options.Collation{Locale: "en", Strength: 2}))
+	if err != nil {
+		return fmt.Errorf("failed to match game titles: %v", err)
+	}
+
+	var contents []gameImportContent
+	if err := cursor.All(context.TODO(), &contents); err != nil {
+		return fmt.Errorf("failed to match game titles: %v", err)
+	}
+
+	titleContents := map[string][]gameImportContent{}
+	for _, content := range contents {
+		titleKey := strings.ToLower(strings.TrimSpace(content.Title))
+		originalKey := strings.ToLower(strings.TrimSpace(content.TitleOriginal))
+		if titleKey != "" {
+			titleContents[titleKey] = append(titleContents[titleKey], content)
+		}
+		if originalKey != "" && originalKey != titleKey {
+			titleContents[originalKey] = append(titleContents[originalKey], content)
+		}
+	}
+
+	for _, index := range indexes {
+		candidates := titleContents[strings.ToLower(strings.TrimSpace(entries[index].Title))]
+		if entries[index].Year == 0 && entries[index].Platform == "" && len(candidates) > 1 {
+			continue
+		}
+
+		bestRank := 0
+		for _, content := range candidates {
+			if rank := content.matchRank(entries[index]); rank > bestRank {
+				bestRank = rank
+				contentIDs[index] = content.ID.Hex()
+			}
+		}
+	}
+
+	return nil
+}
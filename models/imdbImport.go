@@ -23,22 +23,18 @@ import (
 //lint:file-ignore ST1005 Ignore all
 
 type IMDBImportModel struct {
-	MovieCollection     *mongo.Collection
-	TVCollection        *mongo.Collection
-	MovieListCollection *mongo.Collection
-	TVListCollection    *mongo.Collection
+	MovieCollection *mongo.Collection
+	TVCollection    *mongo.Collection
 }
 
 func NewIMDBImportModel(mongoDB *db.MongoDB) *IMDBImportModel {
 	return &IMDBImportModel{
-		MovieCollection:     mongoDB.Database.Collection("movies"),
-		TVCollection:        mongoDB.Database.Collection("tv-series"),
-		MovieListCollection: mongoDB.Database.Collection("movie-watch-lists"),
-		TVListCollection:    mongoDB.Database.Collection("tvseries-watch-lists"),
+		MovieCollection: mongoDB.Database.Collection("movies"),
+		TVCollection:    mongoDB.Database.Collection("tv-series"),
 	}
 }
 
-func (i *IMDBImportModel) ImportUserWatchlist(userID, imdbUserID, imdbListID string) (responses.IMDBImportResponse, error) {
+func (i *IMDBImportModel) FetchWatchlistEntries(imdbUserID, imdbListID string) ([]*ImportEntry, error) {
 	// Determine URL based on input
 	var targetURL string
 	if imdbUserID != "" {
@@ -46,184 +42,44 @@ func (i *IMDBImportModel) ImportUserWatchlist(userID, imdbUserID, imdbListID str
 	} else if imdbListID != "" {
 		targetURL = fmt.Sprintf("https://www.imdb.com/list/%s/", imdbListID)
 	} else {
-		return responses.IMDBImportResponse{}, fmt.Errorf("either IMDB User ID or List ID must be provided")
+		return nil, fmt.Errorf("either IMDB User ID or List ID must be provided")
 	}
 
 	// Validate and fetch watchlist
 	entries, err := i.fetchIMDBWatchlist(targetURL)
 	if err != nil {
-		return responses.IMDBImportResponse{}, err
+		return nil, err
 	}
 
 	logrus.WithFields(logrus.Fields{
-		"user_id":       userID,
 		"imdb_user_id":  imdbUserID,
 		"imdb_list_id":  imdbListID,
 		"total_entries": len(entries),
 	}).Info("Starting IMDB import")
 
-	// Get existing entries in bulk
-	existingMovieEntries, err := i.getExistingMovieEntries(userID)
-	if err != nil {
-		return responses.IMDBImportResponse{}, fmt.Errorf("failed to get existing movie entries: %v", err)
-	}
-
-	existingTVEntries, err := i.getExistingTVEntries(userID)
-	if err != nil {
-		return responses.IMDBImportResponse{}, fmt.Errorf("failed to get existing TV entries: %v", err)
-	}
-
-	// Get all IMDB IDs from database
-	movieIMDBIDs, err := i.getAllMovieIMDBIDs()
-	if err != nil {
-		return responses.IMDBImportResponse{}, fmt.Errorf("failed to get movie IMDB IDs: %v", err)
-	}
-
-	tvIMDBIDs, err := i.getAllTVIMDBIDs()
-	if err != nil {
-		return responses.IMDBImportResponse{}, fmt.Errorf("failed to get TV IMDB IDs: %v", err)
-	}
-
-	logrus.WithFields(logrus.Fields{
-		"user_id":          userID,
-		"movie_imdb_count": len(movieIMDBIDs),
-		"tv_imdb_count":    len(tvIMDBIDs),
-	}).Info("loaded IMDB mappings from database")
-
-	var importedCount, skippedCount, errorCount int
-	var movieEntriesToInsert []interface{}
-	var tvEntriesToInsert []interface{}
-
-	// Process entries
+	imdbEntries := newImportEntries()
 	for _, entry := range entries {
-		logrus.WithFields(logrus.Fields{
-			"imdb_id": entry.IMDBID,
-			"title":   entry.Title,
-			"type":    entry.Type,
-		}).Debug("processing IMDB entry")
-
-		// Check if it's a movie
-		if movieObjectID, exists := movieIMDBIDs[entry.IMDBID]; exists {
-			// Check if already exists in user's movie list
-			if _, exists := existingMovieEntries[movieObjectID]; exists {
-				// Update existing entry
-				err := i.updateExistingMovieEntry(userID, movieObjectID, entry.UserRating, "planning")
-				if err != nil {
-					logrus.WithFields(logrus.Fields{
-						"imdb_id":  entry.IMDBID,
-						"title":    entry.Title,
-						"movie_id": movieObjectID,
-						"error":    err.Error(),
-					}).Error("failed to update existing movie entry")
-					errorCount++
-				} else {
-					skippedCount++
-				}
-				continue
-			}
-
-			// Create new movie list entry
-			var score *float32
-			if entry.UserRating > 0 {
-				score = &entry.UserRating
-			}
-			movieListEntry := MovieWatchList{
-				UserID:    userID,
-				MovieID:   movieObjectID,
-				Status:    "planning",
-				Score:     score,
-				CreatedAt: time.Now().UTC(),
-				UpdatedAt: time.Now().UTC(),
-			}
-
-			movieEntriesToInsert = append(movieEntriesToInsert, movieListEntry)
-			importedCount++
-		} else if tvObjectID, exists := tvIMDBIDs[entry.IMDBID]; exists {
-			// Check if already exists in user's TV list
-			if _, exists := existingTVEntries[tvObjectID]; exists {
-				// Update existing entry
-				err := i.updateExistingTVEntry(userID, tvObjectID, entry.UserRating, "planning")
-				if err != nil {
-					logrus.WithFields(logrus.Fields{
-						"imdb_id": entry.IMDBID,
-						"title":   entry.Title,
-						"tv_id":   tvObjectID,
-						"error":   err.Error(),
-					}).Error("failed to update existing TV entry")
-					errorCount++
-				} else {
-					skippedCount++
-				}
-				continue
-			}
-
-			// Create new TV list entry
-			var tvScore *float32
-			if entry.UserRating > 0 {
-				tvScore = &entry.UserRating
-			}
-			tvListEntry := TVSeriesWatchList{
-				UserID:    userID,
-				TvID:      tvObjectID,
-				Status:    "planning",
-				Score:     tvScore,
-				CreatedAt: time.Now().UTC(),
-				UpdatedAt: time.Now().UTC(),
-			}
-
-			tvEntriesToInsert = append(tvEntriesToInsert, tvListEntry)
-			importedCount++
-		} else {
-			logrus.WithFields(logrus.Fields{
-				"imdb_id": entry.IMDBID,
-				"title":   entry.Title,
-			}).Debug("content not found in database, skipping")
-			errorCount++
+		if entry.IMDBID == "" {
+			continue
 		}
-	}
 
-	// Bulk insert movie entries
-	if len(movieEntriesToInsert) > 0 {
-		_, err = i.MovieListCollection.InsertMany(context.TODO(), movieEntriesToInsert)
-		if err != nil {
-			logrus.WithFields(logrus.Fields{
-				"user_id": userID,
-				"count":   len(movieEntriesToInsert),
-				"error":   err.Error(),
-			}).Error("failed to bulk insert movie entries")
-			return responses.IMDBImportResponse{}, fmt.Errorf("failed to import movie entries: %v", err)
+		importEntry := ImportEntry{
+			ContentType: entry.Type,
+			Title:       entry.Title,
+			Year:        entry.Year,
+			IMDBID:      entry.IMDBID,
+			Status:      "planto",
 		}
-	}
 
-	// Bulk insert TV entries
-	if len(tvEntriesToInsert) > 0 {
-		_, err = i.TVListCollection.InsertMany(context.TODO(), tvEntriesToInsert)
-		if err != nil {
-			logrus.WithFields(logrus.Fields{
-				"user_id": userID,
-				"count":   len(tvEntriesToInsert),
-				"error":   err.Error(),
-			}).Error("failed to bulk insert TV entries")
-			return responses.IMDBImportResponse{}, fmt.Errorf("failed to import TV entries: %v", err)
+		if entry.UserRating > 0 {
+			rating := entry.UserRating
+			importEntry.Score = &rating
 		}
-	}
 
-	message := fmt.Sprintf("Import completed: %d imported, %d skipped, %d errors",
-		importedCount, skippedCount, errorCount)
+		imdbEntries.add(importEntry)
+	}
 
-	logrus.WithFields(logrus.Fields{
-		"user_id":        userID,
-		"imported_count": importedCount,
-		"skipped_count":  skippedCount,
-		"error_count":    errorCount,
-	}).Info("IMDB import completed")
-
-	return responses.IMDBImportResponse{
-		ImportedCount: importedCount,
-		SkippedCount:  skippedCount,
-		ErrorCount:    errorCount,
-		Message:       message,
-	}, nil
+	return imdbEntries.entries, nil
 }
 
 func (i *IMDBImportModel) fetchIMDBWatchlist(url string) ([]responses.IMDBEntry, error) {
@@ -243,7 +99,7 @@ func (i *IMDBImportModel) fetchIMDBWatchlist(url string) ([]responses.IMDBEntry,
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch IMDB page: %v", err)
+		return nil, importRequestError("failed to fetch IMDB page", err)
 	}
 	defer resp.Body.Close()
 
@@ -252,7 +108,7 @@ func (i *IMDBImportModel) fetchIMDBWatchlist(url string) ([]responses.IMDBEntry,
 	}
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("IMDB returned status code: %d", resp.StatusCode)
+		return nil, importStatusError("IMDB returned status code", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -331,54 +187,6 @@ func (i *IMDBImportModel) extractIMDBIDFromURL(url string) string {
 	return ""
 }
 
-func (i *IMDBImportModel) getExistingMovieEntries(userID string) (map[string]bool, error) {
-	filter := bson.M{"user_id": userID}
-	projection := bson.M{"movie_id": 1}
-
-	cursor, err := i.MovieListCollection.Find(context.TODO(), filter, options.Find().SetProjection(projection))
-	if err != nil {
-		return nil, err
-	}
-	defer cursor.Close(context.TODO())
-
-	existingEntries := make(map[string]bool)
-	for cursor.Next(context.TODO()) {
-		var entry struct {
-			MovieID string `bson:"movie_id"`
-		}
-		if err := cursor.Decode(&entry); err != nil {
-			continue
-		}
-		existingEntries[entry.MovieID] = true
-	}
-
-	return existingEntries, nil
-}
-
-func (i *IMDBImportModel) getExistingTVEntries(userID string) (map[string]bool, error) {
-	filter := bson.M{"user_id": userID}
-	projection := bson.M{"tv_id": 1}
-
-	cursor, err := i.TVListCollection.Find(context.TODO(), filter, options.Find().SetProjection(projection))
-	if err != nil {
-		return nil, err
-	}
-	defer cursor.Close(context.TODO())
-
-	existingEntries := make(map[string]bool)
-	for cursor.Next(context.TODO()) {
-		var entry struct {
-			TvID string `bson:"tv_id"`
-		}
-		if err := cursor.Decode(&entry); err != nil {
-			continue
-		}
-		existingEntries[entry.TvID] = true
-	}
-
-	return existingEntries, nil
-}
-
 func (i *IMDBImportModel) getAllMovieIMDBIDs() (map[string]string, error) {
 	filter := bson.M{"imdb_id": bson.M{"$exists": true, "$ne": ""}}
 	projection := bson.M{"_id": 1, "imdb_id": 1}
@@ -428,43 +236,3 @@ func (i *IMDBImportModel) getAllTVIMDBIDs() (map[string]string, error) {
 
 	return tvIMDBIDs, nil
 }
-
-func (i *IMDBImportModel) updateExistingMovieEntry(userID, movieID string, rating float32, status string) error {
-	filter := bson.M{
-		"user_id":  userID,
-		"movie_id": movieID,
-	}
-
-	update := bson.M{
-		"$set": bson.M{
-			"updated_at": time.Now().UTC(),
-		},
-	}
-
-	if rating > 0 {
-		update["$set"].(bson.M)["score"] = &rating
-	}
-
-	_, err := i.MovieListCollection.UpdateOne(context.TODO(), filter, update)
-	return err
-}
-
-func (i *IMDBImportModel) updateExistingTVEntry(userID, tvID string, rating float32, status string) error {
-	filter := bson.M{
-		"user_id": userID,
-		"tv_id":   tvID,
-	}
-
-	update := bson.M{
-		"$set": bson.M{
-			"updated_at": time.Now().UTC(),
-		},
-	}
-
-	if rating > 0 {
-		update["$set"].(bson.M)["score"] = &rating
-	}
-
-	_, err := i.TVListCollection.UpdateOne(context.TODO(), filter, update)
-	return err
-}
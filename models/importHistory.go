@@ -0,0 +1,221 @@
+package models
+
+import (
+	"app/db"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+//lint:file-ignore ST1005 Ignore all
+
+// ImportHistoryModel keeps the list entries that are written by the imports,
+// so that an import can be rolled back.
+type ImportHistoryModel struct {
+	ImportChangeCollection *mongo.Collection
+	ImportJobCollection    *mongo.Collection
+	ListWriteModel         *ListWriteModel
+}
+
+func NewImportHistoryModel(mongoDB *db.MongoDB) *ImportHistoryModel {
+	return &ImportHistoryModel{
+		ImportChangeCollection: mongoDB.Database.Collection("import-changes"),
+		ImportJobCollection:    mongoDB.Database.Collection("import-jobs"),
+		ListWriteModel:         NewListWriteModel(mongoDB),
+	}
+}
+
+const (
+	InsertImportChangeAction = "insert"
+	UpdateImportChangeAction = "update"
+)
+
+// ImportChange is a list entry that is inserted or updated by the import,
+// previous is the whole entry before it's updated.
+type ImportChange struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"_id"`
+	UserID      string             `bson:"user_id" json:"user_id"`
+	ImportJobID primitive.ObjectID `bson:"import_job_id" json:"import_job_id"`
+	ContentType string             `bson:"content_type" json:"content_type"`
+	ContentID   string             `bson:"content_id" json:"content_id"`
+	Title       string             `bson:"title" json:"title"`
+	ListID      primitive.ObjectID `bson:"list_id" json:"list_id"`
+	Action      string             `bson:"action" json:"action"`
+	Previous    bson.M             `bson:"previous,omitempty" json:"-"`
+	WrittenAt   time.Time          `bson:"written_at" json:"written_at"`
+}
+
+// ImportRollback is the result of the rollback, entries that are changed after
+// the import are skipped.
+type ImportRollback struct {
+	RestoredCount int `json:"restored_count"`
+	DeletedCount  int `json:"deleted_count"`
+	SkippedCount  int `json:"skipped_count"`
+}
+
+// ! Create
+func (importHistoryModel *ImportHistoryModel) SaveImportChanges(importJobID primitive.ObjectID, changes []ImportChange) {
+	if len(changes) == 0 {
+		return
+	}
+
+	documents := make([]interface{}, 0, len(changes))
+	for _, change := range changes {
+		change.ImportJobID = importJobID
+		documents = append(documents, change)
+	}
+
+	if _, err := importHistoryModel.ImportChangeCollection.InsertMany(context.TODO(), documents); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"import_job_id": importJobID,
+			"count":         len(documents),
+		}).Error("failed to save import changes: ", err)
+	}
+}
+
+// ! Update
+// RollbackImportJob deletes the inserted entries and restores the updated ones
+// of the completed or failed import. Only the entries that are not changed since
+// the import are reverted, isRolledBack is false if it's already rolled back.
+func (importHistoryModel *ImportHistoryModel) RollbackImportJob(importJob ImportJob) (ImportRollback, bool, error) {
+	now := time.Now().UTC()
+
+	claimResult, err := importHistoryModel.ImportJobCollection.UpdateOne(context.TODO(), bson.M{
+		"_id":    importJob.ID,
+		"status": bson.M{"$in": bson.A{CompletedImportJobStatus, FailedImportJobStatus}},
+	}, bson.M{"$set": bson.M{
+		"status":         RolledBackImportJobStatus,
+		"rolled_back_at": now,
+	}})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"import_job_id": importJob.ID,
+		}).Error("failed to claim import job for rollback: ", err)
+
+		return ImportRollback{}, false, fmt.Errorf("Failed to roll back import.")
+	}
+
+	if claimResult.ModifiedCount == 0 {
+		return ImportRollback{}, false, nil
+	}
+
+	rollback, err := importHistoryModel.revertImportChanges(importJob.ID)
+	if err != nil {
+		// Reverting is repeatable, the job can be rolled back again.
+		importHistoryModel.ImportJobCollection.UpdateOne(context.TODO(), bson.M{
+			"_id": importJob.ID,
+		}, bson.M{
+			"$set":   bson.M{"status": importJob.Status},
+			"$unset": bson.M{"rolled_back_at": true},
+		})
+
+		return ImportRollback{}, false, err
+	}
+
+	if _, err := importHistoryModel.ImportJobCollection.UpdateOne(context.TODO(), bson.M{
+		"_id": importJob.ID,
+	}, bson.M{"$set": bson.M{
+		"message": fmt.Sprintf("Import rolled back: %d restored, %d deleted, %d skipped since they are changed after the import",
+			rollback.RestoredCount, rollback.DeletedCount, rollback.SkippedCount),
+	}}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"import_job_id": importJob.ID,
+		}).Error("failed to update rolled back import job: ", err)
+	}
+
+	importHistoryModel.deleteImportChanges(bson.M{"import_job_id": importJob.ID})
+
+	return rollback, true, nil
+}
+
+// revertImportChanges reverts the changes of the job latest first, so that an
+// entry that is written more than once ends up as it was before the import.
+func (importHistoryModel *ImportHistoryModel) revertImportChanges(importJobID primitive.ObjectID) (ImportRollback, error) {
+	cursor, err := importHistoryModel.ImportChangeCollection.Find(context.TODO(), bson.M{
+		"import_job_id": importJobID,
+	}, options.Find().SetSort(bson.D{{Key: "written_at", Value: -1}, {Key: "_id", Value: -1}}))
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"import_job_id": importJobID,
+		}).Error("failed to find import changes: ", err)
+
+		return ImportRollback{}, fmt.Errorf("Failed to roll back import.")
+	}
+
+	var changes []ImportChange
+	if err := cursor.All(context.TODO(), &changes); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"import_job_id": importJobID,
+		}).Error("failed to decode import changes: ", err)
+
+		return ImportRollback{}, fmt.Errorf("Failed to roll back import.")
+	}
+
+	contentTypes := []string{}
+	reverts := map[string][]mongo.WriteModel{}
+	for _, change := range changes {
+		// Entries are only reverted if they are not changed after the import,
+		// every list write sets updated_at.
+		filter := bson.M{
+			"_id":        change.ListID,
+			"updated_at": change.WrittenAt,
+		}
+
+		var revert mongo.WriteModel
+		switch {
+		case change.Action == InsertImportChangeAction:
+			revert = mongo.NewDeleteOneModel().SetFilter(filter)
+		case change.Previous != nil:
+			revert = mongo.NewReplaceOneModel().SetFilter(filter).SetReplacement(change.Previous)
+		default:
+			continue
+		}
+
+		if _, ok := reverts[change.ContentType]; !ok {
+			contentTypes = append(contentTypes, change.ContentType)
+		}
+		reverts[change.ContentType] = append(reverts[change.ContentType], revert)
+	}
+
+	rollback := ImportRollback{SkippedCount: len(changes)}
+	for _, contentType := range contentTypes {
+		result, err := importHistoryModel.ListWriteModel.getListCollection(contentType).BulkWrite(
+			context.TODO(), reverts[contentType], options.BulkWrite().SetOrdered(true),
+		)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"import_job_id": importJobID,
+				"content_type":  contentType,
+				"count":         len(reverts[contentType]),
+			}).Error("failed to revert import changes: ", err)
+
+			return ImportRollback{}, fmt.Errorf("Failed to roll back %s entries.", contentType)
+		}
+
+		rollback.RestoredCount += int(result.MatchedCount)
+		rollback.DeletedCount += int(result.DeletedCount)
+	}
+
+	rollback.SkippedCount -= rollback.RestoredCount + rollback.DeletedCount
+
+	return rollback, nil
+}
+
+// ! Delete
+func (importHistoryModel *ImportHistoryModel) DeleteImportChangesByUserID(uid string) {
+	importHistoryModel.deleteImportChanges(bson.M{"user_id": uid})
+}
+
+func (importHistoryModel *ImportHistoryModel) deleteImportChanges(filter bson.M) {
+	if _, err := importHistoryModel.ImportChangeCollection.DeleteMany(context.TODO(), filter); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"filter": filter,
+		}).Error("failed to delete import changes: ", err)
+	}
+}
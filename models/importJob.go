@@ -0,0 +1,700 @@
+package models
+
+import (
+	"app/db"
+	"app/requests"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	p "github.com/gobeam/mongo-go-pagination"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+//lint:file-ignore ST1005 Ignore all
+
+type ImportJobModel struct {
+	ImportJobCollection         *mongo.Collection
+	ImportPreviewItemCollection *mongo.Collection
+	Database                    *mongo.Database
+}
+
+func NewImportJobModel(mongoDB *db.MongoDB) *ImportJobModel {
+	return &ImportJobModel{
+		ImportJobCollection:         mongoDB.Database.Collection("import-jobs"),
+		ImportPreviewItemCollection: mongoDB.Database.Collection("import-preview-items"),
+		Database:                    mongoDB.Database,
+	}
+}
+
+const (
+	MALImportSource     = "mal"
+	SteamImportSource   = "steam"
+	IMDBImportSource    = "imdb"
+	TMDBImportSource    = "tmdb"
+	AniListImportSource = "anilist"
+	TraktImportSource   = "trakt"
+	// Sources of the uploaded export files.
+	MALFileImportSource    = "mal_file"
+	LetterboxdImportSource = "letterboxd"
+	IMDBFileImportSource   = "imdb_file"
+	TraktFileImportSource  = "trakt_file"
+	BackloggdImportSource  = "backloggd"
+	HLTBImportSource       = "hltb"
+	GOGImportSource        = "gog"
+)
+
+const (
+	PendingImportJobStatus    = "pending"
+	ProcessingImportJobStatus = "processing"
+	CompletedImportJobStatus  = "completed"
+	FailedImportJobStatus     = "failed"
+	// Dry run jobs are previewed, nothing is written until the preview is applied.
+	PreviewedImportJobStatus = "previewed"
+	// Rolled back jobs' entries are restored to their state before the import.
+	RolledBackImportJobStatus = "rolled_back"
+)
+
+const (
+	// Transient failures are retried until the job is attempted this many times.
+	importJobMaxAttempts = 3
+	importJobRetryDelay  = 30 * time.Second
+	// Processing jobs renew their heartbeat, jobs without a recent heartbeat were
+	// interrupted (e.g. restart) and are picked up again.
+	ImportJobHeartbeatInterval = time.Minute
+	importJobStaleTimeout      = 5 * time.Minute
+	// Uploaded files are kept until their job is completed or failed.
+	importFileBucket    = "import-files"
+	importJobPagination = 25
+)
+
+// ImportJob is an import that runs in the background, params are the source
+// specific arguments of the import e.g. username, dry_run and policy.
+type ImportJob struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty" json:"_id"`
+	UserID string             `bson:"user_id" json:"user_id"`
+	Source string             `bson:"source" json:"source"`
+	Params map[string]string  `bson:"params" json:"params"`
+	Status string             `bson:"status" json:"status"`
+	// IsActive is set while the job is pending or processing, a user can have one
+	// active job per source.
+	IsActive      bool `bson:"is_active" json:"-"`
+	Attempts      int  `bson:"attempts" json:"attempts"`
+	ImportedCount int  `bson:"imported_count" json:"imported_count"`
+	UpdatedCount  int  `bson:"updated_count" json:"updated_count"`
+	SkippedCount  int  `bson:"skipped_count" json:"skipped_count"`
+	// Limited entries are not imported since the list limit of the free membership is reached.
+	LimitedCount   int            `bson:"limited_count" json:"limited_count"`
+	ErrorCount     int            `bson:"error_count" json:"error_count"`
+	Message        string         `bson:"message" json:"message"`
+	ImportedTitles []string       `bson:"imported_titles" json:"imported_titles"`
+	UpdatedTitles  []string       `bson:"updated_titles" json:"updated_titles"`
+	SkippedTitles  []string       `bson:"skipped_titles" json:"skipped_titles"`
+	LimitedTitles  []string       `bson:"limited_titles" json:"limited_titles"`
+	Preview        *ImportPreview `bson:"preview,omitempty" json:"preview,omitempty"`
+	// Choices are the keep_mine or take_theirs choices of the preview items by key.
+	Choices      map[string]string `bson:"choices,omitempty" json:"choices,omitempty"`
+	Error        *string           `bson:"error" json:"error"`
+	RunAt        time.Time         `bson:"run_at" json:"-"`
+	HeartbeatAt  *time.Time        `bson:"heartbeat_at" json:"-"`
+	CreatedAt    time.Time         `bson:"created_at" json:"created_at"`
+	StartedAt    *time.Time        `bson:"started_at" json:"started_at"`
+	CompletedAt  *time.Time        `bson:"completed_at" json:"completed_at"`
+	RolledBackAt *time.Time        `bson:"rolled_back_at,omitempty" json:"rolled_back_at,omitempty"`
+}
+
+// ImportJobResult is either the result of the written import, or the preview of
+// a dry run.
+type ImportJobResult struct {
+	ImportedCount  int
+	UpdatedCount   int
+	SkippedCount   int
+	LimitedCount   int
+	ErrorCount     int
+	Message        string
+	ImportedTitles []string
+	UpdatedTitles  []string
+	SkippedTitles  []string
+	LimitedTitles  []string
+	Preview        *ImportPreview
+	// UnmatchedItems are kept for the user to link them, they are not stored on the job.
+	UnmatchedItems []ImportItem
+	// Changes are kept as the history of the import to roll it back.
+	Changes []ImportChange
+}
+
+// importTransientError marks failures that may succeed when retried, e.g.
+// timeouts, rate limits and server errors of the source.
+type importTransientError struct {
+	err error
+}
+
+func (transientError *importTransientError) Error() string {
+	return transientError.err.Error()
+}
+
+func (transientError *importTransientError) Unwrap() error {
+	return transientError.err
+}
+
+func importRequestError(message string, err error) error {
+	return &importTransientError{err: fmt.Errorf("%s: %v", message, err)}
+}
+
+func importStatusError(message string, statusCode int) error {
+	err := fmt.Errorf("%s: %d", message, statusCode)
+	if statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError {
+		return &importTransientError{err: err}
+	}
+
+	return err
+}
+
+func IsTransientImportError(err error) bool {
+	var transientError *importTransientError
+	return errors.As(err, &transientError)
+}
+
+// importPreviewItem is an item of the previewed job, items are kept apart from
+// the job since the preview of a large library exceeds the document size limit.
+type importPreviewItem struct {
+	ImportJobID primitive.ObjectID `bson:"import_job_id"`
+	UserID      string             `bson:"user_id"`
+	Index       int                `bson:"index"`
+	Item        ImportItem         `bson:"item"`
+}
+
+// CreateImportJobIndexes creates the unique index that allows a single active
+// job per user and source, and the index of the preview items.
+func (importJobModel *ImportJobModel) CreateImportJobIndexes() {
+	if _, err := importJobModel.ImportJobCollection.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "source", Value: 1}},
+		Options: options.Index().
+			SetName("active_import_job").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"is_active": true}),
+	}); err != nil {
+		logrus.Error("failed to create active import job index: ", err)
+	}
+
+	if _, err := importJobModel.ImportPreviewItemCollection.Indexes().CreateOne(context.TODO(), mongo.IndexModel{
+		Keys: bson.D{{Key: "import_job_id", Value: 1}, {Key: "index", Value: 1}},
+	}); err != nil {
+		logrus.Error("failed to create import preview item index: ", err)
+	}
+}
+
+// ! Create
+// CreateImportJob queues the import unless the user already has one for the
+// same source in progress, isCreated is false with the existing job otherwise.
+func (importJobModel *ImportJobModel) CreateImportJob(uid, source string, params map[string]string) (ImportJob, bool, error) {
+	now := time.Now().UTC()
+	importJob := ImportJob{
+		ID:             primitive.NewObjectID(),
+		UserID:         uid,
+		Source:         source,
+		Params:         params,
+		Status:         PendingImportJobStatus,
+		IsActive:       true,
+		ImportedTitles: []string{},
+		UpdatedTitles:  []string{},
+		SkippedTitles:  []string{},
+		LimitedTitles:  []string{},
+		RunAt:          now,
+		CreatedAt:      now,
+	}
+
+	// Concurrent requests are rejected by the unique index of the active jobs.
+	result, err := importJobModel.ImportJobCollection.UpdateOne(context.TODO(), bson.M{
+		"user_id":   uid,
+		"source":    source,
+		"is_active": true,
+	}, bson.M{"$setOnInsert": importJob}, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		activeImportJob, err := importJobModel.GetActiveImportJob(uid, source)
+		return activeImportJob, false, err
+	}
+
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid":    uid,
+			"source": source,
+		}).Error("failed to create import job: ", err)
+
+		return ImportJob{}, false, fmt.Errorf("Failed to start import.")
+	}
+
+	if result.UpsertedID == nil {
+		activeImportJob, err := importJobModel.GetActiveImportJob(uid, source)
+		return activeImportJob, false, err
+	}
+
+	return importJob, true, nil
+}
+
+func (importJobModel *ImportJobModel) getBucket() (*gridfs.Bucket, error) {
+	return gridfs.NewBucket(importJobModel.Database, options.GridFSBucket().SetName(importFileBucket))
+}
+
+// SaveImportFile stores the uploaded file until its import job is done.
+func (importJobModel *ImportJobModel) SaveImportFile(uid, fileName string, file io.Reader) (primitive.ObjectID, error) {
+	bucket, err := importJobModel.getBucket()
+	if err == nil {
+		var fileID primitive.ObjectID
+		if fileID, err = bucket.UploadFromStream(fileName, file, options.GridFSUpload().SetMetadata(bson.M{
+			"user_id": uid,
+		})); err == nil {
+			return fileID, nil
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"uid":       uid,
+		"file_name": fileName,
+	}).Error("failed to save import file: ", err)
+
+	return primitive.NilObjectID, fmt.Errorf("Failed to upload file.")
+}
+
+func (importJobModel *ImportJobModel) ReadImportFile(fileID string) ([]byte, error) {
+	objectID, _ := primitive.ObjectIDFromHex(fileID)
+
+	bucket, err := importJobModel.getBucket()
+	if err == nil {
+		var buffer bytes.Buffer
+		if _, err = bucket.DownloadToStream(objectID, &buffer); err == nil {
+			return buffer.Bytes(), nil
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"file_id": fileID,
+	}).Error("failed to read import file: ", err)
+
+	if err == gridfs.ErrFileNotFound {
+		return nil, fmt.Errorf("Uploaded file is not found, please upload it again.")
+	}
+
+	return nil, &importTransientError{err: fmt.Errorf("Failed to read uploaded file.")}
+}
+
+func (importJobModel *ImportJobModel) DeleteImportFile(fileID string) {
+	objectID, err := primitive.ObjectIDFromHex(fileID)
+	if err != nil {
+		return
+	}
+
+	bucket, err := importJobModel.getBucket()
+	if err == nil {
+		err = bucket.Delete(objectID)
+	}
+
+	if err != nil && err != gridfs.ErrFileNotFound {
+		logrus.WithFields(logrus.Fields{
+			"file_id": fileID,
+		}).Error("failed to delete import file: ", err)
+	}
+}
+
+// ! Update
+// ClaimNextImportJob marks the next due job as processing so that no other
+// worker runs it, stale processing jobs are claimed again.
+func (importJobModel *ImportJobModel) ClaimNextImportJob() (ImportJob, error) {
+	now := time.Now().UTC()
+
+	result := importJobModel.ImportJobCollection.FindOneAndUpdate(context.TODO(), bson.M{
+		"$or": bson.A{
+			bson.M{"status": PendingImportJobStatus, "run_at": bson.M{"$lte": now}},
+			bson.M{"status": ProcessingImportJobStatus, "heartbeat_at": bson.M{"$lt": now.Add(-importJobStaleTimeout)}},
+		},
+	}, bson.M{
+		"$set": bson.M{
+			"status":       ProcessingImportJobStatus,
+			"heartbeat_at": now,
+			"started_at":   now,
+		},
+		"$inc": bson.M{"attempts": 1},
+	}, options.FindOneAndUpdate().SetSort(bson.M{"run_at": 1}).SetReturnDocument(options.After))
+
+	var importJob ImportJob
+	if err := result.Decode(&importJob); err != nil && err != mongo.ErrNoDocuments {
+		logrus.Error("failed to claim import job: ", err)
+
+		return ImportJob{}, fmt.Errorf("Failed to claim import job.")
+	}
+
+	// Jobs that keep getting interrupted are given up on instead of looping.
+	if importJob.Attempts > importJobMaxAttempts {
+		importJobModel.FailImportJob(importJob, fmt.Errorf("Import was interrupted, please try again."))
+		importJob.Status = FailedImportJobStatus
+	}
+
+	return importJob, nil
+}
+
+func (importJobModel *ImportJobModel) UpdateImportJobHeartbeat(importJobID primitive.ObjectID) {
+	importJobModel.updateImportJob(importJobID, bson.M{
+		"heartbeat_at": time.Now().UTC(),
+	})
+}
+
+func (importJobModel *ImportJobModel) CompleteImportJob(importJob ImportJob, result ImportJobResult) {
+	if result.Preview != nil {
+		if err := importJobModel.saveImportPreviewItems(importJob, result.Preview.Items); err != nil {
+			importJobModel.FailImportJob(importJob, err)
+			return
+		}
+	}
+
+	importJobModel.DeleteImportFile(importJob.Params["file_id"])
+
+	if result.Preview != nil {
+		importJobModel.updateImportJob(importJob.ID, bson.M{
+			"status":    PreviewedImportJobStatus,
+			"is_active": false,
+			"message": fmt.Sprintf("Preview is ready: %d new, %d unchanged, %d conflicts, %d unmatched",
+				result.Preview.NewCount, result.Preview.UnchangedCount, result.Preview.ConflictCount, result.Preview.UnmatchedCount),
+			"preview":      result.Preview,
+			"error":        nil,
+			"completed_at": time.Now().UTC(),
+		})
+
+		return
+	}
+
+	importedTitles, updatedTitles, skippedTitles, limitedTitles := result.ImportedTitles, result.UpdatedTitles, result.SkippedTitles, result.LimitedTitles
+	if importedTitles == nil {
+		importedTitles = []string{}
+	}
+	if updatedTitles == nil {
+		updatedTitles = []string{}
+	}
+	if skippedTitles == nil {
+		skippedTitles = []string{}
+	}
+	if limitedTitles == nil {
+		limitedTitles = []string{}
+	}
+
+	importJobModel.updateImportJob(importJob.ID, bson.M{
+		"status":          CompletedImportJobStatus,
+		"is_active":       false,
+		"imported_count":  result.ImportedCount,
+		"updated_count":   result.UpdatedCount,
+		"skipped_count":   result.SkippedCount,
+		"limited_count":   result.LimitedCount,
+		"error_count":     result.ErrorCount,
+		"message":         result.Message,
+		"imported_titles": importedTitles,
+		"updated_titles":  updatedTitles,
+		"skipped_titles":  skippedTitles,
+		"limited_titles":  limitedTitles,
+		"error":           nil,
+		"completed_at":    time.Now().UTC(),
+	})
+}
+
+// ApplyImportJob queues the previewed job again to write its items, isApplied is
+// false if the preview was already applied or another import of the source is
+// in progress.
+func (importJobModel *ImportJobModel) ApplyImportJob(importJob ImportJob, policy string, choices map[string]string) (ImportJob, bool, error) {
+	now := time.Now().UTC()
+
+	result, err := importJobModel.ImportJobCollection.UpdateOne(context.TODO(), bson.M{
+		"_id":    importJob.ID,
+		"status": PreviewedImportJobStatus,
+	}, bson.M{"$set": bson.M{
+		"status":        PendingImportJobStatus,
+		"is_active":     true,
+		"params.policy": policy,
+		"choices":       choices,
+		"attempts":      0,
+		"error":         nil,
+		"run_at":        now,
+		"completed_at":  nil,
+	}})
+	if mongo.IsDuplicateKeyError(err) {
+		return importJob, false, nil
+	}
+
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"import_job_id": importJob.ID,
+		}).Error("failed to apply import job: ", err)
+
+		return ImportJob{}, false, fmt.Errorf("Failed to apply import.")
+	}
+
+	if result.ModifiedCount == 0 {
+		return importJob, false, nil
+	}
+
+	if importJob.Params == nil {
+		importJob.Params = map[string]string{}
+	}
+
+	importJob.Status = PendingImportJobStatus
+	importJob.IsActive = true
+	importJob.Params["policy"] = policy
+	importJob.Choices = choices
+	importJob.Attempts = 0
+	importJob.Error = nil
+	importJob.CompletedAt = nil
+
+	return importJob, true, nil
+}
+
+// FailImportJob schedules the job again with an increasing delay if the error
+// is transient and it has attempts left, otherwise the job fails.
+func (importJobModel *ImportJobModel) FailImportJob(importJob ImportJob, importErr error) {
+	errMessage := importErr.Error()
+
+	if IsTransientImportError(importErr) && importJob.Attempts < importJobMaxAttempts {
+		importJobModel.updateImportJob(importJob.ID, bson.M{
+			"status": PendingImportJobStatus,
+			"error":  errMessage,
+			"run_at": time.Now().UTC().Add(importJobRetryDelay * time.Duration(importJob.Attempts*importJob.Attempts)),
+		})
+
+		return
+	}
+
+	importJobModel.DeleteImportFile(importJob.Params["file_id"])
+
+	importJobModel.updateImportJob(importJob.ID, bson.M{
+		"status":       FailedImportJobStatus,
+		"is_active":    false,
+		"error":        errMessage,
+		"completed_at": time.Now().UTC(),
+	})
+}
+
+// saveImportPreviewItems replaces the preview items of the job, items of an
+// interrupted attempt are removed first.
+func (importJobModel *ImportJobModel) saveImportPreviewItems(importJob ImportJob, items []ImportItem) error {
+	if _, err := importJobModel.ImportPreviewItemCollection.DeleteMany(context.TODO(), bson.M{
+		"import_job_id": importJob.ID,
+	}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"import_job_id": importJob.ID,
+		}).Error("failed to delete import preview items: ", err)
+
+		return &importTransientError{err: fmt.Errorf("Failed to save import preview.")}
+	}
+
+	if len(items) == 0 {
+		return nil
+	}
+
+	documents := make([]interface{}, 0, len(items))
+	for index, item := range items {
+		documents = append(documents, importPreviewItem{
+			ImportJobID: importJob.ID,
+			UserID:      importJob.UserID,
+			Index:       index,
+			Item:        item,
+		})
+	}
+
+	if _, err := importJobModel.ImportPreviewItemCollection.InsertMany(context.TODO(), documents); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"import_job_id": importJob.ID,
+			"count":         len(documents),
+		}).Error("failed to save import preview items: ", err)
+
+		return &importTransientError{err: fmt.Errorf("Failed to save import preview.")}
+	}
+
+	return nil
+}
+
+func (importJobModel *ImportJobModel) updateImportJob(importJobID primitive.ObjectID, set bson.M) {
+	if _, err := importJobModel.ImportJobCollection.UpdateOne(context.TODO(), bson.M{
+		"_id": importJobID,
+	}, bson.M{"$set": set}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"import_job_id": importJobID,
+			"set":           set,
+		}).Error("failed to update import job: ", err)
+	}
+}
+
+// ! Get
+func (importJobModel *ImportJobModel) GetImportJobByID(importJobID string) (ImportJob, error) {
+	objectID, _ := primitive.ObjectIDFromHex(importJobID)
+
+	result := importJobModel.ImportJobCollection.FindOne(context.TODO(), bson.M{
+		"_id": objectID,
+	})
+
+	var importJob ImportJob
+	if err := result.Decode(&importJob); err != nil && err != mongo.ErrNoDocuments {
+		logrus.WithFields(logrus.Fields{
+			"import_job_id": importJobID,
+		}).Error("failed to find import job by id: ", err)
+
+		return ImportJob{}, fmt.Errorf("Failed to find import job.")
+	}
+
+	return importJob, nil
+}
+
+// GetImportJobs returns the import history of the user, latest first. Titles
+// and previews are returned with the job itself.
+func (importJobModel *ImportJobModel) GetImportJobs(uid string, data requests.GetImportHistory) ([]ImportJob, p.PaginationData, error) {
+	match := bson.M{"user_id": uid}
+
+	if data.Source != nil {
+		match["source"] = *data.Source
+	}
+
+	paginatedData, err := p.New(importJobModel.ImportJobCollection).Context(context.TODO()).
+		Limit(importJobPagination).Page(data.Page).Sort("created_at", -1).Aggregate(
+		bson.M{"$match": match},
+		bson.M{"$project": bson.M{
+			"imported_titles": 0,
+			"updated_titles":  0,
+			"skipped_titles":  0,
+			"limited_titles":  0,
+			"preview":         0,
+			"choices":         0,
+		}},
+	)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid":     uid,
+			"request": data,
+		}).Error("failed to aggregate import jobs: ", err)
+
+		return nil, p.PaginationData{}, fmt.Errorf("Failed to get import history.")
+	}
+
+	importJobs := []ImportJob{}
+	for _, raw := range paginatedData.Data {
+		var importJob *ImportJob
+		if marshalErr := bson.Unmarshal(raw, &importJob); marshalErr == nil {
+			importJobs = append(importJobs, *importJob)
+		}
+	}
+
+	return importJobs, paginatedData.Pagination, nil
+}
+
+// GetImportPreviewItems sets the items of the previewed job in their order.
+func (importJobModel *ImportJobModel) GetImportPreviewItems(importJob *ImportJob) error {
+	if importJob.Preview == nil {
+		return nil
+	}
+
+	cursor, err := importJobModel.ImportPreviewItemCollection.Find(context.TODO(), bson.M{
+		"import_job_id": importJob.ID,
+	}, options.Find().SetSort(bson.M{"index": 1}))
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"import_job_id": importJob.ID,
+		}).Error("failed to find import preview items: ", err)
+
+		return &importTransientError{err: fmt.Errorf("Failed to get import preview.")}
+	}
+
+	var previewItems []importPreviewItem
+	if err := cursor.All(context.TODO(), &previewItems); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"import_job_id": importJob.ID,
+		}).Error("failed to decode import preview items: ", err)
+
+		return &importTransientError{err: fmt.Errorf("Failed to get import preview.")}
+	}
+
+	// Jobs that are previewed before the items were stored apart keep them on the job.
+	if len(previewItems) == 0 && importJob.Preview.NewCount+importJob.Preview.UnchangedCount+
+		importJob.Preview.ConflictCount+importJob.Preview.UnmatchedCount > 0 {
+		var legacyImportJob struct {
+			Preview struct {
+				Items []ImportItem `bson:"items"`
+			} `bson:"preview"`
+		}
+		if err := importJobModel.ImportJobCollection.FindOne(context.TODO(), bson.M{
+			"_id": importJob.ID,
+		}, options.FindOne().SetProjection(bson.M{"preview.items": 1})).Decode(&legacyImportJob); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"import_job_id": importJob.ID,
+			}).Error("failed to find import preview items of the job: ", err)
+
+			return &importTransientError{err: fmt.Errorf("Failed to get import preview.")}
+		}
+
+		importJob.Preview.Items = legacyImportJob.Preview.Items
+		return nil
+	}
+
+	importJob.Preview.Items = make([]ImportItem, 0, len(previewItems))
+	for _, previewItem := range previewItems {
+		importJob.Preview.Items = append(importJob.Preview.Items, previewItem.Item)
+	}
+
+	return nil
+}
+
+func (importJobModel *ImportJobModel) GetActiveImportJob(uid, source string) (ImportJob, error) {
+	result := importJobModel.ImportJobCollection.FindOne(context.TODO(), bson.M{
+		"user_id": uid,
+		"source":  source,
+		"status":  bson.M{"$in": bson.A{PendingImportJobStatus, ProcessingImportJobStatus}},
+	})
+
+	var importJob ImportJob
+	if err := result.Decode(&importJob); err != nil && err != mongo.ErrNoDocuments {
+		logrus.WithFields(logrus.Fields{
+			"uid":    uid,
+			"source": source,
+		}).Error("failed to find active import job: ", err)
+
+		return ImportJob{}, fmt.Errorf("Failed to find import job.")
+	}
+
+	return importJob, nil
+}
+
+// ! Delete
+// DeleteImportJobsByUserID deletes the import jobs of the user with their files.
+func (importJobModel *ImportJobModel) DeleteImportJobsByUserID(uid string) {
+	if bucket, err := importJobModel.getBucket(); err == nil {
+		cursor, err := bucket.Find(bson.M{"metadata.user_id": uid})
+		if err == nil {
+			var files []struct {
+				ID primitive.ObjectID `bson:"_id"`
+			}
+			if err := cursor.All(context.TODO(), &files); err == nil {
+				for _, file := range files {
+					importJobModel.DeleteImportFile(file.ID.Hex())
+				}
+			}
+		}
+	}
+
+	if _, err := importJobModel.ImportJobCollection.DeleteMany(context.TODO(), bson.M{
+		"user_id": uid,
+	}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to delete import jobs by user id: ", err)
+	}
+
+	if _, err := importJobModel.ImportPreviewItemCollection.DeleteMany(context.TODO(), bson.M{
+		"user_id": uid,
+	}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to delete import preview items by user id: ", err)
+	}
+}
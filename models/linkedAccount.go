@@ -0,0 +1,270 @@
+package models
+
+import (
+	"app/db"
+	"app/requests"
+	"context"
+	"fmt"
+	"time"
+
+	p "github.com/gobeam/mongo-go-pagination"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+//lint:file-ignore ST1005 Ignore all
+
+// LinkedAccountModel manages the external accounts on the user's profile, they
+// are synced periodically with import jobs. Accounts are stored on the user
+// document but not in User, so that UpdateUser doesn't overwrite the syncs.
+type LinkedAccountModel struct {
+	UserCollection      *mongo.Collection
+	ImportJobCollection *mongo.Collection
+}
+
+func NewLinkedAccountModel(mongoDB *db.MongoDB) *LinkedAccountModel {
+	return &LinkedAccountModel{
+		UserCollection:      mongoDB.Database.Collection("users"),
+		ImportJobCollection: mongoDB.Database.Collection("import-jobs"),
+	}
+}
+
+const (
+	LinkedAccountSyncInterval = 12 * time.Hour
+	linkedAccountPagination   = 25
+	// Marks the import jobs of the syncs, they are the sync history of the link.
+	LinkedAccountImportParam = "linked_account"
+)
+
+// LinkedAccountImportParams are the import params that keep the account id of
+// the source.
+var LinkedAccountImportParams = map[string]string{
+	SteamImportSource:   "steam_id",
+	TraktImportSource:   "trakt_username",
+	AniListImportSource: "anilist_username",
+}
+
+type LinkedAccount struct {
+	Source         string     `bson:"source" json:"source"`
+	AccountID      string     `bson:"account_id" json:"account_id"`
+	LastSyncStatus *string    `bson:"last_sync_status" json:"last_sync_status"`
+	LastSyncedAt   *time.Time `bson:"last_synced_at" json:"last_synced_at"`
+	NextSyncAt     time.Time  `bson:"next_sync_at" json:"next_sync_at"`
+	LinkedAt       time.Time  `bson:"linked_at" json:"linked_at"`
+}
+
+// ImportParams are the params of the sync, conflicts take the latest change so
+// that e.g. hours played and progress follow the source without overwriting the
+// entries edited after the source.
+func (linkedAccount LinkedAccount) ImportParams() map[string]string {
+	return map[string]string{
+		LinkedAccountImportParams[linkedAccount.Source]: linkedAccount.AccountID,
+		LinkedAccountImportParam:                        "true",
+		"dry_run":                                       "false",
+		"policy":                                        LatestImportPolicy,
+	}
+}
+
+// ! Create
+// LinkAccount links the account of the source, the previous account of the
+// same source is replaced. Linked accounts are synced right away.
+func (linkedAccountModel *LinkedAccountModel) LinkAccount(uid, source, accountID string) (LinkedAccount, error) {
+	objectUID, _ := primitive.ObjectIDFromHex(uid)
+
+	now := time.Now().UTC()
+	linkedAccount := LinkedAccount{
+		Source:     source,
+		AccountID:  accountID,
+		NextSyncAt: now,
+		LinkedAt:   now,
+	}
+
+	if _, err := linkedAccountModel.UserCollection.UpdateOne(context.TODO(), bson.M{
+		"_id": objectUID,
+	}, bson.A{
+		bson.M{"$set": bson.M{
+			"linked_accounts": bson.M{"$concatArrays": bson.A{
+				bson.M{"$filter": bson.M{
+					"input": bson.M{"$ifNull": bson.A{"$linked_accounts", bson.A{}}},
+					"cond":  bson.M{"$ne": bson.A{"$$this.source", source}},
+				}},
+				bson.A{linkedAccount},
+			}},
+		}},
+	}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid":        uid,
+			"source":     source,
+			"account_id": accountID,
+		}).Error("failed to link account: ", err)
+
+		return LinkedAccount{}, fmt.Errorf("Failed to link account.")
+	}
+
+	return linkedAccount, nil
+}
+
+// ! Update
+// ClaimNextLinkedAccount reschedules the next due account and returns it with
+// its user, the user id is empty if no account is due.
+func (linkedAccountModel *LinkedAccountModel) ClaimNextLinkedAccount() (string, LinkedAccount, error) {
+	now := time.Now().UTC()
+
+	result := linkedAccountModel.UserCollection.FindOneAndUpdate(context.TODO(), bson.M{
+		"linked_accounts": bson.M{"$elemMatch": bson.M{"next_sync_at": bson.M{"$lte": now}}},
+	}, bson.M{"$set": bson.M{
+		"linked_accounts.$.next_sync_at": now.Add(LinkedAccountSyncInterval),
+	}}, options.FindOneAndUpdate().SetProjection(bson.M{
+		"linked_accounts": 1,
+	}))
+
+	var user struct {
+		ID             primitive.ObjectID `bson:"_id"`
+		LinkedAccounts []LinkedAccount    `bson:"linked_accounts"`
+	}
+	if err := result.Decode(&user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return "", LinkedAccount{}, nil
+		}
+
+		logrus.Error("failed to claim linked account: ", err)
+
+		return "", LinkedAccount{}, fmt.Errorf("Failed to claim linked account.")
+	}
+
+	// Positional update changes the first due account, which is the one returned.
+	for _, linkedAccount := range user.LinkedAccounts {
+		if !linkedAccount.NextSyncAt.After(now) {
+			return user.ID.Hex(), linkedAccount, nil
+		}
+	}
+
+	return "", LinkedAccount{}, nil
+}
+
+// UpdateLinkedAccountSync records the result of the sync if the account is
+// still linked.
+func (linkedAccountModel *LinkedAccountModel) UpdateLinkedAccountSync(uid, source, accountID, status string) {
+	objectUID, _ := primitive.ObjectIDFromHex(uid)
+
+	if _, err := linkedAccountModel.UserCollection.UpdateOne(context.TODO(), bson.M{
+		"_id": objectUID,
+		"linked_accounts": bson.M{"$elemMatch": bson.M{
+			"source":     source,
+			"account_id": accountID,
+		}},
+	}, bson.M{"$set": bson.M{
+		"linked_accounts.$.last_sync_status": status,
+		"linked_accounts.$.last_synced_at":   time.Now().UTC(),
+	}}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid":    uid,
+			"source": source,
+			"status": status,
+		}).Error("failed to update linked account sync: ", err)
+	}
+}
+
+// ! Get
+func (linkedAccountModel *LinkedAccountModel) GetLinkedAccounts(uid string) ([]LinkedAccount, error) {
+	objectUID, _ := primitive.ObjectIDFromHex(uid)
+
+	result := linkedAccountModel.UserCollection.FindOne(context.TODO(), bson.M{
+		"_id": objectUID,
+	}, options.FindOne().SetProjection(bson.M{
+		"linked_accounts": 1,
+	}))
+
+	var user struct {
+		LinkedAccounts []LinkedAccount `bson:"linked_accounts"`
+	}
+	if err := result.Decode(&user); err != nil && err != mongo.ErrNoDocuments {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to get linked accounts: ", err)
+
+		return nil, fmt.Errorf("Failed to get linked accounts.")
+	}
+
+	if user.LinkedAccounts == nil {
+		return []LinkedAccount{}, nil
+	}
+
+	return user.LinkedAccounts, nil
+}
+
+func (linkedAccountModel *LinkedAccountModel) GetLinkedAccount(uid, source string) (LinkedAccount, error) {
+	linkedAccounts, err := linkedAccountModel.GetLinkedAccounts(uid)
+	if err != nil {
+		return LinkedAccount{}, err
+	}
+
+	for _, linkedAccount := range linkedAccounts {
+		if linkedAccount.Source == source {
+			return linkedAccount, nil
+		}
+	}
+
+	return LinkedAccount{}, nil
+}
+
+// GetLinkedAccountSyncs returns the sync jobs of the linked account, the latest
+// first.
+func (linkedAccountModel *LinkedAccountModel) GetLinkedAccountSyncs(uid string, linkedAccount LinkedAccount, data requests.GetLinkedAccountSyncs) ([]ImportJob, p.PaginationData, error) {
+	paginatedData, err := p.New(linkedAccountModel.ImportJobCollection).Context(context.TODO()).
+		Limit(linkedAccountPagination).Page(data.Page).Sort("created_at", -1).Aggregate(
+		bson.M{"$match": bson.M{
+			"user_id": uid,
+			"source":  linkedAccount.Source,
+			"params." + LinkedAccountImportParams[linkedAccount.Source]: linkedAccount.AccountID,
+			"params." + LinkedAccountImportParam:                        "true",
+		}},
+		bson.M{"$project": bson.M{
+			"imported_titles": 0,
+			"updated_titles":  0,
+			"skipped_titles":  0,
+			"limited_titles":  0,
+		}},
+	)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid":     uid,
+			"request": data,
+		}).Error("failed to aggregate linked account syncs: ", err)
+
+		return nil, p.PaginationData{}, fmt.Errorf("Failed to get sync history.")
+	}
+
+	importJobs := []ImportJob{}
+	for _, raw := range paginatedData.Data {
+		var importJob *ImportJob
+		if marshalErr := bson.Unmarshal(raw, &importJob); marshalErr == nil {
+			importJobs = append(importJobs, *importJob)
+		}
+	}
+
+	return importJobs, paginatedData.Pagination, nil
+}
+
+// ! Delete
+func (linkedAccountModel *LinkedAccountModel) UnlinkAccount(uid, source string) error {
+	objectUID, _ := primitive.ObjectIDFromHex(uid)
+
+	if _, err := linkedAccountModel.UserCollection.UpdateOne(context.TODO(), bson.M{
+		"_id": objectUID,
+	}, bson.M{"$pull": bson.M{
+		"linked_accounts": bson.M{"source": source},
+	}}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid":    uid,
+			"source": source,
+		}).Error("failed to unlink account: ", err)
+
+		return fmt.Errorf("Failed to unlink account.")
+	}
+
+	return nil
+}
@@ -0,0 +1,292 @@
+package models
+
+import (
+	"app/db"
+	"app/requests"
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	p "github.com/gobeam/mongo-go-pagination"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+//lint:file-ignore ST1005 Ignore all
+
+type ListChangeModel struct {
+	ListChangeCollection *mongo.Collection
+}
+
+func NewListChangeModel(mongoDB *db.MongoDB) *ListChangeModel {
+	return &ListChangeModel{
+		ListChangeCollection: mongoDB.Database.Collection("list-changes"),
+	}
+}
+
+const listChangePagination = 25
+
+const (
+	CreateListChangeAction = "create"
+	UpdateListChangeAction = "update"
+	DeleteListChangeAction = "delete"
+)
+
+// Fields that are not user data, they are neither journaled nor restored.
+var untrackedListFields = map[string]bool{
+	"_id":        true,
+	"user_id":    true,
+	"created_at": true,
+	"updated_at": true,
+}
+
+type ListFieldChange struct {
+	Field  string      `bson:"field" json:"field"`
+	Before interface{} `bson:"before" json:"before"`
+	After  interface{} `bson:"after" json:"after"`
+}
+
+// ListRecords are the records that are deleted with a list entry, they are kept
+// in the delete change to be restored with the entry.
+type ListRecords struct {
+	EpisodeWatches      []EpisodeWatch       `bson:"episode_watches"`
+	ConsumptionSessions []ConsumptionSession `bson:"consumption_sessions"`
+}
+
+// ListChange is a single mutation of a list entry. Deleted entries keep the
+// whole document in snapshot so that they can be restored as they were, the
+// entries moved to consume later are removed from it when they are restored.
+type ListChange struct {
+	ID                    primitive.ObjectID `bson:"_id,omitempty" json:"_id"`
+	UserID                string             `bson:"user_id" json:"user_id"`
+	ListID                string             `bson:"list_id" json:"list_id"`
+	ContentID             string             `bson:"content_id" json:"content_id"`
+	ContentType           string             `bson:"content_type" json:"content_type"`
+	Action                string             `bson:"action" json:"action"`
+	Changes               []ListFieldChange  `bson:"changes" json:"changes"`
+	Snapshot              bson.M             `bson:"snapshot,omitempty" json:"-"`
+	Records               *ListRecords       `bson:"records,omitempty" json:"-"`
+	IsMovedToConsumeLater bool               `bson:"is_moved_to_consume_later" json:"is_moved_to_consume_later"`
+	IsUndone              bool               `bson:"is_undone" json:"is_undone"`
+	UndoneAt              *time.Time         `bson:"undone_at" json:"undone_at"`
+	CreatedAt             time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// listDocument converts a list entry to its stored form so that entries of every
+// content type can be compared field by field.
+func listDocument(list interface{}) bson.M {
+	if list == nil {
+		return nil
+	}
+
+	data, err := bson.Marshal(list)
+	if err != nil {
+		return nil
+	}
+
+	var document bson.M
+	if err := bson.Unmarshal(data, &document); err != nil {
+		return nil
+	}
+
+	return document
+}
+
+// getListFieldChanges returns the changed fields, a missing document is treated
+// as a document with empty fields.
+func getListFieldChanges(before, after bson.M) []ListFieldChange {
+	fields := make(map[string]bool)
+	for field := range before {
+		fields[field] = true
+	}
+	for field := range after {
+		fields[field] = true
+	}
+
+	changes := []ListFieldChange{}
+	for field := range fields {
+		if untrackedListFields[field] || reflect.DeepEqual(before[field], after[field]) {
+			continue
+		}
+
+		changes = append(changes, ListFieldChange{
+			Field:  field,
+			Before: before[field],
+			After:  after[field],
+		})
+	}
+
+	return changes
+}
+
+// ! Create
+// CreateListChange journals the difference between before and after, before is nil
+// for created entries and after is nil for deleted ones.
+func (listChangeModel *ListChangeModel) CreateListChange(uid, contentType, action string, before, after interface{}) error {
+	return listChangeModel.createListChange(uid, contentType, action, before, after, nil, false)
+}
+
+// CreateDeleteListChange journals a deleted entry with the records deleted with it,
+// so that undoing the delete restores them too.
+func (listChangeModel *ListChangeModel) CreateDeleteListChange(uid, contentType string, list interface{}, records ListRecords, isMovedToConsumeLater bool) error {
+	return listChangeModel.createListChange(uid, contentType, DeleteListChangeAction, list, nil, &records, isMovedToConsumeLater)
+}
+
+func (listChangeModel *ListChangeModel) createListChange(
+	uid, contentType, action string, before, after interface{}, records *ListRecords, isMovedToConsumeLater bool,
+) error {
+	beforeDocument := listDocument(before)
+	afterDocument := listDocument(after)
+
+	document := afterDocument
+	if document == nil {
+		document = beforeDocument
+	}
+
+	listID, ok := document["_id"].(primitive.ObjectID)
+	if !ok {
+		return fmt.Errorf("Failed to record list change.")
+	}
+
+	changes := getListFieldChanges(beforeDocument, afterDocument)
+	if action == UpdateListChangeAction && len(changes) == 0 {
+		return nil
+	}
+
+	contentID, _ := document[listContentIDFields[contentType]].(string)
+
+	listChange := &ListChange{
+		UserID:                uid,
+		ListID:                listID.Hex(),
+		ContentID:             contentID,
+		ContentType:           contentType,
+		Action:                action,
+		Changes:               changes,
+		Records:               records,
+		IsMovedToConsumeLater: isMovedToConsumeLater,
+		CreatedAt:             time.Now().UTC(),
+	}
+
+	if action == DeleteListChangeAction {
+		listChange.Snapshot = beforeDocument
+	}
+
+	if _, err := listChangeModel.ListChangeCollection.InsertOne(context.TODO(), listChange); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid":     uid,
+			"list_id": listID.Hex(),
+			"action":  action,
+		}).Error("failed to create list change: ", err)
+
+		return fmt.Errorf("Failed to record list change.")
+	}
+
+	return nil
+}
+
+// CreateListFieldChange journals a single field update, it is used when entries are
+// updated in bulk without reading them back.
+func (listChangeModel *ListChangeModel) CreateListFieldChange(uid, contentType string, list interface{}, field string, value interface{}) error {
+	after := bson.M{}
+	for key, beforeValue := range listDocument(list) {
+		after[key] = beforeValue
+	}
+	after[field] = value
+
+	return listChangeModel.CreateListChange(uid, contentType, UpdateListChangeAction, list, after)
+}
+
+// ! Update
+func (listChangeModel *ListChangeModel) MarkListChangeAsUndone(listChange ListChange) error {
+	if _, err := listChangeModel.ListChangeCollection.UpdateOne(context.TODO(), bson.M{
+		"_id": listChange.ID,
+	}, bson.M{"$set": bson.M{
+		"is_undone": true,
+		"undone_at": time.Now().UTC(),
+	}}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"list_change_id": listChange.ID,
+		}).Error("failed to mark list change as undone: ", err)
+
+		return fmt.Errorf("Failed to undo change.")
+	}
+
+	return nil
+}
+
+// ! Get
+func (listChangeModel *ListChangeModel) GetListChanges(uid string, data requests.GetListChanges) ([]ListChange, p.PaginationData, error) {
+	match := bson.M{
+		"user_id": uid,
+	}
+
+	if data.ID != nil {
+		match["list_id"] = *data.ID
+	}
+
+	if data.Type != nil {
+		match["content_type"] = *data.Type
+	}
+
+	paginatedData, err := p.New(listChangeModel.ListChangeCollection).Context(context.TODO()).
+		Limit(listChangePagination).Page(data.Page).Sort("created_at", -1).Aggregate(
+		bson.M{"$match": match},
+		bson.M{"$project": bson.M{"snapshot": 0, "records": 0}},
+	)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid":     uid,
+			"request": data,
+		}).Error("failed to aggregate list changes: ", err)
+
+		return nil, p.PaginationData{}, fmt.Errorf("Failed to get list history.")
+	}
+
+	listChanges := []ListChange{}
+	for _, raw := range paginatedData.Data {
+		var listChange *ListChange
+		if marshalErr := bson.Unmarshal(raw, &listChange); marshalErr == nil {
+			listChanges = append(listChanges, *listChange)
+		}
+	}
+
+	return listChanges, paginatedData.Pagination, nil
+}
+
+// GetLastListChange returns the latest change of the entry that is not undone yet,
+// undoing repeatedly walks back through the history.
+func (listChangeModel *ListChangeModel) GetLastListChange(uid, contentType, listID string) (ListChange, error) {
+	result := listChangeModel.ListChangeCollection.FindOne(context.TODO(), bson.M{
+		"user_id":      uid,
+		"list_id":      listID,
+		"content_type": contentType,
+		"is_undone":    false,
+	}, options.FindOne().SetSort(bson.M{"created_at": -1}))
+
+	var listChange ListChange
+	if err := result.Decode(&listChange); err != nil && err != mongo.ErrNoDocuments {
+		logrus.WithFields(logrus.Fields{
+			"uid":     uid,
+			"list_id": listID,
+		}).Error("failed to find last list change: ", err)
+
+		return ListChange{}, fmt.Errorf("Failed to find list change.")
+	}
+
+	return listChange, nil
+}
+
+// ! Delete
+func (listChangeModel *ListChangeModel) DeleteListChangesByUserID(uid string) {
+	if _, err := listChangeModel.ListChangeCollection.DeleteMany(context.TODO(), bson.M{
+		"user_id": uid,
+	}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to delete list changes by user id: ", err)
+	}
+}
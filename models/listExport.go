@@ -0,0 +1,547 @@
+package models
+
+import (
+	"app/db"
+	"app/responses"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+//lint:file-ignore ST1005 Ignore all
+
+type ListExportModel struct {
+	AnimeListCollection         *mongo.Collection
+	MangaListCollection         *mongo.Collection
+	MovieWatchListCollection    *mongo.Collection
+	TVSeriesWatchListCollection *mongo.Collection
+}
+
+func NewListExportModel(mongoDB *db.MongoDB) *ListExportModel {
+	return &ListExportModel{
+		AnimeListCollection:         mongoDB.Database.Collection("anime-lists"),
+		MangaListCollection:         mongoDB.Database.Collection("manga-lists"),
+		MovieWatchListCollection:    mongoDB.Database.Collection("movie-watch-lists"),
+		TVSeriesWatchListCollection: mongoDB.Database.Collection("tvseries-watch-lists"),
+	}
+}
+
+const malExportEmptyDate = "0000-00-00"
+
+// External id fields of the list types and the field they are exposed as.
+var listExportExternalIDFields = map[string]string{
+	"anime_mal_id":  "mal_id",
+	"manga_mal_id":  "mal_id",
+	"movie_tmdb_id": "tmdb_id",
+	"tv_tmdb_id":    "tmdb_id",
+}
+
+// listExportEntry is a list entry of any type with the content details needed
+// by the export formats, external ids of the entry are used when the content is
+// missing.
+type listExportEntry struct {
+	Status          string             `bson:"status"`
+	Score           *float32           `bson:"score"`
+	TimesFinished   int                `bson:"times_finished"`
+	WatchedEpisodes int64              `bson:"watched_episodes"`
+	ReadChapters    int64              `bson:"read_chapters"`
+	ReadVolumes     int64              `bson:"read_volumes"`
+	StartedAt       *time.Time         `bson:"started_at"`
+	FinishedAt      *time.Time         `bson:"finished_at"`
+	Priority        *int               `bson:"priority"`
+	PrivateNote     *string            `bson:"private_note"`
+	Tags            []string           `bson:"tags"`
+	MALID           int64              `bson:"mal_id"`
+	TmdbID          string             `bson:"tmdb_id"`
+	Content         *listExportContent `bson:"content"`
+}
+
+type listExportContent struct {
+	TitleOriginal string `bson:"title_original"`
+	TitleEn       string `bson:"title_en"`
+	Type          string `bson:"type"`
+	Episodes      *int64 `bson:"episodes"`
+	Chapters      *int64 `bson:"chapters"`
+	Volumes       *int64 `bson:"volumes"`
+	ImdbID        string `bson:"imdb_id"`
+	TraktID       *int64 `bson:"trakt_id"`
+	ReleaseDate   string `bson:"release_date"`
+	FirstAirDate  string `bson:"first_air_date"`
+}
+
+func (entry listExportEntry) title() string {
+	if entry.Content == nil {
+		return ""
+	}
+
+	if entry.Content.TitleEn != "" {
+		return entry.Content.TitleEn
+	}
+
+	return entry.Content.TitleOriginal
+}
+
+// originalTitle is used by MyAnimeList exports, it's the main title on MyAnimeList.
+func (entry listExportEntry) originalTitle() string {
+	if entry.Content == nil {
+		return ""
+	}
+
+	return entry.Content.TitleOriginal
+}
+
+// year returns the year of the release or first air date.
+func (entry listExportEntry) year() *int {
+	if entry.Content == nil {
+		return nil
+	}
+
+	date := entry.Content.ReleaseDate
+	if date == "" {
+		date = entry.Content.FirstAirDate
+	}
+
+	if len(date) < 4 {
+		return nil
+	}
+
+	year, err := strconv.Atoi(date[:4])
+	if err != nil {
+		return nil
+	}
+
+	return &year
+}
+
+// getListExportEntries returns the entries of the user with their content. The
+// external id of the entry is exposed as mal_id or tmdb_id.
+func (listExportModel *ListExportModel) getListExportEntries(
+	collection *mongo.Collection, uid, contentField, externalIDField, contentCollection string,
+) ([]listExportEntry, error) {
+	match := bson.M{"$match": bson.M{
+		"user_id": uid,
+	}}
+
+	addFields := bson.M{"$addFields": bson.M{
+		"content_obj_id": bson.M{
+			"$toObjectId": "$" + contentField,
+		},
+		listExportExternalIDFields[externalIDField]: "$" + externalIDField,
+	}}
+
+	contentLookup := bson.M{"$lookup": bson.M{
+		"from": contentCollection,
+		"let": bson.M{
+			"content_obj_id": "$content_obj_id",
+		},
+		"pipeline": bson.A{
+			bson.M{
+				"$match": bson.M{
+					"$expr": bson.M{
+						"$eq": bson.A{"$_id", "$$content_obj_id"},
+					},
+				},
+			},
+			bson.M{
+				"$project": bson.M{
+					"title_original": 1,
+					"title_en":       1,
+					"type":           1,
+					"episodes":       1,
+					"chapters":       1,
+					"volumes":        1,
+					"imdb_id":        1,
+					"trakt_id":       1,
+					"release_date":   1,
+					"first_air_date": 1,
+				},
+			},
+		},
+		"as": "content",
+	}}
+
+	unwind := bson.M{"$unwind": bson.M{
+		"path":                       "$content",
+		"preserveNullAndEmptyArrays": true,
+	}}
+
+	sort := bson.M{"$sort": bson.M{
+		"created_at": 1,
+	}}
+
+	cursor, err := collection.Aggregate(context.TODO(), bson.A{
+		match, addFields, contentLookup, unwind, sort,
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid":        uid,
+			"collection": collection.Name(),
+		}).Error("failed to aggregate list export entries: ", err)
+
+		return nil, fmt.Errorf("Failed to export list.")
+	}
+
+	var entries []listExportEntry
+	if err = cursor.All(context.TODO(), &entries); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid":        uid,
+			"collection": collection.Name(),
+		}).Error("failed to decode list export entries: ", err)
+
+		return nil, fmt.Errorf("Failed to export list.")
+	}
+
+	return entries, nil
+}
+
+// ! MyAnimeList
+func (listExportModel *ListExportModel) ExportMALAnimeList(uid string) ([]byte, error) {
+	entries, err := listExportModel.getListExportEntries(
+		listExportModel.AnimeListCollection, uid, "anime_id", "anime_mal_id", "animes",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	malExport := responses.MALExport{
+		MyInfo: responses.MALExportInfo{UserExportType: 1},
+	}
+
+	for _, entry := range entries {
+		var (
+			seriesType     string
+			seriesEpisodes int64
+		)
+
+		if entry.Content != nil {
+			seriesType = entry.Content.Type
+			if entry.Content.Episodes != nil {
+				seriesEpisodes = *entry.Content.Episodes
+			}
+		}
+
+		malExport.Anime = append(malExport.Anime, responses.MALExportAnime{
+			SeriesAnimeDBID:   entry.MALID,
+			SeriesTitle:       responses.MALExportCDATA{Value: entry.originalTitle()},
+			SeriesType:        seriesType,
+			SeriesEpisodes:    seriesEpisodes,
+			MyWatchedEpisodes: entry.WatchedEpisodes,
+			MyStartDate:       malExportDate(entry.StartedAt),
+			MyFinishDate:      malExportDate(entry.FinishedAt),
+			MyScore:           malExportScore(entry.Score),
+			MyStatus:          malExportStatus(entry.Status, "Watching", "Plan to Watch"),
+			MyTimesWatched:    malExportTimesRepeated(entry.TimesFinished),
+			MyTags:            responses.MALExportCDATA{Value: strings.Join(entry.Tags, ", ")},
+			MyComments:        responses.MALExportCDATA{Value: malExportComments(entry.PrivateNote)},
+			MyPriority:        malExportPriority(entry.Priority),
+			UpdateOnImport:    1,
+		})
+	}
+
+	return marshalMALExport(malExport)
+}
+
+func (listExportModel *ListExportModel) ExportMALMangaList(uid string) ([]byte, error) {
+	entries, err := listExportModel.getListExportEntries(
+		listExportModel.MangaListCollection, uid, "manga_id", "manga_mal_id", "mangas",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	malExport := responses.MALExport{
+		MyInfo: responses.MALExportInfo{UserExportType: 2},
+	}
+
+	for _, entry := range entries {
+		var mangaVolumes, mangaChapters int64
+
+		if entry.Content != nil {
+			if entry.Content.Volumes != nil {
+				mangaVolumes = *entry.Content.Volumes
+			}
+
+			if entry.Content.Chapters != nil {
+				mangaChapters = *entry.Content.Chapters
+			}
+		}
+
+		malExport.Manga = append(malExport.Manga, responses.MALExportManga{
+			MangaMangaDBID: entry.MALID,
+			MangaTitle:     responses.MALExportCDATA{Value: entry.originalTitle()},
+			MangaVolumes:   mangaVolumes,
+			MangaChapters:  mangaChapters,
+			MyReadVolumes:  entry.ReadVolumes,
+			MyReadChapters: entry.ReadChapters,
+			MyStartDate:    malExportDate(entry.StartedAt),
+			MyFinishDate:   malExportDate(entry.FinishedAt),
+			MyScore:        malExportScore(entry.Score),
+			MyStatus:       malExportStatus(entry.Status, "Reading", "Plan to Read"),
+			MyTimesRead:    malExportTimesRepeated(entry.TimesFinished),
+			MyTags:         responses.MALExportCDATA{Value: strings.Join(entry.Tags, ", ")},
+			MyComments:     responses.MALExportCDATA{Value: malExportComments(entry.PrivateNote)},
+			MyPriority:     malExportPriority(entry.Priority),
+			UpdateOnImport: 1,
+		})
+	}
+
+	return marshalMALExport(malExport)
+}
+
+func marshalMALExport(malExport responses.MALExport) ([]byte, error) {
+	data, err := xml.MarshalIndent(malExport, "", "  ")
+	if err != nil {
+		logrus.Error("failed to marshal mal export: ", err)
+
+		return nil, fmt.Errorf("Failed to export list.")
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
+
+func malExportDate(date *time.Time) string {
+	if date == nil {
+		return malExportEmptyDate
+	}
+
+	return date.UTC().Format("2006-01-02")
+}
+
+// malExportScore rounds the score, MyAnimeList only accepts whole scores.
+func malExportScore(score *float32) int {
+	if score == nil {
+		return 0
+	}
+
+	return int(math.Round(float64(*score)))
+}
+
+func malExportStatus(status, activeStatus, planToStatus string) string {
+	switch status {
+	case "active":
+		return activeStatus
+	case "finished":
+		return "Completed"
+	case "dropped":
+		return "Dropped"
+	}
+
+	return planToStatus
+}
+
+// malExportTimesRepeated converts times finished to MyAnimeList rewatch/reread count.
+func malExportTimesRepeated(timesFinished int) int {
+	if timesFinished <= 1 {
+		return 0
+	}
+
+	return timesFinished - 1
+}
+
+func malExportComments(privateNote *string) string {
+	if privateNote == nil {
+		return ""
+	}
+
+	return *privateNote
+}
+
+// malExportPriority maps priority (1-5) to MyAnimeList priorities.
+func malExportPriority(priority *int) string {
+	if priority == nil || *priority <= 2 {
+		return "LOW"
+	}
+
+	if *priority == 3 {
+		return "MEDIUM"
+	}
+
+	return "HIGH"
+}
+
+// ! Letterboxd
+// ExportLetterboxdMovieList renders finished movies, or plan to watch movies for
+// watchlist, in the Letterboxd import format. Letterboxd ratings are 0.5-5.
+func (listExportModel *ListExportModel) ExportLetterboxdMovieList(uid string, isWatchlist bool) ([]byte, error) {
+	entries, err := listExportModel.getListExportEntries(
+		listExportModel.MovieWatchListCollection, uid, "movie_id", "movie_tmdb_id", "movies",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	csvWriter := csv.NewWriter(&buffer)
+
+	csvWriter.Write([]string{"tmdbID", "imdbID", "Title", "Year", "Rating", "WatchedDate", "Rewatch", "Tags"})
+
+	for _, entry := range entries {
+		if (isWatchlist && entry.Status != "planto") || (!isWatchlist && entry.Status != "finished") {
+			continue
+		}
+
+		var imdbID, year, rating, watchedDate, rewatch string
+
+		if entry.Content != nil {
+			imdbID = entry.Content.ImdbID
+		}
+
+		if entryYear := entry.year(); entryYear != nil {
+			year = strconv.Itoa(*entryYear)
+		}
+
+		if !isWatchlist {
+			if entry.Score != nil && *entry.Score > 0 {
+				letterboxdRating := math.Max(math.Round(float64(*entry.Score)), 1) / 2
+				rating = strconv.FormatFloat(letterboxdRating, 'f', -1, 64)
+			}
+
+			if entry.FinishedAt != nil {
+				watchedDate = entry.FinishedAt.UTC().Format("2006-01-02")
+			}
+
+			rewatch = strconv.FormatBool(entry.TimesFinished > 1)
+		}
+
+		csvWriter.Write([]string{
+			entry.TmdbID, imdbID, entry.title(), year, rating,
+			watchedDate, rewatch, strings.Join(entry.Tags, ", "),
+		})
+	}
+
+	csvWriter.Flush()
+	if err := csvWriter.Error(); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to write letterboxd export: ", err)
+
+		return nil, fmt.Errorf("Failed to export list.")
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// ! Trakt
+func (listExportModel *ListExportModel) ExportTraktList(uid string) ([]byte, error) {
+	movieEntries, err := listExportModel.getListExportEntries(
+		listExportModel.MovieWatchListCollection, uid, "movie_id", "movie_tmdb_id", "movies",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	tvEntries, err := listExportModel.getListExportEntries(
+		listExportModel.TVSeriesWatchListCollection, uid, "tv_id", "tv_tmdb_id", "tv-series",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	traktExport := responses.TraktExport{
+		History:   responses.TraktExportSection{Movies: []responses.TraktExportItem{}, Shows: []responses.TraktExportItem{}},
+		Ratings:   responses.TraktExportSection{Movies: []responses.TraktExportItem{}, Shows: []responses.TraktExportItem{}},
+		Watchlist: responses.TraktExportSection{Movies: []responses.TraktExportItem{}, Shows: []responses.TraktExportItem{}},
+	}
+
+	for _, entry := range movieEntries {
+		history, rating, watchlist := traktExportItems(entry)
+
+		if history != nil {
+			traktExport.History.Movies = append(traktExport.History.Movies, *history)
+		}
+
+		if rating != nil {
+			traktExport.Ratings.Movies = append(traktExport.Ratings.Movies, *rating)
+		}
+
+		if watchlist != nil {
+			traktExport.Watchlist.Movies = append(traktExport.Watchlist.Movies, *watchlist)
+		}
+	}
+
+	for _, entry := range tvEntries {
+		history, rating, watchlist := traktExportItems(entry)
+
+		if history != nil {
+			traktExport.History.Shows = append(traktExport.History.Shows, *history)
+		}
+
+		if rating != nil {
+			traktExport.Ratings.Shows = append(traktExport.Ratings.Shows, *rating)
+		}
+
+		if watchlist != nil {
+			traktExport.Watchlist.Shows = append(traktExport.Watchlist.Shows, *watchlist)
+		}
+	}
+
+	data, err := json.MarshalIndent(traktExport, "", "  ")
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to marshal trakt export: ", err)
+
+		return nil, fmt.Errorf("Failed to export list.")
+	}
+
+	return data, nil
+}
+
+// traktExportItems returns the history, rating and watchlist items of the entry.
+// Only finished entries are added to history since episodes are not exported.
+func traktExportItems(entry listExportEntry) (*responses.TraktExportItem, *responses.TraktExportItem, *responses.TraktExportItem) {
+	item := responses.TraktExportItem{
+		Title: entry.title(),
+		Year:  entry.year(),
+	}
+
+	if tmdbID, err := strconv.ParseInt(entry.TmdbID, 10, 64); err == nil {
+		item.IDs.Tmdb = &tmdbID
+	}
+
+	if entry.Content != nil {
+		item.IDs.Trakt = entry.Content.TraktID
+
+		if entry.Content.ImdbID != "" {
+			item.IDs.Imdb = &entry.Content.ImdbID
+		}
+	}
+
+	var history, rating, watchlist *responses.TraktExportItem
+
+	switch entry.Status {
+	case "finished":
+		historyItem := item
+		if entry.FinishedAt != nil {
+			watchedAt := entry.FinishedAt.UTC().Format(time.RFC3339)
+			historyItem.WatchedAt = &watchedAt
+		}
+
+		history = &historyItem
+	case "planto":
+		watchlistItem := item
+		watchlist = &watchlistItem
+	}
+
+	// Trakt ratings are whole numbers between 1 and 10.
+	if entry.Score != nil && *entry.Score > 0 {
+		score := int(math.Max(math.Round(float64(*entry.Score)), 1))
+
+		ratingItem := item
+		ratingItem.Rating = &score
+		rating = &ratingItem
+	}
+
+	return history, rating, watchlist
+}
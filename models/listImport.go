@@ -0,0 +1,809 @@
+package models
+
+import (
+	"app/db"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+//lint:file-ignore ST1005 Ignore all
+
+// ListImportModel matches the entries of every import source with our content,
+// compares them with the user's lists and writes them with ListWriteModel.
+type ListImportModel struct {
+	MovieCollection     *mongo.Collection
+	TVCollection        *mongo.Collection
+	GameCollection      *mongo.Collection
+	AnimeListCollection *mongo.Collection
+	MangaListCollection *mongo.Collection
+	GameListCollection  *mongo.Collection
+	MovieListCollection *mongo.Collection
+	TVListCollection    *mongo.Collection
+	MALImportModel      *MALImportModel
+	AniListImportModel  *AniListImportModel
+	SteamImportModel    *SteamImportModel
+	IMDBImportModel     *IMDBImportModel
+	TraktImportModel    *TraktImportModel
+	// Unmatched items that the user linked are matched with their content.
+	UnmatchedImportModel *UnmatchedImportModel
+	ListWriteModel       *ListWriteModel
+}
+
+func NewListImportModel(mongoDB *db.MongoDB) *ListImportModel {
+	return &ListImportModel{
+		MovieCollection:      mongoDB.Database.Collection("movies"),
+		TVCollection:         mongoDB.Database.Collection("tv-series"),
+		GameCollection:       mongoDB.Database.Collection("games"),
+		AnimeListCollection:  mongoDB.Database.Collection("anime-lists"),
+		MangaListCollection:  mongoDB.Database.Collection("manga-lists"),
+		GameListCollection:   mongoDB.Database.Collection("game-lists"),
+		MovieListCollection:  mongoDB.Database.Collection("movie-watch-lists"),
+		TVListCollection:     mongoDB.Database.Collection("tvseries-watch-lists"),
+		MALImportModel:       NewMALImportModel(mongoDB),
+		AniListImportModel:   NewAniListImportModel(mongoDB),
+		SteamImportModel:     NewSteamImportModel(mongoDB),
+		IMDBImportModel:      NewIMDBImportModel(mongoDB),
+		TraktImportModel:     NewTraktImportModel(mongoDB),
+		UnmatchedImportModel: NewUnmatchedImportModel(mongoDB),
+		ListWriteModel:       NewListWriteModel(mongoDB),
+	}
+}
+
+const (
+	NewImportItemAction       = "new"
+	UnchangedImportItemAction = "unchanged"
+	ConflictImportItemAction  = "conflict"
+	UnmatchedImportItemAction = "unmatched"
+)
+
+// Conflicting entries are resolved with the policy, items can be resolved one by
+// one with keep mine or take theirs.
+const (
+	KeepMineImportPolicy    = "keep_mine"
+	TakeTheirsImportPolicy  = "take_theirs"
+	HigherScoreImportPolicy = "higher_score"
+	LatestImportPolicy      = "latest"
+)
+
+// DefaultImportPolicy returns the policy of the imports that don't choose one,
+// Steam and IMDb imports always refreshed the entries that are already in the list.
+func DefaultImportPolicy(source string) string {
+	if source == SteamImportSource || source == IMDBImportSource {
+		return TakeTheirsImportPolicy
+	}
+
+	return KeepMineImportPolicy
+}
+
+// ImportEntry is a list entry of another service. Content is matched by its ids,
+// movies and tv series without ids are matched by title and year.
+type ImportEntry struct {
+	ContentType string   `bson:"content_type" json:"content_type"`
+	Title       string   `bson:"title" json:"title"`
+	Year        int      `bson:"year,omitempty" json:"year,omitempty"`
+	MALID       int64    `bson:"mal_id,omitempty" json:"mal_id,omitempty"`
+	AniListID   int64    `bson:"anilist_id,omitempty" json:"anilist_id,omitempty"`
+	SteamAppID  int64    `bson:"steam_app_id,omitempty" json:"steam_app_id,omitempty"`
+	IMDBID      string   `bson:"imdb_id,omitempty" json:"imdb_id,omitempty"`
+	TMDBID      string   `bson:"tmdb_id,omitempty" json:"tmdb_id,omitempty"`
+	TraktID     int64    `bson:"trakt_id,omitempty" json:"trakt_id,omitempty"`
+	Status      string   `bson:"status" json:"status"`
+	Score       *float32 `bson:"score" json:"score"`
+	Progress    int64    `bson:"progress" json:"progress"`
+	Volumes     int64    `bson:"volumes,omitempty" json:"volumes,omitempty"`
+	HoursPlayed *int     `bson:"hours_played,omitempty" json:"hours_played,omitempty"`
+	// AchievementStatus is the completion percentage of the game.
+	AchievementStatus *float32 `bson:"achievement_status,omitempty" json:"achievement_status,omitempty"`
+	// Platform is the comma separated platforms of the game, used for matching.
+	Platform      string     `bson:"platform,omitempty" json:"platform,omitempty"`
+	TimesFinished int        `bson:"times_finished" json:"times_finished"`
+	StartedAt     *time.Time `bson:"started_at,omitempty" json:"started_at,omitempty"`
+	FinishedAt    *time.Time `bson:"finished_at,omitempty" json:"finished_at,omitempty"`
+	// UpdatedAt is the last change of the entry on the source if it's known.
+	UpdatedAt *time.Time `bson:"updated_at,omitempty" json:"updated_at,omitempty"`
+}
+
+func (entry ImportEntry) key() string {
+	switch {
+	case entry.MALID != 0:
+		return fmt.Sprintf("%s:mal:%d", entry.ContentType, entry.MALID)
+	case entry.AniListID != 0:
+		return fmt.Sprintf("%s:anilist:%d", entry.ContentType, entry.AniListID)
+	case entry.SteamAppID != 0:
+		return fmt.Sprintf("%s:steam:%d", entry.ContentType, entry.SteamAppID)
+	case entry.IMDBID != "":
+		return fmt.Sprintf("%s:imdb:%s", entry.ContentType, entry.IMDBID)
+	case entry.TMDBID != "":
+		return fmt.Sprintf("%s:tmdb:%s", entry.ContentType, entry.TMDBID)
+	case entry.TraktID != 0:
+		return fmt.Sprintf("%s:trakt:%d", entry.ContentType, entry.TraktID)
+	}
+
+	return fmt.Sprintf("%s:title:%s", entry.ContentType, importTitleKey(entry.Title, entry.Year))
+}
+
+func importTitleKey(title string, year int) string {
+	return fmt.Sprintf("%s|%d", strings.ToLower(strings.TrimSpace(title)), year)
+}
+
+// Higher ranked status wins when the same content appears more than once, e.g.
+// a movie that is both in the watchlist and the diary is finished.
+var importStatusRanks = map[string]int{
+	"planto":   0,
+	"active":   1,
+	"dropped":  2,
+	"finished": 3,
+}
+
+// importEntries merges the entries of the same content, sources list rewatches,
+// ratings and custom lists separately.
+type importEntries struct {
+	entries []*ImportEntry
+	keys    map[string]*ImportEntry
+}
+
+func newImportEntries() *importEntries {
+	return &importEntries{
+		entries: []*ImportEntry{},
+		keys:    make(map[string]*ImportEntry),
+	}
+}
+
+func (importEntries *importEntries) add(entry ImportEntry) {
+	existing, ok := importEntries.keys[entry.key()]
+	if !ok {
+		importEntries.keys[entry.key()] = &entry
+		importEntries.entries = append(importEntries.entries, &entry)
+
+		return
+	}
+
+	if importStatusRanks[entry.Status] > importStatusRanks[existing.Status] {
+		existing.Status = entry.Status
+	}
+
+	if entry.Score != nil {
+		existing.Score = entry.Score
+	}
+
+	if entry.Progress > existing.Progress {
+		existing.Progress = entry.Progress
+	}
+
+	if entry.Volumes > existing.Volumes {
+		existing.Volumes = entry.Volumes
+	}
+
+	existing.TimesFinished += entry.TimesFinished
+
+	// Playtime of the same game on different platforms adds up.
+	if entry.HoursPlayed != nil {
+		hoursPlayed := *entry.HoursPlayed
+		if existing.HoursPlayed != nil {
+			hoursPlayed += *existing.HoursPlayed
+		}
+		existing.HoursPlayed = &hoursPlayed
+	}
+
+	if entry.AchievementStatus != nil && (existing.AchievementStatus == nil || *entry.AchievementStatus > *existing.AchievementStatus) {
+		existing.AchievementStatus = entry.AchievementStatus
+	}
+
+	if entry.FinishedAt != nil && (existing.FinishedAt == nil || entry.FinishedAt.After(*existing.FinishedAt)) {
+		existing.FinishedAt = entry.FinishedAt
+	}
+}
+
+// ImportCurrentEntry is the entry that is already in the user's list.
+type ImportCurrentEntry struct {
+	ID          primitive.ObjectID `bson:"_id" json:"_id"`
+	Status      string             `bson:"status" json:"status"`
+	Score       *float32           `bson:"score" json:"score"`
+	Progress    int64              `bson:"progress" json:"progress"`
+	HoursPlayed *int               `bson:"hours_played,omitempty" json:"hours_played,omitempty"`
+	// AchievementStatus is the completion percentage of the game.
+	AchievementStatus *float32   `bson:"achievement_status,omitempty" json:"achievement_status,omitempty"`
+	StartedAt         *time.Time `bson:"started_at,omitempty" json:"started_at,omitempty"`
+	FinishedAt        *time.Time `bson:"finished_at,omitempty" json:"finished_at,omitempty"`
+	UpdatedAt         time.Time  `bson:"updated_at" json:"updated_at"`
+}
+
+// lastChangedAt returns the latest of updated_at and the dates of the entry.
+// Entries that are edited before updated_at is set on every write still have
+// their creation time, their dates are the only sign of a later change.
+func (current ImportCurrentEntry) lastChangedAt() time.Time {
+	lastChangedAt := current.UpdatedAt
+
+	for _, date := range []*time.Time{current.StartedAt, current.FinishedAt} {
+		if date != nil && date.After(lastChangedAt) {
+			lastChangedAt = *date
+		}
+	}
+
+	return lastChangedAt
+}
+
+// ImportItem is an entry of the import compared with the user's list, key is
+// used to choose how a conflict is resolved.
+type ImportItem struct {
+	Key       string              `bson:"key" json:"key"`
+	Action    string              `bson:"action" json:"action"`
+	ContentID string              `bson:"content_id" json:"content_id"`
+	Entry     ImportEntry         `bson:"entry" json:"entry"`
+	Current   *ImportCurrentEntry `bson:"current" json:"current"`
+}
+
+type ImportPreview struct {
+	NewCount       int `bson:"new_count" json:"new_count"`
+	UnchangedCount int `bson:"unchanged_count" json:"unchanged_count"`
+	ConflictCount  int `bson:"conflict_count" json:"conflict_count"`
+	UnmatchedCount int `bson:"unmatched_count" json:"unmatched_count"`
+	// Items are stored apart from the job, see importPreviewItem.
+	Items []ImportItem `bson:"-" json:"items"`
+}
+
+func newImportPreview(items []ImportItem) ImportPreview {
+	preview := ImportPreview{Items: items}
+	for _, item := range items {
+		switch item.Action {
+		case NewImportItemAction:
+			preview.NewCount++
+		case UnchangedImportItemAction:
+			preview.UnchangedCount++
+		case ConflictImportItemAction:
+			preview.ConflictCount++
+		case UnmatchedImportItemAction:
+			preview.UnmatchedCount++
+		}
+	}
+
+	return preview
+}
+
+// isConflict reports whether the entry would change the user's entry. Values
+// the source doesn't have, e.g. score of a watchlist, are not compared.
+func (entry ImportEntry) isConflict(current ImportCurrentEntry) bool {
+	if entry.Status != current.Status {
+		return true
+	}
+
+	if entry.Score != nil && (current.Score == nil || *entry.Score != *current.Score) {
+		return true
+	}
+
+	if entry.Progress > 0 && entry.Progress != current.Progress {
+		return true
+	}
+
+	if entry.HoursPlayed != nil && (current.HoursPlayed == nil || *entry.HoursPlayed != *current.HoursPlayed) {
+		return true
+	}
+
+	return entry.AchievementStatus != nil &&
+		(current.AchievementStatus == nil || *entry.AchievementStatus != *current.AchievementStatus)
+}
+
+// takeTheirs reports whether the conflict is resolved with the imported entry,
+// choice of the item overrides the policy.
+func (item ImportItem) takeTheirs(policy string, choices map[string]string) bool {
+	if choice, ok := choices[item.Key]; ok {
+		policy = choice
+	}
+
+	switch policy {
+	case TakeTheirsImportPolicy:
+		return true
+	case HigherScoreImportPolicy:
+		return item.Entry.Score != nil && (item.Current.Score == nil || *item.Entry.Score > *item.Current.Score)
+	case LatestImportPolicy:
+		updatedAt := item.Entry.UpdatedAt
+		if updatedAt == nil {
+			updatedAt = item.Entry.FinishedAt
+		}
+		if updatedAt == nil {
+			updatedAt = item.Entry.StartedAt
+		}
+
+		return updatedAt != nil && updatedAt.After(item.Current.lastChangedAt())
+	}
+
+	return false
+}
+
+// ! Preview
+// PreviewEntries compares the entries with the user's lists without writing.
+func (listImportModel *ListImportModel) PreviewEntries(userID string, entries []*ImportEntry) (ImportPreview, error) {
+	contentIDs, err := listImportModel.matchEntries(entries)
+	if err != nil {
+		return ImportPreview{}, err
+	}
+
+	if err := listImportModel.matchLinkedEntries(userID, entries, contentIDs); err != nil {
+		return ImportPreview{}, err
+	}
+
+	items := make([]ImportItem, 0, len(entries))
+	matchedContents := map[string]bool{}
+	for index, entry := range entries {
+		item := ImportItem{
+			Key:       entry.key(),
+			Action:    UnmatchedImportItemAction,
+			ContentID: contentIDs[index],
+			Entry:     *entry,
+		}
+
+		// Entries of the same content, e.g. matched by different titles, are imported once.
+		if item.ContentID != "" {
+			if matchedContents[entry.ContentType+":"+item.ContentID] {
+				continue
+			}
+			matchedContents[entry.ContentType+":"+item.ContentID] = true
+		}
+
+		items = append(items, item)
+	}
+
+	if err := listImportModel.compareItems(userID, items); err != nil {
+		return ImportPreview{}, err
+	}
+
+	return newImportPreview(items), nil
+}
+
+// compareItems sets the action of the matched items with the current state of
+// the user's lists.
+func (listImportModel *ListImportModel) compareItems(userID string, items []ImportItem) error {
+	contentTypes := map[string]bool{}
+	for _, item := range items {
+		if item.ContentID != "" {
+			contentTypes[item.Entry.ContentType] = true
+		}
+	}
+
+	for contentType := range contentTypes {
+		currentEntries, err := listImportModel.getCurrentEntries(contentType, userID)
+		if err != nil {
+			return fmt.Errorf("failed to get existing entries: %v", err)
+		}
+
+		for index := range items {
+			item := &items[index]
+			if item.ContentID == "" || item.Entry.ContentType != contentType {
+				continue
+			}
+
+			current, exists := currentEntries[item.ContentID]
+			switch {
+			case !exists:
+				item.Action = NewImportItemAction
+				item.Current = nil
+			case item.Entry.isConflict(current):
+				item.Action = ConflictImportItemAction
+				item.Current = &current
+			default:
+				item.Action = UnchangedImportItemAction
+				item.Current = &current
+			}
+		}
+	}
+
+	return nil
+}
+
+// ! Apply
+// ApplyEntries imports the entries, conflicts are resolved with the policy.
+func (listImportModel *ListImportModel) ApplyEntries(userID string, entries []*ImportEntry, policy string) (ImportJobResult, error) {
+	preview, err := listImportModel.PreviewEntries(userID, entries)
+	if err != nil {
+		return ImportJobResult{}, err
+	}
+
+	return listImportModel.ListWriteModel.WriteItems(userID, preview.Items, policy, nil)
+}
+
+// ApplyPreview imports the items of a preview. Items are compared again since
+// the user's lists may have changed after the preview.
+func (listImportModel *ListImportModel) ApplyPreview(userID string, items []ImportItem, policy string, choices map[string]string) (ImportJobResult, error) {
+	items = append([]ImportItem{}, items...)
+	if err := listImportModel.compareItems(userID, items); err != nil {
+		return ImportJobResult{}, err
+	}
+
+	return listImportModel.ListWriteModel.WriteItems(userID, items, policy, choices)
+}
+
+// ! Match
+// matchEntries returns the content id of every entry, empty if the content is
+// not found. Only the mappings of the content types of the entries are loaded.
+func (listImportModel *ListImportModel) matchEntries(entries []*ImportEntry) ([]string, error) {
+	var (
+		animeMALIDs, mangaMALIDs, animeAniListIDs, mangaAniListIDs map[int64]string
+		gameSteamIDs, movieTraktIDs, tvTraktIDs                    map[int64]string
+		movieIMDBIDs, tvIMDBIDs                                    map[string]string
+		err                                                        error
+	)
+
+	contentTypes := map[string]bool{}
+	for _, entry := range entries {
+		contentTypes[entry.ContentType] = true
+	}
+
+	if contentTypes["anime"] {
+		if animeMALIDs, err = listImportModel.MALImportModel.getAllAnimeMALIDs(); err != nil {
+			return nil, fmt.Errorf("failed to get anime MAL IDs: %v", err)
+		}
+
+		if animeAniListIDs, err = listImportModel.AniListImportModel.getAllAnimeAniListIDs(); err != nil {
+			return nil, fmt.Errorf("failed to get anime AniList IDs: %v", err)
+		}
+	}
+
+	if contentTypes["manga"] {
+		if mangaMALIDs, err = listImportModel.MALImportModel.getAllMangaMALIDs(); err != nil {
+			return nil, fmt.Errorf("failed to get manga MAL IDs: %v", err)
+		}
+
+		if mangaAniListIDs, err = listImportModel.AniListImportModel.getAllMangaAniListIDs(); err != nil {
+			return nil, fmt.Errorf("failed to get manga AniList IDs: %v", err)
+		}
+	}
+
+	if contentTypes["game"] {
+		if gameSteamIDs, err = listImportModel.SteamImportModel.getAllGameSteamIDs(); err != nil {
+			return nil, fmt.Errorf("failed to get game Steam IDs: %v", err)
+		}
+	}
+
+	// IMDb ids are unique across movies and tv series, both are loaded to match the
+	// entries of a wrong type e.g. a tv series in the watchlist scraped as a movie.
+	if contentTypes["movie"] || contentTypes["tv"] {
+		if movieIMDBIDs, err = listImportModel.IMDBImportModel.getAllMovieIMDBIDs(); err != nil {
+			return nil, fmt.Errorf("failed to get movie IMDB IDs: %v", err)
+		}
+
+		if tvIMDBIDs, err = listImportModel.IMDBImportModel.getAllTVIMDBIDs(); err != nil {
+			return nil, fmt.Errorf("failed to get TV IMDB IDs: %v", err)
+		}
+	}
+
+	if contentTypes["movie"] {
+		if movieTraktIDs, err = listImportModel.TraktImportModel.getAllMovieTraktIDs(); err != nil {
+			return nil, fmt.Errorf("failed to get movie Trakt IDs: %v", err)
+		}
+	}
+
+	if contentTypes["tv"] {
+		if tvTraktIDs, err = listImportModel.TraktImportModel.getAllTVTraktIDs(); err != nil {
+			return nil, fmt.Errorf("failed to get TV Trakt IDs: %v", err)
+		}
+	}
+
+	contentIDs := make([]string, len(entries))
+
+	var (
+		tmdbIDs, titles map[string][]int
+		gameIndexes     []int
+	)
+	for index, entry := range entries {
+		switch entry.ContentType {
+		case "anime":
+			contentIDs[index] = animeMALIDs[entry.MALID]
+			if contentIDs[index] == "" && entry.AniListID != 0 {
+				contentIDs[index] = animeAniListIDs[entry.AniListID]
+			}
+		case "manga":
+			contentIDs[index] = mangaMALIDs[entry.MALID]
+			if contentIDs[index] == "" && entry.AniListID != 0 {
+				contentIDs[index] = mangaAniListIDs[entry.AniListID]
+			}
+		case "game":
+			contentIDs[index] = gameSteamIDs[entry.SteamAppID]
+			if contentIDs[index] == "" && entry.Title != "" {
+				gameIndexes = append(gameIndexes, index)
+			}
+		case "movie":
+			contentIDs[index] = movieIMDBIDs[entry.IMDBID]
+			if contentIDs[index] == "" && entry.TraktID != 0 {
+				contentIDs[index] = movieTraktIDs[entry.TraktID]
+			}
+			if contentIDs[index] == "" && entry.IMDBID != "" && tvIMDBIDs[entry.IMDBID] != "" {
+				entry.ContentType = "tv"
+				contentIDs[index] = tvIMDBIDs[entry.IMDBID]
+			}
+		case "tv":
+			contentIDs[index] = tvIMDBIDs[entry.IMDBID]
+			if contentIDs[index] == "" && entry.TraktID != 0 {
+				contentIDs[index] = tvTraktIDs[entry.TraktID]
+			}
+			if contentIDs[index] == "" && entry.IMDBID != "" && movieIMDBIDs[entry.IMDBID] != "" {
+				entry.ContentType = "movie"
+				contentIDs[index] = movieIMDBIDs[entry.IMDBID]
+			}
+		}
+
+		if contentIDs[index] != "" || (entry.ContentType != "movie" && entry.ContentType != "tv") {
+			continue
+		}
+
+		if entry.TMDBID != "" {
+			if tmdbIDs == nil {
+				tmdbIDs = map[string][]int{}
+			}
+			tmdbIDs[entry.ContentType+":"+entry.TMDBID] = append(tmdbIDs[entry.ContentType+":"+entry.TMDBID], index)
+		} else if entry.Title != "" {
+			if titles == nil {
+				titles = map[string][]int{}
+			}
+			key := entry.ContentType + ":" + importTitleKey(entry.Title, entry.Year)
+			titles[key] = append(titles[key], index)
+		}
+	}
+
+	if err := listImportModel.matchGamesByTitles(entries, gameIndexes, contentIDs); err != nil {
+		return nil, err
+	}
+
+	for _, contentType := range []string{"movie", "tv"} {
+		if !contentTypes[contentType] {
+			continue
+		}
+
+		if err := listImportModel.matchByTMDBIDs(contentType, entries, tmdbIDs, contentIDs); err != nil {
+			return nil, err
+		}
+
+		if err := listImportModel.matchByTitles(contentType, entries, titles, contentIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	return contentIDs, nil
+}
+
+// matchLinkedEntries matches the unmatched entries with the contents that the
+// user linked them to before.
+func (listImportModel *ListImportModel) matchLinkedEntries(userID string, entries []*ImportEntry, contentIDs []string) error {
+	var keys []string
+	for index, entry := range entries {
+		if contentIDs[index] == "" {
+			keys = append(keys, entry.key())
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	linkedContentIDs, err := listImportModel.UnmatchedImportModel.getLinkedContentIDs(userID, keys)
+	if err != nil {
+		return fmt.Errorf("failed to get linked import items: %v", err)
+	}
+
+	for index, entry := range entries {
+		if contentIDs[index] == "" {
+			contentIDs[index] = linkedContentIDs[entry.key()]
+		}
+	}
+
+	return nil
+}
+
+type importContent struct {
+	ID            primitive.ObjectID `bson:"_id"`
+	TmdbID        string             `bson:"tmdb_id"`
+	TitleEn       string             `bson:"title_en"`
+	TitleOriginal string             `bson:"title_original"`
+	ReleaseDate   string             `bson:"release_date"`
+	FirstAirDate  string             `bson:"first_air_date"`
+}
+
+func (content importContent) year() int {
+	date := content.ReleaseDate
+	if date == "" {
+		date = content.FirstAirDate
+	}
+
+	if len(date) < 4 {
+		return 0
+	}
+
+	year, _ := strconv.Atoi(date[:4])
+	return year
+}
+
+func (listImportModel *ListImportModel) findContents(contentType string, filter bson.M) ([]importContent, error) {
+	collection := listImportModel.MovieCollection
+	if contentType == "tv" {
+		collection = listImportModel.TVCollection
+	}
+
+	cursor, err := collection.Find(context.TODO(), filter, options.Find().SetProjection(bson.M{
+		"_id":            1,
+		"tmdb_id":        1,
+		"title_en":       1,
+		"title_original": 1,
+		"release_date":   1,
+		"first_air_date": 1,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	var contents []importContent
+	if err := cursor.All(context.TODO(), &contents); err != nil {
+		return nil, err
+	}
+
+	return contents, nil
+}
+
+func (listImportModel *ListImportModel) matchByTMDBIDs(contentType string, entries []*ImportEntry, tmdbIDs map[string][]int, contentIDs []string) error {
+	var ids []string
+	for _, entry := range entries {
+		if entry.ContentType == contentType && tmdbIDs[contentType+":"+entry.TMDBID] != nil {
+			ids = append(ids, entry.TMDBID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	contents, err := listImportModel.findContents(contentType, bson.M{"tmdb_id": bson.M{"$in": ids}})
+	if err != nil {
+		return fmt.Errorf("failed to match %s TMDB IDs: %v", contentType, err)
+	}
+
+	for _, content := range contents {
+		for _, index := range tmdbIDs[contentType+":"+content.TmdbID] {
+			contentIDs[index] = content.ID.Hex()
+		}
+	}
+
+	return nil
+}
+
+// matchByTitles matches the original or english title with the same release year,
+// entries without a year match the title only and are left unmatched if more
+// than one content has the title.
+func (listImportModel *ListImportModel) matchByTitles(contentType string, entries []*ImportEntry, titles map[string][]int, contentIDs []string) error {
+	var titleList []string
+	for key, indexes := range titles {
+		if strings.HasPrefix(key, contentType+":") {
+			titleList = append(titleList, entries[indexes[0]].Title)
+		}
+	}
+
+	if len(titleList) == 0 {
+		return nil
+	}
+
+	contents, err := listImportModel.findContents(contentType, bson.M{"$or": bson.A{
+		bson.M{"title_original": bson.M{"$in": titleList}},
+		bson.M{"title_en": bson.M{"$in": titleList}},
+	}})
+	if err != nil {
+		return fmt.Errorf("failed to match %s titles: %v", contentType, err)
+	}
+
+	titleContentIDs := map[string]map[string]bool{}
+	for _, content := range contents {
+		for _, title := range []string{content.TitleOriginal, content.TitleEn} {
+			if year := content.year(); year != 0 {
+				for _, index := range titles[contentType+":"+importTitleKey(title, year)] {
+					if contentIDs[index] == "" {
+						contentIDs[index] = content.ID.Hex()
+					}
+				}
+			}
+
+			key := contentType + ":" + importTitleKey(title, 0)
+			if titles[key] != nil {
+				if titleContentIDs[key] == nil {
+					titleContentIDs[key] = map[string]bool{}
+				}
+				titleContentIDs[key][content.ID.Hex()] = true
+			}
+		}
+	}
+
+	for key, ids := range titleContentIDs {
+		if len(ids) != 1 {
+			continue
+		}
+
+		for contentID := range ids {
+			for _, index := range titles[key] {
+				if contentIDs[index] == "" {
+					contentIDs[index] = contentID
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (listImportModel *ListImportModel) getListCollection(contentType string) *mongo.Collection {
+	switch contentType {
+	case "anime":
+		return listImportModel.AnimeListCollection
+	case "manga":
+		return listImportModel.MangaListCollection
+	case "game":
+		return listImportModel.GameListCollection
+	case "movie":
+		return listImportModel.MovieListCollection
+	}
+
+	return listImportModel.TVListCollection
+}
+
+// getCurrentEntries gets the entries of the user's list in bulk by content id.
+func (listImportModel *ListImportModel) getCurrentEntries(contentType, userID string) (map[string]ImportCurrentEntry, error) {
+	cursor, err := listImportModel.getListCollection(contentType).Find(context.TODO(), bson.M{
+		"user_id": userID,
+	}, options.Find().SetProjection(bson.M{
+		listContentIDFields[contentType]: 1,
+		"status":                         1,
+		"score":                          1,
+		"watched_episodes":               1,
+		"read_chapters":                  1,
+		"hours_played":                   1,
+		"achievement_status":             1,
+		"started_at":                     1,
+		"finished_at":                    1,
+		"updated_at":                     1,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.TODO())
+
+	currentEntries := make(map[string]ImportCurrentEntry)
+	for cursor.Next(context.TODO()) {
+		var entry struct {
+			ID                primitive.ObjectID `bson:"_id"`
+			AnimeID           string             `bson:"anime_id"`
+			MangaID           string             `bson:"manga_id"`
+			GameID            string             `bson:"game_id"`
+			MovieID           string             `bson:"movie_id"`
+			TvID              string             `bson:"tv_id"`
+			Status            string             `bson:"status"`
+			Score             *float32           `bson:"score"`
+			WatchedEpisodes   int64              `bson:"watched_episodes"`
+			ReadChapters      int64              `bson:"read_chapters"`
+			HoursPlayed       *int               `bson:"hours_played"`
+			AchievementStatus *float32           `bson:"achievement_status"`
+			StartedAt         *time.Time         `bson:"started_at"`
+			FinishedAt        *time.Time         `bson:"finished_at"`
+			UpdatedAt         time.Time          `bson:"updated_at"`
+		}
+		if err := cursor.Decode(&entry); err != nil {
+			continue
+		}
+
+		// Only the content id of the list type is set.
+		currentEntries[entry.AnimeID+entry.MangaID+entry.GameID+entry.MovieID+entry.TvID] = ImportCurrentEntry{
+			ID:                entry.ID,
+			Status:            entry.Status,
+			Score:             entry.Score,
+			Progress:          entry.WatchedEpisodes + entry.ReadChapters,
+			HoursPlayed:       entry.HoursPlayed,
+			AchievementStatus: entry.AchievementStatus,
+			StartedAt:         entry.StartedAt,
+			FinishedAt:        entry.FinishedAt,
+			UpdatedAt:         entry.UpdatedAt,
+		}
+	}
+
+	return currentEntries, nil
+}
@@ -0,0 +1,674 @@
+package models
+
+import (
+	"app/db"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+//lint:file-ignore ST1005 Ignore all
+
+// ListWriteModel writes the entries that are not added by the user one by one,
+// e.g. imports and synced accounts, the same way the list endpoints do. Free
+// users' list limit is applied, logs are created at the time of the activity
+// and achievements are checked once all entries are written.
+type ListWriteModel struct {
+	AnimeCollection     *mongo.Collection
+	MangaCollection     *mongo.Collection
+	GameCollection      *mongo.Collection
+	MovieCollection     *mongo.Collection
+	TVCollection        *mongo.Collection
+	AnimeListCollection *mongo.Collection
+	MangaListCollection *mongo.Collection
+	GameListCollection  *mongo.Collection
+	MovieListCollection *mongo.Collection
+	TVListCollection    *mongo.Collection
+	UserModel           *UserModel
+	UserListModel       *UserListModel
+	LogsModel           *LogsModel
+	AchievementModel    *AchievementModel
+}
+
+func NewListWriteModel(mongoDB *db.MongoDB) *ListWriteModel {
+	return &ListWriteModel{
+		AnimeCollection:     mongoDB.Database.Collection("animes"),
+		MangaCollection:     mongoDB.Database.Collection("mangas"),
+		GameCollection:      mongoDB.Database.Collection("games"),
+		MovieCollection:     mongoDB.Database.Collection("movies"),
+		TVCollection:        mongoDB.Database.Collection("tv-series"),
+		AnimeListCollection: mongoDB.Database.Collection("anime-lists"),
+		MangaListCollection: mongoDB.Database.Collection("manga-lists"),
+		GameListCollection:  mongoDB.Database.Collection("game-lists"),
+		MovieListCollection: mongoDB.Database.Collection("movie-watch-lists"),
+		TVListCollection:    mongoDB.Database.Collection("tvseries-watch-lists"),
+		UserModel:           NewUserModel(mongoDB),
+		UserListModel:       NewUserListModel(mongoDB),
+		LogsModel:           NewLogsModel(mongoDB),
+		AchievementModel:    NewAchievementModel(mongoDB),
+	}
+}
+
+// WriteItems inserts the new items and updates the conflicts that are resolved
+// with the imported entry. New items over the list limit of free users are not
+// written, the result is partial with their titles. Entries are written per
+// content type, if writing fails the result is returned with the error and has
+// the changes of the entries that are already written.
+func (listWriteModel *ListWriteModel) WriteItems(userID string, items []ImportItem, policy string, choices map[string]string) (ImportJobResult, error) {
+	var result ImportJobResult
+
+	// Entries are written at the same time, rollback only reverts the entries
+	// that are not changed since then. Mongo stores milliseconds.
+	writtenAt := time.Now().UTC().Truncate(time.Millisecond)
+
+	contentIDs := map[string][]string{}
+	for _, item := range items {
+		if item.Action == NewImportItemAction || item.Action == ConflictImportItemAction {
+			contentIDs[item.Entry.ContentType] = append(contentIDs[item.Entry.ContentType], item.ContentID)
+		}
+	}
+
+	contents := map[string]map[string]listWriteContent{}
+	for contentType, ids := range contentIDs {
+		var err error
+		if contents[contentType], err = listWriteModel.getContents(contentType, ids); err != nil {
+			return ImportJobResult{}, fmt.Errorf("failed to get contents: %v", err)
+		}
+	}
+
+	remainingCount, err := listWriteModel.getRemainingListCount(userID)
+	if err != nil {
+		return ImportJobResult{}, err
+	}
+
+	var (
+		insertLogs    = map[string][]interface{}{}
+		updateLogs    = map[string][]interface{}{}
+		writtenLogs   []interface{}
+		activityTypes = []string{"first_activity"}
+		isFinished    = map[string]bool{}
+	)
+
+	entriesToInsert := map[string][]interface{}{}
+	entriesToUpdate := map[string][]mongo.WriteModel{}
+	insertChanges := map[string][]ImportChange{}
+	updateChanges := map[string][]ImportChange{}
+	for _, item := range items {
+		contentType := item.Entry.ContentType
+		content := contents[contentType][item.ContentID]
+
+		switch {
+		case item.Action == UnmatchedImportItemAction:
+			logrus.WithFields(logrus.Fields{
+				"content_type": contentType,
+				"title":        item.Entry.Title,
+			}).Debug("content not found in database, skipping")
+			result.ErrorCount++
+			result.UnmatchedItems = append(result.UnmatchedItems, item)
+			continue
+		case item.Action == NewImportItemAction && remainingCount == 0 && contentType != "manga":
+			result.LimitedCount++
+			result.LimitedTitles = append(result.LimitedTitles, item.Entry.Title)
+			continue
+		case item.Action == NewImportItemAction:
+			// Ids are set before the insert to know the written entries if it fails partway.
+			change := item.importChange(userID, InsertImportChangeAction, writtenAt)
+			change.ListID = primitive.NewObjectID()
+
+			entriesToInsert[contentType] = append(
+				entriesToInsert[contentType],
+				item.Entry.listEntry(change.ListID, userID, item.ContentID, content, writtenAt),
+			)
+			insertChanges[contentType] = append(insertChanges[contentType], change)
+			insertLogs[contentType] = append(insertLogs[contentType], item.Entry.listLog(userID, AddLogAction, item.ContentID, content))
+			result.ImportedCount++
+			result.ImportedTitles = append(result.ImportedTitles, item.Entry.Title)
+
+			// Manga lists are not counted in the limit.
+			if remainingCount > 0 && contentType != "manga" {
+				remainingCount--
+			}
+		case item.Action == ConflictImportItemAction && item.takeTheirs(policy, choices):
+			entriesToUpdate[contentType] = append(
+				entriesToUpdate[contentType],
+				mongo.NewUpdateOneModel().SetFilter(bson.M{"_id": item.Current.ID}).SetUpdate(item.Entry.listUpdate(writtenAt)),
+			)
+			updateChanges[contentType] = append(
+				updateChanges[contentType],
+				item.importChange(userID, UpdateImportChangeAction, writtenAt),
+			)
+			updateLogs[contentType] = append(updateLogs[contentType], item.Entry.listLog(userID, UpdateLogAction, item.ContentID, content))
+			result.UpdatedCount++
+			result.UpdatedTitles = append(result.UpdatedTitles, item.Entry.Title)
+		default:
+			result.SkippedCount++
+			result.SkippedTitles = append(result.SkippedTitles, item.Entry.Title)
+			continue
+		}
+
+		if item.Entry.Status == "finished" && !isFinished[contentType] {
+			isFinished[contentType] = true
+			activityTypes = append(activityTypes, contentType+"_finished")
+		}
+	}
+
+	for contentType, listEntries := range entriesToInsert {
+		if _, err := listWriteModel.getListCollection(contentType).InsertMany(context.TODO(), listEntries); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"user_id":      userID,
+				"content_type": contentType,
+				"count":        len(listEntries),
+			}).Error("failed to bulk insert import entries: ", err)
+
+			changes, logs := listWriteModel.getWrittenChanges(contentType, insertChanges[contentType], insertLogs[contentType], writtenAt)
+			result.Changes = append(result.Changes, changes...)
+			listWriteModel.createWriteLogs(userID, append(writtenLogs, logs...), activityTypes)
+
+			return result, fmt.Errorf("failed to import %s entries: %v", contentType, err)
+		}
+
+		result.Changes = append(result.Changes, insertChanges[contentType]...)
+		writtenLogs = append(writtenLogs, insertLogs[contentType]...)
+	}
+
+	for contentType, updates := range entriesToUpdate {
+		previousEntries, err := listWriteModel.getListEntries(contentType, updateChanges[contentType])
+		if err != nil {
+			listWriteModel.createWriteLogs(userID, writtenLogs, activityTypes)
+
+			return result, fmt.Errorf("failed to get %s entries: %v", contentType, err)
+		}
+
+		for index, change := range updateChanges[contentType] {
+			updateChanges[contentType][index].Previous = previousEntries[change.ListID]
+		}
+
+		if _, err := listWriteModel.getListCollection(contentType).BulkWrite(context.TODO(), updates); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"user_id":      userID,
+				"content_type": contentType,
+				"count":        len(updates),
+			}).Error("failed to bulk update import entries: ", err)
+
+			changes, logs := listWriteModel.getWrittenChanges(contentType, updateChanges[contentType], updateLogs[contentType], writtenAt)
+			result.Changes = append(result.Changes, changes...)
+			listWriteModel.createWriteLogs(userID, append(writtenLogs, logs...), activityTypes)
+
+			return result, fmt.Errorf("failed to update %s entries: %v", contentType, err)
+		}
+
+		result.Changes = append(result.Changes, updateChanges[contentType]...)
+		writtenLogs = append(writtenLogs, updateLogs[contentType]...)
+	}
+
+	listWriteModel.createWriteLogs(userID, writtenLogs, activityTypes)
+
+	result.Message = fmt.Sprintf("Import completed: %d imported, %d updated, %d skipped, %d errors",
+		result.ImportedCount, result.UpdatedCount, result.SkippedCount, result.ErrorCount)
+
+	if result.LimitedCount > 0 {
+		result.Message = fmt.Sprintf("%s. %d entries are not imported since your list reached the limit of %d, upgrade to premium to import them.",
+			result.Message, result.LimitedCount, UserListLimit)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":        userID,
+		"policy":         policy,
+		"imported_count": result.ImportedCount,
+		"updated_count":  result.UpdatedCount,
+		"skipped_count":  result.SkippedCount,
+		"limited_count":  result.LimitedCount,
+		"error_count":    result.ErrorCount,
+	}).Info("list import completed")
+
+	return result, nil
+}
+
+// createWriteLogs creates the logs of the written entries and checks the
+// achievements once.
+func (listWriteModel *ListWriteModel) createWriteLogs(userID string, logs []interface{}, activityTypes []string) {
+	if len(logs) == 0 {
+		return
+	}
+
+	listWriteModel.LogsModel.CreateLogs(userID, logs)
+	listWriteModel.AchievementModel.CheckAndUnlockAchievements(userID, activityTypes...)
+}
+
+// getWrittenChanges returns the changes and logs of the entries that are written
+// by a write that failed partway, written entries have updated_at of the write.
+// Every change is returned if they can't be found, rollback skips the entries
+// that are not written.
+func (listWriteModel *ListWriteModel) getWrittenChanges(
+	contentType string, changes []ImportChange, logs []interface{}, writtenAt time.Time,
+) ([]ImportChange, []interface{}) {
+	listIDs := make(bson.A, 0, len(changes))
+	for _, change := range changes {
+		listIDs = append(listIDs, change.ListID)
+	}
+
+	cursor, err := listWriteModel.getListCollection(contentType).Find(context.TODO(), bson.M{
+		"_id":        bson.M{"$in": listIDs},
+		"updated_at": writtenAt,
+	}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"content_type": contentType,
+		}).Error("failed to find written import entries: ", err)
+
+		return changes, logs
+	}
+
+	var results []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(context.TODO(), &results); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"content_type": contentType,
+		}).Error("failed to decode written import entries: ", err)
+
+		return changes, logs
+	}
+
+	isWritten := make(map[primitive.ObjectID]bool, len(results))
+	for _, result := range results {
+		isWritten[result.ID] = true
+	}
+
+	var (
+		writtenChanges []ImportChange
+		writtenLogs    []interface{}
+	)
+	for index, change := range changes {
+		if isWritten[change.ListID] {
+			writtenChanges = append(writtenChanges, change)
+			writtenLogs = append(writtenLogs, logs[index])
+		}
+	}
+
+	return writtenChanges, writtenLogs
+}
+
+// getRemainingListCount returns how many entries the user can add, -1 if the
+// user is premium.
+func (listWriteModel *ListWriteModel) getRemainingListCount(userID string) (int64, error) {
+	if isPremium, _ := listWriteModel.UserModel.IsUserPremium(userID); isPremium {
+		return -1, nil
+	}
+
+	count, err := listWriteModel.UserListModel.GetUserListCount(userID)
+	if err != nil {
+		return 0, &importTransientError{err: fmt.Errorf("failed to count list entries: %v", err)}
+	}
+
+	return max(UserListLimit-count, 0), nil
+}
+
+func (entry ImportEntry) listEntry(listID primitive.ObjectID, userID, contentID string, content listWriteContent, now time.Time) interface{} {
+	timesFinished := entry.TimesFinished
+	if entry.Status == "finished" && timesFinished == 0 {
+		timesFinished = 1
+	}
+
+	switch entry.ContentType {
+	case "anime":
+		return AnimeList{
+			ID:              listID,
+			UserID:          userID,
+			AnimeID:         contentID,
+			AnimeMALID:      content.MALID,
+			Status:          entry.Status,
+			WatchedEpisodes: entry.Progress,
+			Score:           entry.Score,
+			TimesFinished:   timesFinished,
+			StartedAt:       entry.StartedAt,
+			FinishedAt:      entry.FinishedAt,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		}
+	case "manga":
+		return MangaList{
+			ID:            listID,
+			UserID:        userID,
+			MangaID:       contentID,
+			MangaMALID:    content.MALID,
+			Status:        entry.Status,
+			ReadChapters:  entry.Progress,
+			ReadVolumes:   entry.Volumes,
+			Score:         entry.Score,
+			TimesFinished: timesFinished,
+			StartedAt:     entry.StartedAt,
+			FinishedAt:    entry.FinishedAt,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+	case "game":
+		return GameList{
+			ID:                listID,
+			UserID:            userID,
+			GameID:            contentID,
+			GameRAWGID:        content.RawgID,
+			Status:            entry.Status,
+			Score:             entry.Score,
+			HoursPlayed:       entry.HoursPlayed,
+			AchievementStatus: entry.AchievementStatus,
+			TimesFinished:     timesFinished,
+			StartedAt:         entry.StartedAt,
+			FinishedAt:        entry.FinishedAt,
+			CreatedAt:         now,
+			UpdatedAt:         now,
+		}
+	case "movie":
+		return MovieWatchList{
+			ID:            listID,
+			UserID:        userID,
+			MovieID:       contentID,
+			MovieTmdbID:   content.TmdbID,
+			Status:        entry.Status,
+			Score:         entry.Score,
+			TimesFinished: timesFinished,
+			StartedAt:     entry.StartedAt,
+			FinishedAt:    entry.FinishedAt,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+	}
+
+	return TVSeriesWatchList{
+		ID:              listID,
+		UserID:          userID,
+		TvID:            contentID,
+		TvTmdbID:        content.TmdbID,
+		Status:          entry.Status,
+		WatchedEpisodes: int(entry.Progress),
+		Score:           entry.Score,
+		TimesFinished:   timesFinished,
+		StartedAt:       entry.StartedAt,
+		FinishedAt:      entry.FinishedAt,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+}
+
+// listUpdate replaces the user's entry with the imported one, values the source
+// doesn't have are kept.
+func (entry ImportEntry) listUpdate(now time.Time) bson.M {
+	set := bson.M{
+		"status":     entry.Status,
+		"updated_at": now,
+	}
+
+	if entry.Score != nil {
+		set["score"] = entry.Score
+	}
+
+	if entry.Progress > 0 {
+		switch entry.ContentType {
+		case "anime", "tv":
+			set["watched_episodes"] = entry.Progress
+		case "manga":
+			set["read_chapters"] = entry.Progress
+		}
+	}
+
+	if entry.Volumes > 0 && entry.ContentType == "manga" {
+		set["read_volumes"] = entry.Volumes
+	}
+
+	if entry.HoursPlayed != nil && entry.ContentType == "game" {
+		set["hours_played"] = entry.HoursPlayed
+	}
+
+	if entry.AchievementStatus != nil && entry.ContentType == "game" {
+		set["achievement_status"] = entry.AchievementStatus
+	}
+
+	if entry.StartedAt != nil {
+		set["started_at"] = entry.StartedAt
+	}
+
+	if entry.FinishedAt != nil {
+		set["finished_at"] = entry.FinishedAt
+	}
+
+	update := bson.M{"$set": set}
+
+	timesFinished := entry.TimesFinished
+	if entry.Status == "finished" && timesFinished == 0 {
+		timesFinished = 1
+	}
+
+	if timesFinished > 0 {
+		update["$max"] = bson.M{"times_finished": timesFinished}
+	}
+
+	return update
+}
+
+// importChange records the written item, list id of the inserted entries and
+// previous values of the updated ones are set once they are written.
+func (item ImportItem) importChange(userID, action string, writtenAt time.Time) ImportChange {
+	change := ImportChange{
+		UserID:      userID,
+		ContentType: item.Entry.ContentType,
+		ContentID:   item.ContentID,
+		Title:       item.Entry.Title,
+		Action:      action,
+		WrittenAt:   writtenAt,
+	}
+
+	if item.Current != nil {
+		change.ListID = item.Current.ID
+	}
+
+	return change
+}
+
+// listLog is the log of the written entry, it's created at the time of the
+// activity on the source so that streaks and stats include the history.
+func (entry ImportEntry) listLog(userID, logAction, contentID string, content listWriteContent) *Log {
+	log := createLogObject(
+		userID,
+		UserListLogType,
+		logAction,
+		entry.Status,
+		content.title(entry.ContentType),
+		content.ImageURL,
+		entry.ContentType,
+		contentID,
+	)
+
+	if activityAt := entry.activityAt(); activityAt != nil {
+		log.CreatedAt = activityAt.UTC()
+	}
+
+	return log
+}
+
+// CreateListEntryLogs creates the logs of the entries of a bulk operation with
+// a single write, user list updates have the status of the entry as details.
+func (listWriteModel *ListWriteModel) CreateListEntryLogs(userID, logType, logAction string, entries []ListEntry) {
+	contentIDs := map[string][]string{}
+	for _, entry := range entries {
+		contentIDs[entry.ContentType] = append(contentIDs[entry.ContentType], entry.ContentID)
+	}
+
+	contents := map[string]map[string]listWriteContent{}
+	for contentType, ids := range contentIDs {
+		var err error
+		if contents[contentType], err = listWriteModel.getContents(contentType, ids); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"uid":          userID,
+				"content_type": contentType,
+			}).Error("failed to get contents of logs: ", err)
+		}
+	}
+
+	logs := make([]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		var logActionDetails string
+		if logType == UserListLogType && logAction != DeleteLogAction {
+			logActionDetails = entry.Status
+		}
+
+		content := contents[entry.ContentType][entry.ContentID]
+
+		logs = append(logs, createLogObject(
+			userID,
+			logType,
+			logAction,
+			logActionDetails,
+			content.title(entry.ContentType),
+			content.ImageURL,
+			entry.ContentType,
+			entry.ContentID,
+		))
+	}
+
+	if len(logs) > 0 {
+		listWriteModel.LogsModel.CreateLogs(userID, logs)
+	}
+}
+
+// activityAt is the latest known time of the entry on the source.
+func (entry ImportEntry) activityAt() *time.Time {
+	for _, activityAt := range []*time.Time{entry.FinishedAt, entry.UpdatedAt, entry.StartedAt} {
+		if activityAt != nil && !activityAt.IsZero() {
+			return activityAt
+		}
+	}
+
+	return nil
+}
+
+// listWriteContent is the content of the entry, ids of the content on its source
+// are stored on the list entries.
+type listWriteContent struct {
+	ID            primitive.ObjectID `bson:"_id"`
+	MALID         int64              `bson:"mal_id"`
+	RawgID        int64              `bson:"rawg_id"`
+	TmdbID        string             `bson:"tmdb_id"`
+	Title         string             `bson:"title"`
+	TitleEn       string             `bson:"title_en"`
+	TitleOriginal string             `bson:"title_original"`
+	ImageURL      string             `bson:"image_url"`
+}
+
+// title is the title that the list endpoints log for the content type.
+func (content listWriteContent) title(contentType string) string {
+	switch contentType {
+	case "anime", "manga":
+		return content.TitleOriginal
+	case "game":
+		return content.Title
+	}
+
+	return content.TitleEn
+}
+
+func (listWriteModel *ListWriteModel) getContents(contentType string, contentIDs []string) (map[string]listWriteContent, error) {
+	contents := map[string]listWriteContent{}
+	if len(contentIDs) == 0 {
+		return contents, nil
+	}
+
+	objectIDs := bson.A{}
+	for _, contentID := range contentIDs {
+		if objectID, err := primitive.ObjectIDFromHex(contentID); err == nil {
+			objectIDs = append(objectIDs, objectID)
+		}
+	}
+
+	cursor, err := listWriteModel.getContentCollection(contentType).Find(context.TODO(), bson.M{
+		"_id": bson.M{"$in": objectIDs},
+	}, options.Find().SetProjection(bson.M{
+		"_id":            1,
+		"mal_id":         1,
+		"rawg_id":        1,
+		"tmdb_id":        1,
+		"title":          1,
+		"title_en":       1,
+		"title_original": 1,
+		"image_url":      1,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	var results []listWriteContent
+	if err := cursor.All(context.TODO(), &results); err != nil {
+		return nil, err
+	}
+
+	for _, content := range results {
+		contents[content.ID.Hex()] = content
+	}
+
+	return contents, nil
+}
+
+// getListEntries returns the list entries of the changes as they are, so that
+// they can be restored.
+func (listWriteModel *ListWriteModel) getListEntries(contentType string, changes []ImportChange) (map[primitive.ObjectID]bson.M, error) {
+	listIDs := make(bson.A, 0, len(changes))
+	for _, change := range changes {
+		listIDs = append(listIDs, change.ListID)
+	}
+
+	cursor, err := listWriteModel.getListCollection(contentType).Find(context.TODO(), bson.M{
+		"_id": bson.M{"$in": listIDs},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var results []bson.M
+	if err := cursor.All(context.TODO(), &results); err != nil {
+		return nil, err
+	}
+
+	listEntries := make(map[primitive.ObjectID]bson.M, len(results))
+	for _, listEntry := range results {
+		if listID, ok := listEntry["_id"].(primitive.ObjectID); ok {
+			listEntries[listID] = listEntry
+		}
+	}
+
+	return listEntries, nil
+}
+
+func (listWriteModel *ListWriteModel) getContentCollection(contentType string) *mongo.Collection {
+	switch contentType {
+	case "anime":
+		return listWriteModel.AnimeCollection
+	case "manga":
+		return listWriteModel.MangaCollection
+	case "game":
+		return listWriteModel.GameCollection
+	case "movie":
+		return listWriteModel.MovieCollection
+	}
+
+	return listWriteModel.TVCollection
+}
+
+func (listWriteModel *ListWriteModel) getListCollection(contentType string) *mongo.Collection {
+	switch contentType {
+	case "anime":
+		return listWriteModel.AnimeListCollection
+	case "manga":
+		return listWriteModel.MangaListCollection
+	case "game":
+		return listWriteModel.GameListCollection
+	case "movie":
+		return listWriteModel.MovieListCollection
+	}
+
+	return listWriteModel.TVListCollection
+}
@@ -87,6 +87,17 @@ func (logsModel *LogsModel) CreateLog(uid string, data requests.CreateLog) {
 	}
 }
 
+// CreateLogs creates the logs of bulk writes e.g. imports, logs keep their own
+// creation date.
+func (logsModel *LogsModel) CreateLogs(uid string, logs []interface{}) {
+	if _, err := logsModel.LogsCollection.InsertMany(context.TODO(), logs); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid":   uid,
+			"count": len(logs),
+		}).Error("failed to create logs: ", err)
+	}
+}
+
 func (logsModel *LogsModel) MostLikedGenresByLogs(uid string) ([]responses.MostLikedGenres, error) {
 	match := bson.M{"$match": bson.M{
 		"user_id":            uid,
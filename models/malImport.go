@@ -20,17 +20,20 @@ import (
 //lint:file-ignore ST1005 Ignore all
 
 type MALImportModel struct {
-	AnimeCollection     *mongo.Collection
-	AnimeListCollection *mongo.Collection
+	AnimeCollection *mongo.Collection
+	MangaCollection *mongo.Collection
 }
 
 func NewMALImportModel(mongoDB *db.MongoDB) *MALImportModel {
 	return &MALImportModel{
-		AnimeCollection:     mongoDB.Database.Collection("animes"),
-		AnimeListCollection: mongoDB.Database.Collection("anime-lists"),
+		AnimeCollection: mongoDB.Database.Collection("animes"),
+		MangaCollection: mongoDB.Database.Collection("mangas"),
 	}
 }
 
+// MAL returns at most this many entries per page of the manga list.
+const malMangaListPageSize = 300
+
 type MALAPIResponse struct {
 	Data []struct {
 		Node struct {
@@ -52,110 +55,66 @@ type MALAPIResponse struct {
 	} `json:"paging"`
 }
 
-func (m *MALImportModel) ImportUserAnimeList(userID, malUsername string) (responses.MALImportResponse, error) {
-	// Fetch anime list from MAL
+func (m *MALImportModel) FetchAnimeListEntries(malUsername string) ([]*ImportEntry, error) {
 	animeEntries, err := m.fetchMALAnimeList(malUsername)
 	if err != nil {
-		return responses.MALImportResponse{}, err
+		return nil, err
 	}
 
 	logrus.WithFields(logrus.Fields{
-		"user_id":       userID,
 		"mal_username":  malUsername,
 		"total_entries": len(animeEntries),
 	}).Info("Starting MAL import")
 
-	// Get existing anime entries in bulk to avoid repeated database calls
-	existingEntries, err := m.getExistingAnimeEntries(userID)
-	if err != nil {
-		return responses.MALImportResponse{}, fmt.Errorf("failed to get existing entries: %v", err)
+	malEntries := newImportEntries()
+	for _, entry := range animeEntries {
+		malEntries.add(ImportEntry{
+			ContentType: "anime",
+			Title:       entry.Title,
+			MALID:       int64(entry.ID),
+			Status:      entry.Status,
+			Score:       malImportScore(entry.Score),
+			Progress:    int64(entry.WatchedEpisodes),
+		})
 	}
 
-	// Get all anime MAL IDs from our database in bulk
-	animeMALIDs, err := m.getAllAnimeMALIDs()
+	return malEntries.entries, nil
+}
+
+func (m *MALImportModel) FetchMangaListEntries(malUsername string) ([]*ImportEntry, error) {
+	mangaEntries, err := m.fetchMALMangaList(malUsername)
 	if err != nil {
-		return responses.MALImportResponse{}, fmt.Errorf("failed to get anime MAL IDs: %v", err)
+		return nil, err
 	}
 
-	var importedCount, skippedCount, errorCount int
-	var entriesToInsert []interface{}
-	var importedTitles, skippedTitles []string
-
-	// Process entries in batches
-	for _, entry := range animeEntries {
-		// Check if anime exists in our database
-		animeObjectID, exists := animeMALIDs[int64(entry.ID)]
-		if !exists {
-			logrus.WithFields(logrus.Fields{
-				"mal_id": entry.ID,
-				"title":  entry.Title,
-			}).Debug("anime not found in database, skipping")
-			errorCount++
-			continue
-		}
-
-		// Check if already exists in user's list
-		if _, exists := existingEntries[int64(entry.ID)]; exists {
-			skippedCount++
-			skippedTitles = append(skippedTitles, entry.Title)
-			continue
-		}
-
-		// Convert score to float32 pointer
-		var score *float32
-		if entry.Score != nil {
-			scoreFloat := float32(*entry.Score)
-			score = &scoreFloat
-		}
-
-		// Create anime list entry for batch insert
-		animeListEntry := AnimeList{
-			UserID:          userID,
-			AnimeID:         animeObjectID,
-			AnimeMALID:      int64(entry.ID),
-			Status:          entry.Status,
-			WatchedEpisodes: int64(entry.WatchedEpisodes),
-			Score:           score,
-			TimesFinished:   entry.TimesFinished,
-			CreatedAt:       time.Now().UTC(),
-			UpdatedAt:       time.Now().UTC(),
-		}
-
-		entriesToInsert = append(entriesToInsert, animeListEntry)
-		importedCount++
-		importedTitles = append(importedTitles, entry.Title)
+	logrus.WithFields(logrus.Fields{
+		"mal_username":  malUsername,
+		"total_entries": len(mangaEntries),
+	}).Info("Starting MAL manga import")
+
+	malEntries := newImportEntries()
+	for _, entry := range mangaEntries {
+		malEntries.add(ImportEntry{
+			ContentType: "manga",
+			Title:       entry.Title,
+			MALID:       int64(entry.ID),
+			Status:      entry.Status,
+			Score:       malImportScore(entry.Score),
+			Progress:    int64(entry.ReadChapters),
+			Volumes:     int64(entry.ReadVolumes),
+		})
 	}
 
-	// Bulk insert all entries at once
-	if len(entriesToInsert) > 0 {
-		_, err = m.AnimeListCollection.InsertMany(context.TODO(), entriesToInsert)
-		if err != nil {
-			logrus.WithFields(logrus.Fields{
-				"user_id": userID,
-				"count":   len(entriesToInsert),
-			}).Error("failed to bulk insert anime entries: ", err)
-			return responses.MALImportResponse{}, fmt.Errorf("failed to import anime entries: %v", err)
-		}
-	}
+	return malEntries.entries, nil
+}
 
-	message := fmt.Sprintf("Import completed: %d imported, %d skipped, %d errors",
-		importedCount, skippedCount, errorCount)
+func malImportScore(score *int) *float32 {
+	if score == nil {
+		return nil
+	}
 
-	logrus.WithFields(logrus.Fields{
-		"user_id":        userID,
-		"imported_count": importedCount,
-		"skipped_count":  skippedCount,
-		"error_count":    errorCount,
-	}).Info("MAL import completed")
-
-	return responses.MALImportResponse{
-		ImportedCount:  importedCount,
-		SkippedCount:   skippedCount,
-		ErrorCount:     errorCount,
-		Message:        message,
-		ImportedTitles: importedTitles,
-		SkippedTitles:  skippedTitles,
-	}, nil
+	scoreFloat := float32(*score)
+	return &scoreFloat
 }
 
 func (m *MALImportModel) fetchMALAnimeList(username string) ([]responses.MALAnimeEntry, error) {
@@ -178,7 +137,7 @@ func (m *MALImportModel) fetchMALAnimeList(username string) ([]responses.MALAnim
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch anime list: %v", err)
+		return nil, importRequestError("failed to fetch anime list", err)
 	}
 	defer resp.Body.Close()
 
@@ -187,7 +146,7 @@ func (m *MALImportModel) fetchMALAnimeList(username string) ([]responses.MALAnim
 	}
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, importStatusError("unexpected status code", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -243,7 +202,7 @@ func (m *MALImportModel) fetchMALAnimeList(username string) ([]responses.MALAnim
 			case 4:
 				entry.Status = "dropped" // Dropped
 			case 6:
-				entry.Status = "active" // Plan to Watch (map to active)
+				entry.Status = "planto" // Plan to Watch
 			default:
 				entry.Status = "active"
 			}
@@ -275,28 +234,102 @@ func (m *MALImportModel) fetchMALAnimeList(username string) ([]responses.MALAnim
 	return animeEntries, nil
 }
 
-// getExistingAnimeEntries gets all existing anime entries for a user in bulk
-func (m *MALImportModel) getExistingAnimeEntries(userID string) (map[int64]bool, error) {
-	cursor, err := m.AnimeListCollection.Find(context.TODO(), bson.M{
-		"user_id": userID,
-	}, options.Find().SetProjection(bson.M{"anime_mal_id": 1}))
-	if err != nil {
-		return nil, err
+func (m *MALImportModel) fetchMALMangaList(username string) ([]responses.MALMangaEntry, error) {
+	client := &http.Client{
+		Timeout: 30 * time.Second,
 	}
-	defer cursor.Close(context.TODO())
 
-	existingEntries := make(map[int64]bool)
-	for cursor.Next(context.TODO()) {
-		var entry struct {
-			AnimeMALID int64 `bson:"anime_mal_id"`
+	var mangaEntries []responses.MALMangaEntry
+	for offset := 0; ; offset += malMangaListPageSize {
+		url := fmt.Sprintf("https://myanimelist.net/mangalist/%s/load.json?offset=%d", username, offset)
+
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %v", err)
 		}
-		if err := cursor.Decode(&entry); err != nil {
-			continue
+
+		req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+		req.Header.Set("Accept", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, importRequestError("failed to fetch manga list", err)
+		}
+
+		if resp.StatusCode == 404 {
+			resp.Body.Close()
+			return nil, fmt.Errorf("user not found or manga list is private")
+		}
+
+		if resp.StatusCode != 200 {
+			resp.Body.Close()
+			return nil, importStatusError("unexpected status code", resp.StatusCode)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %v", err)
+		}
+
+		var malData []map[string]interface{}
+		if err := json.Unmarshal(body, &malData); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON response: %v", err)
+		}
+
+		for _, item := range malData {
+			mangaID, ok := item["manga_id"].(float64)
+			if !ok {
+				continue
+			}
+
+			var title string
+			if titleVal, exists := item["manga_title"]; exists {
+				if titleStr, ok := titleVal.(string); ok {
+					title = titleStr
+				} else {
+					title = fmt.Sprintf("%v", titleVal)
+				}
+			}
+
+			readChapters, _ := item["num_read_chapters"].(float64)
+			readVolumes, _ := item["num_read_volumes"].(float64)
+
+			entry := responses.MALMangaEntry{
+				ID:           int(mangaID),
+				Title:        title,
+				ReadChapters: int(readChapters),
+				ReadVolumes:  int(readVolumes),
+			}
+
+			// Map MAL status to our status, on-hold is active
+			entry.Status = "active"
+			if statusVal, ok := item["status"].(float64); ok {
+				switch int(statusVal) {
+				case 2:
+					entry.Status = "finished" // Completed
+				case 4:
+					entry.Status = "dropped" // Dropped
+				case 6:
+					entry.Status = "planto" // Plan to Read
+				}
+			}
+
+			// Handle score (0 means no score)
+			if score, ok := item["score"].(float64); ok && score > 0 {
+				scoreInt := int(score)
+				entry.Score = &scoreInt
+			}
+
+			mangaEntries = append(mangaEntries, entry)
+		}
+
+		if len(malData) < malMangaListPageSize {
+			break
 		}
-		existingEntries[entry.AnimeMALID] = true
 	}
 
-	return existingEntries, nil
+	return mangaEntries, nil
 }
 
 // getAllAnimeMALIDs gets all anime MAL IDs from our database in bulk
@@ -323,3 +356,28 @@ func (m *MALImportModel) getAllAnimeMALIDs() (map[int64]string, error) {
 
 	return animeMALIDs, nil
 }
+
+// getAllMangaMALIDs gets all manga MAL IDs from our database in bulk
+func (m *MALImportModel) getAllMangaMALIDs() (map[int64]string, error) {
+	cursor, err := m.MangaCollection.Find(context.TODO(), bson.M{
+		"mal_id": bson.M{"$exists": true, "$ne": nil},
+	}, options.Find().SetProjection(bson.M{"_id": 1, "mal_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.TODO())
+
+	mangaMALIDs := make(map[int64]string)
+	for cursor.Next(context.TODO()) {
+		var manga struct {
+			ID    primitive.ObjectID `bson:"_id"`
+			MALID int64              `bson:"mal_id"`
+		}
+		if err := cursor.Decode(&manga); err != nil {
+			continue
+		}
+		mangaMALIDs[manga.MALID] = manga.ID.Hex()
+	}
+
+	return mangaMALIDs, nil
+}
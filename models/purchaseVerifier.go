@@ -0,0 +1,526 @@
+package models
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+//lint:file-ignore ST1005 Ignore all
+
+const (
+	AppStorePurchaseStore  = "app_store"
+	PlayStorePurchaseStore = "play_store"
+)
+
+const (
+	appStoreVerifyURL        = "https://buy.itunes.apple.com/verifyReceipt"
+	appStoreSandboxVerifyURL = "https://sandbox.itunes.apple.com/verifyReceipt"
+	// Receipts of TestFlight and the sandbox are sent to production first.
+	appStoreSandboxReceiptStatus = 21007
+	playStoreAPIURL              = "https://androidpublisher.googleapis.com/androidpublisher/v3/applications/"
+	playStoreScope               = "https://www.googleapis.com/auth/androidpublisher"
+)
+
+// VerifiedPurchase is the purchase as the store knows it, expires at is nil
+// for the purchases that don't expire.
+type VerifiedPurchase struct {
+	ProductID     string
+	TransactionID string
+	ExpiresAt     *time.Time
+	RefundedAt    *time.Time
+}
+
+// PurchaseVerifier verifies the purchase token with its store, invalid tokens
+// return an error that IsInvalidPurchaseError reports.
+type PurchaseVerifier interface {
+	VerifyPurchase(productID, purchaseToken string, isSubscription bool) (VerifiedPurchase, error)
+}
+
+var (
+	purchaseVerifiers     = map[string]PurchaseVerifier{}
+	purchaseVerifiersLock sync.RWMutex
+)
+
+// SetPurchaseVerifier replaces the verifier of the store, e.g. with the stub.
+func SetPurchaseVerifier(store string, verifier PurchaseVerifier) {
+	purchaseVerifiersLock.Lock()
+	defer purchaseVerifiersLock.Unlock()
+
+	purchaseVerifiers[store] = verifier
+}
+
+// getPurchaseVerifier returns the verifier of the store, the stub is used out
+// of production if PURCHASE_VERIFIER is "stub".
+func getPurchaseVerifier(store string) PurchaseVerifier {
+	purchaseVerifiersLock.RLock()
+	verifier, ok := purchaseVerifiers[store]
+	purchaseVerifiersLock.RUnlock()
+
+	if ok {
+		return verifier
+	}
+
+	if os.Getenv("ENV") != "Production" && os.Getenv("PURCHASE_VERIFIER") == "stub" {
+		return &StubPurchaseVerifier{}
+	}
+
+	if store == AppStorePurchaseStore {
+		return appStoreVerifier{}
+	}
+
+	return defaultPlayStoreVerifier
+}
+
+type purchaseInvalidError struct {
+	err error
+}
+
+func (invalidError *purchaseInvalidError) Error() string {
+	return invalidError.err.Error()
+}
+
+func invalidPurchaseError(message string, args ...interface{}) error {
+	return &purchaseInvalidError{err: fmt.Errorf(message, args...)}
+}
+
+func IsInvalidPurchaseError(err error) bool {
+	var invalidError *purchaseInvalidError
+	return errors.As(err, &invalidError)
+}
+
+// StubPurchaseVerifier accepts every token as a purchase that expires in a
+// month, it's for local development and tests. Purchases can be set by token.
+type StubPurchaseVerifier struct {
+	Purchases map[string]VerifiedPurchase
+}
+
+func (stubVerifier *StubPurchaseVerifier) VerifyPurchase(productID, purchaseToken string, isSubscription bool) (VerifiedPurchase, error) {
+	if purchase, ok := stubVerifier.Purchases[purchaseToken]; ok {
+		return purchase, nil
+	}
+
+	if purchaseToken == "invalid" {
+		return VerifiedPurchase{}, invalidPurchaseError("Purchase is not valid.")
+	}
+
+	purchase := VerifiedPurchase{
+		ProductID:     productID,
+		TransactionID: purchaseToken,
+	}
+
+	if isSubscription {
+		expiresAt := time.Now().UTC().AddDate(0, 1, 0)
+		purchase.ExpiresAt = &expiresAt
+	}
+
+	return purchase, nil
+}
+
+// appStoreVerifier verifies the receipt of the app, receipt has every purchase
+// of the user and the latest renewal of the subscriptions.
+type appStoreVerifier struct{}
+
+type appStoreReceiptResponse struct {
+	Status      int    `json:"status"`
+	Environment string `json:"environment"`
+	Receipt     struct {
+		BundleID string                `json:"bundle_id"`
+		InApp    []appStoreTransaction `json:"in_app"`
+	} `json:"receipt"`
+	LatestReceiptInfo []appStoreTransaction `json:"latest_receipt_info"`
+}
+
+type appStoreTransaction struct {
+	ProductID             string `json:"product_id"`
+	OriginalTransactionID string `json:"original_transaction_id"`
+	ExpiresDateMS         string `json:"expires_date_ms"`
+	CancellationDateMS    string `json:"cancellation_date_ms"`
+}
+
+func (verifier appStoreVerifier) VerifyPurchase(productID, purchaseToken string, isSubscription bool) (VerifiedPurchase, error) {
+	bundleID := os.Getenv("APP_STORE_BUNDLE_ID")
+	if bundleID == "" {
+		return VerifiedPurchase{}, fmt.Errorf("APP_STORE_BUNDLE_ID is not set")
+	}
+
+	isSandbox := false
+	receipt, err := verifier.verifyReceipt(appStoreVerifyURL, purchaseToken)
+	if err == nil && receipt.Status == appStoreSandboxReceiptStatus {
+		isSandbox = true
+		receipt, err = verifier.verifyReceipt(appStoreSandboxVerifyURL, purchaseToken)
+	}
+
+	if err != nil {
+		return VerifiedPurchase{}, err
+	}
+
+	if receipt.Status != 0 {
+		return VerifiedPurchase{}, invalidPurchaseError("Receipt is not valid, status %d.", receipt.Status)
+	}
+
+	// Receipts of the other apps are valid too, they must not grant premium.
+	if receipt.Receipt.BundleID != bundleID {
+		return VerifiedPurchase{}, invalidPurchaseError("Receipt is not of this app.")
+	}
+
+	if (isSandbox || receipt.Environment == "Sandbox") && !isAppStoreSandboxAllowed() {
+		return VerifiedPurchase{}, invalidPurchaseError("Sandbox receipts are not accepted.")
+	}
+
+	var (
+		purchase VerifiedPurchase
+		isFound  bool
+	)
+
+	// Renewals are separate transactions, the one that expires last is used.
+	for _, transaction := range append(receipt.LatestReceiptInfo, receipt.Receipt.InApp...) {
+		if transaction.ProductID != productID {
+			continue
+		}
+
+		expiresAt := parseMillisecondTime(transaction.ExpiresDateMS)
+		if isFound && (expiresAt == nil || purchase.ExpiresAt == nil || !expiresAt.After(*purchase.ExpiresAt)) {
+			continue
+		}
+
+		isFound = true
+		purchase = VerifiedPurchase{
+			ProductID:     transaction.ProductID,
+			TransactionID: transaction.OriginalTransactionID,
+			ExpiresAt:     expiresAt,
+			RefundedAt:    parseMillisecondTime(transaction.CancellationDateMS),
+		}
+	}
+
+	if !isFound {
+		return VerifiedPurchase{}, invalidPurchaseError("Product is not found in the receipt.")
+	}
+
+	if isSubscription && purchase.ExpiresAt == nil {
+		return VerifiedPurchase{}, invalidPurchaseError("Product is not a subscription.")
+	}
+
+	return purchase, nil
+}
+
+// isAppStoreSandboxAllowed reports whether the sandbox purchases grant premium,
+// production accepts them only if APP_STORE_ALLOW_SANDBOX is set for the App
+// Review and TestFlight builds.
+func isAppStoreSandboxAllowed() bool {
+	return os.Getenv("ENV") != "Production" || os.Getenv("APP_STORE_ALLOW_SANDBOX") == "true"
+}
+
+func (verifier appStoreVerifier) verifyReceipt(verifyURL, receiptData string) (appStoreReceiptResponse, error) {
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"receipt-data":             receiptData,
+		"password":                 os.Getenv("APP_STORE_SHARED_SECRET"),
+		"exclude-old-transactions": true,
+	})
+	if err != nil {
+		return appStoreReceiptResponse{}, err
+	}
+
+	body, statusCode, err := purchaseRequest(http.MethodPost, verifyURL, "application/json", "", bytes.NewReader(jsonData))
+	if err != nil {
+		return appStoreReceiptResponse{}, err
+	}
+
+	if statusCode != http.StatusOK {
+		return appStoreReceiptResponse{}, fmt.Errorf("App Store returned status code: %d", statusCode)
+	}
+
+	var receipt appStoreReceiptResponse
+	if err := json.Unmarshal(body, &receipt); err != nil {
+		return appStoreReceiptResponse{}, fmt.Errorf("failed to decode receipt: %v", err)
+	}
+
+	return receipt, nil
+}
+
+// playStoreVerifier verifies the purchase token with the Play Developer API,
+// it's authorized with the service account in PLAY_STORE_SERVICE_ACCOUNT.
+type playStoreVerifier struct {
+	lock        sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+var defaultPlayStoreVerifier = &playStoreVerifier{}
+
+type playStoreServiceAccount struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+type playStorePurchase struct {
+	OrderID              string `json:"orderId"`
+	ExpiryTimeMillis     string `json:"expiryTimeMillis"`
+	PurchaseState        *int   `json:"purchaseState"`
+	AcknowledgementState int    `json:"acknowledgementState"`
+}
+
+func (verifier *playStoreVerifier) VerifyPurchase(productID, purchaseToken string, isSubscription bool) (VerifiedPurchase, error) {
+	accessToken, err := verifier.getAccessToken()
+	if err != nil {
+		return VerifiedPurchase{}, err
+	}
+
+	purchaseType := "products"
+	if isSubscription {
+		purchaseType = "subscriptions"
+	}
+
+	purchaseURL := playStoreAPIURL + url.PathEscape(os.Getenv("PLAY_STORE_PACKAGE_NAME")) +
+		"/purchases/" + purchaseType + "/" + url.PathEscape(productID) + "/tokens/" + url.PathEscape(purchaseToken)
+
+	body, statusCode, err := purchaseRequest(http.MethodGet, purchaseURL, "", accessToken, nil)
+	if err != nil {
+		return VerifiedPurchase{}, err
+	}
+
+	switch {
+	case statusCode == http.StatusBadRequest, statusCode == http.StatusNotFound, statusCode == http.StatusGone:
+		return VerifiedPurchase{}, invalidPurchaseError("Purchase token is not valid.")
+	case statusCode != http.StatusOK:
+		return VerifiedPurchase{}, fmt.Errorf("Play Store returned status code: %d", statusCode)
+	}
+
+	var storePurchase playStorePurchase
+	if err := json.Unmarshal(body, &storePurchase); err != nil {
+		return VerifiedPurchase{}, fmt.Errorf("failed to decode purchase: %v", err)
+	}
+
+	// Renewals keep the token, so the token identifies the purchase.
+	purchase := VerifiedPurchase{
+		ProductID:     productID,
+		TransactionID: purchaseToken,
+		ExpiresAt:     parseMillisecondTime(storePurchase.ExpiryTimeMillis),
+	}
+
+	if isSubscription && purchase.ExpiresAt == nil {
+		return VerifiedPurchase{}, invalidPurchaseError("Product is not a subscription.")
+	}
+
+	// Refunded products are cancelled, 2 is pending and not paid yet.
+	if storePurchase.PurchaseState != nil {
+		switch *storePurchase.PurchaseState {
+		case 1:
+			now := time.Now().UTC()
+			purchase.RefundedAt = &now
+		case 2:
+			return VerifiedPurchase{}, invalidPurchaseError("Purchase is pending.")
+		}
+	}
+
+	// Purchases that are not acknowledged in three days are refunded by Google.
+	if storePurchase.AcknowledgementState == 0 && purchase.RefundedAt == nil {
+		if _, _, err := purchaseRequest(http.MethodPost, purchaseURL+":acknowledge", "application/json", accessToken, nil); err != nil {
+			return VerifiedPurchase{}, err
+		}
+	}
+
+	return purchase, nil
+}
+
+func (verifier *playStoreVerifier) getAccessToken() (string, error) {
+	verifier.lock.Lock()
+	defer verifier.lock.Unlock()
+
+	if verifier.accessToken != "" && time.Now().Before(verifier.expiresAt) {
+		return verifier.accessToken, nil
+	}
+
+	var serviceAccount playStoreServiceAccount
+	if err := json.Unmarshal([]byte(os.Getenv("PLAY_STORE_SERVICE_ACCOUNT")), &serviceAccount); err != nil {
+		return "", fmt.Errorf("failed to read service account: %v", err)
+	}
+
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(serviceAccount.PrivateKey))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse service account key: %v", err)
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   serviceAccount.ClientEmail,
+		"scope": playStoreScope,
+		"aud":   serviceAccount.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign service account assertion: %v", err)
+	}
+
+	body, statusCode, err := purchaseRequest(http.MethodPost, serviceAccount.TokenURI, "application/x-www-form-urlencoded", "", bytes.NewBufferString(url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}.Encode()))
+	if err != nil {
+		return "", err
+	}
+
+	if statusCode != http.StatusOK {
+		return "", fmt.Errorf("Google returned status code: %d", statusCode)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", fmt.Errorf("failed to decode access token: %v", err)
+	}
+
+	verifier.accessToken = token.AccessToken
+	verifier.expiresAt = now.Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+
+	return verifier.accessToken, nil
+}
+
+func purchaseRequest(method, requestURL, contentType, accessToken string, requestBody io.Reader) ([]byte, int, error) {
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	req, err := http.NewRequest(method, requestURL, requestBody)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+
+	return body, resp.StatusCode, nil
+}
+
+func parseMillisecondTime(milliseconds string) *time.Time {
+	if milliseconds == "" {
+		return nil
+	}
+
+	value, err := strconv.ParseInt(milliseconds, 10, 64)
+	if err != nil || value == 0 {
+		return nil
+	}
+
+	parsedTime := time.UnixMilli(value).UTC()
+	return &parsedTime
+}
+
+// ParseAppStoreNotification returns the original transaction id of the App
+// Store Server Notification. Signed payload isn't verified since the purchase
+// is verified again with the App Store.
+func ParseAppStoreNotification(payload []byte) (string, error) {
+	var notification struct {
+		SignedPayload string `json:"signedPayload"`
+	}
+	if err := json.Unmarshal(payload, &notification); err != nil {
+		return "", invalidPurchaseError("Invalid notification: %v", err)
+	}
+
+	var notificationPayload struct {
+		Data struct {
+			SignedTransactionInfo string `json:"signedTransactionInfo"`
+		} `json:"data"`
+	}
+	if err := decodeJWSPayload(notification.SignedPayload, &notificationPayload); err != nil {
+		return "", err
+	}
+
+	var transaction struct {
+		OriginalTransactionID string `json:"originalTransactionId"`
+	}
+	if err := decodeJWSPayload(notificationPayload.Data.SignedTransactionInfo, &transaction); err != nil {
+		return "", err
+	}
+
+	return transaction.OriginalTransactionID, nil
+}
+
+// ParsePlayStoreNotification returns the purchase token of the Real-time
+// Developer Notification that Pub/Sub pushes.
+func ParsePlayStoreNotification(payload []byte) (string, error) {
+	var message struct {
+		Message struct {
+			Data []byte `json:"data"`
+		} `json:"message"`
+	}
+	if err := json.Unmarshal(payload, &message); err != nil {
+		return "", invalidPurchaseError("Invalid notification: %v", err)
+	}
+
+	var notification struct {
+		SubscriptionNotification *struct {
+			PurchaseToken string `json:"purchaseToken"`
+		} `json:"subscriptionNotification"`
+		OneTimeProductNotification *struct {
+			PurchaseToken string `json:"purchaseToken"`
+		} `json:"oneTimeProductNotification"`
+		VoidedPurchaseNotification *struct {
+			PurchaseToken string `json:"purchaseToken"`
+		} `json:"voidedPurchaseNotification"`
+	}
+	if err := json.Unmarshal(message.Message.Data, &notification); err != nil {
+		return "", invalidPurchaseError("Invalid notification: %v", err)
+	}
+
+	switch {
+	case notification.SubscriptionNotification != nil:
+		return notification.SubscriptionNotification.PurchaseToken, nil
+	case notification.OneTimeProductNotification != nil:
+		return notification.OneTimeProductNotification.PurchaseToken, nil
+	case notification.VoidedPurchaseNotification != nil:
+		return notification.VoidedPurchaseNotification.PurchaseToken, nil
+	}
+
+	// Test notifications don't have a purchase.
+	return "", nil
+}
+
+func decodeJWSPayload(jws string, payload interface{}) error {
+	parts := strings.Split(jws, ".")
+	if len(parts) != 3 {
+		return invalidPurchaseError("Invalid signed payload.")
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return invalidPurchaseError("Invalid signed payload: %v", err)
+	}
+
+	if err := json.Unmarshal(data, payload); err != nil {
+		return invalidPurchaseError("Invalid signed payload: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,333 @@
+package models
+
+import (
+	"app/db"
+	"app/responses"
+	"app/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+//lint:file-ignore ST1005 Ignore all
+
+// ScrobbleModel keeps the webhook tokens of the users and resolves the played
+// items of the media servers.
+type ScrobbleModel struct {
+	ScrobbleTokenCollection *mongo.Collection
+	MovieCollection         *mongo.Collection
+	TVCollection            *mongo.Collection
+}
+
+func NewScrobbleModel(mongoDB *db.MongoDB) *ScrobbleModel {
+	return &ScrobbleModel{
+		ScrobbleTokenCollection: mongoDB.Database.Collection("scrobble-tokens"),
+		MovieCollection:         mongoDB.Database.Collection("movies"),
+		TVCollection:            mongoDB.Database.Collection("tv-series"),
+	}
+}
+
+const (
+	PlexScrobbleSource     = "plex"
+	JellyfinScrobbleSource = "jellyfin"
+	EmbyScrobbleSource     = "emby"
+)
+
+// ScrobbleToken is the secret of the user's webhook urls, only its hash is
+// stored.
+type ScrobbleToken struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"_id"`
+	UserID     string             `bson:"user_id" json:"user_id"`
+	TokenHash  string             `bson:"token_hash" json:"-"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	LastUsedAt *time.Time         `bson:"last_used_at" json:"last_used_at"`
+}
+
+// Scrobble is the played item of a media server, ids are the ids of the movie
+// or the show.
+type Scrobble struct {
+	IsFinished    bool
+	ContentType   string
+	Title         string
+	Year          int
+	TmdbID        string
+	IMDBID        string
+	TVDBID        string
+	SeasonNumber  int
+	EpisodeNumber int
+}
+
+// ! Create
+// CreateScrobbleToken generates a new token for the user, the previous token
+// stops working.
+func (scrobbleModel *ScrobbleModel) CreateScrobbleToken(uid string) (string, error) {
+	token := utils.GenerateToken()
+
+	if _, err := scrobbleModel.ScrobbleTokenCollection.UpdateOne(context.TODO(), bson.M{
+		"user_id": uid,
+	}, bson.M{"$set": bson.M{
+		"token_hash":   utils.HashToken(token),
+		"created_at":   time.Now().UTC(),
+		"last_used_at": nil,
+	}}, options.Update().SetUpsert(true)); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to create scrobble token: ", err)
+
+		return "", fmt.Errorf("Failed to create scrobble token.")
+	}
+
+	return token, nil
+}
+
+// ! Parse
+// ParseScrobble converts the webhook payload of the source, events other than
+// finished playbacks are not finished.
+func ParseScrobble(source string, payload []byte) (Scrobble, error) {
+	var scrobble Scrobble
+
+	switch source {
+	case PlexScrobbleSource:
+		var webhook responses.PlexWebhook
+		if err := json.Unmarshal(payload, &webhook); err != nil {
+			return Scrobble{}, fmt.Errorf("Invalid Plex payload.")
+		}
+
+		scrobble = Scrobble{
+			IsFinished:    webhook.Event == "media.scrobble",
+			ContentType:   webhook.Metadata.Type,
+			Title:         webhook.Metadata.Title,
+			Year:          webhook.Metadata.Year,
+			SeasonNumber:  webhook.Metadata.ParentIndex,
+			EpisodeNumber: webhook.Metadata.Index,
+		}
+
+		if scrobble.ContentType == "episode" {
+			scrobble = scrobble.withSeries(webhook.Metadata.GrandparentTitle, "", "", "")
+			scrobble.setPlexID(webhook.Metadata.GrandparentGUID)
+		} else {
+			for _, guid := range webhook.Metadata.Guid {
+				scrobble.setPlexID(guid.ID)
+			}
+		}
+	case JellyfinScrobbleSource:
+		var webhook responses.JellyfinWebhook
+		if err := json.Unmarshal(payload, &webhook); err != nil {
+			return Scrobble{}, fmt.Errorf("Invalid Jellyfin payload.")
+		}
+
+		scrobble = Scrobble{
+			IsFinished:    webhook.NotificationType == "PlaybackStop" && webhook.PlayedToCompletion,
+			ContentType:   strings.ToLower(webhook.ItemType),
+			Title:         webhook.Name,
+			Year:          webhook.Year,
+			TmdbID:        webhook.ProviderTmdb,
+			IMDBID:        webhook.ProviderImdb,
+			TVDBID:        webhook.ProviderTvdb,
+			SeasonNumber:  webhook.SeasonNumber,
+			EpisodeNumber: webhook.EpisodeNumber,
+		}
+
+		if scrobble.ContentType == "episode" {
+			scrobble = scrobble.withSeries(
+				webhook.SeriesName, webhook.SeriesProviderTmdb,
+				webhook.SeriesProviderImdb, webhook.SeriesProviderTvdb,
+			)
+		}
+	case EmbyScrobbleSource:
+		var webhook responses.EmbyWebhook
+		if err := json.Unmarshal(payload, &webhook); err != nil {
+			return Scrobble{}, fmt.Errorf("Invalid Emby payload.")
+		}
+
+		scrobble = Scrobble{
+			IsFinished: webhook.Event == "item.markplayed" ||
+				(webhook.Event == "playback.stop" && webhook.PlaybackInfo.PlayedToCompletion),
+			ContentType:   strings.ToLower(webhook.Item.Type),
+			Title:         webhook.Item.Name,
+			Year:          webhook.Item.ProductionYear,
+			TmdbID:        webhook.Item.ProviderIds.Tmdb,
+			IMDBID:        webhook.Item.ProviderIds.Imdb,
+			TVDBID:        webhook.Item.ProviderIds.Tvdb,
+			SeasonNumber:  webhook.Item.ParentIndexNumber,
+			EpisodeNumber: webhook.Item.IndexNumber,
+		}
+
+		if scrobble.ContentType == "episode" {
+			scrobble = scrobble.withSeries(webhook.Item.SeriesName, "", "", "")
+		}
+	default:
+		return Scrobble{}, fmt.Errorf("Unknown scrobble source.")
+	}
+
+	switch scrobble.ContentType {
+	case "movie":
+	case "episode":
+		scrobble.ContentType = "tv"
+	default:
+		// Music, trailers etc. are not tracked.
+		scrobble.IsFinished = false
+	}
+
+	return scrobble, nil
+}
+
+// withSeries replaces the episode with its series, ids of the episode are not
+// the ids of the series so they are replaced by the series ids the source has.
+func (scrobble Scrobble) withSeries(seriesName, tmdbID, imdbID, tvdbID string) Scrobble {
+	scrobble.Title = seriesName
+	scrobble.Year = 0
+	scrobble.TmdbID = tmdbID
+	scrobble.IMDBID = imdbID
+	scrobble.TVDBID = tvdbID
+
+	return scrobble
+}
+
+// setPlexID sets the id of a Plex guid, e.g. "tmdb://278" or the guids of the
+// legacy agents e.g. "com.plexapp.agents.thetvdb://81189?lang=en". Guids of the
+// Plex agent, e.g. "plex://show/...", are not ids of a provider.
+func (scrobble *Scrobble) setPlexID(guid string) {
+	provider, id, ok := strings.Cut(guid, "://")
+	if !ok {
+		return
+	}
+
+	id, _, _ = strings.Cut(id, "?")
+	id, _, _ = strings.Cut(id, "/")
+	if id == "" {
+		return
+	}
+
+	switch strings.TrimPrefix(provider, "com.plexapp.agents.") {
+	case "tmdb", "themoviedb":
+		scrobble.TmdbID = id
+	case "imdb":
+		scrobble.IMDBID = id
+	case "tvdb", "thetvdb":
+		scrobble.TVDBID = id
+	}
+}
+
+// ! Get
+// GetUserIDByScrobbleToken returns the owner of the token, empty if the token
+// is not valid.
+func (scrobbleModel *ScrobbleModel) GetUserIDByScrobbleToken(token string) (string, error) {
+	if token == "" {
+		return "", nil
+	}
+
+	result := scrobbleModel.ScrobbleTokenCollection.FindOneAndUpdate(context.TODO(), bson.M{
+		"token_hash": utils.HashToken(token),
+	}, bson.M{"$set": bson.M{
+		"last_used_at": time.Now().UTC(),
+	}})
+
+	var scrobbleToken ScrobbleToken
+	if err := result.Decode(&scrobbleToken); err != nil && err != mongo.ErrNoDocuments {
+		logrus.Error("failed to find scrobble token: ", err)
+
+		return "", fmt.Errorf("Failed to find scrobble token.")
+	}
+
+	return scrobbleToken.UserID, nil
+}
+
+// ResolveScrobbleContent returns the id of the movie or tv series, ids are
+// matched first and then the title with the year if the source has it. Titles
+// that match more than one content are not resolved.
+func (scrobbleModel *ScrobbleModel) ResolveScrobbleContent(scrobble Scrobble) (string, error) {
+	collection := scrobbleModel.MovieCollection
+	if scrobble.ContentType == "tv" {
+		collection = scrobbleModel.TVCollection
+	}
+
+	var ids bson.A
+	if scrobble.TmdbID != "" {
+		ids = append(ids, bson.M{"tmdb_id": scrobble.TmdbID})
+	}
+	if scrobble.IMDBID != "" {
+		ids = append(ids, bson.M{"imdb_id": scrobble.IMDBID})
+	}
+	if scrobble.TVDBID != "" {
+		ids = append(ids, bson.M{"tvdb_id": scrobble.TVDBID})
+	}
+
+	var content importContent
+	if len(ids) > 0 {
+		err := collection.FindOne(context.TODO(), bson.M{"$or": ids}, options.FindOne().SetProjection(bson.M{
+			"_id": 1,
+		})).Decode(&content)
+		if err == nil {
+			return content.ID.Hex(), nil
+		}
+
+		if err != mongo.ErrNoDocuments {
+			logrus.WithFields(logrus.Fields{
+				"scrobble": scrobble,
+			}).Error("failed to find scrobble content by ids: ", err)
+
+			return "", fmt.Errorf("Failed to find content.")
+		}
+	}
+
+	if scrobble.Title == "" {
+		return "", nil
+	}
+
+	// Titles are compared case insensitively.
+	cursor, err := collection.Find(context.TODO(), bson.M{"$or": bson.A{
+		bson.M{"title_en": scrobble.Title},
+		bson.M{"title_original": scrobble.Title},
+	}}, options.Find().SetProjection(bson.M{
+		"_id":            1,
+		"release_date":   1,
+		"first_air_date": 1,
+	}).SetCollation(&options.Collation{Locale: "en", Strength: 2}).SetLimit(10))
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"scrobble": scrobble,
+		}).Error("failed to find scrobble content by title: ", err)
+
+		return "", fmt.Errorf("Failed to find content.")
+	}
+
+	var contents []importContent
+	if err := cursor.All(context.TODO(), &contents); err != nil {
+		return "", fmt.Errorf("Failed to find content.")
+	}
+
+	var contentID string
+	for _, content := range contents {
+		if scrobble.Year != 0 && content.year() != scrobble.Year {
+			continue
+		}
+
+		if contentID != "" {
+			return "", nil
+		}
+
+		contentID = content.ID.Hex()
+	}
+
+	return contentID, nil
+}
+
+// ! Delete
+func (scrobbleModel *ScrobbleModel) DeleteScrobbleTokenByUserID(uid string) {
+	if _, err := scrobbleModel.ScrobbleTokenCollection.DeleteMany(context.TODO(), bson.M{
+		"user_id": uid,
+	}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to delete scrobble token by user id: ", err)
+	}
+}
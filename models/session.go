@@ -0,0 +1,358 @@
+package models
+
+import (
+	"app/db"
+	"app/utils"
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+//lint:file-ignore ST1005 Ignore all
+
+// SessionModel keeps the device sessions of the users. Access tokens are short
+// lived, sessions are kept alive by rotating their refresh token. Revoked
+// sessions are listed in Redis until their access tokens expire.
+type SessionModel struct {
+	SessionCollection *mongo.Collection
+	RedisClient       *redis.Client
+}
+
+func NewSessionModel(mongoDB *db.MongoDB, redisClient *redis.Client) *SessionModel {
+	return &SessionModel{
+		SessionCollection: mongoDB.Database.Collection("sessions"),
+		RedisClient:       redisClient,
+	}
+}
+
+const (
+	AccessTokenTimeout  = 15 * time.Minute
+	RefreshTokenTimeout = 90 * 24 * time.Hour
+	revokedSessionKey   = "revoked-session:"
+	revokedUserKey      = "revoked-user:"
+	// Access tokens issued before the sessions don't have a session and are
+	// valid for a month.
+	legacyAccessTokenTimeout = 730 * time.Hour
+)
+
+// Session is the login of a device, refresh token is replaced every time it's
+// used. Previous refresh token is kept to notice if a used token is stolen.
+type Session struct {
+	ID                       primitive.ObjectID `bson:"_id,omitempty" json:"_id"`
+	UserID                   string             `bson:"user_id" json:"-"`
+	DeviceName               string             `bson:"device_name" json:"device_name"`
+	FCMToken                 string             `bson:"fcm_token" json:"-"`
+	IP                       string             `bson:"ip" json:"ip"`
+	RefreshTokenHash         string             `bson:"refresh_token_hash" json:"-"`
+	PreviousRefreshTokenHash string             `bson:"previous_refresh_token_hash" json:"-"`
+	LastSeenAt               time.Time          `bson:"last_seen_at" json:"last_seen_at"`
+	ExpiresAt                time.Time          `bson:"expires_at" json:"expires_at"`
+	RevokedAt                *time.Time         `bson:"revoked_at" json:"-"`
+	CreatedAt                time.Time          `bson:"created_at" json:"created_at"`
+	IsCurrent                bool               `bson:"-" json:"is_current"`
+}
+
+// ! Create
+// CreateSession starts the session of the device and returns its refresh token,
+// only the hash of the token is stored.
+func (sessionModel *SessionModel) CreateSession(uid, deviceName, fcmToken, ip string) (Session, string, error) {
+	now := time.Now().UTC()
+	refreshToken := utils.GenerateToken()
+
+	session := Session{
+		UserID:           uid,
+		DeviceName:       deviceName,
+		FCMToken:         fcmToken,
+		IP:               ip,
+		RefreshTokenHash: utils.HashToken(refreshToken),
+		LastSeenAt:       now,
+		ExpiresAt:        now.Add(RefreshTokenTimeout),
+		CreatedAt:        now,
+	}
+
+	result, err := sessionModel.SessionCollection.InsertOne(context.TODO(), session)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid":         uid,
+			"device_name": deviceName,
+		}).Error("failed to create session: ", err)
+
+		return Session{}, "", fmt.Errorf("Failed to create session.")
+	}
+
+	session.ID = result.InsertedID.(primitive.ObjectID)
+
+	return session, refreshToken, nil
+}
+
+// ! Update
+// RotateRefreshToken replaces the refresh token of the session with a new one.
+// Empty session is returned if the token is not valid. Reusing a replaced token
+// means it's stolen, so its session is revoked.
+func (sessionModel *SessionModel) RotateRefreshToken(refreshToken, ip string) (Session, string, error) {
+	now := time.Now().UTC()
+	tokenHash := utils.HashToken(refreshToken)
+	newRefreshToken := utils.GenerateToken()
+
+	result := sessionModel.SessionCollection.FindOneAndUpdate(context.TODO(), bson.M{
+		"refresh_token_hash": tokenHash,
+		"revoked_at":         nil,
+		"expires_at":         bson.M{"$gt": now},
+	}, bson.M{"$set": bson.M{
+		"refresh_token_hash":          utils.HashToken(newRefreshToken),
+		"previous_refresh_token_hash": tokenHash,
+		"ip":                          ip,
+		"last_seen_at":                now,
+		"expires_at":                  now.Add(RefreshTokenTimeout),
+	}}, options.FindOneAndUpdate().SetReturnDocument(options.After))
+
+	var session Session
+	if err := result.Decode(&session); err == nil {
+		return session, newRefreshToken, nil
+	} else if err != mongo.ErrNoDocuments {
+		logrus.Error("failed to rotate refresh token: ", err)
+
+		return Session{}, "", fmt.Errorf("Failed to refresh session.")
+	}
+
+	reusedSession, err := sessionModel.getSession(bson.M{
+		"previous_refresh_token_hash": tokenHash,
+		"revoked_at":                  nil,
+	})
+	if err != nil {
+		return Session{}, "", err
+	}
+
+	if reusedSession.UserID != "" {
+		logrus.WithFields(logrus.Fields{
+			"uid":        reusedSession.UserID,
+			"session_id": reusedSession.ID,
+		}).Warn("replaced refresh token is reused, revoking session")
+
+		if err := sessionModel.RevokeSession(reusedSession); err != nil {
+			return Session{}, "", err
+		}
+	}
+
+	return Session{}, "", nil
+}
+
+// RevokeSession ends the session, its access token is rejected until it expires.
+func (sessionModel *SessionModel) RevokeSession(session Session) error {
+	if _, err := sessionModel.SessionCollection.UpdateOne(context.TODO(), bson.M{
+		"_id": session.ID,
+	}, bson.M{"$set": bson.M{
+		"revoked_at": time.Now().UTC(),
+	}}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"session_id": session.ID,
+		}).Error("failed to revoke session: ", err)
+
+		return fmt.Errorf("Failed to revoke session.")
+	}
+
+	sessionModel.addRevokedSessions(session.ID.Hex())
+
+	return nil
+}
+
+// RevokeUserSessions ends every session of the user except the given one, e.g.
+// the session that changed the password. Access tokens without a session that
+// are issued before are rejected too.
+func (sessionModel *SessionModel) RevokeUserSessions(uid, exceptSessionID string) error {
+	if err := sessionModel.revokeUserTokens(uid); err != nil {
+		return err
+	}
+
+	sessions, err := sessionModel.GetActiveSessions(uid)
+	if err != nil {
+		return err
+	}
+
+	sessionIDs := []string{}
+	objectIDs := bson.A{}
+	for _, session := range sessions {
+		if session.ID.Hex() != exceptSessionID {
+			sessionIDs = append(sessionIDs, session.ID.Hex())
+			objectIDs = append(objectIDs, session.ID)
+		}
+	}
+
+	if len(objectIDs) == 0 {
+		return nil
+	}
+
+	if _, err := sessionModel.SessionCollection.UpdateMany(context.TODO(), bson.M{
+		"_id": bson.M{"$in": objectIDs},
+	}, bson.M{"$set": bson.M{
+		"revoked_at": time.Now().UTC(),
+	}}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to revoke user sessions: ", err)
+
+		return fmt.Errorf("Failed to revoke sessions.")
+	}
+
+	sessionModel.addRevokedSessions(sessionIDs...)
+
+	return nil
+}
+
+// addRevokedSessions lists the sessions as revoked as long as an access token
+// issued before can be valid.
+func (sessionModel *SessionModel) addRevokedSessions(sessionIDs ...string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pipe := sessionModel.RedisClient.Pipeline()
+	for _, sessionID := range sessionIDs {
+		pipe.Set(ctx, revokedSessionKey+sessionID, 1, AccessTokenTimeout)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"session_ids": sessionIDs,
+		}).Error("failed to add revoked sessions: ", err)
+	}
+}
+
+// revokeUserTokens keeps the revocation time of the user as long as an access
+// token without a session can be valid.
+func (sessionModel *SessionModel) revokeUserTokens(uid string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := sessionModel.RedisClient.Set(
+		ctx, revokedUserKey+uid, time.Now().UTC().Unix(), legacyAccessTokenTimeout,
+	).Err(); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to revoke user tokens: ", err)
+
+		return fmt.Errorf("Failed to revoke sessions.")
+	}
+
+	return nil
+}
+
+// ! Get
+func (sessionModel *SessionModel) GetActiveSessions(uid string) ([]Session, error) {
+	cursor, err := sessionModel.SessionCollection.Find(context.TODO(), bson.M{
+		"user_id":    uid,
+		"revoked_at": nil,
+		"expires_at": bson.M{"$gt": time.Now().UTC()},
+	}, options.Find().SetSort(bson.M{"last_seen_at": -1}))
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to find sessions: ", err)
+
+		return nil, fmt.Errorf("Failed to find sessions.")
+	}
+
+	sessions := []Session{}
+	if err := cursor.All(context.TODO(), &sessions); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to decode sessions: ", err)
+
+		return nil, fmt.Errorf("Failed to find sessions.")
+	}
+
+	return sessions, nil
+}
+
+func (sessionModel *SessionModel) GetSessionByID(sessionID string) (Session, error) {
+	objectID, _ := primitive.ObjectIDFromHex(sessionID)
+
+	return sessionModel.getSession(bson.M{"_id": objectID})
+}
+
+func (sessionModel *SessionModel) getSession(filter bson.M) (Session, error) {
+	result := sessionModel.SessionCollection.FindOne(context.TODO(), filter)
+
+	var session Session
+	if err := result.Decode(&session); err != nil && err != mongo.ErrNoDocuments {
+		logrus.WithFields(logrus.Fields{
+			"filter": filter,
+		}).Error("failed to find session: ", err)
+
+		return Session{}, fmt.Errorf("Failed to find session.")
+	}
+
+	return session, nil
+}
+
+// IsSessionRevoked checks the revocation list, the session is checked from the
+// database if Redis is not reachable and rejected if that fails too.
+func (sessionModel *SessionModel) IsSessionRevoked(sessionID string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	count, err := sessionModel.RedisClient.Exists(ctx, revokedSessionKey+sessionID).Result()
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"session_id": sessionID,
+		}).Error("failed to check revoked session: ", err)
+
+		session, err := sessionModel.GetSessionByID(sessionID)
+		if err != nil {
+			return true
+		}
+
+		return session.UserID == "" || session.RevokedAt != nil
+	}
+
+	return count > 0
+}
+
+// IsUserTokenRevoked reports whether the sessions of the user are revoked after
+// the access token without a session is issued, it's rejected if Redis is not
+// reachable.
+func (sessionModel *SessionModel) IsUserTokenRevoked(uid string, issuedAt time.Time) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	value, err := sessionModel.RedisClient.Get(ctx, revokedUserKey+uid).Result()
+	if err == redis.Nil {
+		return false
+	} else if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to check revoked user tokens: ", err)
+
+		return true
+	}
+
+	revokedAt, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return true
+	}
+
+	return issuedAt.Unix() <= revokedAt
+}
+
+// ! Delete
+// DeleteSessionsByUserID revokes and deletes the sessions of the user.
+func (sessionModel *SessionModel) DeleteSessionsByUserID(uid string) {
+	if err := sessionModel.RevokeUserSessions(uid, ""); err != nil {
+		return
+	}
+
+	if _, err := sessionModel.SessionCollection.DeleteMany(context.TODO(), bson.M{
+		"user_id": uid,
+	}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to delete sessions: ", err)
+	}
+}
@@ -23,187 +23,55 @@ import (
 //lint:file-ignore ST1005 Ignore all
 
 type SteamImportModel struct {
-	GameCollection     *mongo.Collection
-	GameListCollection *mongo.Collection
+	GameCollection *mongo.Collection
 }
 
 func NewSteamImportModel(mongoDB *db.MongoDB) *SteamImportModel {
 	return &SteamImportModel{
-		GameCollection:     mongoDB.Database.Collection("games"),
-		GameListCollection: mongoDB.Database.Collection("game-lists"),
+		GameCollection: mongoDB.Database.Collection("games"),
 	}
 }
 
-func (s *SteamImportModel) ImportUserGameLibrary(userID, steamID string) (responses.SteamImportResponse, error) {
+func (s *SteamImportModel) FetchGameLibraryEntries(steamID string) ([]*ImportEntry, error) {
 	// Validate Steam ID and check profile visibility
 	if err := s.validateSteamProfile(steamID); err != nil {
-		return responses.SteamImportResponse{}, err
+		return nil, err
 	}
 
 	// Fetch game library from Steam
 	gameEntries, err := s.fetchSteamGameLibrary(steamID)
 	if err != nil {
-		return responses.SteamImportResponse{}, err
+		return nil, err
 	}
 
 	logrus.WithFields(logrus.Fields{
-		"user_id":       userID,
 		"steam_id":      steamID,
 		"total_entries": len(gameEntries),
 	}).Info("Starting Steam import")
 
-	// Get existing game entries in bulk to avoid repeated database calls
-	existingEntries, err := s.getExistingGameEntries(userID)
-	if err != nil {
-		return responses.SteamImportResponse{}, fmt.Errorf("failed to get existing entries: %v", err)
-	}
-
-	// Get all game Steam App IDs from our database in bulk
-	gameSteamIDs, err := s.getAllGameSteamIDs()
-	if err != nil {
-		return responses.SteamImportResponse{}, fmt.Errorf("failed to get game Steam IDs: %v", err)
-	}
-
-	logrus.WithFields(logrus.Fields{
-		"user_id":           userID,
-		"steam_games_count": len(gameSteamIDs),
-	}).Info("loaded Steam game mappings from database")
-
-	var importedCount, skippedCount, errorCount int
-	var entriesToInsert []interface{}
-	var importedTitles, skippedTitles []string
-
-	// Process entries in batches
+	steamEntries := newImportEntries()
 	for _, entry := range gameEntries {
-		logrus.WithFields(logrus.Fields{
-			"steam_appid":   entry.AppID,
-			"title":         entry.Name,
-			"playtime_mins": entry.PlaytimeForever,
-		}).Debug("processing Steam game entry")
-
-		// Check if game exists in our database
-		gameObjectID, exists := gameSteamIDs[int64(entry.AppID)]
-		if !exists {
-			logrus.WithFields(logrus.Fields{
-				"steam_appid": entry.AppID,
-				"title":       entry.Name,
-			}).Debug("game not found in database, skipping")
-			errorCount++
-			continue
-		}
-
-		logrus.WithFields(logrus.Fields{
-			"steam_appid": entry.AppID,
-			"title":       entry.Name,
-			"game_id":     gameObjectID,
-		}).Debug("found matching game in database")
-
-		// Determine status based on playtime
-		status := s.determineGameStatus(entry.PlaytimeForever)
-
 		// Convert playtime from minutes to hours for storage
-		playtimeHours := float32(entry.PlaytimeForever) / 60.0
-		playtimeHoursInt := int(playtimeHours)
-
-		// Note: Steam provides last played timestamp (entry.RtimeLastPlayed) but
-		// the GameList struct doesn't have a last_played_at field to store it
-
-		// Check if already exists in user's list
-		if _, exists := existingEntries[gameObjectID]; exists {
-			// Update existing entry with new playtime data
-			err := s.updateExistingGameEntry(userID, gameObjectID, playtimeHoursInt, status, s.calculateTimesFinished(entry.PlaytimeForever))
-			if err != nil {
-				logrus.WithFields(logrus.Fields{
-					"steam_appid": entry.AppID,
-					"title":       entry.Name,
-					"game_id":     gameObjectID,
-					"error":       err.Error(),
-				}).Error("failed to update existing game entry")
-				errorCount++
-			} else {
-				logrus.WithFields(logrus.Fields{
-					"steam_appid":  entry.AppID,
-					"title":        entry.Name,
-					"game_id":      gameObjectID,
-					"hours_played": playtimeHoursInt,
-				}).Debug("updated existing game entry with new playtime")
-				skippedCount++ // Count as skipped since it wasn't a new import
-				skippedTitles = append(skippedTitles, entry.Name)
-			}
-			continue
-		}
-
-		// Create game list entry for batch insert
-		gameListEntry := GameList{
-			UserID:        userID,
-			GameID:        gameObjectID,
-			GameRAWGID:    int64(entry.AppID), // Using Steam AppID as identifier
-			Status:        status,
-			HoursPlayed:   &playtimeHoursInt, // Always include hours, even if 0
+		playtimeHours := entry.PlaytimeForever / 60
+
+		importEntry := ImportEntry{
+			ContentType:   "game",
+			Title:         entry.Name,
+			SteamAppID:    int64(entry.AppID),
+			Status:        s.determineGameStatus(entry.PlaytimeForever),
+			HoursPlayed:   &playtimeHours, // Always include hours, even if 0
 			TimesFinished: s.calculateTimesFinished(entry.PlaytimeForever),
-			CreatedAt:     time.Now().UTC(),
-			UpdatedAt:     time.Now().UTC(),
 		}
 
-		logrus.WithFields(logrus.Fields{
-			"steam_appid":    entry.AppID,
-			"title":          entry.Name,
-			"game_id":        gameObjectID,
-			"status":         status,
-			"hours_played":   playtimeHoursInt,
-			"times_finished": s.calculateTimesFinished(entry.PlaytimeForever),
-		}).Debug("prepared game entry for import")
-
-		entriesToInsert = append(entriesToInsert, gameListEntry)
-		importedCount++
-		importedTitles = append(importedTitles, entry.Name)
-	}
-
-	// Bulk insert all entries at once
-	if len(entriesToInsert) > 0 {
-		logrus.WithFields(logrus.Fields{
-			"user_id":      userID,
-			"insert_count": len(entriesToInsert),
-		}).Info("attempting bulk insert of game entries")
-
-		_, err = s.GameListCollection.InsertMany(context.TODO(), entriesToInsert)
-		if err != nil {
-			logrus.WithFields(logrus.Fields{
-				"user_id": userID,
-				"count":   len(entriesToInsert),
-				"error":   err.Error(),
-			}).Error("failed to bulk insert game entries")
-			return responses.SteamImportResponse{}, fmt.Errorf("failed to import game entries: %v", err)
+		if entry.RtimeLastPlayed > 0 {
+			lastPlayedAt := time.Unix(entry.RtimeLastPlayed, 0).UTC()
+			importEntry.UpdatedAt = &lastPlayedAt
 		}
 
-		logrus.WithFields(logrus.Fields{
-			"user_id":      userID,
-			"insert_count": len(entriesToInsert),
-		}).Info("successfully bulk inserted game entries")
-	} else {
-		logrus.WithFields(logrus.Fields{
-			"user_id": userID,
-		}).Info("no new entries to insert")
+		steamEntries.add(importEntry)
 	}
 
-	message := fmt.Sprintf("Import completed: %d imported, %d skipped, %d errors",
-		importedCount, skippedCount, errorCount)
-
-	logrus.WithFields(logrus.Fields{
-		"user_id":        userID,
-		"imported_count": importedCount,
-		"skipped_count":  skippedCount,
-		"error_count":    errorCount,
-	}).Info("Steam import completed")
-
-	return responses.SteamImportResponse{
-		ImportedCount:  importedCount,
-		SkippedCount:   skippedCount,
-		ErrorCount:     errorCount,
-		Message:        message,
-		ImportedTitles: importedTitles,
-		SkippedTitles:  skippedTitles,
-	}, nil
+	return steamEntries.entries, nil
 }
 
 func (s *SteamImportModel) ResolveSteamUsername(username string) (string, error) {
@@ -221,12 +89,12 @@ func (s *SteamImportModel) ResolveSteamUsername(username string) (string, error)
 
 	resp, err := client.Get(url)
 	if err != nil {
-		return "", fmt.Errorf("failed to resolve Steam username: %v", err)
+		return "", importRequestError("failed to resolve Steam username", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("Steam API returned status code: %d", resp.StatusCode)
+		return "", importStatusError("Steam API returned status code", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -272,12 +140,12 @@ func (s *SteamImportModel) validateSteamProfile(steamID string) error {
 
 	resp, err := client.Get(url)
 	if err != nil {
-		return fmt.Errorf("failed to validate Steam profile: %v", err)
+		return importRequestError("failed to validate Steam profile", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return fmt.Errorf("Steam API returned status code: %d", resp.StatusCode)
+		return importStatusError("Steam API returned status code", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -316,12 +184,12 @@ func (s *SteamImportModel) fetchSteamGameLibrary(steamID string) ([]responses.St
 
 	resp, err := client.Get(url)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch Steam game library: %v", err)
+		return nil, importRequestError("failed to fetch Steam game library", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("Steam API returned status code: %d", resp.StatusCode)
+		return nil, importStatusError("Steam API returned status code", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -343,7 +211,7 @@ func (s *SteamImportModel) fetchSteamGameLibrary(steamID string) ([]responses.St
 
 func (s *SteamImportModel) determineGameStatus(playtimeMinutes int) string {
 	if playtimeMinutes == 0 {
-		return "planning" // Never played
+		return "planto" // Never played
 	} else if playtimeMinutes < 60 { // Less than 1 hour
 		return "active" // Started but not much progress
 	} else if playtimeMinutes >= 60 {
@@ -361,43 +229,6 @@ func (s *SteamImportModel) calculateTimesFinished(playtimeMinutes int) int {
 	return 0
 }
 
-func (s *SteamImportModel) getExistingGameEntries(userID string) (map[string]bool, error) {
-	filter := bson.M{"user_id": userID}
-	projection := bson.M{"game_id": 1}
-
-	cursor, err := s.GameListCollection.Find(context.TODO(), filter, options.Find().SetProjection(projection))
-	if err != nil {
-		logrus.WithFields(logrus.Fields{
-			"user_id": userID,
-			"error":   err.Error(),
-		}).Error("failed to query existing game entries")
-		return nil, err
-	}
-	defer cursor.Close(context.TODO())
-
-	existingEntries := make(map[string]bool)
-	for cursor.Next(context.TODO()) {
-		var entry struct {
-			GameID string `bson:"game_id"`
-		}
-		if err := cursor.Decode(&entry); err != nil {
-			logrus.WithFields(logrus.Fields{
-				"user_id": userID,
-				"error":   err.Error(),
-			}).Debug("failed to decode existing game entry")
-			continue
-		}
-		existingEntries[entry.GameID] = true
-	}
-
-	logrus.WithFields(logrus.Fields{
-		"user_id":        userID,
-		"existing_count": len(existingEntries),
-	}).Info("loaded existing game entries for user")
-
-	return existingEntries, nil
-}
-
 func (s *SteamImportModel) getAllGameSteamIDs() (map[int64]string, error) {
 	filter := bson.M{"stores": bson.M{"$exists": true, "$ne": nil}}
 	projection := bson.M{"_id": 1, "stores": 1, "title": 1}
@@ -468,33 +299,6 @@ func (s *SteamImportModel) getAllGameSteamIDs() (map[int64]string, error) {
 	return gameSteamIDs, nil
 }
 
-func (s *SteamImportModel) updateExistingGameEntry(userID, gameID string, hoursPlayed int, status string, timesFinished int) error {
-	filter := bson.M{
-		"user_id": userID,
-		"game_id": gameID,
-	}
-
-	update := bson.M{
-		"$set": bson.M{
-			"hours_played":   &hoursPlayed,
-			"status":         status,
-			"times_finished": timesFinished,
-			"updated_at":     time.Now().UTC(),
-		},
-	}
-
-	result, err := s.GameListCollection.UpdateOne(context.TODO(), filter, update)
-	if err != nil {
-		return fmt.Errorf("failed to update game entry: %v", err)
-	}
-
-	if result.MatchedCount == 0 {
-		return fmt.Errorf("no game entry found to update")
-	}
-
-	return nil
-}
-
 func (s *SteamImportModel) extractSteamAppIDFromURL(steamURL string) int64 {
 	// Steam store URLs are in format: https://store.steampowered.com/app/292030/The_Witcher_3_Wild_Hunt/
 	// We need to extract the App ID (292030 in this example)
@@ -8,7 +8,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -21,155 +24,80 @@ import (
 //lint:file-ignore ST1005 Ignore all
 
 type TraktImportModel struct {
-	MovieCollection     *mongo.Collection
-	TVCollection        *mongo.Collection
-	MovieListCollection *mongo.Collection
-	TVListCollection    *mongo.Collection
+	MovieCollection *mongo.Collection
+	TVCollection    *mongo.Collection
+	TraktSyncModel  *TraktSyncModel
 }
 
 func NewTraktImportModel(mongoDB *db.MongoDB) *TraktImportModel {
 	return &TraktImportModel{
-		MovieCollection:     mongoDB.Database.Collection("movies"),
-		TVCollection:        mongoDB.Database.Collection("tv-series"),
-		MovieListCollection: mongoDB.Database.Collection("movie-watch-lists"),
-		TVListCollection:    mongoDB.Database.Collection("tvseries-watch-lists"),
+		MovieCollection: mongoDB.Database.Collection("movies"),
+		TVCollection:    mongoDB.Database.Collection("tv-series"),
+		TraktSyncModel:  NewTraktSyncModel(mongoDB),
 	}
 }
 
-func (t *TraktImportModel) ImportUserData(userID, traktUsername string) (responses.TraktImportResponse, error) {
+// FetchUserEntries pulls the public data of the user, if the user connected the
+// same Trakt account, it is pulled with the access token and recorded as the
+// sync state so that the pulled entries are not pushed back.
+func (t *TraktImportModel) FetchUserEntries(uid, traktUsername string) ([]*ImportEntry, error) {
 	clientID := os.Getenv("TRAKT_CLIENT_ID")
 	if clientID == "" {
-		return responses.TraktImportResponse{}, fmt.Errorf("Trakt client ID not configured")
+		return nil, fmt.Errorf("Trakt client ID not configured")
 	}
 
 	logrus.WithFields(logrus.Fields{
-		"user_id":        userID,
 		"trakt_username": traktUsername,
 	}).Info("Starting Trakt import")
 
-	// Get existing entries in bulk
-	existingMovieEntries, err := t.getExistingMovieEntries(userID)
-	if err != nil {
-		return responses.TraktImportResponse{}, fmt.Errorf("failed to get existing movie entries: %v", err)
-	}
-
-	existingTVEntries, err := t.getExistingTVEntries(userID)
-	if err != nil {
-		return responses.TraktImportResponse{}, fmt.Errorf("failed to get existing TV entries: %v", err)
-	}
-
-	// Get all Trakt/IMDB IDs from database
-	movieTraktIDs, err := t.getAllMovieTraktIDs()
-	if err != nil {
-		return responses.TraktImportResponse{}, fmt.Errorf("failed to get movie Trakt IDs: %v", err)
-	}
-
-	movieIMDBIDs, err := t.getAllMovieIMDBIDs()
-	if err != nil {
-		return responses.TraktImportResponse{}, fmt.Errorf("failed to get movie IMDB IDs: %v", err)
-	}
-
-	tvTraktIDs, err := t.getAllTVTraktIDs()
-	if err != nil {
-		return responses.TraktImportResponse{}, fmt.Errorf("failed to get TV Trakt IDs: %v", err)
+	connection, accessToken, err := t.TraktSyncModel.GetAccessToken(uid)
+	if err != nil || !strings.EqualFold(connection.Username, traktUsername) {
+		accessToken = ""
 	}
 
-	tvIMDBIDs, err := t.getAllTVIMDBIDs()
-	if err != nil {
-		return responses.TraktImportResponse{}, fmt.Errorf("failed to get TV IMDB IDs: %v", err)
-	}
-
-	logrus.WithFields(logrus.Fields{
-		"user_id":           userID,
-		"movie_trakt_count": len(movieTraktIDs),
-		"movie_imdb_count":  len(movieIMDBIDs),
-		"tv_trakt_count":    len(tvTraktIDs),
-		"tv_imdb_count":     len(tvIMDBIDs),
-	}).Info("loaded Trakt mappings from database")
-
-	var importedCount, skippedCount, errorCount int
-	var movieEntriesToInsert []interface{}
-	var tvEntriesToInsert []interface{}
-	var importedTitles, skippedTitles []string
+	traktEntries := newImportEntries()
 
 	// Import watched movies
-	watchedMovies, err := t.fetchWatchedMovies(traktUsername, clientID)
+	watchedMovies, err := t.fetchWatchedMovies(traktUsername, clientID, accessToken)
 	if err != nil {
 		logrus.WithError(err).Warn("failed to fetch watched movies, continuing with other data")
 	} else {
-		t.processWatchedMovies(watchedMovies, userID, movieTraktIDs, movieIMDBIDs, existingMovieEntries,
-			&movieEntriesToInsert, &importedCount, &skippedCount, &errorCount, &importedTitles, &skippedTitles)
+		for _, movie := range watchedMovies {
+			traktEntries.add(traktWatchedMovieEntry(movie))
+		}
 	}
 
 	// Import watched TV shows
-	watchedShows, err := t.fetchWatchedShows(traktUsername, clientID)
+	watchedShows, err := t.fetchWatchedShows(traktUsername, clientID, accessToken)
 	if err != nil {
 		logrus.WithError(err).Warn("failed to fetch watched shows, continuing with other data")
 	} else {
-		t.processWatchedShows(watchedShows, userID, tvTraktIDs, tvIMDBIDs, existingTVEntries,
-			&tvEntriesToInsert, &importedCount, &skippedCount, &errorCount, &importedTitles, &skippedTitles)
+		for _, show := range watchedShows {
+			traktEntries.add(traktWatchedShowEntry(show))
+		}
 	}
 
 	// Import watchlist
-	watchlistItems, err := t.fetchWatchlist(traktUsername, clientID)
+	watchlistItems, err := t.fetchWatchlist(traktUsername, clientID, accessToken)
 	if err != nil {
 		logrus.WithError(err).Warn("failed to fetch watchlist, continuing with other data")
 	} else {
-		t.processWatchlistItems(watchlistItems, userID, movieTraktIDs, movieIMDBIDs, tvTraktIDs, tvIMDBIDs,
-			existingMovieEntries, existingTVEntries, &movieEntriesToInsert, &tvEntriesToInsert,
-			&importedCount, &skippedCount, &errorCount, &importedTitles, &skippedTitles)
-	}
-
-	// Bulk insert movie entries
-	if len(movieEntriesToInsert) > 0 {
-		_, err = t.MovieListCollection.InsertMany(context.TODO(), movieEntriesToInsert)
-		if err != nil {
-			logrus.WithFields(logrus.Fields{
-				"user_id": userID,
-				"count":   len(movieEntriesToInsert),
-				"error":   err.Error(),
-			}).Error("failed to bulk insert movie entries")
-			return responses.TraktImportResponse{}, fmt.Errorf("failed to import movie entries: %v", err)
+		for _, item := range watchlistItems {
+			traktEntries.add(traktImportEntry(item.Movie, item.Show, "planto", nil))
 		}
 	}
 
-	// Bulk insert TV entries
-	if len(tvEntriesToInsert) > 0 {
-		_, err = t.TVListCollection.InsertMany(context.TODO(), tvEntriesToInsert)
-		if err != nil {
-			logrus.WithFields(logrus.Fields{
-				"user_id": userID,
-				"count":   len(tvEntriesToInsert),
-				"error":   err.Error(),
-			}).Error("failed to bulk insert TV entries")
-			return responses.TraktImportResponse{}, fmt.Errorf("failed to import TV entries: %v", err)
-		}
+	if accessToken != "" {
+		t.TraktSyncModel.RecordPulledStates(uid, watchedMovies, watchedShows, watchlistItems)
 	}
 
-	message := fmt.Sprintf("Import completed: %d imported, %d skipped, %d errors",
-		importedCount, skippedCount, errorCount)
-
-	logrus.WithFields(logrus.Fields{
-		"user_id":        userID,
-		"imported_count": importedCount,
-		"skipped_count":  skippedCount,
-		"error_count":    errorCount,
-	}).Info("Trakt import completed")
-
-	return responses.TraktImportResponse{
-		ImportedCount:  importedCount,
-		SkippedCount:   skippedCount,
-		ErrorCount:     errorCount,
-		Message:        message,
-		ImportedTitles: importedTitles,
-		SkippedTitles:  skippedTitles,
-	}, nil
+	return traktEntries.entries, nil
 }
 
-func (t *TraktImportModel) fetchWatchedMovies(username, clientID string) ([]responses.TraktWatchedMovie, error) {
+func (t *TraktImportModel) fetchWatchedMovies(username, clientID, accessToken string) ([]responses.TraktWatchedMovie, error) {
 	url := fmt.Sprintf("https://api.trakt.tv/users/%s/watched/movies", username)
 
-	body, err := t.makeTraktRequest(url, clientID)
+	body, err := t.makeTraktRequest(url, clientID, accessToken)
 	if err != nil {
 		return nil, err
 	}
@@ -182,10 +110,10 @@ func (t *TraktImportModel) fetchWatchedMovies(username, clientID string) ([]resp
 	return movies, nil
 }
 
-func (t *TraktImportModel) fetchWatchedShows(username, clientID string) ([]responses.TraktWatchedShow, error) {
+func (t *TraktImportModel) fetchWatchedShows(username, clientID, accessToken string) ([]responses.TraktWatchedShow, error) {
 	url := fmt.Sprintf("https://api.trakt.tv/users/%s/watched/shows", username)
 
-	body, err := t.makeTraktRequest(url, clientID)
+	body, err := t.makeTraktRequest(url, clientID, accessToken)
 	if err != nil {
 		return nil, err
 	}
@@ -198,10 +126,10 @@ func (t *TraktImportModel) fetchWatchedShows(username, clientID string) ([]respo
 	return shows, nil
 }
 
-func (t *TraktImportModel) fetchWatchlist(username, clientID string) ([]responses.TraktWatchlistItem, error) {
+func (t *TraktImportModel) fetchWatchlist(username, clientID, accessToken string) ([]responses.TraktWatchlistItem, error) {
 	url := fmt.Sprintf("https://api.trakt.tv/users/%s/watchlist", username)
 
-	body, err := t.makeTraktRequest(url, clientID)
+	body, err := t.makeTraktRequest(url, clientID, accessToken)
 	if err != nil {
 		return nil, err
 	}
@@ -214,7 +142,40 @@ func (t *TraktImportModel) fetchWatchlist(username, clientID string) ([]response
 	return items, nil
 }
 
-func (t *TraktImportModel) makeTraktRequest(url, clientID string) ([]byte, error) {
+// ResolveTraktUsername checks that the user exists and returns its username as
+// Trakt has it, private profiles are only visible to the connected account.
+func (t *TraktImportModel) ResolveTraktUsername(uid, traktUsername string) (string, error) {
+	clientID := os.Getenv("TRAKT_CLIENT_ID")
+	if clientID == "" {
+		return "", fmt.Errorf("Trakt client ID not configured")
+	}
+
+	connection, accessToken, err := t.TraktSyncModel.GetAccessToken(uid)
+	if err != nil || !strings.EqualFold(connection.Username, traktUsername) {
+		accessToken = ""
+	}
+
+	body, err := t.makeTraktRequest(fmt.Sprintf("https://api.trakt.tv/users/%s", url.PathEscape(traktUsername)), clientID, accessToken)
+	if err != nil {
+		return "", err
+	}
+
+	var user struct {
+		Username string `json:"username"`
+	}
+
+	if err := json.Unmarshal(body, &user); err != nil {
+		return "", fmt.Errorf("failed to parse user response: %v", err)
+	}
+
+	if user.Username == "" {
+		return "", fmt.Errorf("Trakt username '%s' not found", traktUsername)
+	}
+
+	return user.Username, nil
+}
+
+func (t *TraktImportModel) makeTraktRequest(url, clientID, accessToken string) ([]byte, error) {
 	client := &http.Client{
 		Timeout: 30 * time.Second,
 	}
@@ -227,10 +188,13 @@ func (t *TraktImportModel) makeTraktRequest(url, clientID string) ([]byte, error
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("trakt-api-version", "2")
 	req.Header.Set("trakt-api-key", clientID)
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to make request: %v", err)
+		return nil, importRequestError("failed to make request", err)
 	}
 	defer resp.Body.Close()
 
@@ -239,7 +203,7 @@ func (t *TraktImportModel) makeTraktRequest(url, clientID string) ([]byte, error
 	}
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("Trakt API returned status code: %d", resp.StatusCode)
+		return nil, importStatusError("Trakt API returned status code", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -250,250 +214,48 @@ func (t *TraktImportModel) makeTraktRequest(url, clientID string) ([]byte, error
 	return body, nil
 }
 
-func (t *TraktImportModel) processWatchedMovies(movies []responses.TraktWatchedMovie, userID string,
-	movieTraktIDs map[int64]string, movieIMDBIDs map[string]string, existingMovieEntries map[string]bool,
-	movieEntriesToInsert *[]interface{}, importedCount, skippedCount, errorCount *int,
-	importedTitles, skippedTitles *[]string) {
-
-	for _, movie := range movies {
-		logrus.WithFields(logrus.Fields{
-			"trakt_id": movie.Movie.IDs.Trakt,
-			"imdb_id":  movie.Movie.IDs.IMDB,
-			"title":    movie.Movie.Title,
-		}).Debug("processing Trakt watched movie")
-
-		// Try to find movie by Trakt ID first, then IMDB ID
-		var movieObjectID string
-		var found bool
-
-		if movieObjectID, found = movieTraktIDs[int64(movie.Movie.IDs.Trakt)]; !found {
-			if movie.Movie.IDs.IMDB != "" {
-				movieObjectID, found = movieIMDBIDs[movie.Movie.IDs.IMDB]
-			}
-		}
-
-		if !found {
-			logrus.WithFields(logrus.Fields{
-				"trakt_id": movie.Movie.IDs.Trakt,
-				"imdb_id":  movie.Movie.IDs.IMDB,
-				"title":    movie.Movie.Title,
-			}).Debug("movie not found in database, skipping")
-			*errorCount++
-			continue
-		}
-
-		// Check if already exists in user's list
-		if _, exists := existingMovieEntries[movieObjectID]; exists {
-			*skippedCount++
-			*skippedTitles = append(*skippedTitles, movie.Movie.Title)
-			continue
+func traktImportEntry(movie *responses.TraktMovie, show *responses.TraktShow, status string, score *float32) ImportEntry {
+	entry := ImportEntry{Status: status, Score: score}
+
+	if movie != nil {
+		entry.ContentType = "movie"
+		entry.Title = movie.Title
+		entry.Year = movie.Year
+		entry.IMDBID = movie.IDs.IMDB
+		entry.TraktID = int64(movie.IDs.Trakt)
+		if movie.IDs.TMDB != 0 {
+			entry.TMDBID = strconv.Itoa(movie.IDs.TMDB)
 		}
-
-		// Create movie list entry
-		movieListEntry := MovieWatchList{
-			UserID:        userID,
-			MovieID:       movieObjectID,
-			Status:        "finished",
-			TimesFinished: movie.Plays,
-			CreatedAt:     time.Now().UTC(),
-			UpdatedAt:     time.Now().UTC(),
+	} else if show != nil {
+		entry.ContentType = "tv"
+		entry.Title = show.Title
+		entry.Year = show.Year
+		entry.IMDBID = show.IDs.IMDB
+		entry.TraktID = int64(show.IDs.Trakt)
+		if show.IDs.TMDB != 0 {
+			entry.TMDBID = strconv.Itoa(show.IDs.TMDB)
 		}
-
-		*movieEntriesToInsert = append(*movieEntriesToInsert, movieListEntry)
-		*importedCount++
-		*importedTitles = append(*importedTitles, movie.Movie.Title)
 	}
-}
-
-func (t *TraktImportModel) processWatchedShows(shows []responses.TraktWatchedShow, userID string,
-	tvTraktIDs map[int64]string, tvIMDBIDs map[string]string, existingTVEntries map[string]bool,
-	tvEntriesToInsert *[]interface{}, importedCount, skippedCount, errorCount *int,
-	importedTitles, skippedTitles *[]string) {
-
-	for _, show := range shows {
-		logrus.WithFields(logrus.Fields{
-			"trakt_id": show.Show.IDs.Trakt,
-			"imdb_id":  show.Show.IDs.IMDB,
-			"title":    show.Show.Title,
-		}).Debug("processing Trakt watched show")
-
-		// Try to find show by Trakt ID first, then IMDB ID
-		var tvObjectID string
-		var found bool
-
-		if tvObjectID, found = tvTraktIDs[int64(show.Show.IDs.Trakt)]; !found {
-			if show.Show.IDs.IMDB != "" {
-				tvObjectID, found = tvIMDBIDs[show.Show.IDs.IMDB]
-			}
-		}
-
-		if !found {
-			logrus.WithFields(logrus.Fields{
-				"trakt_id": show.Show.IDs.Trakt,
-				"imdb_id":  show.Show.IDs.IMDB,
-				"title":    show.Show.Title,
-			}).Debug("show not found in database, skipping")
-			*errorCount++
-			continue
-		}
-
-		// Check if already exists in user's list
-		if _, exists := existingTVEntries[tvObjectID]; exists {
-			*skippedCount++
-			*skippedTitles = append(*skippedTitles, show.Show.Title)
-			continue
-		}
-
-		// Calculate total episodes watched
-		totalEpisodes := 0
-		for _, season := range show.Seasons {
-			totalEpisodes += len(season.Episodes)
-		}
-
-		// Create TV list entry
-		tvListEntry := TVSeriesWatchList{
-			UserID:          userID,
-			TvID:            tvObjectID,
-			Status:          "finished",
-			WatchedEpisodes: totalEpisodes,
-			TimesFinished:   show.Plays,
-			CreatedAt:       time.Now().UTC(),
-			UpdatedAt:       time.Now().UTC(),
-		}
 
-		*tvEntriesToInsert = append(*tvEntriesToInsert, tvListEntry)
-		*importedCount++
-		*importedTitles = append(*importedTitles, show.Show.Title)
-	}
-}
-
-func (t *TraktImportModel) processWatchlistItems(items []responses.TraktWatchlistItem, userID string,
-	movieTraktIDs map[int64]string, movieIMDBIDs map[string]string, tvTraktIDs map[int64]string, tvIMDBIDs map[string]string,
-	existingMovieEntries, existingTVEntries map[string]bool,
-	movieEntriesToInsert, tvEntriesToInsert *[]interface{},
-	importedCount, skippedCount, errorCount *int, importedTitles, skippedTitles *[]string) {
-
-	for _, item := range items {
-		if item.Type == "movie" && item.Movie != nil {
-			// Process movie watchlist item
-			var movieObjectID string
-			var found bool
-
-			if movieObjectID, found = movieTraktIDs[int64(item.Movie.IDs.Trakt)]; !found {
-				if item.Movie.IDs.IMDB != "" {
-					movieObjectID, found = movieIMDBIDs[item.Movie.IDs.IMDB]
-				}
-			}
-
-			if !found {
-				*errorCount++
-				continue
-			}
-
-			if _, exists := existingMovieEntries[movieObjectID]; exists {
-				*skippedCount++
-				*skippedTitles = append(*skippedTitles, item.Movie.Title)
-				continue
-			}
-
-			movieListEntry := MovieWatchList{
-				UserID:        userID,
-				MovieID:       movieObjectID,
-				Status:        "active",
-				TimesFinished: 0,
-				CreatedAt:     time.Now().UTC(),
-				UpdatedAt:     time.Now().UTC(),
-			}
-
-			*movieEntriesToInsert = append(*movieEntriesToInsert, movieListEntry)
-			*importedCount++
-			*importedTitles = append(*importedTitles, item.Movie.Title)
-
-		} else if item.Type == "show" && item.Show != nil {
-			// Process show watchlist item
-			var tvObjectID string
-			var found bool
-
-			if tvObjectID, found = tvTraktIDs[int64(item.Show.IDs.Trakt)]; !found {
-				if item.Show.IDs.IMDB != "" {
-					tvObjectID, found = tvIMDBIDs[item.Show.IDs.IMDB]
-				}
-			}
-
-			if !found {
-				*errorCount++
-				continue
-			}
-
-			if _, exists := existingTVEntries[tvObjectID]; exists {
-				*skippedCount++
-				*skippedTitles = append(*skippedTitles, item.Show.Title)
-				continue
-			}
-
-			tvListEntry := TVSeriesWatchList{
-				UserID:          userID,
-				TvID:            tvObjectID,
-				Status:          "active",
-				WatchedEpisodes: 0,
-				TimesFinished:   0,
-				CreatedAt:       time.Now().UTC(),
-				UpdatedAt:       time.Now().UTC(),
-			}
-
-			*tvEntriesToInsert = append(*tvEntriesToInsert, tvListEntry)
-			*importedCount++
-			*importedTitles = append(*importedTitles, item.Show.Title)
-		}
-	}
+	return entry
 }
 
-func (t *TraktImportModel) getExistingMovieEntries(userID string) (map[string]bool, error) {
-	filter := bson.M{"user_id": userID}
-	projection := bson.M{"movie_id": 1}
-
-	cursor, err := t.MovieListCollection.Find(context.TODO(), filter, options.Find().SetProjection(projection))
-	if err != nil {
-		return nil, err
-	}
-	defer cursor.Close(context.TODO())
-
-	existingEntries := make(map[string]bool)
-	for cursor.Next(context.TODO()) {
-		var entry struct {
-			MovieID string `bson:"movie_id"`
-		}
-		if err := cursor.Decode(&entry); err != nil {
-			continue
-		}
-		existingEntries[entry.MovieID] = true
-	}
+func traktWatchedMovieEntry(movie responses.TraktWatchedMovie) ImportEntry {
+	entry := traktImportEntry(&movie.Movie, nil, "finished", nil)
+	entry.TimesFinished = movie.Plays
+	entry.FinishedAt = parseImportDate(movie.LastWatchedAt)
 
-	return existingEntries, nil
+	return entry
 }
 
-func (t *TraktImportModel) getExistingTVEntries(userID string) (map[string]bool, error) {
-	filter := bson.M{"user_id": userID}
-	projection := bson.M{"tv_id": 1}
-
-	cursor, err := t.TVListCollection.Find(context.TODO(), filter, options.Find().SetProjection(projection))
-	if err != nil {
-		return nil, err
+func traktWatchedShowEntry(show responses.TraktWatchedShow) ImportEntry {
+	entry := traktImportEntry(nil, &show.Show, "finished", nil)
+	for _, season := range show.Seasons {
+		entry.Progress += int64(len(season.Episodes))
 	}
-	defer cursor.Close(context.TODO())
+	entry.FinishedAt = parseImportDate(show.LastWatchedAt)
 
-	existingEntries := make(map[string]bool)
-	for cursor.Next(context.TODO()) {
-		var entry struct {
-			TvID string `bson:"tv_id"`
-		}
-		if err := cursor.Decode(&entry); err != nil {
-			continue
-		}
-		existingEntries[entry.TvID] = true
-	}
-
-	return existingEntries, nil
+	return entry
 }
 
 func (t *TraktImportModel) getAllMovieTraktIDs() (map[int64]string, error) {
@@ -521,31 +283,6 @@ func (t *TraktImportModel) getAllMovieTraktIDs() (map[int64]string, error) {
 	return movieTraktIDs, nil
 }
 
-func (t *TraktImportModel) getAllMovieIMDBIDs() (map[string]string, error) {
-	filter := bson.M{"imdb_id": bson.M{"$exists": true, "$ne": ""}}
-	projection := bson.M{"_id": 1, "imdb_id": 1}
-
-	cursor, err := t.MovieCollection.Find(context.TODO(), filter, options.Find().SetProjection(projection))
-	if err != nil {
-		return nil, err
-	}
-	defer cursor.Close(context.TODO())
-
-	movieIMDBIDs := make(map[string]string)
-	for cursor.Next(context.TODO()) {
-		var movie struct {
-			ID     primitive.ObjectID `bson:"_id"`
-			IMDBID string             `bson:"imdb_id"`
-		}
-		if err := cursor.Decode(&movie); err != nil {
-			continue
-		}
-		movieIMDBIDs[movie.IMDBID] = movie.ID.Hex()
-	}
-
-	return movieIMDBIDs, nil
-}
-
 func (t *TraktImportModel) getAllTVTraktIDs() (map[int64]string, error) {
 	filter := bson.M{"trakt_id": bson.M{"$exists": true, "$ne": nil}}
 	projection := bson.M{"_id": 1, "trakt_id": 1}
@@ -570,28 +307,3 @@ func (t *TraktImportModel) getAllTVTraktIDs() (map[int64]string, error) {
 
 	return tvTraktIDs, nil
 }
-
-func (t *TraktImportModel) getAllTVIMDBIDs() (map[string]string, error) {
-	filter := bson.M{"imdb_id": bson.M{"$exists": true, "$ne": ""}}
-	projection := bson.M{"_id": 1, "imdb_id": 1}
-
-	cursor, err := t.TVCollection.Find(context.TODO(), filter, options.Find().SetProjection(projection))
-	if err != nil {
-		return nil, err
-	}
-	defer cursor.Close(context.TODO())
-
-	tvIMDBIDs := make(map[string]string)
-	for cursor.Next(context.TODO()) {
-		var tv struct {
-			ID     primitive.ObjectID `bson:"_id"`
-			IMDBID string             `bson:"imdb_id"`
-		}
-		if err := cursor.Decode(&tv); err != nil {
-			continue
-		}
-		tvIMDBIDs[tv.IMDBID] = tv.ID.Hex()
-	}
-
-	return tvIMDBIDs, nil
-}
@@ -0,0 +1,721 @@
+package models
+
+import (
+	"app/db"
+	"app/responses"
+	"app/utils"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+//lint:file-ignore ST1005 Ignore all
+
+// TraktSyncModel keeps the OAuth tokens of the connected Trakt accounts and
+// pushes the list changes to them. Sync states are the last known state of the
+// content on Trakt, both pushes and pulls update them so that the changes are
+// not echoed back.
+type TraktSyncModel struct {
+	TraktConnectionCollection *mongo.Collection
+	TraktSyncStateCollection  *mongo.Collection
+}
+
+func NewTraktSyncModel(mongoDB *db.MongoDB) *TraktSyncModel {
+	return &TraktSyncModel{
+		TraktConnectionCollection: mongoDB.Database.Collection("trakt-connections"),
+		TraktSyncStateCollection:  mongoDB.Database.Collection("trakt-sync-states"),
+	}
+}
+
+const (
+	traktAPIURL = "https://api.trakt.tv"
+	// Access tokens are refreshed when they expire within this duration.
+	traktTokenRefreshWindow = time.Hour
+)
+
+const (
+	TraktDevicePending   = "pending"
+	TraktDeviceSlowDown  = "slow_down"
+	TraktDeviceExpired   = "expired"
+	TraktDeviceDenied    = "denied"
+	TraktDeviceConnected = "connected"
+)
+
+// TraktConnection is the Trakt account of the user, tokens and the device code
+// are encrypted.
+type TraktConnection struct {
+	ID                  primitive.ObjectID `bson:"_id,omitempty" json:"_id"`
+	UserID              string             `bson:"user_id" json:"user_id"`
+	Username            string             `bson:"username" json:"username"`
+	AccessToken         string             `bson:"access_token" json:"-"`
+	RefreshToken        string             `bson:"refresh_token" json:"-"`
+	ExpiresAt           *time.Time         `bson:"expires_at" json:"-"`
+	DeviceCode          string             `bson:"device_code" json:"-"`
+	DeviceCodeExpiresAt *time.Time         `bson:"device_code_expires_at" json:"-"`
+	ConnectedAt         *time.Time         `bson:"connected_at" json:"connected_at"`
+	LastPushedAt        *time.Time         `bson:"last_pushed_at" json:"last_pushed_at"`
+	CreatedAt           time.Time          `bson:"created_at" json:"created_at"`
+}
+
+func (connection TraktConnection) IsConnected() bool {
+	return connection.AccessToken != ""
+}
+
+// TraktSyncState is the last known state of the movie or tv series on Trakt,
+// content is identified by its TMDB id since pulls are not matched yet.
+type TraktSyncState struct {
+	ID            primitive.ObjectID   `bson:"_id,omitempty" json:"_id"`
+	UserID        string               `bson:"user_id" json:"user_id"`
+	ContentType   string               `bson:"content_type" json:"content_type"`
+	TmdbID        string               `bson:"tmdb_id" json:"tmdb_id"`
+	Plays         int                  `bson:"plays" json:"plays"`
+	IsWatchlisted bool                 `bson:"is_watchlisted" json:"is_watchlisted"`
+	Episodes      []TraktSyncedEpisode `bson:"episodes" json:"episodes"`
+	UpdatedAt     time.Time            `bson:"updated_at" json:"updated_at"`
+}
+
+type TraktSyncedEpisode struct {
+	SeasonNumber  int `bson:"season_number" json:"season_number"`
+	EpisodeNumber int `bson:"episode_number" json:"episode_number"`
+}
+
+// ! Create
+// RequestDeviceCode starts the device flow, the user enters the code on the
+// verification url and the device code is polled until it is authorized.
+func (traktSyncModel *TraktSyncModel) RequestDeviceCode(uid string) (responses.TraktDevice, error) {
+	body, statusCode, err := traktAPIRequest(http.MethodPost, "/oauth/device/code", "", map[string]string{
+		"client_id": os.Getenv("TRAKT_CLIENT_ID"),
+	})
+	if err != nil || statusCode != http.StatusOK {
+		logrus.WithFields(logrus.Fields{
+			"uid":         uid,
+			"status_code": statusCode,
+		}).Error("failed to request trakt device code: ", err)
+
+		return responses.TraktDevice{}, fmt.Errorf("Failed to request Trakt device code.")
+	}
+
+	var deviceCode responses.TraktDeviceCode
+	if err := json.Unmarshal(body, &deviceCode); err != nil {
+		return responses.TraktDevice{}, fmt.Errorf("Failed to parse Trakt device code.")
+	}
+
+	expiresAt := time.Now().UTC().Add(time.Duration(deviceCode.ExpiresIn) * time.Second)
+
+	if _, err := traktSyncModel.TraktConnectionCollection.UpdateOne(context.TODO(), bson.M{
+		"user_id": uid,
+	}, bson.M{
+		"$set": bson.M{
+			"device_code":            utils.Encrypt(deviceCode.DeviceCode),
+			"device_code_expires_at": expiresAt,
+		},
+		"$setOnInsert": bson.M{
+			"username":      "",
+			"access_token":  "",
+			"refresh_token": "",
+			"created_at":    time.Now().UTC(),
+		},
+	}, options.Update().SetUpsert(true)); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to save trakt device code: ", err)
+
+		return responses.TraktDevice{}, fmt.Errorf("Failed to request Trakt device code.")
+	}
+
+	return responses.TraktDevice{
+		UserCode:        deviceCode.UserCode,
+		VerificationURL: deviceCode.VerificationURL,
+		ExpiresIn:       deviceCode.ExpiresIn,
+		Interval:        deviceCode.Interval,
+	}, nil
+}
+
+// ! Update
+// PollDeviceToken exchanges the device code with the tokens once the user
+// authorizes it, the status is one of the TraktDevice statuses.
+func (traktSyncModel *TraktSyncModel) PollDeviceToken(uid string) (TraktConnection, string, error) {
+	connection, err := traktSyncModel.GetTraktConnection(uid)
+	if err != nil {
+		return TraktConnection{}, "", err
+	}
+
+	if connection.DeviceCode == "" || connection.DeviceCodeExpiresAt == nil ||
+		connection.DeviceCodeExpiresAt.Before(time.Now().UTC()) {
+		return TraktConnection{}, TraktDeviceExpired, nil
+	}
+
+	body, statusCode, err := traktAPIRequest(http.MethodPost, "/oauth/device/token", "", map[string]string{
+		"code":          utils.Decrypt(connection.DeviceCode),
+		"client_id":     os.Getenv("TRAKT_CLIENT_ID"),
+		"client_secret": os.Getenv("TRAKT_CLIENT_SECRET"),
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to poll trakt device token: ", err)
+
+		return TraktConnection{}, "", fmt.Errorf("Failed to get Trakt token.")
+	}
+
+	switch statusCode {
+	case http.StatusOK:
+	case http.StatusBadRequest:
+		return TraktConnection{}, TraktDevicePending, nil
+	case http.StatusTooManyRequests:
+		return TraktConnection{}, TraktDeviceSlowDown, nil
+	case http.StatusNotFound, http.StatusConflict, http.StatusGone:
+		return TraktConnection{}, TraktDeviceExpired, nil
+	case http.StatusTeapot:
+		return TraktConnection{}, TraktDeviceDenied, nil
+	default:
+		logrus.WithFields(logrus.Fields{
+			"uid":         uid,
+			"status_code": statusCode,
+		}).Error("unexpected trakt device token status")
+
+		return TraktConnection{}, "", fmt.Errorf("Failed to get Trakt token.")
+	}
+
+	var token responses.TraktToken
+	if err := json.Unmarshal(body, &token); err != nil {
+		return TraktConnection{}, "", fmt.Errorf("Failed to parse Trakt token.")
+	}
+
+	body, statusCode, err = traktAPIRequest(http.MethodGet, "/users/settings", token.AccessToken, nil)
+	if err != nil || statusCode != http.StatusOK {
+		logrus.WithFields(logrus.Fields{
+			"uid":         uid,
+			"status_code": statusCode,
+		}).Error("failed to get trakt user settings: ", err)
+
+		return TraktConnection{}, "", fmt.Errorf("Failed to get Trakt account.")
+	}
+
+	var settings responses.TraktUserSettings
+	if err := json.Unmarshal(body, &settings); err != nil {
+		return TraktConnection{}, "", fmt.Errorf("Failed to parse Trakt account.")
+	}
+
+	now := time.Now().UTC()
+	expiresAt := traktTokenExpiresAt(token)
+
+	connection.Username = settings.User.IDs.Slug
+	connection.AccessToken = utils.Encrypt(token.AccessToken)
+	connection.RefreshToken = utils.Encrypt(token.RefreshToken)
+	connection.ExpiresAt = &expiresAt
+	connection.DeviceCode = ""
+	connection.DeviceCodeExpiresAt = nil
+	connection.ConnectedAt = &now
+
+	if _, err := traktSyncModel.TraktConnectionCollection.UpdateOne(context.TODO(), bson.M{
+		"_id": connection.ID,
+	}, bson.M{
+		"$set": bson.M{
+			"username":      connection.Username,
+			"access_token":  connection.AccessToken,
+			"refresh_token": connection.RefreshToken,
+			"expires_at":    connection.ExpiresAt,
+			"connected_at":  connection.ConnectedAt,
+		},
+		"$unset": bson.M{
+			"device_code":            "",
+			"device_code_expires_at": "",
+		},
+	}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to save trakt connection: ", err)
+
+		return TraktConnection{}, "", fmt.Errorf("Failed to save Trakt connection.")
+	}
+
+	return connection, TraktDeviceConnected, nil
+}
+
+// refreshAccessToken exchanges the refresh token, the connection is removed if
+// Trakt rejects it since the user has to connect again.
+func (traktSyncModel *TraktSyncModel) refreshAccessToken(connection TraktConnection) (TraktConnection, error) {
+	body, statusCode, err := traktAPIRequest(http.MethodPost, "/oauth/token", "", map[string]string{
+		"refresh_token": utils.Decrypt(connection.RefreshToken),
+		"client_id":     os.Getenv("TRAKT_CLIENT_ID"),
+		"client_secret": os.Getenv("TRAKT_CLIENT_SECRET"),
+		"redirect_uri":  "urn:ietf:wg:oauth:2.0:oob",
+		"grant_type":    "refresh_token",
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": connection.UserID,
+		}).Error("failed to refresh trakt token: ", err)
+
+		return TraktConnection{}, fmt.Errorf("Failed to refresh Trakt token.")
+	}
+
+	if statusCode == http.StatusBadRequest || statusCode == http.StatusUnauthorized {
+		// Another request may have refreshed the token in the meantime.
+		latestConnection, err := traktSyncModel.GetTraktConnection(connection.UserID)
+		if err == nil && latestConnection.RefreshToken != connection.RefreshToken {
+			return latestConnection, nil
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"uid": connection.UserID,
+		}).Warn("trakt refresh token is rejected, disconnecting")
+
+		traktSyncModel.DeleteTraktConnectionByUserID(connection.UserID)
+
+		return TraktConnection{}, nil
+	}
+
+	if statusCode != http.StatusOK {
+		logrus.WithFields(logrus.Fields{
+			"uid":         connection.UserID,
+			"status_code": statusCode,
+		}).Error("unexpected trakt refresh token status")
+
+		return TraktConnection{}, fmt.Errorf("Failed to refresh Trakt token.")
+	}
+
+	var token responses.TraktToken
+	if err := json.Unmarshal(body, &token); err != nil {
+		return TraktConnection{}, fmt.Errorf("Failed to parse Trakt token.")
+	}
+
+	expiresAt := traktTokenExpiresAt(token)
+
+	connection.AccessToken = utils.Encrypt(token.AccessToken)
+	connection.RefreshToken = utils.Encrypt(token.RefreshToken)
+	connection.ExpiresAt = &expiresAt
+
+	if _, err := traktSyncModel.TraktConnectionCollection.UpdateOne(context.TODO(), bson.M{
+		"_id": connection.ID,
+	}, bson.M{"$set": bson.M{
+		"access_token":  connection.AccessToken,
+		"refresh_token": connection.RefreshToken,
+		"expires_at":    connection.ExpiresAt,
+	}}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": connection.UserID,
+		}).Error("failed to save refreshed trakt token: ", err)
+
+		return TraktConnection{}, fmt.Errorf("Failed to refresh Trakt token.")
+	}
+
+	return connection, nil
+}
+
+// PushMovie adds the plays and the watchlist addition of the movie that Trakt
+// doesn't know yet.
+func (traktSyncModel *TraktSyncModel) PushMovie(uid string, movieList MovieWatchList) {
+	tmdbID, err := strconv.Atoi(movieList.MovieTmdbID)
+	if err != nil {
+		return
+	}
+
+	accessToken, state, shouldReturn := traktSyncModel.getPushState(uid, "movie", movieList.MovieTmdbID)
+	if shouldReturn {
+		return
+	}
+
+	plays := movieList.TimesFinished
+	if movieList.Status == "finished" && plays == 0 {
+		plays = 1
+	}
+
+	if plays > state.Plays {
+		watchedAt := time.Now().UTC()
+		if movieList.FinishedAt != nil {
+			watchedAt = *movieList.FinishedAt
+		}
+
+		var movies []responses.TraktSyncItem
+		for i := state.Plays; i < plays; i++ {
+			movies = append(movies, responses.TraktSyncItem{
+				IDs:       responses.TraktSyncIDs{Tmdb: tmdbID},
+				WatchedAt: watchedAt.Format(time.RFC3339),
+			})
+		}
+
+		if traktSyncModel.push(uid, "/sync/history", accessToken, responses.TraktSyncItems{Movies: movies}) {
+			traktSyncModel.updateSyncState(uid, "movie", movieList.MovieTmdbID, bson.M{
+				"$max": bson.M{"plays": plays},
+			})
+		}
+
+		return
+	}
+
+	if movieList.Status == "planto" && state.Plays == 0 && !state.IsWatchlisted {
+		traktSyncModel.pushWatchlist(uid, "movie", movieList.MovieTmdbID, tmdbID, accessToken)
+	}
+}
+
+// PushTVSeries adds the watched episodes and the watchlist addition of the tv
+// series that Trakt doesn't know yet.
+func (traktSyncModel *TraktSyncModel) PushTVSeries(uid string, tvList TVSeriesWatchList, episodeWatches []EpisodeWatch) {
+	tmdbID, err := strconv.Atoi(tvList.TvTmdbID)
+	if err != nil {
+		return
+	}
+
+	accessToken, state, shouldReturn := traktSyncModel.getPushState(uid, "tv", tvList.TvTmdbID)
+	if shouldReturn {
+		return
+	}
+
+	syncedEpisodes := make(map[TraktSyncedEpisode]bool, len(state.Episodes))
+	for _, episode := range state.Episodes {
+		syncedEpisodes[episode] = true
+	}
+
+	var (
+		seasons     []responses.TraktSyncSeason
+		newEpisodes []TraktSyncedEpisode
+	)
+
+	for _, episodeWatch := range episodeWatches {
+		episode := TraktSyncedEpisode{
+			SeasonNumber:  episodeWatch.SeasonNumber,
+			EpisodeNumber: episodeWatch.EpisodeNumber,
+		}
+		if syncedEpisodes[episode] {
+			continue
+		}
+
+		// Episode watches are sorted by season.
+		if len(seasons) == 0 || seasons[len(seasons)-1].Number != episode.SeasonNumber {
+			seasons = append(seasons, responses.TraktSyncSeason{Number: episode.SeasonNumber})
+		}
+
+		seasons[len(seasons)-1].Episodes = append(seasons[len(seasons)-1].Episodes, responses.TraktSyncEpisode{
+			Number:    episode.EpisodeNumber,
+			WatchedAt: episodeWatch.WatchedAt.Format(time.RFC3339),
+		})
+		newEpisodes = append(newEpisodes, episode)
+	}
+
+	if len(newEpisodes) > 0 {
+		if traktSyncModel.push(uid, "/sync/history", accessToken, responses.TraktSyncItems{Shows: []responses.TraktSyncItem{{
+			IDs:     responses.TraktSyncIDs{Tmdb: tmdbID},
+			Seasons: seasons,
+		}}}) {
+			traktSyncModel.updateSyncState(uid, "tv", tvList.TvTmdbID, bson.M{
+				"$addToSet": bson.M{"episodes": bson.M{"$each": newEpisodes}},
+			})
+		}
+
+		return
+	}
+
+	if tvList.Status == "planto" && len(state.Episodes) == 0 && !state.IsWatchlisted {
+		traktSyncModel.pushWatchlist(uid, "tv", tvList.TvTmdbID, tmdbID, accessToken)
+	}
+}
+
+// PushWatchlist adds the movie or tv series to the watchlist if it is not in
+// the watchlist or watched on Trakt.
+func (traktSyncModel *TraktSyncModel) PushWatchlist(uid, contentType, tmdbID string) {
+	tmdbIntID, err := strconv.Atoi(tmdbID)
+	if err != nil {
+		return
+	}
+
+	accessToken, state, shouldReturn := traktSyncModel.getPushState(uid, contentType, tmdbID)
+	if shouldReturn || state.IsWatchlisted || state.Plays > 0 || len(state.Episodes) > 0 {
+		return
+	}
+
+	traktSyncModel.pushWatchlist(uid, contentType, tmdbID, tmdbIntID, accessToken)
+}
+
+func (traktSyncModel *TraktSyncModel) pushWatchlist(uid, contentType, tmdbID string, tmdbIntID int, accessToken string) {
+	items := responses.TraktSyncItems{}
+	item := responses.TraktSyncItem{IDs: responses.TraktSyncIDs{Tmdb: tmdbIntID}}
+
+	if contentType == "movie" {
+		items.Movies = []responses.TraktSyncItem{item}
+	} else {
+		items.Shows = []responses.TraktSyncItem{item}
+	}
+
+	if traktSyncModel.push(uid, "/sync/watchlist", accessToken, items) {
+		traktSyncModel.updateSyncState(uid, contentType, tmdbID, bson.M{
+			"$set": bson.M{"is_watchlisted": true},
+		})
+	}
+}
+
+func (traktSyncModel *TraktSyncModel) push(uid, path, accessToken string, items responses.TraktSyncItems) bool {
+	_, statusCode, err := traktAPIRequest(http.MethodPost, path, accessToken, items)
+	if err != nil || statusCode != http.StatusCreated {
+		logrus.WithFields(logrus.Fields{
+			"uid":         uid,
+			"path":        path,
+			"status_code": statusCode,
+		}).Error("failed to push to trakt: ", err)
+
+		return false
+	}
+
+	if _, err := traktSyncModel.TraktConnectionCollection.UpdateOne(context.TODO(), bson.M{
+		"user_id": uid,
+	}, bson.M{"$set": bson.M{
+		"last_pushed_at": time.Now().UTC(),
+	}}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to update trakt last pushed at: ", err)
+	}
+
+	return true
+}
+
+// RecordPulledStates keeps what is pulled from Trakt as the sync state, so
+// that the pulled entries are not pushed back.
+func (traktSyncModel *TraktSyncModel) RecordPulledStates(
+	uid string, watchedMovies []responses.TraktWatchedMovie,
+	watchedShows []responses.TraktWatchedShow, watchlistItems []responses.TraktWatchlistItem,
+) {
+	var writes []mongo.WriteModel
+
+	syncStateWrite := func(contentType string, tmdbID int, set, update bson.M) {
+		set["updated_at"] = time.Now().UTC()
+		update["$set"] = set
+
+		writes = append(writes, mongo.NewUpdateOneModel().SetFilter(bson.M{
+			"user_id":      uid,
+			"content_type": contentType,
+			"tmdb_id":      strconv.Itoa(tmdbID),
+		}).SetUpdate(update).SetUpsert(true))
+	}
+
+	for _, movie := range watchedMovies {
+		if movie.Movie.IDs.TMDB != 0 {
+			syncStateWrite("movie", movie.Movie.IDs.TMDB, bson.M{}, bson.M{
+				"$max": bson.M{"plays": movie.Plays},
+			})
+		}
+	}
+
+	for _, show := range watchedShows {
+		if show.Show.IDs.TMDB == 0 {
+			continue
+		}
+
+		episodes := []TraktSyncedEpisode{}
+		for _, season := range show.Seasons {
+			for _, episode := range season.Episodes {
+				episodes = append(episodes, TraktSyncedEpisode{
+					SeasonNumber:  season.Number,
+					EpisodeNumber: episode.Number,
+				})
+			}
+		}
+
+		syncStateWrite("tv", show.Show.IDs.TMDB, bson.M{}, bson.M{
+			"$addToSet": bson.M{"episodes": bson.M{"$each": episodes}},
+		})
+	}
+
+	for _, item := range watchlistItems {
+		if item.Movie != nil && item.Movie.IDs.TMDB != 0 {
+			syncStateWrite("movie", item.Movie.IDs.TMDB, bson.M{"is_watchlisted": true}, bson.M{})
+		} else if item.Show != nil && item.Show.IDs.TMDB != 0 {
+			syncStateWrite("tv", item.Show.IDs.TMDB, bson.M{"is_watchlisted": true}, bson.M{})
+		}
+	}
+
+	if len(writes) == 0 {
+		return
+	}
+
+	if _, err := traktSyncModel.TraktSyncStateCollection.BulkWrite(
+		context.TODO(), writes, options.BulkWrite().SetOrdered(false),
+	); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to record pulled trakt sync states: ", err)
+	}
+}
+
+func (traktSyncModel *TraktSyncModel) updateSyncState(uid, contentType, tmdbID string, update bson.M) {
+	if update["$set"] == nil {
+		update["$set"] = bson.M{}
+	}
+	update["$set"].(bson.M)["updated_at"] = time.Now().UTC()
+
+	if _, err := traktSyncModel.TraktSyncStateCollection.UpdateOne(context.TODO(), bson.M{
+		"user_id":      uid,
+		"content_type": contentType,
+		"tmdb_id":      tmdbID,
+	}, update, options.Update().SetUpsert(true)); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid":          uid,
+			"content_type": contentType,
+			"tmdb_id":      tmdbID,
+		}).Error("failed to update trakt sync state: ", err)
+	}
+}
+
+// ! Get
+func (traktSyncModel *TraktSyncModel) GetTraktConnection(uid string) (TraktConnection, error) {
+	result := traktSyncModel.TraktConnectionCollection.FindOne(context.TODO(), bson.M{
+		"user_id": uid,
+	})
+
+	var connection TraktConnection
+	if err := result.Decode(&connection); err != nil && err != mongo.ErrNoDocuments {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to find trakt connection: ", err)
+
+		return TraktConnection{}, fmt.Errorf("Failed to find Trakt connection.")
+	}
+
+	return connection, nil
+}
+
+// GetAccessToken returns the decrypted access token of the connected account,
+// the token is refreshed if it is about to expire. Token is empty if the user
+// is not connected.
+func (traktSyncModel *TraktSyncModel) GetAccessToken(uid string) (TraktConnection, string, error) {
+	connection, err := traktSyncModel.GetTraktConnection(uid)
+	if err != nil || !connection.IsConnected() {
+		return TraktConnection{}, "", err
+	}
+
+	if connection.ExpiresAt == nil || connection.ExpiresAt.Before(time.Now().UTC().Add(traktTokenRefreshWindow)) {
+		if connection, err = traktSyncModel.refreshAccessToken(connection); err != nil || !connection.IsConnected() {
+			return TraktConnection{}, "", err
+		}
+	}
+
+	return connection, utils.Decrypt(connection.AccessToken), nil
+}
+
+// getPushState returns the access token and the sync state of the content,
+// shouldReturn is true if the user is not connected.
+func (traktSyncModel *TraktSyncModel) getPushState(uid, contentType, tmdbID string) (string, TraktSyncState, bool) {
+	_, accessToken, err := traktSyncModel.GetAccessToken(uid)
+	if err != nil || accessToken == "" {
+		return "", TraktSyncState{}, true
+	}
+
+	result := traktSyncModel.TraktSyncStateCollection.FindOne(context.TODO(), bson.M{
+		"user_id":      uid,
+		"content_type": contentType,
+		"tmdb_id":      tmdbID,
+	})
+
+	var state TraktSyncState
+	if err := result.Decode(&state); err != nil && err != mongo.ErrNoDocuments {
+		logrus.WithFields(logrus.Fields{
+			"uid":          uid,
+			"content_type": contentType,
+			"tmdb_id":      tmdbID,
+		}).Error("failed to find trakt sync state: ", err)
+
+		return "", TraktSyncState{}, true
+	}
+
+	return accessToken, state, false
+}
+
+// ! Delete
+// Disconnect revokes the access token and removes the connection with its
+// sync states.
+func (traktSyncModel *TraktSyncModel) Disconnect(uid string) {
+	if _, accessToken, err := traktSyncModel.GetAccessToken(uid); err == nil && accessToken != "" {
+		if _, _, err := traktAPIRequest(http.MethodPost, "/oauth/revoke", "", map[string]string{
+			"token":         accessToken,
+			"client_id":     os.Getenv("TRAKT_CLIENT_ID"),
+			"client_secret": os.Getenv("TRAKT_CLIENT_SECRET"),
+		}); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"uid": uid,
+			}).Warn("failed to revoke trakt token: ", err)
+		}
+	}
+
+	traktSyncModel.DeleteTraktConnectionByUserID(uid)
+}
+
+func (traktSyncModel *TraktSyncModel) DeleteTraktConnectionByUserID(uid string) {
+	if _, err := traktSyncModel.TraktConnectionCollection.DeleteMany(context.TODO(), bson.M{
+		"user_id": uid,
+	}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to delete trakt connection by user id: ", err)
+	}
+
+	if _, err := traktSyncModel.TraktSyncStateCollection.DeleteMany(context.TODO(), bson.M{
+		"user_id": uid,
+	}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to delete trakt sync states by user id: ", err)
+	}
+}
+
+func traktTokenExpiresAt(token responses.TraktToken) time.Time {
+	createdAt := time.Now().UTC()
+	if token.CreatedAt != 0 {
+		createdAt = time.Unix(token.CreatedAt, 0).UTC()
+	}
+
+	return createdAt.Add(time.Duration(token.ExpiresIn) * time.Second)
+}
+
+func traktAPIRequest(method, path, accessToken string, data interface{}) ([]byte, int, error) {
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+
+	var requestBody io.Reader
+	if data != nil {
+		jsonData, err := json.Marshal(data)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		requestBody = bytes.NewReader(jsonData)
+	}
+
+	req, err := http.NewRequest(method, traktAPIURL+path, requestBody)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("trakt-api-version", "2")
+	req.Header.Set("trakt-api-key", os.Getenv("TRAKT_CLIENT_ID"))
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+
+	return body, resp.StatusCode, nil
+}
@@ -0,0 +1,529 @@
+package models
+
+import (
+	"app/db"
+	"app/requests"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	p "github.com/gobeam/mongo-go-pagination"
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+//lint:file-ignore ST1005 Ignore all
+
+// UnmatchedImportModel keeps the import entries that couldn't be matched with
+// our content so that the user can link them later.
+type UnmatchedImportModel struct {
+	UnmatchedImportCollection *mongo.Collection
+	AnimeCollection           *mongo.Collection
+	MangaCollection           *mongo.Collection
+	GameCollection            *mongo.Collection
+	MovieCollection           *mongo.Collection
+	TVCollection              *mongo.Collection
+}
+
+func NewUnmatchedImportModel(mongoDB *db.MongoDB) *UnmatchedImportModel {
+	return &UnmatchedImportModel{
+		UnmatchedImportCollection: mongoDB.Database.Collection("unmatched-import-items"),
+		AnimeCollection:           mongoDB.Database.Collection("animes"),
+		MangaCollection:           mongoDB.Database.Collection("mangas"),
+		GameCollection:            mongoDB.Database.Collection("games"),
+		MovieCollection:           mongoDB.Database.Collection("movies"),
+		TVCollection:              mongoDB.Database.Collection("tv-series"),
+	}
+}
+
+const unmatchedImportPagination = 25
+
+const (
+	PendingUnmatchedImportStatus   = "pending"
+	LinkedUnmatchedImportStatus    = "linked"
+	DismissedUnmatchedImportStatus = "dismissed"
+)
+
+const (
+	// Search results that are compared with the title of the entry.
+	importCandidateSearchLimit = 10
+	importCandidateLimit       = 5
+	// Candidates below this similarity are not proposed, 1 is the same title.
+	importCandidateMinSimilarity = 0.5
+	importCandidateYearBonus     = 0.1
+	importCandidateYearPenalty   = 0.2
+)
+
+// Search indexes of the content types, manga doesn't have one so it doesn't
+// get candidates.
+var importCandidateSearchIndexes = map[string]string{
+	"anime": "anime_search",
+	"game":  "game_search",
+	"movie": "movies_search",
+	"tv":    "tv_series_search",
+}
+
+var importCandidateSearchPaths = map[string]bson.A{
+	"anime": {"title_en", "title_jp", "title_original"},
+	"game":  {"title", "title_original"},
+	"movie": {"title_en", "title_original"},
+	"tv":    {"title_en", "title_original"},
+}
+
+// UnmatchedImportItem is an import entry that is not in our catalog. Items are
+// unique by user and key, linked items are matched with their content when the
+// same entry is imported again.
+type UnmatchedImportItem struct {
+	ID          primitive.ObjectID     `bson:"_id,omitempty" json:"_id"`
+	UserID      string                 `bson:"user_id" json:"user_id"`
+	Source      string                 `bson:"source" json:"source"`
+	ImportJobID primitive.ObjectID     `bson:"import_job_id" json:"import_job_id"`
+	Key         string                 `bson:"key" json:"key"`
+	Entry       ImportEntry            `bson:"entry" json:"entry"`
+	Status      string                 `bson:"status" json:"status"`
+	ContentID   string                 `bson:"content_id" json:"content_id"`
+	Candidates  []ImportMatchCandidate `bson:"candidates" json:"candidates"`
+	CreatedAt   time.Time              `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time              `bson:"updated_at" json:"updated_at"`
+}
+
+type ImportMatchCandidate struct {
+	ContentID  string  `bson:"content_id" json:"content_id"`
+	Title      string  `bson:"title" json:"title"`
+	Year       int     `bson:"year" json:"year"`
+	ImageURL   string  `bson:"image_url" json:"image_url"`
+	Similarity float64 `bson:"similarity" json:"similarity"`
+}
+
+type importCandidateContent struct {
+	ID            primitive.ObjectID `bson:"_id"`
+	Title         string             `bson:"title"`
+	TitleEn       string             `bson:"title_en"`
+	TitleJP       string             `bson:"title_jp"`
+	TitleOriginal string             `bson:"title_original"`
+	ImageURL      string             `bson:"image_url"`
+	Year          *int               `bson:"year"`
+	ReleaseDate   string             `bson:"release_date"`
+	FirstAirDate  string             `bson:"first_air_date"`
+}
+
+func (content importCandidateContent) titles() []string {
+	var titles []string
+	for _, title := range []string{content.TitleEn, content.Title, content.TitleOriginal, content.TitleJP} {
+		if title != "" {
+			titles = append(titles, title)
+		}
+	}
+
+	return titles
+}
+
+func (content importCandidateContent) year() int {
+	if content.Year != nil {
+		return *content.Year
+	}
+
+	date := content.ReleaseDate
+	if date == "" {
+		date = content.FirstAirDate
+	}
+
+	if len(date) < 4 {
+		return 0
+	}
+
+	year, _ := strconv.Atoi(date[:4])
+	return year
+}
+
+// ! Create
+// SaveUnmatchedItems keeps the unmatched items of the import with their
+// candidates. Items that were already saved only get the latest entry, linked
+// and dismissed items are left as they are.
+func (unmatchedImportModel *UnmatchedImportModel) SaveUnmatchedItems(uid, source string, importJobID primitive.ObjectID, items []ImportItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(items))
+	for _, item := range items {
+		keys = append(keys, item.Key)
+	}
+
+	existingItems, err := unmatchedImportModel.getUnmatchedItemsByKeys(uid, keys)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	writes := make([]mongo.WriteModel, 0, len(items))
+	for _, item := range items {
+		if existingItem, exists := existingItems[item.Key]; exists {
+			if existingItem.Status == PendingUnmatchedImportStatus {
+				writes = append(writes, mongo.NewUpdateOneModel().SetFilter(bson.M{
+					"_id": existingItem.ID,
+				}).SetUpdate(bson.M{"$set": bson.M{
+					"source":        source,
+					"import_job_id": importJobID,
+					"entry":         item.Entry,
+					"updated_at":    now,
+				}}))
+			}
+
+			continue
+		}
+
+		existingItems[item.Key] = UnmatchedImportItem{Status: PendingUnmatchedImportStatus}
+
+		writes = append(writes, mongo.NewInsertOneModel().SetDocument(UnmatchedImportItem{
+			UserID:      uid,
+			Source:      source,
+			ImportJobID: importJobID,
+			Key:         item.Key,
+			Entry:       item.Entry,
+			Status:      PendingUnmatchedImportStatus,
+			Candidates:  unmatchedImportModel.getMatchCandidates(item.Entry),
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}))
+	}
+
+	if len(writes) == 0 {
+		return nil
+	}
+
+	if _, err := unmatchedImportModel.UnmatchedImportCollection.BulkWrite(
+		context.TODO(), writes, options.BulkWrite().SetOrdered(false),
+	); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid":           uid,
+			"import_job_id": importJobID,
+			"count":         len(writes),
+		}).Error("failed to save unmatched import items: ", err)
+
+		return fmt.Errorf("Failed to save unmatched import items.")
+	}
+
+	return nil
+}
+
+// getMatchCandidates proposes the contents with a similar title, the same year
+// ranks a candidate higher. Search failures only leave the item without
+// candidates.
+func (unmatchedImportModel *UnmatchedImportModel) getMatchCandidates(entry ImportEntry) []ImportMatchCandidate {
+	candidates := []ImportMatchCandidate{}
+
+	index, ok := importCandidateSearchIndexes[entry.ContentType]
+	if !ok || normalizeImportTitle(entry.Title) == "" {
+		return candidates
+	}
+
+	cursor, err := unmatchedImportModel.getContentCollection(entry.ContentType).Aggregate(context.TODO(), bson.A{
+		bson.M{"$search": bson.M{
+			"index": index,
+			"text": bson.M{
+				"query": entry.Title,
+				"path":  importCandidateSearchPaths[entry.ContentType],
+				"fuzzy": bson.M{
+					"maxEdits": 1,
+				},
+			},
+		}},
+		bson.M{"$limit": importCandidateSearchLimit},
+		bson.M{"$project": bson.M{
+			"title":          1,
+			"title_en":       1,
+			"title_jp":       1,
+			"title_original": 1,
+			"image_url":      1,
+			"year":           1,
+			"release_date":   1,
+			"first_air_date": 1,
+		}},
+	})
+	if err == nil {
+		var contents []importCandidateContent
+		if err = cursor.All(context.TODO(), &contents); err == nil {
+			for _, content := range contents {
+				similarity := 0.0
+				for _, title := range content.titles() {
+					if titleSimilarity := importTitleSimilarity(entry.Title, title); titleSimilarity > similarity {
+						similarity = titleSimilarity
+					}
+				}
+
+				year := content.year()
+				if entry.Year != 0 && year != 0 {
+					switch yearDifference := entry.Year - year; {
+					case yearDifference == 0:
+						similarity += importCandidateYearBonus
+					case yearDifference > 1 || yearDifference < -1:
+						similarity -= importCandidateYearPenalty
+					}
+				}
+
+				if similarity < importCandidateMinSimilarity {
+					continue
+				}
+
+				candidates = append(candidates, ImportMatchCandidate{
+					ContentID:  content.ID.Hex(),
+					Title:      content.titles()[0],
+					Year:       year,
+					ImageURL:   content.ImageURL,
+					Similarity: similarity,
+				})
+			}
+		}
+	}
+
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"content_type": entry.ContentType,
+			"title":        entry.Title,
+		}).Error("failed to search import match candidates: ", err)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Similarity > candidates[j].Similarity
+	})
+
+	if len(candidates) > importCandidateLimit {
+		candidates = candidates[:importCandidateLimit]
+	}
+
+	return candidates
+}
+
+// normalizeImportTitle lowercases the title and drops punctuation so that e.g.
+// "Spider-Man: No Way Home" and "spider man no way home" are the same.
+func normalizeImportTitle(title string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}), " ")
+}
+
+// importTitleSimilarity is 1 minus the edit distance of the normalized titles
+// relative to the longer title.
+func importTitleSimilarity(title, otherTitle string) float64 {
+	a, b := []rune(normalizeImportTitle(title)), []rune(normalizeImportTitle(otherTitle))
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return 1 - float64(previous[len(b)])/float64(max(len(a), len(b)))
+}
+
+// ! Update
+func (unmatchedImportModel *UnmatchedImportModel) LinkUnmatchedItem(item UnmatchedImportItem, contentID string) error {
+	return unmatchedImportModel.updateUnmatchedItemStatus(item, LinkedUnmatchedImportStatus, contentID)
+}
+
+func (unmatchedImportModel *UnmatchedImportModel) DismissUnmatchedItem(item UnmatchedImportItem) error {
+	return unmatchedImportModel.updateUnmatchedItemStatus(item, DismissedUnmatchedImportStatus, "")
+}
+
+func (unmatchedImportModel *UnmatchedImportModel) updateUnmatchedItemStatus(item UnmatchedImportItem, status, contentID string) error {
+	if _, err := unmatchedImportModel.UnmatchedImportCollection.UpdateOne(context.TODO(), bson.M{
+		"_id": item.ID,
+	}, bson.M{"$set": bson.M{
+		"status":     status,
+		"content_id": contentID,
+		"updated_at": time.Now().UTC(),
+	}}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"unmatched_item_id": item.ID,
+			"status":            status,
+		}).Error("failed to update unmatched import item: ", err)
+
+		return fmt.Errorf("Failed to update unmatched import item.")
+	}
+
+	return nil
+}
+
+// ! Get
+func (unmatchedImportModel *UnmatchedImportModel) GetUnmatchedItems(uid string, data requests.GetUnmatchedImportItems) ([]UnmatchedImportItem, p.PaginationData, error) {
+	match := bson.M{
+		"user_id": uid,
+		"status":  PendingUnmatchedImportStatus,
+	}
+
+	if data.Status != nil {
+		match["status"] = *data.Status
+	}
+
+	if data.Type != nil {
+		match["entry.content_type"] = *data.Type
+	}
+
+	paginatedData, err := p.New(unmatchedImportModel.UnmatchedImportCollection).Context(context.TODO()).
+		Limit(unmatchedImportPagination).Page(data.Page).Sort("created_at", -1).Aggregate(
+		bson.M{"$match": match},
+	)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid":     uid,
+			"request": data,
+		}).Error("failed to aggregate unmatched import items: ", err)
+
+		return nil, p.PaginationData{}, fmt.Errorf("Failed to get unmatched import items.")
+	}
+
+	items := []UnmatchedImportItem{}
+	for _, raw := range paginatedData.Data {
+		var item *UnmatchedImportItem
+		if marshalErr := bson.Unmarshal(raw, &item); marshalErr == nil {
+			items = append(items, *item)
+		}
+	}
+
+	return items, paginatedData.Pagination, nil
+}
+
+func (unmatchedImportModel *UnmatchedImportModel) GetUnmatchedItemByID(itemID string) (UnmatchedImportItem, error) {
+	objectID, _ := primitive.ObjectIDFromHex(itemID)
+
+	result := unmatchedImportModel.UnmatchedImportCollection.FindOne(context.TODO(), bson.M{
+		"_id": objectID,
+	})
+
+	var item UnmatchedImportItem
+	if err := result.Decode(&item); err != nil && err != mongo.ErrNoDocuments {
+		logrus.WithFields(logrus.Fields{
+			"unmatched_item_id": itemID,
+		}).Error("failed to find unmatched import item by id: ", err)
+
+		return UnmatchedImportItem{}, fmt.Errorf("Failed to find unmatched import item.")
+	}
+
+	return item, nil
+}
+
+// IsContentExists checks the content that the item is linked to.
+func (unmatchedImportModel *UnmatchedImportModel) IsContentExists(contentType, contentID string) (bool, error) {
+	objectID, err := primitive.ObjectIDFromHex(contentID)
+	if err != nil {
+		return false, nil
+	}
+
+	count, err := unmatchedImportModel.getContentCollection(contentType).CountDocuments(context.TODO(), bson.M{
+		"_id": objectID,
+	}, options.Count().SetLimit(1))
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"content_type": contentType,
+			"content_id":   contentID,
+		}).Error("failed to count content: ", err)
+
+		return false, fmt.Errorf("Failed to find content.")
+	}
+
+	return count > 0, nil
+}
+
+// getLinkedContentIDs returns the content ids of the linked items by key.
+func (unmatchedImportModel *UnmatchedImportModel) getLinkedContentIDs(uid string, keys []string) (map[string]string, error) {
+	cursor, err := unmatchedImportModel.UnmatchedImportCollection.Find(context.TODO(), bson.M{
+		"user_id": uid,
+		"key":     bson.M{"$in": keys},
+		"status":  LinkedUnmatchedImportStatus,
+	}, options.Find().SetProjection(bson.M{
+		"key":        1,
+		"content_id": 1,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	var items []UnmatchedImportItem
+	if err := cursor.All(context.TODO(), &items); err != nil {
+		return nil, err
+	}
+
+	contentIDs := make(map[string]string, len(items))
+	for _, item := range items {
+		contentIDs[item.Key] = item.ContentID
+	}
+
+	return contentIDs, nil
+}
+
+func (unmatchedImportModel *UnmatchedImportModel) getUnmatchedItemsByKeys(uid string, keys []string) (map[string]UnmatchedImportItem, error) {
+	cursor, err := unmatchedImportModel.UnmatchedImportCollection.Find(context.TODO(), bson.M{
+		"user_id": uid,
+		"key":     bson.M{"$in": keys},
+	}, options.Find().SetProjection(bson.M{
+		"key":    1,
+		"status": 1,
+	}))
+	if err == nil {
+		var items []UnmatchedImportItem
+		if err = cursor.All(context.TODO(), &items); err == nil {
+			existingItems := make(map[string]UnmatchedImportItem, len(items))
+			for _, item := range items {
+				existingItems[item.Key] = item
+			}
+
+			return existingItems, nil
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"uid": uid,
+	}).Error("failed to get unmatched import items by keys: ", err)
+
+	return nil, fmt.Errorf("Failed to get unmatched import items.")
+}
+
+func (unmatchedImportModel *UnmatchedImportModel) getContentCollection(contentType string) *mongo.Collection {
+	switch contentType {
+	case "anime":
+		return unmatchedImportModel.AnimeCollection
+	case "manga":
+		return unmatchedImportModel.MangaCollection
+	case "game":
+		return unmatchedImportModel.GameCollection
+	case "movie":
+		return unmatchedImportModel.MovieCollection
+	}
+
+	return unmatchedImportModel.TVCollection
+}
+
+// ! Delete
+func (unmatchedImportModel *UnmatchedImportModel) DeleteUnmatchedItemsByUserID(uid string) {
+	if _, err := unmatchedImportModel.UnmatchedImportCollection.DeleteMany(context.TODO(), bson.M{
+		"user_id": uid,
+	}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to delete unmatched import items by user id: ", err)
+	}
+}
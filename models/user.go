@@ -8,26 +8,38 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 //lint:file-ignore ST1005 Ignore all
 
 type UserModel struct {
-	Collection *mongo.Collection
+	Collection            *mongo.Collection
+	EntitlementCollection *mongo.Collection
 }
 
 func NewUserModel(mongoDB *db.MongoDB) *UserModel {
 	return &UserModel{
-		Collection: mongoDB.Database.Collection("users"),
+		Collection:            mongoDB.Database.Collection("users"),
+		EntitlementCollection: mongoDB.Database.Collection("entitlements"),
 	}
 }
 
+const (
+	PasswordResetTimeout      = 30 * time.Minute
+	passwordResetCooldown     = 5 * time.Minute
+	EmailVerificationTimeout  = 24 * time.Hour
+	emailVerificationCooldown = 2 * time.Minute
+	emailVerificationPurpose  = "email-verification"
+)
+
 const legendMovieThreshold = 6
 const legendTVThreshold = 3
 const legendAnimeThreshold = 3
@@ -36,26 +48,34 @@ const legendGameThreshold = 3
 const legendGameHoursPlayedThreshold = 350
 
 type User struct {
-	ID                 primitive.ObjectID `bson:"_id,omitempty" json:"_id"`
-	Username           string             `bson:"username" json:"username"`
-	EmailAddress       string             `bson:"email" json:"email"`
-	Image              string             `bson:"image" json:"image"`
-	Friends            []string           `bson:"friends" json:"friends"`
-	Password           string             `bson:"password" json:"-"`
-	PasswordResetToken string             `bson:"reset_token" json:"-"`
-	CreatedAt          time.Time          `bson:"created_at" json:"-"`
-	UpdatedAt          time.Time          `bson:"updated_at" json:"-"`
-	IsPremium          bool               `bson:"is_premium" json:"is_premium"`
-	IsLifetimePremium  bool               `bson:"is_lifetime_premium" json:"is_lifetime_premium"`
-	IsBanned           bool               `bson:"is_banned" json:"is_banned"`
-	MembershipType     int                `bson:"membership_type" json:"membership_type"` //0 Basic, 1 Premium 2 Premium Supporter
-	IsOAuthUser        bool               `bson:"is_oauth" json:"is_oauth"`
-	OAuthType          *int               `bson:"oauth_type" json:"oauth_type"` //0 google, 1 apple
-	RefreshToken       *string            `bson:"refresh_token" json:"-"`
-	FCMToken           string             `bson:"fcm_token" json:"fcm_token"`
-	CanChangeUsername  bool               `bson:"can_change_username" json:"can_change_username"`
-	AppNotification    Notification       `bson:"app_notification" json:"app_notification"`
-	MailNotification   Notification       `bson:"mail_notification" json:"mail_notification"`
+	ID                     primitive.ObjectID `bson:"_id,omitempty" json:"_id"`
+	Username               string             `bson:"username" json:"username"`
+	EmailAddress           string             `bson:"email" json:"email"`
+	Image                  string             `bson:"image" json:"image"`
+	Friends                []string           `bson:"friends" json:"friends"`
+	Password               string             `bson:"password" json:"-"`
+	PasswordResetTokenHash string             `bson:"reset_token_hash" json:"-"`
+	PasswordResetExpiresAt *time.Time         `bson:"reset_token_expires_at" json:"-"`
+	CreatedAt              time.Time          `bson:"created_at" json:"-"`
+	UpdatedAt              time.Time          `bson:"updated_at" json:"-"`
+	IsPremium              bool               `bson:"is_premium" json:"is_premium"`
+	IsLifetimePremium      bool               `bson:"is_lifetime_premium" json:"is_lifetime_premium"`
+	IsBanned               bool               `bson:"is_banned" json:"is_banned"`
+	MembershipType         int                `bson:"membership_type" json:"membership_type"` //0 Basic, 1 Premium 2 Premium Supporter
+	IsOAuthUser            bool               `bson:"is_oauth" json:"is_oauth"`
+	OAuthType              *int               `bson:"oauth_type" json:"oauth_type"` //0 google, 1 apple
+	RefreshToken           *string            `bson:"refresh_token" json:"-"`
+	FCMToken               string             `bson:"fcm_token" json:"fcm_token"`
+	CanChangeUsername      bool               `bson:"can_change_username" json:"can_change_username"`
+	AppNotification        Notification       `bson:"app_notification" json:"app_notification"`
+	MailNotification       Notification       `bson:"mail_notification" json:"mail_notification"`
+	// IsEmailVerified is nil for the users registered before the verification,
+	// they are counted as verified.
+	IsEmailVerified         *bool      `bson:"is_email_verified" json:"-"`
+	EmailVerificationSentAt *time.Time `bson:"email_verification_sent_at" json:"-"`
+	// IsLegacyPremium is set by the migration for the members that purchased
+	// before the receipts were verified, clients can't write it.
+	IsLegacyPremium bool `bson:"is_legacy_premium" json:"-"`
 }
 
 type Notification struct {
@@ -65,8 +85,14 @@ type Notification struct {
 	ReviewLikes bool `bson:"review_likes" json:"review_likes"`
 }
 
+func (user User) HasVerifiedEmail() bool {
+	return user.IsEmailVerified == nil || *user.IsEmailVerified
+}
+
 // Create
 func createUserObject(emailAddress, username, password, fcmToken, image string) *User {
+	isEmailVerified := false
+
 	return &User{
 		Username:          username,
 		EmailAddress:      emailAddress,
@@ -84,10 +110,14 @@ func createUserObject(emailAddress, username, password, fcmToken, image string)
 		AppNotification:   createNotificationObject(true, true, true, true),
 		MailNotification:  createNotificationObject(true, true, false, false),
 		FCMToken:          fcmToken,
+		IsEmailVerified:   &isEmailVerified,
 	}
 }
 
 func createOAuthUserObject(emailAddress, username, fcmToken, image string, refreshToken *string, oAuthType int) *User {
+	// Emails of the OAuth providers are already verified.
+	isEmailVerified := true
+
 	return &User{
 		EmailAddress:      emailAddress,
 		Username:          username,
@@ -106,6 +136,7 @@ func createOAuthUserObject(emailAddress, username, fcmToken, image string, refre
 		OAuthType:         &oAuthType,
 		RefreshToken:      refreshToken,
 		FCMToken:          fcmToken,
+		IsEmailVerified:   &isEmailVerified,
 	}
 }
 
@@ -118,6 +149,15 @@ func createNotificationObject(promotions, updates, follows, reviewLikes bool) No
 	}
 }
 
+// CreateEmailVerificationToken returns the token of the verification mail.
+func CreateEmailVerificationToken(user User) string {
+	return utils.SignToken(
+		emailVerificationPurpose,
+		user.ID.Hex()+":"+user.EmailAddress,
+		time.Now().Add(EmailVerificationTimeout),
+	)
+}
+
 func (userModel *UserModel) CreateUser(data requests.Register) (*User, error) {
 	user := createUserObject(data.EmailAddress, data.Username, data.Password, data.FCMToken, data.Image)
 
@@ -188,26 +228,166 @@ func (userModel *UserModel) UpdateUser(user User) error {
 	return nil
 }
 
-func (userModel *UserModel) UpdateUserMembership(uid string, data requests.ChangeMembership) error {
+// CreatePasswordResetToken replaces the password reset token of the user and
+// returns it, false is returned if the previous one is created recently.
+func (userModel *UserModel) CreatePasswordResetToken(uid string) (string, bool, error) {
+	objectUID, _ := primitive.ObjectIDFromHex(uid)
+	now := time.Now().UTC()
+	token := utils.GenerateToken()
+
+	result, err := userModel.Collection.UpdateOne(context.TODO(), bson.M{
+		"_id": objectUID,
+		"$or": bson.A{
+			bson.M{"reset_token_expires_at": nil},
+			bson.M{"reset_token_expires_at": bson.M{"$lte": now.Add(PasswordResetTimeout - passwordResetCooldown)}},
+		},
+	}, bson.M{"$set": bson.M{
+		"reset_token_hash":       utils.HashToken(token),
+		"reset_token_expires_at": now.Add(PasswordResetTimeout),
+	}})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to create password reset token: ", err)
+
+		return "", false, fmt.Errorf("Failed to create password reset token.")
+	}
+
+	return token, result.ModifiedCount > 0, nil
+}
+
+// ResetPassword changes the password of the reset token's user, the token can
+// be used once. Empty user is returned if the token is invalid or expired.
+func (userModel *UserModel) ResetPassword(token, newPassword string) (User, error) {
+	now := time.Now().UTC()
+
+	result := userModel.Collection.FindOneAndUpdate(context.TODO(), bson.M{
+		"reset_token_hash":       utils.HashToken(token),
+		"reset_token_expires_at": bson.M{"$gt": now},
+		"is_oauth":               false,
+	}, bson.M{"$set": bson.M{
+		"password":               utils.HashPassword(newPassword),
+		"reset_token_hash":       "",
+		"reset_token_expires_at": nil,
+		"updated_at":             now,
+	}})
+
+	var user User
+	if err := result.Decode(&user); err != nil && err != mongo.ErrNoDocuments {
+		logrus.Error("failed to reset password: ", err)
+
+		return User{}, fmt.Errorf("Failed to reset password.")
+	}
+
+	return user, nil
+}
+
+// ClaimEmailVerificationMail marks the verification mail as sent, false is
+// returned if the previous one is sent recently or the email is verified.
+func (userModel *UserModel) ClaimEmailVerificationMail(uid string) (bool, error) {
+	objectUID, _ := primitive.ObjectIDFromHex(uid)
+	now := time.Now().UTC()
+
+	result, err := userModel.Collection.UpdateOne(context.TODO(), bson.M{
+		"_id":               objectUID,
+		"is_email_verified": false,
+		"$or": bson.A{
+			bson.M{"email_verification_sent_at": nil},
+			bson.M{"email_verification_sent_at": bson.M{"$lte": now.Add(-emailVerificationCooldown)}},
+		},
+	}, bson.M{"$set": bson.M{
+		"email_verification_sent_at": now,
+	}})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to claim email verification mail: ", err)
+
+		return false, fmt.Errorf("Failed to send verification mail.")
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+// VerifyEmail verifies the email of the token, tokens are tied to the email so
+// they can't verify a changed email. False is returned if the token is invalid
+// or expired, clicking the link again is still verified.
+func (userModel *UserModel) VerifyEmail(token string) (bool, error) {
+	payload, ok := utils.VerifySignedToken(emailVerificationPurpose, token)
+	if !ok {
+		return false, nil
+	}
+
+	uid, email, found := strings.Cut(payload, ":")
+	if !found {
+		return false, nil
+	}
+
+	objectUID, err := primitive.ObjectIDFromHex(uid)
+	if err != nil {
+		return false, nil
+	}
+
+	result, err := userModel.Collection.UpdateOne(context.TODO(), bson.M{
+		"_id":               objectUID,
+		"email":             email,
+		"is_email_verified": false,
+	}, bson.M{"$set": bson.M{
+		"is_email_verified":          true,
+		"email_verification_sent_at": nil,
+		"updated_at":                 time.Now().UTC(),
+	}})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to verify email: ", err)
+
+		return false, fmt.Errorf("Failed to verify email.")
+	}
+
+	if result.ModifiedCount > 0 {
+		return true, nil
+	}
+
+	count, err := userModel.Collection.CountDocuments(context.TODO(), bson.M{
+		"_id":               objectUID,
+		"email":             email,
+		"is_email_verified": bson.M{"$ne": false},
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to check verified email: ", err)
+
+		return false, fmt.Errorf("Failed to verify email.")
+	}
+
+	return count > 0, nil
+}
+
+// updateUserMembership keeps the membership of the user document in sync with
+// the entitlements, it's shown on the profiles, reviews etc.
+func (userModel *UserModel) updateUserMembership(uid string, isPremium bool, membershipType int) {
 	objectUID, _ := primitive.ObjectIDFromHex(uid)
 
 	if _, err := userModel.Collection.UpdateOne(context.TODO(), bson.M{"_id": objectUID}, bson.M{"$set": bson.M{
-		"is_premium":      data.IsPremium,
-		"membership_type": data.MembershipType,
+		"is_premium":      isPremium,
+		"membership_type": membershipType,
 		"updated_at":      time.Now().UTC(),
 	}}); err != nil {
 		logrus.WithFields(logrus.Fields{
 			"uid":        uid,
-			"is_premium": data.IsPremium,
+			"is_premium": isPremium,
 		}).Error("failed to set membership for user: ", err)
-
-		return fmt.Errorf("Failed to set membership for user.")
 	}
-
-	return nil
 }
 
 // Checks
+// IsUserPremium derives the membership from the active entitlements of the
+// user, lifetime premium is granted without a purchase. Legacy members that
+// don't have any entitlement keep the membership they had before the purchases
+// were verified, until they verify or restore a purchase. is_premium is only a
+// cache of the result and never trusted.
 func (userModel *UserModel) IsUserPremium(uid string) (bool, int) {
 	objectUID, _ := primitive.ObjectIDFromHex(uid)
 
@@ -224,7 +404,73 @@ func (userModel *UserModel) IsUserPremium(uid string) (bool, int) {
 		return false, -1
 	}
 
-	return isUserPremium.IsPremium || isUserPremium.MembershipType == 1 || isUserPremium.MembershipType == 2, isUserPremium.MembershipType
+	now := time.Now().UTC()
+
+	entitlementResult := userModel.EntitlementCollection.FindOne(context.TODO(), bson.M{
+		"user_id":     uid,
+		"refunded_at": nil,
+		"$or": bson.A{
+			bson.M{"expires_at": nil},
+			bson.M{"expires_at": bson.M{"$gt": now}},
+		},
+	}, options.FindOne().SetSort(bson.M{"membership_type": -1}).SetProjection(bson.M{"membership_type": 1}))
+
+	var entitlement struct {
+		MembershipType int `bson:"membership_type"`
+	}
+	if err := entitlementResult.Decode(&entitlement); err != nil && err != mongo.ErrNoDocuments {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to find active entitlement: ", err)
+
+		return false, -1
+	}
+
+	if entitlement.MembershipType == BasicMembershipType && isUserPremium.IsLegacyPremium {
+		hasEntitlement, err := userModel.hasEntitlement(uid)
+		if err != nil {
+			return false, -1
+		}
+
+		if !hasEntitlement {
+			membershipType := isUserPremium.MembershipType
+			if membershipType == BasicMembershipType {
+				membershipType = PremiumMembershipType
+			}
+
+			return true, membershipType
+		}
+	}
+
+	membershipType := entitlement.MembershipType
+	if isUserPremium.IsLifetimePremium && membershipType == BasicMembershipType {
+		membershipType = PremiumMembershipType
+	}
+
+	isPremium := membershipType != BasicMembershipType
+
+	// Expired entitlements don't notify, user document is updated once it's noticed.
+	if isPremium != isUserPremium.IsPremium || membershipType != isUserPremium.MembershipType {
+		userModel.updateUserMembership(uid, isPremium, membershipType)
+	}
+
+	return isPremium, membershipType
+}
+
+// hasEntitlement reports whether the user has ever verified a purchase.
+func (userModel *UserModel) hasEntitlement(uid string) (bool, error) {
+	count, err := userModel.EntitlementCollection.CountDocuments(context.TODO(), bson.M{
+		"user_id": uid,
+	}, options.Count().SetLimit(1))
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to count entitlements: ", err)
+
+		return false, err
+	}
+
+	return count > 0, nil
 }
 
 // Delete
@@ -336,18 +582,17 @@ func (userModel *UserModel) FindUserByRefreshToken(refreshToken string) (User, e
 	return user, nil
 }
 
-func (userModel *UserModel) FindUserByResetTokenAndEmail(token, email string) (User, error) {
+// FindUserByPasswordResetToken returns empty user if the token is invalid or
+// expired.
+func (userModel *UserModel) FindUserByPasswordResetToken(token string) (User, error) {
 	result := userModel.Collection.FindOne(context.TODO(), bson.M{
-		"reset_token": token,
-		"email":       email,
+		"reset_token_hash":       utils.HashToken(token),
+		"reset_token_expires_at": bson.M{"$gt": time.Now().UTC()},
 	})
 
 	var user User
-	if err := result.Decode(&user); err != nil {
-		logrus.WithFields(logrus.Fields{
-			"uid":   user.ID,
-			"token": token,
-		}).Error("failed to find user by reset token: ", err)
+	if err := result.Decode(&user); err != nil && err != mongo.ErrNoDocuments {
+		logrus.Error("failed to find user by reset token: ", err)
 
 		return User{}, fmt.Errorf("Failed to find user by reset token.")
 	}
@@ -355,6 +600,15 @@ func (userModel *UserModel) FindUserByResetTokenAndEmail(token, email string) (U
 	return user, nil
 }
 
+func (userModel *UserModel) IsUserEmailVerified(uid string) (bool, error) {
+	user, err := userModel.FindUserByID(uid)
+	if err != nil {
+		return false, err
+	}
+
+	return user.HasVerifiedEmail(), nil
+}
+
 func (userModel *UserModel) GetUserByID(uid string) (responses.User, error) {
 	objectUID, _ := primitive.ObjectIDFromHex(uid)
 
@@ -371,6 +625,11 @@ func (userModel *UserModel) GetUserByID(uid string) (responses.User, error) {
 		return responses.User{}, fmt.Errorf("Failed to find user by id.")
 	}
 
+	if user.IsEmailVerified == nil {
+		isEmailVerified := true
+		user.IsEmailVerified = &isEmailVerified
+	}
+
 	return user, nil
 }
 
@@ -44,6 +44,7 @@ type ConsumeLaterList struct {
 	ContentExternalIntID *int64             `bson:"content_external_int_id" json:"content_external_int_id"`
 	ContentType          string             `bson:"content_type" json:"content_type"` // anime, movie, tv or game
 	SelfNote             *string            `bson:"self_note" json:"self_note"`
+	Tags                 []string           `bson:"tags" json:"tags"`
 	CreatedAt            time.Time          `bson:"created_at" json:"created_at"`
 }
 
@@ -68,6 +69,7 @@ func (userInteractionModel *UserInteractionModel) CreateConsumeLater(uid string,
 		data.SelfNote,
 		data.ContentExternalIntID,
 	)
+	consumeLater.Tags = normalizeTags(data.Tags)
 
 	var (
 		insertedID *mongo.InsertOneResult
@@ -158,6 +160,10 @@ func (userInteractionModel *UserInteractionModel) GetConsumeLater(uid string, da
 		matchFields["content_type"] = data.ContentType
 	}
 
+	if data.Tag != nil {
+		matchFields["tags"] = *data.Tag
+	}
+
 	match := bson.M{"$match": matchFields}
 
 	set := bson.M{"$set": bson.M{
@@ -445,6 +451,25 @@ func (userInteractionModel *UserInteractionModel) UpdateConsumeLaterSelfNote(dat
 	return nil
 }
 
+func (userInteractionModel *UserInteractionModel) UpdateConsumeLaterTags(consumeLater ConsumeLaterList, data requests.UpdateConsumeLaterTags) (ConsumeLaterList, error) {
+	consumeLater.Tags = normalizeTags(data.Tags)
+
+	if _, err := userInteractionModel.ConsumeLaterCollection.UpdateOne(context.TODO(), bson.M{
+		"_id": consumeLater.ID,
+	}, bson.M{"$set": bson.M{
+		"tags": consumeLater.Tags,
+	}}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"_id":  consumeLater.ID,
+			"data": data,
+		}).Error("failed to update consume later tags: ", err)
+
+		return ConsumeLaterList{}, fmt.Errorf("Failed to update consume later.")
+	}
+
+	return consumeLater, nil
+}
+
 func (userInteractionModel *UserInteractionModel) DeleteConsumeLaterByID(uid, consumeLaterID string) (bool, error) {
 	objectConsumeLaterID, _ := primitive.ObjectIDFromHex(consumeLaterID)
 
@@ -464,6 +489,25 @@ func (userInteractionModel *UserInteractionModel) DeleteConsumeLaterByID(uid, co
 	return count.DeletedCount > 0, nil
 }
 
+// DeleteConsumeLaterByContentID removes the content from consume later, e.g.
+// when its list entry is restored.
+func (userInteractionModel *UserInteractionModel) DeleteConsumeLaterByContentID(uid, contentID, contentType string) error {
+	if _, err := userInteractionModel.ConsumeLaterCollection.DeleteOne(context.TODO(), bson.M{
+		"user_id":      uid,
+		"content_id":   contentID,
+		"content_type": contentType,
+	}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid":        uid,
+			"content_id": contentID,
+		}).Error("failed to delete consume later by content id: ", err)
+
+		return fmt.Errorf("Failed to delete consume later.")
+	}
+
+	return nil
+}
+
 func (userInteractionModel *UserInteractionModel) DeleteAllConsumeLaterByUserID(uid string) error {
 	if _, err := userInteractionModel.ConsumeLaterCollection.DeleteMany(context.TODO(), bson.M{
 		"user_id": uid,
@@ -5,8 +5,10 @@ import (
 	"app/requests"
 	"app/responses"
 	"context"
+	"errors"
 	"fmt"
 	srt "sort"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -59,6 +61,11 @@ type MangaList struct {
 	ReadVolumes   int64              `bson:"read_volumes" json:"read_volumes"`
 	Score         *float32           `bson:"score" json:"score"`
 	TimesFinished int                `bson:"times_finished" json:"times_finished"`
+	StartedAt     *time.Time         `bson:"started_at" json:"started_at"`
+	FinishedAt    *time.Time         `bson:"finished_at" json:"finished_at"`
+	Priority      *int               `bson:"priority" json:"priority"`
+	PrivateNote   *string            `bson:"private_note" json:"private_note"`
+	Tags          []string           `bson:"tags" json:"tags"`
 	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt     time.Time          `bson:"updated_at" json:"-"`
 }
@@ -72,6 +79,11 @@ type AnimeList struct {
 	WatchedEpisodes int64              `bson:"watched_episodes" json:"watched_episodes"`
 	Score           *float32           `bson:"score" json:"score"`
 	TimesFinished   int                `bson:"times_finished" json:"times_finished"`
+	StartedAt       *time.Time         `bson:"started_at" json:"started_at"`
+	FinishedAt      *time.Time         `bson:"finished_at" json:"finished_at"`
+	Priority        *int               `bson:"priority" json:"priority"`
+	PrivateNote     *string            `bson:"private_note" json:"private_note"`
+	Tags            []string           `bson:"tags" json:"tags"`
 	CreatedAt       time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt       time.Time          `bson:"updated_at" json:"-"`
 }
@@ -86,6 +98,11 @@ type GameList struct {
 	AchievementStatus *float32           `bson:"achievement_status" json:"achievement_status"`
 	TimesFinished     int                `bson:"times_finished" json:"times_finished"`
 	HoursPlayed       *int               `bson:"hours_played" json:"hours_played"`
+	StartedAt         *time.Time         `bson:"started_at" json:"started_at"`
+	FinishedAt        *time.Time         `bson:"finished_at" json:"finished_at"`
+	Priority          *int               `bson:"priority" json:"priority"`
+	PrivateNote       *string            `bson:"private_note" json:"private_note"`
+	Tags              []string           `bson:"tags" json:"tags"`
 	CreatedAt         time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt         time.Time          `bson:"updated_at" json:"-"`
 }
@@ -98,6 +115,11 @@ type MovieWatchList struct {
 	Status        string             `bson:"status" json:"status"`
 	Score         *float32           `bson:"score" json:"score"`
 	TimesFinished int                `bson:"times_finished" json:"times_finished"`
+	StartedAt     *time.Time         `bson:"started_at" json:"started_at"`
+	FinishedAt    *time.Time         `bson:"finished_at" json:"finished_at"`
+	Priority      *int               `bson:"priority" json:"priority"`
+	PrivateNote   *string            `bson:"private_note" json:"private_note"`
+	Tags          []string           `bson:"tags" json:"tags"`
 	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt     time.Time          `bson:"updated_at" json:"-"`
 }
@@ -112,10 +134,40 @@ type TVSeriesWatchList struct {
 	WatchedEpisodes int                `bson:"watched_episodes" json:"watched_episodes"`
 	WatchedSeasons  int                `bson:"watched_seasons" json:"watched_seasons"`
 	TimesFinished   int                `bson:"times_finished" json:"times_finished"`
+	StartedAt       *time.Time         `bson:"started_at" json:"started_at"`
+	FinishedAt      *time.Time         `bson:"finished_at" json:"finished_at"`
+	Priority        *int               `bson:"priority" json:"priority"`
+	PrivateNote     *string            `bson:"private_note" json:"private_note"`
+	Tags            []string           `bson:"tags" json:"tags"`
 	CreatedAt       time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt       time.Time          `bson:"updated_at" json:"-"`
 }
 
+// ListEntry is the common part of anime, manga, game, movie and tv series list entries.
+type ListEntry struct {
+	ID            primitive.ObjectID
+	UserID        string
+	ContentID     string
+	ContentType   string
+	Status        string
+	Score         *float32
+	TimesFinished int
+	// External ids are used when an entry is moved to consume later.
+	ContentExternalID    *string
+	ContentExternalIntID *int64
+	// List is the anime, manga, game, movie or tv series list of the entry.
+	List interface{}
+}
+
+// Content id field of each list type.
+var listContentIDFields = map[string]string{
+	"anime": "anime_id",
+	"manga": "manga_id",
+	"game":  "game_id",
+	"movie": "movie_id",
+	"tv":    "tv_id",
+}
+
 func createUserListObject(userID, slug string) *UserList {
 	return &UserList{
 		UserID:   userID,
@@ -205,6 +257,21 @@ func handleTimesFinished(status string, timesFinished *int) int {
 	return *timesFinished
 }
 
+// Priority 0 and empty notes are used to clear the value.
+func handlePriority(priority *int) *int {
+	if priority == nil || *priority == 0 {
+		return nil
+	}
+	return priority
+}
+
+func handlePrivateNote(privateNote *string) *string {
+	if privateNote == nil || strings.TrimSpace(*privateNote) == "" {
+		return nil
+	}
+	return privateNote
+}
+
 // ! Create
 func (userListModel *UserListModel) CreateUserList(uid, slug string) error {
 	userListObject := createUserListObject(uid, slug)
@@ -227,6 +294,12 @@ func (userListModel *UserListModel) CreateAnimeList(uid string, data requests.Cr
 		*data.WatchedEpisodes, data.Score, data.TimesFinished,
 	)
 
+	animeList.StartedAt = data.StartedAt
+	animeList.FinishedAt = data.FinishedAt
+	animeList.Priority = handlePriority(data.Priority)
+	animeList.PrivateNote = handlePrivateNote(data.PrivateNote)
+	animeList.Tags = normalizeTags(data.Tags)
+
 	if anime.Episodes != nil {
 		if *data.WatchedEpisodes > *anime.Episodes {
 			animeList.WatchedEpisodes = *anime.Episodes
@@ -260,6 +333,12 @@ func (userListModel *UserListModel) CreateMangaList(uid string, data requests.Cr
 		*data.ReadChapters, *data.ReadVolumes, data.Score, data.TimesFinished,
 	)
 
+	mangaList.StartedAt = data.StartedAt
+	mangaList.FinishedAt = data.FinishedAt
+	mangaList.Priority = handlePriority(data.Priority)
+	mangaList.PrivateNote = handlePrivateNote(data.PrivateNote)
+	mangaList.Tags = normalizeTags(data.Tags)
+
 	if data.Status == "finished" && data.ReadChapters != nil && manga.Chapters != nil && *data.ReadChapters < *manga.Chapters {
 		mangaList.ReadChapters = *manga.Chapters
 	}
@@ -294,6 +373,12 @@ func (userListModel *UserListModel) CreateGameList(uid string, data requests.Cre
 		data.TimesFinished, data.HoursPlayed,
 	)
 
+	gameList.StartedAt = data.StartedAt
+	gameList.FinishedAt = data.FinishedAt
+	gameList.Priority = handlePriority(data.Priority)
+	gameList.PrivateNote = handlePrivateNote(data.PrivateNote)
+	gameList.Tags = normalizeTags(data.Tags)
+
 	var (
 		insertedID *mongo.InsertOneResult
 		err        error
@@ -319,6 +404,12 @@ func (userListModel *UserListModel) CreateMovieWatchList(uid string, data reques
 		data.Score, data.TimesFinished,
 	)
 
+	movieWatchList.StartedAt = data.StartedAt
+	movieWatchList.FinishedAt = data.FinishedAt
+	movieWatchList.Priority = handlePriority(data.Priority)
+	movieWatchList.PrivateNote = handlePrivateNote(data.PrivateNote)
+	movieWatchList.Tags = normalizeTags(data.Tags)
+
 	var (
 		insertedID *mongo.InsertOneResult
 		err        error
@@ -347,6 +438,12 @@ func (userListModel *UserListModel) CreateTVSeriesWatchList(
 		data.TimesFinished,
 	)
 
+	tvSeriesWatchList.StartedAt = data.StartedAt
+	tvSeriesWatchList.FinishedAt = data.FinishedAt
+	tvSeriesWatchList.Priority = handlePriority(data.Priority)
+	tvSeriesWatchList.PrivateNote = handlePrivateNote(data.PrivateNote)
+	tvSeriesWatchList.Tags = normalizeTags(data.Tags)
+
 	if (*data.WatchedEpisodes > tvSeries.TotalEpisodes) || (data.Status == "finished" && *data.WatchedEpisodes < tvSeries.TotalEpisodes) {
 		tvSeriesWatchList.WatchedEpisodes = tvSeries.TotalEpisodes
 	}
@@ -404,11 +501,13 @@ func (userListModel *UserListModel) IncrementAnimeListEpisodeByID(animeList Anim
 		updateFields = bson.M{"$set": bson.M{
 			"status":           "finished",
 			"watched_episodes": animeList.WatchedEpisodes,
+			"updated_at":       time.Now().UTC(),
 		}}
 	} else {
-		updateFields = bson.M{"$inc": bson.M{
-			"watched_episodes": 1,
-		}}
+		updateFields = bson.M{
+			"$inc": bson.M{"watched_episodes": 1},
+			"$set": bson.M{"updated_at": time.Now().UTC()},
+		}
 	}
 
 	if _, err := userListModel.AnimeListCollection.UpdateOne(context.TODO(), bson.M{
@@ -426,8 +525,10 @@ func (userListModel *UserListModel) IncrementAnimeListEpisodeByID(animeList Anim
 
 func (userListModel *UserListModel) UpdateAnimeListByID(animeList AnimeList, data requests.UpdateAnimeList) (AnimeList, error) {
 	if data.IsUpdatingScore || data.TimesFinished != nil ||
-		data.Status != nil || data.WatchedEpisodes != nil {
-		set := bson.M{}
+		data.Status != nil || data.WatchedEpisodes != nil ||
+		data.IsUpdatingDates || data.Priority != nil || data.PrivateNote != nil ||
+		data.IsUpdatingTags {
+		set := bson.M{"updated_at": time.Now().UTC()}
 
 		if data.IsUpdatingScore && animeList.Score != data.Score {
 			set["score"] = data.Score
@@ -449,6 +550,27 @@ func (userListModel *UserListModel) UpdateAnimeListByID(animeList AnimeList, dat
 			animeList.WatchedEpisodes = *data.WatchedEpisodes
 		}
 
+		if data.IsUpdatingDates {
+			animeList.StartedAt, animeList.FinishedAt = MergeListDates(data.StartedAt, data.FinishedAt, animeList.StartedAt, animeList.FinishedAt)
+			set["started_at"] = animeList.StartedAt
+			set["finished_at"] = animeList.FinishedAt
+		}
+
+		if data.Priority != nil {
+			set["priority"] = handlePriority(data.Priority)
+			animeList.Priority = handlePriority(data.Priority)
+		}
+
+		if data.PrivateNote != nil {
+			set["private_note"] = handlePrivateNote(data.PrivateNote)
+			animeList.PrivateNote = handlePrivateNote(data.PrivateNote)
+		}
+
+		if data.IsUpdatingTags {
+			set["tags"] = normalizeTags(data.Tags)
+			animeList.Tags = normalizeTags(data.Tags)
+		}
+
 		if _, err := userListModel.AnimeListCollection.UpdateOne(context.TODO(), bson.M{
 			"_id": animeList.ID,
 		}, bson.M{"$set": set}); err != nil {
@@ -486,11 +608,13 @@ func (userListModel *UserListModel) IncrementMangaListChapterVolumeByID(
 		updateFields = bson.M{"$set": bson.M{
 			"status":        "finished",
 			"read_chapters": mangaList.ReadChapters,
+			"updated_at":    time.Now().UTC(),
 		}}
 	} else {
-		updateFields = bson.M{"$inc": bson.M{
-			updateField: 1,
-		}}
+		updateFields = bson.M{
+			"$inc": bson.M{updateField: 1},
+			"$set": bson.M{"updated_at": time.Now().UTC()},
+		}
 	}
 
 	if _, err := userListModel.MangaListCollection.UpdateOne(context.TODO(), bson.M{
@@ -509,8 +633,10 @@ func (userListModel *UserListModel) IncrementMangaListChapterVolumeByID(
 
 func (userListModel *UserListModel) UpdateMangaListByID(mangaList MangaList, data requests.UpdateMangaList) (MangaList, error) {
 	if data.IsUpdatingScore || data.TimesFinished != nil ||
-		data.Status != nil || data.ReadChapters != nil || data.ReadVolumes != nil {
-		set := bson.M{}
+		data.Status != nil || data.ReadChapters != nil || data.ReadVolumes != nil ||
+		data.IsUpdatingDates || data.Priority != nil || data.PrivateNote != nil ||
+		data.IsUpdatingTags {
+		set := bson.M{"updated_at": time.Now().UTC()}
 
 		if data.IsUpdatingScore && mangaList.Score != data.Score {
 			set["score"] = data.Score
@@ -537,6 +663,27 @@ func (userListModel *UserListModel) UpdateMangaListByID(mangaList MangaList, dat
 			mangaList.ReadVolumes = *data.ReadVolumes
 		}
 
+		if data.IsUpdatingDates {
+			mangaList.StartedAt, mangaList.FinishedAt = MergeListDates(data.StartedAt, data.FinishedAt, mangaList.StartedAt, mangaList.FinishedAt)
+			set["started_at"] = mangaList.StartedAt
+			set["finished_at"] = mangaList.FinishedAt
+		}
+
+		if data.Priority != nil {
+			set["priority"] = handlePriority(data.Priority)
+			mangaList.Priority = handlePriority(data.Priority)
+		}
+
+		if data.PrivateNote != nil {
+			set["private_note"] = handlePrivateNote(data.PrivateNote)
+			mangaList.PrivateNote = handlePrivateNote(data.PrivateNote)
+		}
+
+		if data.IsUpdatingTags {
+			set["tags"] = normalizeTags(data.Tags)
+			mangaList.Tags = normalizeTags(data.Tags)
+		}
+
 		if _, err := userListModel.MangaListCollection.UpdateOne(context.TODO(), bson.M{
 			"_id": mangaList.ID,
 		}, bson.M{"$set": set}); err != nil {
@@ -570,9 +717,10 @@ func (userListModel *UserListModel) IncrementGameListHourByID(gameList GameList,
 
 	if _, err := userListModel.GameListCollection.UpdateOne(context.TODO(), bson.M{
 		"_id": gameList.ID,
-	}, bson.M{updateOperation: bson.M{
-		"hours_played": 1,
-	}}); err != nil {
+	}, bson.M{
+		updateOperation: bson.M{"hours_played": 1},
+		"$set":          bson.M{"updated_at": time.Now().UTC()},
+	}); err != nil {
 		logrus.WithFields(logrus.Fields{
 			"game_list_id": gameList.ID,
 		}).Error("failed to increment game list hours played: ", err)
@@ -585,8 +733,10 @@ func (userListModel *UserListModel) IncrementGameListHourByID(gameList GameList,
 
 func (userListModel *UserListModel) UpdateGameListByID(gameList GameList, data requests.UpdateGameList) (GameList, error) {
 	if data.IsUpdatingScore || data.TimesFinished != nil ||
-		data.Status != nil || data.AchievementStatus != nil {
-		set := bson.M{}
+		data.Status != nil || data.AchievementStatus != nil ||
+		data.IsUpdatingDates || data.Priority != nil || data.PrivateNote != nil ||
+		data.IsUpdatingTags {
+		set := bson.M{"updated_at": time.Now().UTC()}
 
 		if data.IsUpdatingScore && gameList.Score != data.Score {
 			set["score"] = data.Score
@@ -613,6 +763,27 @@ func (userListModel *UserListModel) UpdateGameListByID(gameList GameList, data r
 			gameList.AchievementStatus = data.AchievementStatus
 		}
 
+		if data.IsUpdatingDates {
+			gameList.StartedAt, gameList.FinishedAt = MergeListDates(data.StartedAt, data.FinishedAt, gameList.StartedAt, gameList.FinishedAt)
+			set["started_at"] = gameList.StartedAt
+			set["finished_at"] = gameList.FinishedAt
+		}
+
+		if data.Priority != nil {
+			set["priority"] = handlePriority(data.Priority)
+			gameList.Priority = handlePriority(data.Priority)
+		}
+
+		if data.PrivateNote != nil {
+			set["private_note"] = handlePrivateNote(data.PrivateNote)
+			gameList.PrivateNote = handlePrivateNote(data.PrivateNote)
+		}
+
+		if data.IsUpdatingTags {
+			set["tags"] = normalizeTags(data.Tags)
+			gameList.Tags = normalizeTags(data.Tags)
+		}
+
 		if _, err := userListModel.GameListCollection.UpdateOne(context.TODO(), bson.M{
 			"_id": gameList.ID,
 		}, bson.M{"$set": set}); err != nil {
@@ -629,8 +800,10 @@ func (userListModel *UserListModel) UpdateGameListByID(gameList GameList, data r
 }
 
 func (userListModel *UserListModel) UpdateMovieListByID(movieList MovieWatchList, data requests.UpdateMovieList) (MovieWatchList, error) {
-	if data.IsUpdatingScore || data.TimesFinished != nil || data.Status != nil {
-		set := bson.M{}
+	if data.IsUpdatingScore || data.TimesFinished != nil || data.Status != nil ||
+		data.IsUpdatingDates || data.Priority != nil || data.PrivateNote != nil ||
+		data.IsUpdatingTags {
+		set := bson.M{"updated_at": time.Now().UTC()}
 
 		if data.IsUpdatingScore && movieList.Score != data.Score {
 			set["score"] = data.Score
@@ -647,6 +820,27 @@ func (userListModel *UserListModel) UpdateMovieListByID(movieList MovieWatchList
 			movieList.Status = *data.Status
 		}
 
+		if data.IsUpdatingDates {
+			movieList.StartedAt, movieList.FinishedAt = MergeListDates(data.StartedAt, data.FinishedAt, movieList.StartedAt, movieList.FinishedAt)
+			set["started_at"] = movieList.StartedAt
+			set["finished_at"] = movieList.FinishedAt
+		}
+
+		if data.Priority != nil {
+			set["priority"] = handlePriority(data.Priority)
+			movieList.Priority = handlePriority(data.Priority)
+		}
+
+		if data.PrivateNote != nil {
+			set["private_note"] = handlePrivateNote(data.PrivateNote)
+			movieList.PrivateNote = handlePrivateNote(data.PrivateNote)
+		}
+
+		if data.IsUpdatingTags {
+			set["tags"] = normalizeTags(data.Tags)
+			movieList.Tags = normalizeTags(data.Tags)
+		}
+
 		if _, err := userListModel.MovieWatchListCollection.UpdateOne(context.TODO(), bson.M{
 			"_id": movieList.ID,
 		}, bson.M{"$set": set}); err != nil {
@@ -684,11 +878,13 @@ func (userListModel *UserListModel) IncrementTVSeriesListEpisodeSeasonByID(
 		updateFields = bson.M{"$set": bson.M{
 			"status":           "finished",
 			"watched_episodes": tvList.WatchedEpisodes,
+			"updated_at":       time.Now().UTC(),
 		}}
 	} else {
-		updateFields = bson.M{"$inc": bson.M{
-			updateField: 1,
-		}}
+		updateFields = bson.M{
+			"$inc": bson.M{updateField: 1},
+			"$set": bson.M{"updated_at": time.Now().UTC()},
+		}
 	}
 
 	if _, err := userListModel.TVSeriesWatchListCollection.UpdateOne(context.TODO(), bson.M{
@@ -708,8 +904,10 @@ func (userListModel *UserListModel) IncrementTVSeriesListEpisodeSeasonByID(
 func (userListModel *UserListModel) UpdateTVSeriesListByID(tvList TVSeriesWatchList, data requests.UpdateTVSeriesList) (TVSeriesWatchList, error) {
 	if data.IsUpdatingScore || data.TimesFinished != nil ||
 		data.Status != nil || data.WatchedEpisodes != nil ||
-		data.WatchedSeasons != nil {
-		set := bson.M{}
+		data.WatchedSeasons != nil ||
+		data.IsUpdatingDates || data.Priority != nil || data.PrivateNote != nil ||
+		data.IsUpdatingTags {
+		set := bson.M{"updated_at": time.Now().UTC()}
 
 		if data.IsUpdatingScore && tvList.Score != data.Score {
 			set["score"] = data.Score
@@ -736,6 +934,27 @@ func (userListModel *UserListModel) UpdateTVSeriesListByID(tvList TVSeriesWatchL
 			tvList.WatchedSeasons = *data.WatchedSeasons
 		}
 
+		if data.IsUpdatingDates {
+			tvList.StartedAt, tvList.FinishedAt = MergeListDates(data.StartedAt, data.FinishedAt, tvList.StartedAt, tvList.FinishedAt)
+			set["started_at"] = tvList.StartedAt
+			set["finished_at"] = tvList.FinishedAt
+		}
+
+		if data.Priority != nil {
+			set["priority"] = handlePriority(data.Priority)
+			tvList.Priority = handlePriority(data.Priority)
+		}
+
+		if data.PrivateNote != nil {
+			set["private_note"] = handlePrivateNote(data.PrivateNote)
+			tvList.PrivateNote = handlePrivateNote(data.PrivateNote)
+		}
+
+		if data.IsUpdatingTags {
+			set["tags"] = normalizeTags(data.Tags)
+			tvList.Tags = normalizeTags(data.Tags)
+		}
+
 		if _, err := userListModel.TVSeriesWatchListCollection.UpdateOne(context.TODO(), bson.M{
 			"_id": tvList.ID,
 		}, bson.M{"$set": set}); err != nil {
@@ -751,6 +970,276 @@ func (userListModel *UserListModel) UpdateTVSeriesListByID(tvList TVSeriesWatchL
 	return tvList, nil
 }
 
+// UpdateAnimeListWatchedEpisodes sets the watched_episodes counter derived from episode records.
+func (userListModel *UserListModel) UpdateAnimeListWatchedEpisodes(animeList AnimeList, anime responses.Anime, watchedEpisodes int64) (AnimeList, error) {
+	set := bson.M{
+		"watched_episodes": watchedEpisodes,
+		"updated_at":       time.Now().UTC(),
+	}
+	animeList.WatchedEpisodes = watchedEpisodes
+
+	if anime.Episodes != nil && *anime.Episodes > 0 && watchedEpisodes >= *anime.Episodes && animeList.Status != "finished" {
+		set["status"] = "finished"
+		animeList.Status = "finished"
+	}
+
+	if _, err := userListModel.AnimeListCollection.UpdateOne(context.TODO(), bson.M{
+		"_id": animeList.ID,
+	}, bson.M{"$set": set}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"anime_list_id":    animeList.ID,
+			"watched_episodes": watchedEpisodes,
+		}).Error("failed to update anime list watched episodes: ", err)
+
+		return AnimeList{}, fmt.Errorf("Failed to update anime list.")
+	}
+
+	return animeList, nil
+}
+
+// UpdateTVSeriesListWatchedCounters sets the watched_episodes and watched_seasons counters derived from episode records.
+func (userListModel *UserListModel) UpdateTVSeriesListWatchedCounters(
+	tvList TVSeriesWatchList,
+	tvSeries responses.TVSeries,
+	watchedEpisodes, watchedSeasons int,
+) (TVSeriesWatchList, error) {
+	set := bson.M{
+		"watched_episodes": watchedEpisodes,
+		"watched_seasons":  watchedSeasons,
+		"updated_at":       time.Now().UTC(),
+	}
+	tvList.WatchedEpisodes = watchedEpisodes
+	tvList.WatchedSeasons = watchedSeasons
+
+	if tvSeries.TotalEpisodes > 0 && watchedEpisodes >= tvSeries.TotalEpisodes && tvList.Status != "finished" {
+		set["status"] = "finished"
+		tvList.Status = "finished"
+	}
+
+	if _, err := userListModel.TVSeriesWatchListCollection.UpdateOne(context.TODO(), bson.M{
+		"_id": tvList.ID,
+	}, bson.M{"$set": set}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"tv_list_id":       tvList.ID,
+			"watched_episodes": watchedEpisodes,
+			"watched_seasons":  watchedSeasons,
+		}).Error("failed to update tv list watched counters: ", err)
+
+		return TVSeriesWatchList{}, fmt.Errorf("Failed to update tv series watch list.")
+	}
+
+	return tvList, nil
+}
+
+// IncrementTimesFinishedByID changes times_finished by delta when a finished
+// session is added or removed, so that the counter the user set is kept. It
+// doesn't go below 0.
+func (userListModel *UserListModel) IncrementTimesFinishedByID(contentType, listID string, delta int) error {
+	objectListID, _ := primitive.ObjectIDFromHex(listID)
+
+	collection := userListModel.getListCollectionByType(contentType)
+	if collection == nil {
+		return fmt.Errorf("Invalid content type.")
+	}
+
+	filter := bson.M{"_id": objectListID}
+	if delta < 0 {
+		filter["times_finished"] = bson.M{"$gte": -delta}
+	}
+
+	if _, err := collection.UpdateOne(context.TODO(), filter, bson.M{
+		"$inc": bson.M{"times_finished": delta},
+		"$set": bson.M{"updated_at": time.Now().UTC()},
+	}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"list_id":      listID,
+			"content_type": contentType,
+			"delta":        delta,
+		}).Error("failed to update times finished: ", err)
+
+		return fmt.Errorf("Failed to update times finished.")
+	}
+
+	return nil
+}
+
+// BulkUpdateListEntries applies the operation to every entry in a single
+// transaction, either all of the entries are updated or none of them. Standalone
+// servers don't support transactions, there the operation is applied without
+// one, consume later is created before the entries are deleted so that a failed
+// operation doesn't lose entries and can be retried.
+func (userListModel *UserListModel) BulkUpdateListEntries(uid string, data requests.BulkUpdateList, entries []ListEntry) error {
+	session, err := userListModel.UserListCollection.Database().Client().StartSession()
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to start bulk update session: ", err)
+
+		return fmt.Errorf("Failed to apply bulk operation.")
+	}
+	defer session.EndSession(context.TODO())
+
+	_, err = session.WithTransaction(context.TODO(), func(sessionContext mongo.SessionContext) (interface{}, error) {
+		return nil, userListModel.applyBulkListOperation(sessionContext, uid, data, entries)
+	})
+	if isTransactionNotSupported(err) {
+		err = userListModel.applyBulkListOperation(context.TODO(), uid, data, entries)
+	}
+
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid":       uid,
+			"operation": data.Operation,
+			"count":     len(entries),
+		}).Error("failed to bulk update list entries: ", err)
+
+		return fmt.Errorf("Failed to apply bulk operation.")
+	}
+
+	return nil
+}
+
+func (userListModel *UserListModel) applyBulkListOperation(ctx context.Context, uid string, data requests.BulkUpdateList, entries []ListEntry) error {
+	listIDsByType := make(map[string][]primitive.ObjectID)
+	for _, entry := range entries {
+		listIDsByType[entry.ContentType] = append(listIDsByType[entry.ContentType], entry.ID)
+	}
+
+	if data.Operation == "later" {
+		if err := userListModel.moveListEntriesToConsumeLater(ctx, uid, entries); err != nil {
+			return err
+		}
+	}
+
+	for contentType, listIDs := range listIDsByType {
+		collection := userListModel.getListCollectionByType(contentType)
+		filter := bson.M{
+			"_id":     bson.M{"$in": listIDs},
+			"user_id": uid,
+		}
+
+		var err error
+		switch data.Operation {
+		case "status":
+			_, err = collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{
+				"status":     *data.Status,
+				"updated_at": time.Now().UTC(),
+			}})
+		case "score":
+			_, err = collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{
+				"score":      data.Score,
+				"updated_at": time.Now().UTC(),
+			}})
+		case "delete", "later":
+			_, err = collection.DeleteMany(ctx, filter)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isTransactionNotSupported reports whether the transaction failed because the
+// server is standalone, it fails with IllegalOperation before writing anything.
+func isTransactionNotSupported(err error) bool {
+	var commandErr mongo.CommandError
+	return errors.As(err, &commandErr) && commandErr.Code == 20
+}
+
+// moveListEntriesToConsumeLater creates consume later for the entries that are not
+// already in the consume later list of the user.
+func (userListModel *UserListModel) moveListEntriesToConsumeLater(ctx context.Context, uid string, entries []ListEntry) error {
+	consumeLaterCollection := userListModel.UserListCollection.Database().Collection("consume-laters")
+
+	contentIDs := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		contentIDs = append(contentIDs, entry.ContentID)
+	}
+
+	cursor, err := consumeLaterCollection.Find(ctx, bson.M{
+		"user_id":    uid,
+		"content_id": bson.M{"$in": contentIDs},
+	})
+	if err != nil {
+		return err
+	}
+
+	var existingConsumeLaters []ConsumeLaterList
+	if err := cursor.All(ctx, &existingConsumeLaters); err != nil {
+		return err
+	}
+
+	existingContentIDs := make(map[string]bool)
+	for _, consumeLater := range existingConsumeLaters {
+		existingContentIDs[consumeLater.ContentID] = true
+	}
+
+	var consumeLaters []interface{}
+	for _, entry := range entries {
+		if existingContentIDs[entry.ContentID] {
+			continue
+		}
+
+		existingContentIDs[entry.ContentID] = true
+		consumeLaters = append(consumeLaters, createConsumeLaterObject(
+			uid, entry.ContentID, entry.ContentType,
+			entry.ContentExternalID, nil, entry.ContentExternalIntID,
+		))
+	}
+
+	if len(consumeLaters) == 0 {
+		return nil
+	}
+
+	_, err = consumeLaterCollection.InsertMany(ctx, consumeLaters)
+
+	return err
+}
+
+// RevertListEntryFields sets the changed fields of the entry back to their previous values.
+func (userListModel *UserListModel) RevertListEntryFields(uid, contentType, listID string, changes []ListFieldChange) error {
+	objectListID, _ := primitive.ObjectIDFromHex(listID)
+
+	set := bson.M{"updated_at": time.Now().UTC()}
+	for _, change := range changes {
+		set[change.Field] = change.Before
+	}
+
+	if _, err := userListModel.getListCollectionByType(contentType).UpdateOne(context.TODO(), bson.M{
+		"_id":     objectListID,
+		"user_id": uid,
+	}, bson.M{"$set": set}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid":     uid,
+			"list_id": listID,
+			"type":    contentType,
+		}).Error("failed to revert list entry: ", err)
+
+		return fmt.Errorf("Failed to revert list entry.")
+	}
+
+	return nil
+}
+
+// RestoreListEntry inserts a deleted entry back with its original id.
+func (userListModel *UserListModel) RestoreListEntry(contentType string, document bson.M) error {
+	document["updated_at"] = time.Now().UTC()
+
+	if _, err := userListModel.getListCollectionByType(contentType).InsertOne(context.TODO(), document); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"list_id": document["_id"],
+			"type":    contentType,
+		}).Error("failed to restore list entry: ", err)
+
+		return fmt.Errorf("Failed to restore list entry.")
+	}
+
+	return nil
+}
+
 // ! Get
 func (userListModel *UserListModel) GetUserListCount(uid string) (int64, error) {
 	movieCount, err := userListModel.MovieWatchListCollection.CountDocuments(context.TODO(), bson.M{"user_id": uid})
@@ -801,6 +1290,26 @@ func (userListModel *UserListModel) GetUserListCount(uid string) (int64, error)
 	return (movieCount + tvCount + animeCount + gameCount), nil
 }
 
+// GetUserListEntryCount counts every list entry of the user including manga,
+// which doesn't count towards the list limit.
+func (userListModel *UserListModel) GetUserListEntryCount(uid string) (int64, error) {
+	count, err := userListModel.GetUserListCount(uid)
+	if err != nil {
+		return -1, err
+	}
+
+	mangaCount, err := userListModel.MangaListCollection.CountDocuments(context.TODO(), bson.M{"user_id": uid})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to count manga list: ", err)
+
+		return -1, err
+	}
+
+	return count + mangaCount, nil
+}
+
 func (userListModel *UserListModel) GetBaseUserListByUserID(uid string) (UserList, error) {
 	result := userListModel.UserListCollection.FindOne(context.TODO(), bson.M{"user_id": uid})
 
@@ -981,6 +1490,216 @@ func (userListModel *UserListModel) GetTVSeriesListByUserIdAndTVId(uid, tvID str
 	return tvList
 }
 
+func (userListModel *UserListModel) IsContentInUserList(uid, contentType, contentID string) (bool, error) {
+	count, err := userListModel.getListCollectionByType(contentType).CountDocuments(context.TODO(), bson.M{
+		"user_id":                        uid,
+		listContentIDFields[contentType]: contentID,
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid":        uid,
+			"type":       contentType,
+			"content_id": contentID,
+		}).Error("failed to count list entries of content: ", err)
+
+		return false, fmt.Errorf("Failed to find list entry.")
+	}
+
+	return count > 0, nil
+}
+
+func (userListModel *UserListModel) GetListEntryByID(contentType, listID string) (ListEntry, error) {
+	switch contentType {
+	case "anime":
+		animeList, err := userListModel.GetBaseAnimeListByID(listID)
+		return newListEntry(animeList), err
+	case "manga":
+		mangaList, err := userListModel.GetBaseMangaListByID(listID)
+		return newListEntry(mangaList), err
+	case "game":
+		gameList, err := userListModel.GetBaseGameListByID(listID)
+		return newListEntry(gameList), err
+	case "movie":
+		movieList, err := userListModel.GetBaseMovieListByID(listID)
+		return newListEntry(movieList), err
+	case "tv":
+		tvList, err := userListModel.GetBaseTVSeriesListByID(listID)
+		return newListEntry(tvList), err
+	}
+
+	return ListEntry{}, fmt.Errorf("Invalid content type.")
+}
+
+// GetListEntriesByIDs returns the entries with a single query for each content
+// type, entries are keyed by their type and id. Missing entries are left out.
+func (userListModel *UserListModel) GetListEntriesByIDs(listIDsByType map[string][]string) (map[string]ListEntry, error) {
+	entries := make(map[string]ListEntry)
+
+	for contentType, listIDs := range listIDsByType {
+		collection := userListModel.getListCollectionByType(contentType)
+		if collection == nil {
+			continue
+		}
+
+		objectListIDs := bson.A{}
+		for _, listID := range listIDs {
+			if objectListID, err := primitive.ObjectIDFromHex(listID); err == nil {
+				objectListIDs = append(objectListIDs, objectListID)
+			}
+		}
+
+		cursor, err := collection.Find(context.TODO(), bson.M{
+			"_id": bson.M{"$in": objectListIDs},
+		})
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"type":  contentType,
+				"count": len(listIDs),
+			}).Error("failed to find list entries: ", err)
+
+			return nil, fmt.Errorf("Failed to find list entries.")
+		}
+
+		typeEntries, err := decodeListEntries(cursor, contentType)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"type":  contentType,
+				"count": len(listIDs),
+			}).Error("failed to decode list entries: ", err)
+
+			return nil, fmt.Errorf("Failed to find list entries.")
+		}
+
+		for _, entry := range typeEntries {
+			entries[contentType+entry.ID.Hex()] = entry
+		}
+	}
+
+	return entries, nil
+}
+
+func decodeListEntries(cursor *mongo.Cursor, contentType string) ([]ListEntry, error) {
+	var entries []ListEntry
+
+	switch contentType {
+	case "anime":
+		var animeLists []AnimeList
+		if err := cursor.All(context.TODO(), &animeLists); err != nil {
+			return nil, err
+		}
+
+		for _, animeList := range animeLists {
+			entries = append(entries, newListEntry(animeList))
+		}
+	case "manga":
+		var mangaLists []MangaList
+		if err := cursor.All(context.TODO(), &mangaLists); err != nil {
+			return nil, err
+		}
+
+		for _, mangaList := range mangaLists {
+			entries = append(entries, newListEntry(mangaList))
+		}
+	case "game":
+		var gameLists []GameList
+		if err := cursor.All(context.TODO(), &gameLists); err != nil {
+			return nil, err
+		}
+
+		for _, gameList := range gameLists {
+			entries = append(entries, newListEntry(gameList))
+		}
+	case "movie":
+		var movieLists []MovieWatchList
+		if err := cursor.All(context.TODO(), &movieLists); err != nil {
+			return nil, err
+		}
+
+		for _, movieList := range movieLists {
+			entries = append(entries, newListEntry(movieList))
+		}
+	case "tv":
+		var tvLists []TVSeriesWatchList
+		if err := cursor.All(context.TODO(), &tvLists); err != nil {
+			return nil, err
+		}
+
+		for _, tvList := range tvLists {
+			entries = append(entries, newListEntry(tvList))
+		}
+	}
+
+	return entries, nil
+}
+
+// newListEntry wraps the list of any content type.
+func newListEntry(list interface{}) ListEntry {
+	switch list := list.(type) {
+	case AnimeList:
+		return ListEntry{
+			ID:                   list.ID,
+			UserID:               list.UserID,
+			ContentID:            list.AnimeID,
+			ContentType:          "anime",
+			Status:               list.Status,
+			Score:                list.Score,
+			TimesFinished:        list.TimesFinished,
+			ContentExternalIntID: &list.AnimeMALID,
+			List:                 list,
+		}
+	case MangaList:
+		return ListEntry{
+			ID:                   list.ID,
+			UserID:               list.UserID,
+			ContentID:            list.MangaID,
+			ContentType:          "manga",
+			Status:               list.Status,
+			Score:                list.Score,
+			TimesFinished:        list.TimesFinished,
+			ContentExternalIntID: &list.MangaMALID,
+			List:                 list,
+		}
+	case GameList:
+		return ListEntry{
+			ID:                   list.ID,
+			UserID:               list.UserID,
+			ContentID:            list.GameID,
+			ContentType:          "game",
+			Status:               list.Status,
+			Score:                list.Score,
+			TimesFinished:        list.TimesFinished,
+			ContentExternalIntID: &list.GameRAWGID,
+			List:                 list,
+		}
+	case MovieWatchList:
+		return ListEntry{
+			ID:                list.ID,
+			UserID:            list.UserID,
+			ContentID:         list.MovieID,
+			ContentType:       "movie",
+			Status:            list.Status,
+			Score:             list.Score,
+			TimesFinished:     list.TimesFinished,
+			ContentExternalID: &list.MovieTmdbID,
+			List:              list,
+		}
+	case TVSeriesWatchList:
+		return ListEntry{
+			ID:                list.ID,
+			UserID:            list.UserID,
+			ContentID:         list.TvID,
+			ContentType:       "tv",
+			Status:            list.Status,
+			Score:             list.Score,
+			TimesFinished:     list.TimesFinished,
+			ContentExternalID: &list.TvTmdbID,
+			List:              list,
+		}
+	}
+
+	return ListEntry{}
+}
+
 func (userListModel *UserListModel) GetMovieListByUserID(uid string) ([]responses.MovieList, error) {
 	match := bson.M{"$match": bson.M{
 		"user_id": uid,
@@ -1346,7 +2065,9 @@ func (userListModel *UserListModel) GetMangaListByUserID(uid string) ([]response
 	return userList, nil
 }
 
-func (userListModel *UserListModel) GetUserListStats(uid string) (responses.UserStats, error) {
+// GetUserListStats aggregates the list stats of the user, tag counts are only
+// included for the user's own stats.
+func (userListModel *UserListModel) GetUserListStats(uid string, includeTagCounts bool) (responses.UserStats, error) {
 	match := bson.M{"$match": bson.M{
 		"user_id": uid,
 	}}
@@ -1655,11 +2376,19 @@ func (userListModel *UserListModel) GetUserListStats(uid string) (responses.User
 		return responses.UserStats{}, fmt.Errorf("Failed to decode user stats.")
 	}
 
+	var tagCounts []responses.TagCount
+	if includeTagCounts {
+		if tagCounts, err = getTagCounts(userListModel.UserListCollection.Database(), uid, userListTagCollections); err != nil {
+			return responses.UserStats{}, err
+		}
+	}
+
 	if len(userStats) > 0 {
+		userStats[0].TagCounts = tagCounts
 		return userStats[0], nil
 	}
 
-	return responses.UserStats{}, nil
+	return responses.UserStats{TagCounts: tagCounts}, nil
 }
 
 func (userListModel *UserListModel) GetUserListByUserID(uid string, data requests.SortList) (responses.UserList, error) {
@@ -1695,6 +2424,8 @@ func (userListModel *UserListModel) GetUserListByUserID(uid string, data request
 		"as":           "tv_watch_list",
 	}}
 
+	filter, sort := getUserListFilterAndSort(data)
+
 	facet := bson.M{"$facet": bson.M{
 		"lookups": bson.A{
 			bson.M{
@@ -1708,6 +2439,11 @@ func (userListModel *UserListModel) GetUserListByUserID(uid string, data request
 						"score":          "$movie_watch_list.score",
 						"times_finished": "$movie_watch_list.times_finished",
 						"created_at":     "$movie_watch_list.created_at",
+						"started_at":     getListFieldValues("$movie_watch_list", "started_at"),
+						"finished_at":    getListFieldValues("$movie_watch_list", "finished_at"),
+						"priority":       getListFieldValues("$movie_watch_list", "priority"),
+						"private_note":   getListFieldValues("$movie_watch_list", "private_note"),
+						"tags":           getListFieldValues("$movie_watch_list", "tags"),
 					},
 					"pipeline": bson.A{
 						bson.M{
@@ -1839,8 +2575,15 @@ func (userListModel *UserListModel) GetUserListByUserID(uid string, data request
 										},
 									},
 								},
+								"started_at":   getListFieldAt("$$started_at", "$$movie_id", "$movie_id"),
+								"finished_at":  getListFieldAt("$$finished_at", "$$movie_id", "$movie_id"),
+								"priority":     getListFieldAt("$$priority", "$$movie_id", "$movie_id"),
+								"private_note": getListFieldAt("$$private_note", "$$movie_id", "$movie_id"),
+								"tags":         getListFieldAt("$$tags", "$$movie_id", "$movie_id"),
 							},
 						},
+						filter,
+						sort,
 					},
 					"as": "movie_watch_list",
 				},
@@ -1858,6 +2601,11 @@ func (userListModel *UserListModel) GetUserListByUserID(uid string, data request
 						"watched_episodes": "$tv_watch_list.watched_episodes",
 						"watched_seasons":  "$tv_watch_list.watched_seasons",
 						"created_at":       "$tv_watch_list.created_at",
+						"started_at":       getListFieldValues("$tv_watch_list", "started_at"),
+						"finished_at":      getListFieldValues("$tv_watch_list", "finished_at"),
+						"priority":         getListFieldValues("$tv_watch_list", "priority"),
+						"private_note":     getListFieldValues("$tv_watch_list", "private_note"),
+						"tags":             getListFieldValues("$tv_watch_list", "tags"),
 					},
 					"pipeline": bson.A{
 						bson.M{
@@ -2013,8 +2761,15 @@ func (userListModel *UserListModel) GetUserListByUserID(uid string, data request
 										},
 									},
 								},
+								"started_at":   getListFieldAt("$$started_at", "$$tv_id", "$tv_id"),
+								"finished_at":  getListFieldAt("$$finished_at", "$$tv_id", "$tv_id"),
+								"priority":     getListFieldAt("$$priority", "$$tv_id", "$tv_id"),
+								"private_note": getListFieldAt("$$private_note", "$$tv_id", "$tv_id"),
+								"tags":         getListFieldAt("$$tags", "$$tv_id", "$tv_id"),
 							},
 						},
+						filter,
+						sort,
 					},
 					"as": "tv_watch_list",
 				},
@@ -2031,6 +2786,11 @@ func (userListModel *UserListModel) GetUserListByUserID(uid string, data request
 						"times_finished":   "$anime_list.times_finished",
 						"watched_episodes": "$anime_list.watched_episodes",
 						"created_at":       "$anime_list.created_at",
+						"started_at":       getListFieldValues("$anime_list", "started_at"),
+						"finished_at":      getListFieldValues("$anime_list", "finished_at"),
+						"priority":         getListFieldValues("$anime_list", "priority"),
+						"private_note":     getListFieldValues("$anime_list", "private_note"),
+						"tags":             getListFieldValues("$anime_list", "tags"),
 					},
 					"pipeline": bson.A{
 						bson.M{
@@ -2176,8 +2936,15 @@ func (userListModel *UserListModel) GetUserListByUserID(uid string, data request
 										},
 									},
 								},
+								"started_at":   getListFieldAt("$$started_at", "$$anime_id", "$anime_id"),
+								"finished_at":  getListFieldAt("$$finished_at", "$$anime_id", "$anime_id"),
+								"priority":     getListFieldAt("$$priority", "$$anime_id", "$anime_id"),
+								"private_note": getListFieldAt("$$private_note", "$$anime_id", "$anime_id"),
+								"tags":         getListFieldAt("$$tags", "$$anime_id", "$anime_id"),
 							},
 						},
+						filter,
+						sort,
 					},
 					"as": "anime_list",
 				},
@@ -2196,6 +2963,11 @@ func (userListModel *UserListModel) GetUserListByUserID(uid string, data request
 						"achievement_status": "$game_list.achievement_status",
 						"hours_played":       "$game_list.hours_played",
 						"created_at":         "$game_list.created_at",
+						"started_at":         getListFieldValues("$game_list", "started_at"),
+						"finished_at":        getListFieldValues("$game_list", "finished_at"),
+						"priority":           getListFieldValues("$game_list", "priority"),
+						"private_note":       getListFieldValues("$game_list", "private_note"),
+						"tags":               getListFieldValues("$game_list", "tags"),
 					},
 					"pipeline": bson.A{
 						bson.M{
@@ -2349,8 +3121,15 @@ func (userListModel *UserListModel) GetUserListByUserID(uid string, data request
 										},
 									},
 								},
+								"started_at":   getListFieldAt("$$started_at", "$$game_id", "$game_id"),
+								"finished_at":  getListFieldAt("$$finished_at", "$$game_id", "$game_id"),
+								"priority":     getListFieldAt("$$priority", "$$game_id", "$game_id"),
+								"private_note": getListFieldAt("$$private_note", "$$game_id", "$game_id"),
+								"tags":         getListFieldAt("$$tags", "$$game_id", "$game_id"),
 							},
 						},
+						filter,
+						sort,
 					},
 					"as": "game_list",
 				},
@@ -2714,7 +3493,7 @@ func (userListModel *UserListModel) GetUserListByUserID(uid string, data request
 
 				return userList[0].GameList[j].TimesFinished < userList[0].GameList[i].TimesFinished
 			})
-		} else {
+		} else if data.Sort == "timeswatched" {
 			srt.Slice(userList[0].AnimeList, func(i, j int) bool {
 				if userList[0].AnimeList[i].StatusSort < userList[0].AnimeList[j].StatusSort {
 					return true
@@ -2762,24 +3541,121 @@ func (userListModel *UserListModel) GetUserListByUserID(uid string, data request
 	return responses.UserList{}, nil
 }
 
-// ! Delete
-func (userListModel *UserListModel) DeleteListByUserIDAndType(uid string, data requests.DeleteList) (bool, error) {
-	objectListID, _ := primitive.ObjectIDFromHex(data.ID)
+// MergeListDates returns the dates of an entry after a date update, a date
+// that isn't sent keeps its stored value and sending none clears both.
+func MergeListDates(startedAt, finishedAt, storedStartedAt, storedFinishedAt *time.Time) (*time.Time, *time.Time) {
+	if startedAt == nil && finishedAt == nil {
+		return nil, nil
+	}
+
+	if startedAt == nil {
+		startedAt = storedStartedAt
+	}
+
+	if finishedAt == nil {
+		finishedAt = storedFinishedAt
+	}
 
-	var collection mongo.Collection
-	switch data.Type {
+	return startedAt, finishedAt
+}
+
+// getUserListFilterAndSort returns the stages applied to every list of the
+// user list, priority and date sorts are done in the aggregation.
+func getUserListFilterAndSort(data requests.SortList) (bson.M, bson.M) {
+	match := bson.M{}
+
+	if data.Status != nil {
+		match["content_status"] = *data.Status
+	}
+
+	if data.Priority != nil {
+		if *data.Priority == 0 {
+			match["priority"] = nil
+		} else {
+			match["priority"] = *data.Priority
+		}
+	}
+
+	if data.Tag != nil {
+		match["tags"] = *data.Tag
+	}
+
+	if data.FinishedFrom != nil || data.FinishedTo != nil {
+		dateString := "2006-01-02"
+		finishedAt := bson.M{}
+
+		if data.FinishedFrom != nil {
+			fromDate, _ := time.Parse(dateString, *data.FinishedFrom)
+			finishedAt["$gte"] = fromDate
+		}
+
+		if data.FinishedTo != nil {
+			toDate, _ := time.Parse(dateString, *data.FinishedTo)
+			finishedAt["$lt"] = toDate.AddDate(0, 0, 1)
+		}
+
+		match["finished_at"] = finishedAt
+	}
+
+	var sort bson.D
+	switch data.Sort {
+	case "priority":
+		sort = bson.D{{Key: "priority", Value: -1}, {Key: "status_sort", Value: 1}, {Key: "_id", Value: 1}}
+	case "started_at":
+		sort = bson.D{{Key: "started_at", Value: -1}, {Key: "_id", Value: 1}}
+	case "finished_at":
+		sort = bson.D{{Key: "finished_at", Value: -1}, {Key: "_id", Value: 1}}
+	default:
+		sort = bson.D{{Key: "status_sort", Value: 1}, {Key: "_id", Value: 1}}
+	}
+
+	return bson.M{"$match": match}, bson.M{"$sort": sort}
+}
+
+// getListFieldValues maps the field of every list entry. Unlike "$list.field",
+// entries without the field are kept as null so indexes stay aligned.
+func getListFieldValues(list, field string) bson.M {
+	return bson.M{"$map": bson.M{
+		"input": list,
+		"as":    "entry",
+		"in":    "$$entry." + field,
+	}}
+}
+
+func getListFieldAt(values, ids, id string) bson.M {
+	return bson.M{
+		"$arrayElemAt": bson.A{
+			values,
+			bson.M{
+				"$indexOfArray": bson.A{ids, id},
+			},
+		},
+	}
+}
+
+func (userListModel *UserListModel) getListCollectionByType(contentType string) *mongo.Collection {
+	switch contentType {
 	case "anime":
-		collection = *userListModel.AnimeListCollection
+		return userListModel.AnimeListCollection
+	case "manga":
+		return userListModel.MangaListCollection
 	case "game":
-		collection = *userListModel.GameListCollection
+		return userListModel.GameListCollection
 	case "movie":
-		collection = *userListModel.MovieWatchListCollection
+		return userListModel.MovieWatchListCollection
 	case "tv":
-		collection = *userListModel.TVSeriesWatchListCollection
-	case "manga":
-		collection = *userListModel.MangaListCollection
+		return userListModel.TVSeriesWatchListCollection
 	}
 
+	return nil
+}
+
+// ! Delete
+func (userListModel *UserListModel) DeleteListByUserIDAndType(uid string, data requests.DeleteList) (bool, error) {
+	objectListID, _ := primitive.ObjectIDFromHex(data.ID)
+
+	collection := userListModel.getListCollectionByType(data.Type)
+
 	count, err := collection.DeleteOne(context.TODO(), bson.M{
 		"_id":     objectListID,
 		"user_id": uid,
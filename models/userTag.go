@@ -0,0 +1,355 @@
+package models
+
+import (
+	"app/db"
+	"app/responses"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+//lint:file-ignore ST1005 Ignore all
+
+type UserTagModel struct {
+	UserTagCollection *mongo.Collection
+}
+
+func NewUserTagModel(mongoDB *db.MongoDB) *UserTagModel {
+	return &UserTagModel{
+		UserTagCollection: mongoDB.Database.Collection("user-tags"),
+	}
+}
+
+// Collections that store tags on their entries, tag operations rewrite all of them.
+var (
+	userListTagCollections = []string{
+		"anime-lists", "manga-lists", "game-lists", "movie-watch-lists", "tvseries-watch-lists",
+	}
+	taggableCollections = append(userListTagCollections, "consume-laters")
+)
+
+type UserTag struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"_id"`
+	UserID    string             `bson:"user_id" json:"user_id"`
+	Name      string             `bson:"name" json:"name"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// normalizeTags trims the tags and removes empty and duplicate ones.
+func normalizeTags(tags []string) []string {
+	normalizedTags := []string{}
+	seen := make(map[string]bool)
+
+	for _, tag := range tags {
+		tag = strings.Join(strings.Fields(tag), " ")
+		if tag == "" || seen[tag] {
+			continue
+		}
+
+		seen[tag] = true
+		normalizedTags = append(normalizedTags, tag)
+	}
+
+	return normalizedTags
+}
+
+// tagUpdate sets updated_at of the list entries along with the tag update,
+// consume later entries don't keep it.
+func tagUpdate(collectionName string, update bson.M) bson.M {
+	if collectionName != "consume-laters" {
+		update["$set"] = bson.M{"updated_at": time.Now().UTC()}
+	}
+
+	return update
+}
+
+// ! Create
+// AddUserTags adds the tags to the vocabulary of the user, existing ones are skipped.
+func (userTagModel *UserTagModel) AddUserTags(uid string, tags []string) error {
+	tags = normalizeTags(tags)
+	if len(tags) == 0 {
+		return nil
+	}
+
+	writeModels := make([]mongo.WriteModel, 0, len(tags))
+	for _, tag := range tags {
+		writeModels = append(writeModels, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{
+				"user_id": uid,
+				"name":    tag,
+			}).
+			SetUpdate(bson.M{"$setOnInsert": bson.M{
+				"user_id":    uid,
+				"name":       tag,
+				"created_at": time.Now().UTC(),
+			}}).
+			SetUpsert(true),
+		)
+	}
+
+	if _, err := userTagModel.UserTagCollection.BulkWrite(
+		context.TODO(), writeModels, options.BulkWrite().SetOrdered(false),
+	); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid":  uid,
+			"tags": tags,
+		}).Error("failed to add user tags: ", err)
+
+		return fmt.Errorf("Failed to add tags.")
+	}
+
+	return nil
+}
+
+// ! Update
+// MergeUserTags replaces the source tags with the target tag on every entry and
+// removes them from the vocabulary. Renaming is merging a single tag. Returns
+// false if none of the source tags exists.
+func (userTagModel *UserTagModel) MergeUserTags(uid string, sourceTags []string, targetTag string) (bool, error) {
+	targetTag = strings.Join(strings.Fields(targetTag), " ")
+	sourceTags = normalizeTags(sourceTags)
+
+	count, err := userTagModel.UserTagCollection.CountDocuments(context.TODO(), bson.M{
+		"user_id": uid,
+		"name":    bson.M{"$in": sourceTags},
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid":  uid,
+			"tags": sourceTags,
+		}).Error("failed to count user tags: ", err)
+
+		return false, fmt.Errorf("Failed to merge tags.")
+	}
+
+	if count == 0 {
+		return false, nil
+	}
+
+	var tags []string
+	for _, tag := range sourceTags {
+		if tag != targetTag {
+			tags = append(tags, tag)
+		}
+	}
+
+	if len(tags) == 0 {
+		return true, nil
+	}
+
+	if err := userTagModel.AddUserTags(uid, []string{targetTag}); err != nil {
+		return false, err
+	}
+
+	database := userTagModel.UserTagCollection.Database()
+	filter := bson.M{
+		"user_id": uid,
+		"tags":    bson.M{"$in": tags},
+	}
+
+	for _, collectionName := range taggableCollections {
+		collection := database.Collection(collectionName)
+
+		// Push and pull can't be applied to the same field in a single update.
+		if _, err := collection.UpdateMany(context.TODO(), filter, tagUpdate(collectionName, bson.M{
+			"$addToSet": bson.M{"tags": targetTag},
+		})); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"uid":        uid,
+				"tags":       tags,
+				"target":     targetTag,
+				"collection": collectionName,
+			}).Error("failed to add merged tag: ", err)
+
+			return false, fmt.Errorf("Failed to merge tags.")
+		}
+
+		if _, err := collection.UpdateMany(context.TODO(), filter, tagUpdate(collectionName, bson.M{
+			"$pull": bson.M{"tags": bson.M{"$in": tags}},
+		})); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"uid":        uid,
+				"tags":       tags,
+				"collection": collectionName,
+			}).Error("failed to remove merged tags: ", err)
+
+			return false, fmt.Errorf("Failed to merge tags.")
+		}
+	}
+
+	if _, err := userTagModel.UserTagCollection.DeleteMany(context.TODO(), bson.M{
+		"user_id": uid,
+		"name":    bson.M{"$in": tags},
+	}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid":  uid,
+			"tags": tags,
+		}).Error("failed to delete merged user tags: ", err)
+
+		return false, fmt.Errorf("Failed to merge tags.")
+	}
+
+	return true, nil
+}
+
+// ! Get
+func (userTagModel *UserTagModel) GetUserTags(uid string) ([]responses.UserTag, error) {
+	cursor, err := userTagModel.UserTagCollection.Find(context.TODO(), bson.M{
+		"user_id": uid,
+	}, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to find user tags: ", err)
+
+		return nil, fmt.Errorf("Failed to find tags.")
+	}
+
+	var userTags []UserTag
+	if err := cursor.All(context.TODO(), &userTags); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to decode user tags: ", err)
+
+		return nil, fmt.Errorf("Failed to decode tags.")
+	}
+
+	tagCounts, err := getTagCounts(userTagModel.UserTagCollection.Database(), uid, taggableCollections)
+	if err != nil {
+		return nil, err
+	}
+
+	countByTag := make(map[string]int)
+	for _, tagCount := range tagCounts {
+		countByTag[tagCount.Tag] = tagCount.Count
+	}
+
+	tags := make([]responses.UserTag, 0, len(userTags))
+	for _, userTag := range userTags {
+		tags = append(tags, responses.UserTag{
+			Name:  userTag.Name,
+			Count: countByTag[userTag.Name],
+		})
+	}
+
+	return tags, nil
+}
+
+// getTagCounts counts the entries of each tag in the given collections.
+func getTagCounts(database *mongo.Database, uid string, collectionNames []string) ([]responses.TagCount, error) {
+	countByTag := make(map[string]int)
+
+	for _, collectionName := range collectionNames {
+		cursor, err := database.Collection(collectionName).Aggregate(context.TODO(), bson.A{
+			bson.M{"$match": bson.M{
+				"user_id": uid,
+				"tags.0":  bson.M{"$exists": true},
+			}},
+			bson.M{"$unwind": "$tags"},
+			bson.M{"$group": bson.M{
+				"_id":   "$tags",
+				"count": bson.M{"$sum": 1},
+			}},
+		})
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"uid":        uid,
+				"collection": collectionName,
+			}).Error("failed to aggregate tag counts: ", err)
+
+			return nil, fmt.Errorf("Failed to count tags.")
+		}
+
+		var results []struct {
+			Tag   string `bson:"_id"`
+			Count int    `bson:"count"`
+		}
+		if err := cursor.All(context.TODO(), &results); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"uid":        uid,
+				"collection": collectionName,
+			}).Error("failed to decode tag counts: ", err)
+
+			return nil, fmt.Errorf("Failed to decode tag counts.")
+		}
+
+		for _, result := range results {
+			countByTag[result.Tag] += result.Count
+		}
+	}
+
+	tagCounts := make([]responses.TagCount, 0, len(countByTag))
+	for tag, count := range countByTag {
+		tagCounts = append(tagCounts, responses.TagCount{
+			Tag:   tag,
+			Count: count,
+		})
+	}
+
+	sort.Slice(tagCounts, func(i, j int) bool {
+		if tagCounts[i].Count != tagCounts[j].Count {
+			return tagCounts[i].Count > tagCounts[j].Count
+		}
+
+		return tagCounts[i].Tag < tagCounts[j].Tag
+	})
+
+	return tagCounts, nil
+}
+
+// ! Delete
+// DeleteUserTag removes the tag from the vocabulary and every entry of the user.
+func (userTagModel *UserTagModel) DeleteUserTag(uid, tag string) (bool, error) {
+	tag = strings.Join(strings.Fields(tag), " ")
+	database := userTagModel.UserTagCollection.Database()
+
+	for _, collectionName := range taggableCollections {
+		if _, err := database.Collection(collectionName).UpdateMany(context.TODO(), bson.M{
+			"user_id": uid,
+			"tags":    tag,
+		}, tagUpdate(collectionName, bson.M{
+			"$pull": bson.M{"tags": tag},
+		})); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"uid":        uid,
+				"tag":        tag,
+				"collection": collectionName,
+			}).Error("failed to remove tag from entries: ", err)
+
+			return false, fmt.Errorf("Failed to delete tag.")
+		}
+	}
+
+	count, err := userTagModel.UserTagCollection.DeleteOne(context.TODO(), bson.M{
+		"user_id": uid,
+		"name":    tag,
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+			"tag": tag,
+		}).Error("failed to delete user tag: ", err)
+
+		return false, fmt.Errorf("Failed to delete tag.")
+	}
+
+	return count.DeletedCount > 0, nil
+}
+
+func (userTagModel *UserTagModel) DeleteUserTagsByUserID(uid string) {
+	if _, err := userTagModel.UserTagCollection.DeleteMany(context.TODO(), bson.M{
+		"user_id": uid,
+	}); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"uid": uid,
+		}).Error("failed to delete user tags by user id: ", err)
+	}
+}
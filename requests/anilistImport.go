@@ -1,5 +1,6 @@
 package requests
 
 type AniListImportRequest struct {
+	ImportOptions
 	AniListUsername string `json:"anilist_username" binding:"required"`
 }
@@ -0,0 +1,28 @@
+package requests
+
+import "time"
+
+type GetConsumptionSessions struct {
+	ID   string `form:"id" binding:"required"`
+	Type string `form:"type" binding:"required,oneof=anime manga game movie tv"`
+}
+
+type CreateConsumptionSession struct {
+	ID         string     `json:"id" binding:"required"`
+	Type       string     `json:"type" binding:"required,oneof=anime manga game movie tv"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+	IsFinished bool       `json:"is_finished"`
+	Score      *float32   `json:"score" binding:"omitempty,number,min=0,max=10"`
+	Notes      string     `json:"notes" binding:"omitempty,max=500"`
+}
+
+type UpdateConsumptionSession struct {
+	ID              string     `json:"id" binding:"required"`
+	StartedAt       *time.Time `json:"started_at"`
+	FinishedAt      *time.Time `json:"finished_at"`
+	IsFinished      *bool      `json:"is_finished"`
+	IsUpdatingScore bool       `json:"is_updating_score"`
+	Score           *float32   `json:"score" binding:"omitempty,number,min=0,max=10"`
+	Notes           *string    `json:"notes" binding:"omitempty,max=500"`
+}
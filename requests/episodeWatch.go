@@ -0,0 +1,32 @@
+package requests
+
+type GetEpisodeWatches struct {
+	ID   string `form:"id" binding:"required"`
+	Type string `form:"type" binding:"required,oneof=anime tv"`
+}
+
+type MarkEpisode struct {
+	ID            string   `json:"id" binding:"required"`
+	Type          string   `json:"type" binding:"required,oneof=anime tv"`
+	SeasonNumber  int      `json:"season_number" binding:"omitempty,number,min=1"`
+	EpisodeNumber int      `json:"episode_number" binding:"required,number,min=1"`
+	IsWatched     *bool    `json:"is_watched" binding:"required"`
+	Score         *float32 `json:"score" binding:"omitempty,number,min=0,max=10"`
+}
+
+type MarkEpisodeSeason struct {
+	ID           string `json:"id" binding:"required"`
+	Type         string `json:"type" binding:"required,oneof=anime tv"`
+	SeasonNumber int    `json:"season_number" binding:"omitempty,number,min=1"`
+	IsWatched    *bool  `json:"is_watched" binding:"required"`
+}
+
+type MarkEpisodeRange struct {
+	ID                string `json:"id" binding:"required"`
+	Type              string `json:"type" binding:"required,oneof=anime tv"`
+	FromSeasonNumber  int    `json:"from_season_number" binding:"omitempty,number,min=1"`
+	FromEpisodeNumber int    `json:"from_episode_number" binding:"required,number,min=1"`
+	ToSeasonNumber    int    `json:"to_season_number" binding:"omitempty,number,min=1"`
+	ToEpisodeNumber   int    `json:"to_episode_number" binding:"required,number,min=1"`
+	IsWatched         *bool  `json:"is_watched" binding:"required"`
+}
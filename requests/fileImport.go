@@ -0,0 +1,6 @@
+package requests
+
+type FileImportRequest struct {
+	ImportOptions
+	Source string `form:"source" binding:"required,oneof=mal letterboxd imdb trakt backloggd hltb gog"`
+}
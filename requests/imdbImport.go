@@ -1,6 +1,7 @@
 package requests
 
 type IMDBImportRequest struct {
+	ImportOptions
 	IMDBUserID string `json:"imdb_user_id" binding:"omitempty"`
 	IMDBListID string `json:"imdb_list_id" binding:"omitempty"`
 }
@@ -0,0 +1,35 @@
+package requests
+
+// ImportOptions are accepted by every import, dry run previews the import and
+// policy resolves the entries that are different from the user's entries.
+type ImportOptions struct {
+	DryRun bool   `json:"dry_run" form:"dry_run"`
+	Policy string `json:"policy" form:"policy" binding:"omitempty,oneof=keep_mine take_theirs higher_score latest"`
+}
+
+type ApplyImportJob struct {
+	Policy  string             `json:"policy" binding:"omitempty,oneof=keep_mine take_theirs higher_score latest"`
+	Choices []ImportItemChoice `json:"choices" binding:"omitempty,dive"`
+}
+
+type ImportItemChoice struct {
+	Key    string `json:"key" binding:"required"`
+	Choice string `json:"choice" binding:"required,oneof=keep_mine take_theirs"`
+}
+
+type GetImportHistory struct {
+	Source *string `form:"source"`
+	Page   int64   `form:"page" binding:"required,number,min=1"`
+}
+
+// Pending items are returned by default.
+type GetUnmatchedImportItems struct {
+	Status *string `form:"status" binding:"omitempty,oneof=pending linked dismissed"`
+	Type   *string `form:"type" binding:"omitempty,oneof=anime game movie tv manga"`
+	Page   int64   `form:"page" binding:"required,number,min=1"`
+}
+
+type ConfirmUnmatchedImportItem struct {
+	ContentID string `json:"content_id" binding:"required"`
+	Policy    string `json:"policy" binding:"omitempty,oneof=keep_mine take_theirs higher_score latest"`
+}
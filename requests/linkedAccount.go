@@ -0,0 +1,16 @@
+package requests
+
+// Steam accounts can be linked with the Steam ID or the username of the profile.
+type LinkAccount struct {
+	Source    string `json:"source" binding:"required,oneof=steam trakt anilist"`
+	AccountID string `json:"account_id" binding:"required"`
+}
+
+type UnlinkAccount struct {
+	Source string `json:"source" binding:"required,oneof=steam trakt anilist"`
+}
+
+type GetLinkedAccountSyncs struct {
+	Source string `form:"source" binding:"required,oneof=steam trakt anilist"`
+	Page   int64  `form:"page" binding:"required,number,min=1"`
+}
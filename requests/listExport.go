@@ -0,0 +1,10 @@
+package requests
+
+type ExportMALList struct {
+	Type string `form:"type" binding:"required,oneof=anime manga"`
+}
+
+// Finished movies are exported by default, watchlist exports plan to watch movies.
+type ExportLetterboxdList struct {
+	List string `form:"list" binding:"omitempty,oneof=watched watchlist"`
+}
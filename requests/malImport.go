@@ -1,5 +1,8 @@
 package requests
 
 type MALImportRequest struct {
+	ImportOptions
 	Username string `json:"username" binding:"required" validate:"required"`
+	// Anime list is imported if type is not set.
+	Type string `json:"type" binding:"omitempty,oneof=anime manga"`
 }
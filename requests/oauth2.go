@@ -1,14 +1,16 @@
 package requests
 
 type GoogleLogin struct {
-	Token    string `json:"token" binding:"required"`
-	Image    string `json:"image" binding:"required"`
-	FCMToken string `json:"fcm_token" binding:"required"`
+	Token      string  `json:"token" binding:"required"`
+	Image      string  `json:"image" binding:"required"`
+	FCMToken   string  `json:"fcm_token" binding:"required"`
+	DeviceName *string `json:"device_name" binding:"omitempty,max=100"`
 }
 
 type AppleSignin struct {
-	Code      string `json:"code" binding:"required"`
-	IsRefresh *bool  `json:"is_refresh" binding:"required"`
-	Image     string `json:"image" binding:"required"`
-	FCMToken  string `json:"fcm_token" binding:"required"`
+	Code       string  `json:"code" binding:"required"`
+	IsRefresh  *bool   `json:"is_refresh" binding:"required"`
+	Image      string  `json:"image" binding:"required"`
+	FCMToken   string  `json:"fcm_token" binding:"required"`
+	DeviceName *string `json:"device_name" binding:"omitempty,max=100"`
 }
@@ -1,6 +1,7 @@
 package requests
 
 type SteamImportRequest struct {
+	ImportOptions
 	SteamID       string `json:"steam_id,omitempty"`
 	SteamUsername string `json:"steam_username,omitempty"`
 }
@@ -1,5 +1,6 @@
 package requests
 
 type TMDBImportRequest struct {
+	ImportOptions
 	TMDBUsername string `json:"tmdb_username" binding:"required"`
 }
@@ -1,5 +1,6 @@
 package requests
 
 type TraktImportRequest struct {
+	ImportOptions
 	TraktUsername string `json:"trakt_username" binding:"required"`
 }
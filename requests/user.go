@@ -4,6 +4,12 @@ type Login struct {
 	EmailAddress string  `json:"email_address" binding:"required,email"`
 	Password     string  `json:"password" binding:"required"`
 	FCMToken     *string `json:"fcm_token" binding:"omitempty"`
+	DeviceName   *string `json:"device_name" binding:"omitempty,max=100"`
+}
+
+// Web clients send the refresh token with the cookie.
+type RefreshSession struct {
+	RefreshToken string `json:"refresh_token"`
 }
 
 type Register struct {
@@ -38,9 +44,12 @@ type ChangeNotification struct {
 	ReviewLikes *bool `json:"review_likes" binding:"required"`
 }
 
-type ChangeMembership struct {
-	IsPremium      bool `json:"is_premium"`
-	MembershipType int  `json:"membership_type"`
+// Purchase token is the base64 receipt of the App Store and the purchase token
+// of the Play Store.
+type VerifyPurchase struct {
+	Store         string `json:"store" binding:"required,oneof=app_store play_store"`
+	ProductID     string `json:"product_id" binding:"required"`
+	PurchaseToken string `json:"purchase_token" binding:"required"`
 }
 
 type ChangeUsername struct {
@@ -51,6 +60,12 @@ type ForgotPassword struct {
 	EmailAddress string `json:"email_address" binding:"required,email"`
 }
 
+// Posted by the reset password form.
+type ResetPassword struct {
+	NewPassword     string `form:"new_password" binding:"required,min=6"`
+	ConfirmPassword string `form:"confirm_password" binding:"required,eqfield=NewPassword"`
+}
+
 type GetProfile struct {
 	Username string `form:"username" binding:"required"`
 }
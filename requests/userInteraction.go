@@ -3,11 +3,12 @@ package requests
 // We have 2 external id's because Movie and TVSeries external ids are string but
 // game and anime external ids are integers.
 type CreateConsumeLater struct {
-	ContentID            string  `json:"content_id" binding:"required"`
-	ContentExternalID    *string `json:"content_external_id"`
-	ContentExternalIntID *int64  `json:"content_external_int_id"`
-	ContentType          string  `json:"content_type" binding:"required,oneof=anime game movie tv"`
-	SelfNote             *string `json:"self_note"`
+	ContentID            string   `json:"content_id" binding:"required"`
+	ContentExternalID    *string  `json:"content_external_id"`
+	ContentExternalIntID *int64   `json:"content_external_int_id"`
+	ContentType          string   `json:"content_type" binding:"required,oneof=anime game movie tv"`
+	SelfNote             *string  `json:"self_note"`
+	Tags                 []string `json:"tags" binding:"omitempty,max=10,dive,max=30"`
 }
 
 type SortFilterConsumeLater struct {
@@ -15,6 +16,7 @@ type SortFilterConsumeLater struct {
 	Genre             *string `form:"genre"`
 	StreamingPlatform *string `form:"streaming_platform"`
 	Sort              string  `form:"sort" binding:"required,oneof=new old alphabetical unalphabetical soon later"`
+	Tag               *string `form:"tag"`
 }
 
 type UpdateConsumeLater struct {
@@ -22,6 +24,11 @@ type UpdateConsumeLater struct {
 	SelfNote *string `json:"self_note"`
 }
 
+type UpdateConsumeLaterTags struct {
+	ID   string   `json:"id" binding:"required"`
+	Tags []string `json:"tags" binding:"omitempty,max=10,dive,max=30"`
+}
+
 type MarkConsumeLater struct {
 	ID    string   `json:"id" binding:"required"`
 	Score *float32 `json:"score" binding:"omitempty,number,min=0,max=10"`
@@ -1,55 +1,86 @@
 package requests
 
+import "time"
+
 type CreateAnimeList struct {
-	AnimeID         string   `json:"anime_id" binding:"required"`
-	AnimeMALID      int64    `json:"anime_mal_id" binding:"required"`
-	Status          string   `json:"status" binding:"required,oneof=active finished dropped planto"`
-	WatchedEpisodes *int64   `json:"watched_episodes" binding:"required,number,min=0"`
-	TimesFinished   *int     `json:"times_finished" binding:"omitempty,number,min=0"`
-	Score           *float32 `json:"score" binding:"omitempty,number,min=0,max=10"`
+	AnimeID         string     `json:"anime_id" binding:"required"`
+	AnimeMALID      int64      `json:"anime_mal_id" binding:"required"`
+	Status          string     `json:"status" binding:"required,oneof=active finished dropped planto"`
+	WatchedEpisodes *int64     `json:"watched_episodes" binding:"required,number,min=0"`
+	TimesFinished   *int       `json:"times_finished" binding:"omitempty,number,min=0"`
+	Score           *float32   `json:"score" binding:"omitempty,number,min=0,max=10"`
+	StartedAt       *time.Time `json:"started_at"`
+	FinishedAt      *time.Time `json:"finished_at"`
+	Priority        *int       `json:"priority" binding:"omitempty,number,min=0,max=5"`
+	PrivateNote     *string    `json:"private_note" binding:"omitempty,max=500"`
+	Tags            []string   `json:"tags" binding:"omitempty,max=10,dive,max=30"`
 }
 
 type CreateGameList struct {
-	GameID            string   `json:"game_id" binding:"required"`
-	GameRAWGID        int64    `json:"game_rawg_id" binding:"required"`
-	Status            string   `json:"status" binding:"required,oneof=active finished dropped planto"`
-	Score             *float32 `json:"score" binding:"omitempty,number,min=0,max=10"`
-	TimesFinished     *int     `json:"times_finished" binding:"omitempty,number,min=0"`
-	HoursPlayed       *int     `json:"hours_played" binding:"omitempty,number,min=0"`
-	AchievementStatus *float32 `json:"achievement_status" binding:"omitempty,number,min=0,max=100"`
+	GameID            string     `json:"game_id" binding:"required"`
+	GameRAWGID        int64      `json:"game_rawg_id" binding:"required"`
+	Status            string     `json:"status" binding:"required,oneof=active finished dropped planto"`
+	Score             *float32   `json:"score" binding:"omitempty,number,min=0,max=10"`
+	TimesFinished     *int       `json:"times_finished" binding:"omitempty,number,min=0"`
+	HoursPlayed       *int       `json:"hours_played" binding:"omitempty,number,min=0"`
+	AchievementStatus *float32   `json:"achievement_status" binding:"omitempty,number,min=0,max=100"`
+	StartedAt         *time.Time `json:"started_at"`
+	FinishedAt        *time.Time `json:"finished_at"`
+	Priority          *int       `json:"priority" binding:"omitempty,number,min=0,max=5"`
+	PrivateNote       *string    `json:"private_note" binding:"omitempty,max=500"`
+	Tags              []string   `json:"tags" binding:"omitempty,max=10,dive,max=30"`
 }
 
 type CreateMovieWatchList struct {
-	MovieID       string   `json:"movie_id" binding:"required"`
-	MovieTmdbID   string   `json:"movie_tmdb_id" binding:"required"`
-	Status        string   `json:"status" binding:"required,oneof=active finished dropped planto"`
-	TimesFinished *int     `json:"times_finished" binding:"omitempty,number,min=0"`
-	Score         *float32 `json:"score" binding:"omitempty,number,min=0,max=10"`
+	MovieID       string     `json:"movie_id" binding:"required"`
+	MovieTmdbID   string     `json:"movie_tmdb_id" binding:"required"`
+	Status        string     `json:"status" binding:"required,oneof=active finished dropped planto"`
+	TimesFinished *int       `json:"times_finished" binding:"omitempty,number,min=0"`
+	Score         *float32   `json:"score" binding:"omitempty,number,min=0,max=10"`
+	StartedAt     *time.Time `json:"started_at"`
+	FinishedAt    *time.Time `json:"finished_at"`
+	Priority      *int       `json:"priority" binding:"omitempty,number,min=0,max=5"`
+	PrivateNote   *string    `json:"private_note" binding:"omitempty,max=500"`
+	Tags          []string   `json:"tags" binding:"omitempty,max=10,dive,max=30"`
 }
 
 type CreateTVSeriesWatchList struct {
-	TvID            string   `json:"tv_id" binding:"required"`
-	TvTmdbID        string   `json:"tv_tmdb_id" binding:"required"`
-	Status          string   `json:"status" binding:"required,oneof=active finished dropped planto"`
-	WatchedEpisodes *int     `json:"watched_episodes" binding:"required,number,min=0"`
-	WatchedSeasons  *int     `json:"watched_seasons" binding:"required,number,min=0"`
-	TimesFinished   *int     `json:"times_finished" binding:"omitempty,number,min=0"`
-	Score           *float32 `json:"score" binding:"omitempty,number,min=0,max=10"`
+	TvID            string     `json:"tv_id" binding:"required"`
+	TvTmdbID        string     `json:"tv_tmdb_id" binding:"required"`
+	Status          string     `json:"status" binding:"required,oneof=active finished dropped planto"`
+	WatchedEpisodes *int       `json:"watched_episodes" binding:"required,number,min=0"`
+	WatchedSeasons  *int       `json:"watched_seasons" binding:"required,number,min=0"`
+	TimesFinished   *int       `json:"times_finished" binding:"omitempty,number,min=0"`
+	Score           *float32   `json:"score" binding:"omitempty,number,min=0,max=10"`
+	StartedAt       *time.Time `json:"started_at"`
+	FinishedAt      *time.Time `json:"finished_at"`
+	Priority        *int       `json:"priority" binding:"omitempty,number,min=0,max=5"`
+	PrivateNote     *string    `json:"private_note" binding:"omitempty,max=500"`
+	Tags            []string   `json:"tags" binding:"omitempty,max=10,dive,max=30"`
 }
 
 type CreateMangaList struct {
-	MangaID       string   `json:"manga_id" binding:"required"`
-	MangaMALID    int64    `json:"manga_mal_id" binding:"required"`
-	Status        string   `json:"status" binding:"required,oneof=active finished dropped planto"`
-	ReadChapters  *int64   `json:"read_chapters" binding:"required,number,min=0"`
-	ReadVolumes   *int64   `json:"read_volumes" binding:"required,number,min=0"`
-	TimesFinished *int     `json:"times_finished" binding:"omitempty,number,min=0"`
-	Score         *float32 `json:"score" binding:"omitempty,number,min=0,max=10"`
+	MangaID       string     `json:"manga_id" binding:"required"`
+	MangaMALID    int64      `json:"manga_mal_id" binding:"required"`
+	Status        string     `json:"status" binding:"required,oneof=active finished dropped planto"`
+	ReadChapters  *int64     `json:"read_chapters" binding:"required,number,min=0"`
+	ReadVolumes   *int64     `json:"read_volumes" binding:"required,number,min=0"`
+	TimesFinished *int       `json:"times_finished" binding:"omitempty,number,min=0"`
+	Score         *float32   `json:"score" binding:"omitempty,number,min=0,max=10"`
+	StartedAt     *time.Time `json:"started_at"`
+	FinishedAt    *time.Time `json:"finished_at"`
+	Priority      *int       `json:"priority" binding:"omitempty,number,min=0,max=5"`
+	PrivateNote   *string    `json:"private_note" binding:"omitempty,max=500"`
+	Tags          []string   `json:"tags" binding:"omitempty,max=10,dive,max=30"`
 }
 
 type SortList struct {
-	Sort   string  `form:"sort" binding:"required,oneof=score timeswatched alphabetical unalphabetical"`
-	Status *string `form:"status" binding:"omitempty,oneof=active finished dropped"`
+	Sort         string  `form:"sort" binding:"required,oneof=score timeswatched alphabetical unalphabetical priority started_at finished_at"`
+	Status       *string `form:"status" binding:"omitempty,oneof=active finished dropped"`
+	Priority     *int    `form:"priority" binding:"omitempty,number,min=0,max=5"`
+	FinishedFrom *string `form:"finished_from" binding:"omitempty,datetime=2006-01-02" time_format:"2006-01-02"`
+	FinishedTo   *string `form:"finished_to" binding:"omitempty,datetime=2006-01-02" time_format:"2006-01-02"`
+	Tag          *string `form:"tag"`
 }
 
 type UpdateUserList struct {
@@ -57,40 +88,68 @@ type UpdateUserList struct {
 }
 
 type UpdateAnimeList struct {
-	ID              string   `json:"id" binding:"required"`
-	IsUpdatingScore bool     `json:"is_updating_score"`
-	Score           *float32 `json:"score" binding:"omitempty,number,min=0,max=10"`
-	TimesFinished   *int     `json:"times_finished" binding:"omitempty,number,min=0"`
-	Status          *string  `json:"status" binding:"omitempty,oneof=active finished dropped planto"`
-	WatchedEpisodes *int64   `json:"watched_episodes" binding:"omitempty,number,min=0"`
+	ID              string     `json:"id" binding:"required"`
+	IsUpdatingScore bool       `json:"is_updating_score"`
+	Score           *float32   `json:"score" binding:"omitempty,number,min=0,max=10"`
+	TimesFinished   *int       `json:"times_finished" binding:"omitempty,number,min=0"`
+	Status          *string    `json:"status" binding:"omitempty,oneof=active finished dropped planto"`
+	WatchedEpisodes *int64     `json:"watched_episodes" binding:"omitempty,number,min=0"`
+	IsUpdatingDates bool       `json:"is_updating_dates"`
+	StartedAt       *time.Time `json:"started_at"`
+	FinishedAt      *time.Time `json:"finished_at"`
+	Priority        *int       `json:"priority" binding:"omitempty,number,min=0,max=5"`
+	PrivateNote     *string    `json:"private_note" binding:"omitempty,max=500"`
+	IsUpdatingTags  bool       `json:"is_updating_tags"`
+	Tags            []string   `json:"tags" binding:"omitempty,max=10,dive,max=30"`
 }
 
 type UpdateGameList struct {
-	ID                string   `json:"id" binding:"required"`
-	IsUpdatingScore   bool     `json:"is_updating_score"`
-	Score             *float32 `json:"score" binding:"omitempty,number,min=0,max=10"`
-	TimesFinished     *int     `json:"times_finished" binding:"omitempty,number,min=0"`
-	HoursPlayed       *int     `json:"hours_played" binding:"omitempty,number,min=0"`
-	Status            *string  `json:"status" binding:"omitempty,oneof=active finished dropped planto"`
-	AchievementStatus *float32 `json:"achievement_status" binding:"omitempty,number,min=0"`
+	ID                string     `json:"id" binding:"required"`
+	IsUpdatingScore   bool       `json:"is_updating_score"`
+	Score             *float32   `json:"score" binding:"omitempty,number,min=0,max=10"`
+	TimesFinished     *int       `json:"times_finished" binding:"omitempty,number,min=0"`
+	HoursPlayed       *int       `json:"hours_played" binding:"omitempty,number,min=0"`
+	Status            *string    `json:"status" binding:"omitempty,oneof=active finished dropped planto"`
+	AchievementStatus *float32   `json:"achievement_status" binding:"omitempty,number,min=0"`
+	IsUpdatingDates   bool       `json:"is_updating_dates"`
+	StartedAt         *time.Time `json:"started_at"`
+	FinishedAt        *time.Time `json:"finished_at"`
+	Priority          *int       `json:"priority" binding:"omitempty,number,min=0,max=5"`
+	PrivateNote       *string    `json:"private_note" binding:"omitempty,max=500"`
+	IsUpdatingTags    bool       `json:"is_updating_tags"`
+	Tags              []string   `json:"tags" binding:"omitempty,max=10,dive,max=30"`
 }
 
 type UpdateMovieList struct {
-	ID              string   `json:"id" binding:"required"`
-	IsUpdatingScore bool     `json:"is_updating_score"`
-	Score           *float32 `json:"score" binding:"omitempty,number,min=0,max=10"`
-	TimesFinished   *int     `json:"times_finished" binding:"omitempty,number,min=0"`
-	Status          *string  `json:"status" binding:"omitempty,oneof=active finished dropped planto"`
+	ID              string     `json:"id" binding:"required"`
+	IsUpdatingScore bool       `json:"is_updating_score"`
+	Score           *float32   `json:"score" binding:"omitempty,number,min=0,max=10"`
+	TimesFinished   *int       `json:"times_finished" binding:"omitempty,number,min=0"`
+	Status          *string    `json:"status" binding:"omitempty,oneof=active finished dropped planto"`
+	IsUpdatingDates bool       `json:"is_updating_dates"`
+	StartedAt       *time.Time `json:"started_at"`
+	FinishedAt      *time.Time `json:"finished_at"`
+	Priority        *int       `json:"priority" binding:"omitempty,number,min=0,max=5"`
+	PrivateNote     *string    `json:"private_note" binding:"omitempty,max=500"`
+	IsUpdatingTags  bool       `json:"is_updating_tags"`
+	Tags            []string   `json:"tags" binding:"omitempty,max=10,dive,max=30"`
 }
 
 type UpdateMangaList struct {
-	ID              string   `json:"id" binding:"required"`
-	IsUpdatingScore bool     `json:"is_updating_score"`
-	Score           *float32 `json:"score" binding:"omitempty,number,min=0,max=10"`
-	TimesFinished   *int     `json:"times_finished" binding:"omitempty,number,min=0"`
-	Status          *string  `json:"status" binding:"omitempty,oneof=active finished dropped planto"`
-	ReadChapters    *int64   `json:"read_chapters" binding:"omitempty,number,min=0"`
-	ReadVolumes     *int64   `json:"read_volumes" binding:"omitempty,number,min=0"`
+	ID              string     `json:"id" binding:"required"`
+	IsUpdatingScore bool       `json:"is_updating_score"`
+	Score           *float32   `json:"score" binding:"omitempty,number,min=0,max=10"`
+	TimesFinished   *int       `json:"times_finished" binding:"omitempty,number,min=0"`
+	Status          *string    `json:"status" binding:"omitempty,oneof=active finished dropped planto"`
+	ReadChapters    *int64     `json:"read_chapters" binding:"omitempty,number,min=0"`
+	ReadVolumes     *int64     `json:"read_volumes" binding:"omitempty,number,min=0"`
+	IsUpdatingDates bool       `json:"is_updating_dates"`
+	StartedAt       *time.Time `json:"started_at"`
+	FinishedAt      *time.Time `json:"finished_at"`
+	Priority        *int       `json:"priority" binding:"omitempty,number,min=0,max=5"`
+	PrivateNote     *string    `json:"private_note" binding:"omitempty,max=500"`
+	IsUpdatingTags  bool       `json:"is_updating_tags"`
+	Tags            []string   `json:"tags" binding:"omitempty,max=10,dive,max=30"`
 }
 
 type IncrementTVSeriesList struct {
@@ -99,16 +158,49 @@ type IncrementTVSeriesList struct {
 }
 
 type UpdateTVSeriesList struct {
-	ID              string   `json:"id" binding:"required"`
-	IsUpdatingScore bool     `json:"is_updating_score"`
-	Score           *float32 `json:"score" binding:"omitempty,number,min=0,max=10"`
-	TimesFinished   *int     `json:"times_finished" binding:"omitempty,number,min=0"`
-	Status          *string  `json:"status" binding:"omitempty,oneof=active finished dropped planto"`
-	WatchedEpisodes *int     `json:"watched_episodes" binding:"omitempty,number,min=0"`
-	WatchedSeasons  *int     `json:"watched_seasons" binding:"omitempty,number,min=0"`
+	ID              string     `json:"id" binding:"required"`
+	IsUpdatingScore bool       `json:"is_updating_score"`
+	Score           *float32   `json:"score" binding:"omitempty,number,min=0,max=10"`
+	TimesFinished   *int       `json:"times_finished" binding:"omitempty,number,min=0"`
+	Status          *string    `json:"status" binding:"omitempty,oneof=active finished dropped planto"`
+	WatchedEpisodes *int       `json:"watched_episodes" binding:"omitempty,number,min=0"`
+	WatchedSeasons  *int       `json:"watched_seasons" binding:"omitempty,number,min=0"`
+	IsUpdatingDates bool       `json:"is_updating_dates"`
+	StartedAt       *time.Time `json:"started_at"`
+	FinishedAt      *time.Time `json:"finished_at"`
+	Priority        *int       `json:"priority" binding:"omitempty,number,min=0,max=5"`
+	PrivateNote     *string    `json:"private_note" binding:"omitempty,max=500"`
+	IsUpdatingTags  bool       `json:"is_updating_tags"`
+	Tags            []string   `json:"tags" binding:"omitempty,max=10,dive,max=30"`
+}
+
+type BulkListItem struct {
+	ID   string `json:"id" binding:"required"`
+	Type string `json:"type" binding:"required,oneof=anime game movie tv manga"`
+}
+
+// Status is required for status operation, nil score clears the score.
+type BulkUpdateList struct {
+	Items     []BulkListItem `json:"items" binding:"required,min=1,max=1000,dive"`
+	Operation string         `json:"operation" binding:"required,oneof=status score delete later"`
+	Status    *string        `json:"status" binding:"required_if=Operation status,omitempty,oneof=active finished dropped planto"`
+	Score     *float32       `json:"score" binding:"omitempty,number,min=0,max=10"`
 }
 
 type DeleteList struct {
 	ID   string `json:"id" binding:"required"`
 	Type string `json:"type" binding:"required,oneof=anime game movie tv manga"`
 }
+
+// History of a single entry is returned when id is set, otherwise the latest
+// changes of every entry including the deleted ones.
+type GetListChanges struct {
+	ID   *string `form:"id"`
+	Type *string `form:"type" binding:"omitempty,oneof=anime game movie tv manga"`
+	Page int64   `form:"page" binding:"required,number,min=1"`
+}
+
+type UndoListChange struct {
+	ID   string `json:"id" binding:"required"`
+	Type string `json:"type" binding:"required,oneof=anime game movie tv manga"`
+}
@@ -0,0 +1,19 @@
+package requests
+
+type CreateUserTag struct {
+	Name string `json:"name" binding:"required,min=1,max=30"`
+}
+
+type RenameUserTag struct {
+	Name    string `json:"name" binding:"required"`
+	NewName string `json:"new_name" binding:"required,min=1,max=30"`
+}
+
+type MergeUserTags struct {
+	Names []string `json:"names" binding:"required,min=1,dive,required"`
+	Into  string   `json:"into" binding:"required,min=1,max=30"`
+}
+
+type DeleteUserTag struct {
+	Name string `json:"name" binding:"required"`
+}
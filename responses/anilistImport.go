@@ -1,14 +1,5 @@
 package responses
 
-type AniListImportResponse struct {
-	ImportedCount  int      `json:"imported_count"`
-	SkippedCount   int      `json:"skipped_count"`
-	ErrorCount     int      `json:"error_count"`
-	Message        string   `json:"message"`
-	ImportedTitles []string `json:"imported_titles"`
-	SkippedTitles  []string `json:"skipped_titles"`
-}
-
 type AniListEntry struct {
 	ID              int     `json:"id"`
 	Title           string  `json:"title"`
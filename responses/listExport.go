@@ -0,0 +1,84 @@
+package responses
+
+import "encoding/xml"
+
+// MyAnimeList XML, the format of the list export of MyAnimeList which is also
+// accepted by its import.
+type MALExport struct {
+	XMLName xml.Name         `xml:"myanimelist"`
+	MyInfo  MALExportInfo    `xml:"myinfo"`
+	Anime   []MALExportAnime `xml:"anime,omitempty"`
+	Manga   []MALExportManga `xml:"manga,omitempty"`
+}
+
+// UserExportType is 1 for anime and 2 for manga lists.
+type MALExportInfo struct {
+	UserExportType int `xml:"user_export_type"`
+}
+
+type MALExportCDATA struct {
+	Value string `xml:",cdata"`
+}
+
+type MALExportAnime struct {
+	SeriesAnimeDBID   int64          `xml:"series_animedb_id"`
+	SeriesTitle       MALExportCDATA `xml:"series_title"`
+	SeriesType        string         `xml:"series_type"`
+	SeriesEpisodes    int64          `xml:"series_episodes"`
+	MyWatchedEpisodes int64          `xml:"my_watched_episodes"`
+	MyStartDate       string         `xml:"my_start_date"`
+	MyFinishDate      string         `xml:"my_finish_date"`
+	MyScore           int            `xml:"my_score"`
+	MyStatus          string         `xml:"my_status"`
+	MyTimesWatched    int            `xml:"my_times_watched"`
+	MyTags            MALExportCDATA `xml:"my_tags"`
+	MyComments        MALExportCDATA `xml:"my_comments"`
+	MyPriority        string         `xml:"my_priority"`
+	UpdateOnImport    int            `xml:"update_on_import"`
+}
+
+type MALExportManga struct {
+	MangaMangaDBID int64          `xml:"manga_mangadb_id"`
+	MangaTitle     MALExportCDATA `xml:"manga_title"`
+	MangaVolumes   int64          `xml:"manga_volumes"`
+	MangaChapters  int64          `xml:"manga_chapters"`
+	MyReadVolumes  int64          `xml:"my_read_volumes"`
+	MyReadChapters int64          `xml:"my_read_chapters"`
+	MyStartDate    string         `xml:"my_start_date"`
+	MyFinishDate   string         `xml:"my_finish_date"`
+	MyScore        int            `xml:"my_score"`
+	MyStatus       string         `xml:"my_status"`
+	MyTimesRead    int            `xml:"my_times_read"`
+	MyTags         MALExportCDATA `xml:"my_tags"`
+	MyComments     MALExportCDATA `xml:"my_comments"`
+	MyPriority     string         `xml:"my_priority"`
+	UpdateOnImport int            `xml:"update_on_import"`
+}
+
+// Trakt JSON, every section is a valid request body of the matching Trakt sync
+// endpoint (/sync/history, /sync/ratings and /sync/watchlist).
+type TraktExport struct {
+	History   TraktExportSection `json:"history"`
+	Ratings   TraktExportSection `json:"ratings"`
+	Watchlist TraktExportSection `json:"watchlist"`
+}
+
+type TraktExportSection struct {
+	Movies []TraktExportItem `json:"movies"`
+	Shows  []TraktExportItem `json:"shows"`
+}
+
+type TraktExportItem struct {
+	Title     string         `json:"title"`
+	Year      *int           `json:"year,omitempty"`
+	IDs       TraktExportIDs `json:"ids"`
+	WatchedAt *string        `json:"watched_at,omitempty"`
+	Rating    *int           `json:"rating,omitempty"`
+	RatedAt   *string        `json:"rated_at,omitempty"`
+}
+
+type TraktExportIDs struct {
+	Trakt *int64  `json:"trakt,omitempty"`
+	Tmdb  *int64  `json:"tmdb,omitempty"`
+	Imdb  *string `json:"imdb,omitempty"`
+}
@@ -1,14 +1,5 @@
 package responses
 
-type MALImportResponse struct {
-	ImportedCount  int      `json:"imported_count"`
-	SkippedCount   int      `json:"skipped_count"`
-	ErrorCount     int      `json:"error_count"`
-	Message        string   `json:"message"`
-	ImportedTitles []string `json:"imported_titles"`
-	SkippedTitles  []string `json:"skipped_titles"`
-}
-
 type MALAnimeEntry struct {
 	ID              int     `json:"id"`
 	Title           string  `json:"title"`
@@ -19,3 +10,12 @@ type MALAnimeEntry struct {
 	StartDate       *string `json:"start_date"`
 	FinishDate      *string `json:"finish_date"`
 }
+
+type MALMangaEntry struct {
+	ID           int    `json:"id"`
+	Title        string `json:"title"`
+	Status       string `json:"status"`
+	Score        *int   `json:"score"`
+	ReadChapters int    `json:"read_chapters"`
+	ReadVolumes  int    `json:"read_volumes"`
+}
@@ -0,0 +1,71 @@
+package responses
+
+// Plex sends the payload as the "payload" field of a multipart form, media.scrobble
+// is sent when 90% of the item is played. Guids are the ids of the item e.g.
+// "imdb://tt0111161" and "tmdb://278", the show of an episode is its grandparent.
+type PlexWebhook struct {
+	Event    string `json:"event"`
+	Metadata struct {
+		Type             string `json:"type"`
+		Title            string `json:"title"`
+		Year             int    `json:"year"`
+		GrandparentTitle string `json:"grandparentTitle"`
+		GrandparentGUID  string `json:"grandparentGuid"`
+		ParentIndex      int    `json:"parentIndex"`
+		Index            int    `json:"index"`
+		Guid             []struct {
+			ID string `json:"id"`
+		} `json:"Guid"`
+	} `json:"Metadata"`
+}
+
+// Jellyfin webhook plugin sends the fields of the template, the template of the
+// destination should render the fields below. Series provider ids are the ids of
+// the show of an episode.
+type JellyfinWebhook struct {
+	NotificationType   string `json:"NotificationType"`
+	ItemType           string `json:"ItemType"`
+	Name               string `json:"Name"`
+	Year               int    `json:"Year"`
+	SeriesName         string `json:"SeriesName"`
+	SeasonNumber       int    `json:"SeasonNumber"`
+	EpisodeNumber      int    `json:"EpisodeNumber"`
+	ProviderTmdb       string `json:"Provider_tmdb"`
+	ProviderImdb       string `json:"Provider_imdb"`
+	ProviderTvdb       string `json:"Provider_tvdb"`
+	SeriesProviderTmdb string `json:"SeriesProvider_tmdb"`
+	SeriesProviderImdb string `json:"SeriesProvider_imdb"`
+	SeriesProviderTvdb string `json:"SeriesProvider_tvdb"`
+	PlayedToCompletion bool   `json:"PlayedToCompletion"`
+}
+
+// Emby doesn't send the ids of the show of an episode, the show is matched by
+// its title.
+type EmbyWebhook struct {
+	Event string `json:"Event"`
+	Item  struct {
+		Name              string `json:"Name"`
+		Type              string `json:"Type"`
+		ProductionYear    int    `json:"ProductionYear"`
+		SeriesName        string `json:"SeriesName"`
+		ParentIndexNumber int    `json:"ParentIndexNumber"`
+		IndexNumber       int    `json:"IndexNumber"`
+		ProviderIds       struct {
+			Tmdb string `json:"Tmdb"`
+			Imdb string `json:"Imdb"`
+			Tvdb string `json:"Tvdb"`
+		} `json:"ProviderIds"`
+	} `json:"Item"`
+	PlaybackInfo struct {
+		PlayedToCompletion bool `json:"PlayedToCompletion"`
+	} `json:"PlaybackInfo"`
+}
+
+// ScrobbleWebhooks are the webhook urls of the media servers, token is shown
+// once since only its hash is stored.
+type ScrobbleWebhooks struct {
+	Token       string `json:"token"`
+	PlexURL     string `json:"plex_url"`
+	JellyfinURL string `json:"jellyfin_url"`
+	EmbyURL     string `json:"emby_url"`
+}
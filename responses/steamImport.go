@@ -1,14 +1,5 @@
 package responses
 
-type SteamImportResponse struct {
-	ImportedCount  int      `json:"imported_count"`
-	SkippedCount   int      `json:"skipped_count"`
-	ErrorCount     int      `json:"error_count"`
-	Message        string   `json:"message"`
-	ImportedTitles []string `json:"imported_titles"`
-	SkippedTitles  []string `json:"skipped_titles"`
-}
-
 type SteamGameEntry struct {
 	AppID                    int    `json:"appid"`
 	Name                     string `json:"name"`
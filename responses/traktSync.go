@@ -0,0 +1,59 @@
+package responses
+
+type TraktDeviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURL string `json:"verification_url"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+}
+
+type TraktToken struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	CreatedAt    int64  `json:"created_at"`
+}
+
+type TraktUserSettings struct {
+	User struct {
+		Username string `json:"username"`
+		IDs      struct {
+			Slug string `json:"slug"`
+		} `json:"ids"`
+	} `json:"user"`
+}
+
+// TraktDevice is the code the user enters on the verification url.
+type TraktDevice struct {
+	UserCode        string `json:"user_code"`
+	VerificationURL string `json:"verification_url"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+}
+
+// TraktSyncItems is the request body of /sync/history and /sync/watchlist.
+type TraktSyncItems struct {
+	Movies []TraktSyncItem `json:"movies,omitempty"`
+	Shows  []TraktSyncItem `json:"shows,omitempty"`
+}
+
+type TraktSyncItem struct {
+	IDs       TraktSyncIDs      `json:"ids"`
+	WatchedAt string            `json:"watched_at,omitempty"`
+	Seasons   []TraktSyncSeason `json:"seasons,omitempty"`
+}
+
+type TraktSyncIDs struct {
+	Tmdb int `json:"tmdb"`
+}
+
+type TraktSyncSeason struct {
+	Number   int                `json:"number"`
+	Episodes []TraktSyncEpisode `json:"episodes"`
+}
+
+type TraktSyncEpisode struct {
+	Number    int    `json:"number"`
+	WatchedAt string `json:"watched_at"`
+}
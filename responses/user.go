@@ -9,6 +9,7 @@ import (
 type IsUserPremium struct {
 	IsPremium         bool `bson:"is_premium" json:"is_premium"`
 	IsLifetimePremium bool `bson:"is_lifetime_premium" json:"is_lifetime_premium"`
+	IsLegacyPremium   bool `bson:"is_legacy_premium" json:"-"`
 	MembershipType    int  `bson:"membership_type" json:"membership_type"`
 }
 
@@ -37,6 +38,7 @@ type User struct {
 	RefreshToken      *string            `bson:"refresh_token" json:"-"`
 	FCMToken          string             `bson:"fcm_token" json:"fcm_token"`
 	CanChangeUsername bool               `bson:"can_change_username" json:"can_change_username"`
+	IsEmailVerified   *bool              `bson:"is_email_verified" json:"is_email_verified"`
 	AppNotification   Notification       `bson:"app_notification" json:"app_notification"`
 	Streak            int                `bson:"streak" json:"streak"`
 	UserListCount     int64              `bson:"user_list_count" json:"user_list_count"`
@@ -50,18 +52,19 @@ type Notification struct {
 }
 
 type UserStats struct {
-	AnimeCount           int   `bson:"anime_count" json:"anime_count"`
-	GameCount            int   `bson:"game_count" json:"game_count"`
-	MovieCount           int   `bson:"movie_count" json:"movie_count"`
-	TVCount              int   `bson:"tv_count" json:"tv_count"`
-	MovieWatchedTime     int64 `bson:"movie_watched_time" json:"movie_watched_time"`
-	TVWatchedEpisodes    int64 `bson:"tv_watched_episodes" json:"tv_watched_episodes"`
-	AnimeWatchedEpisodes int64 `bson:"anime_watched_episodes" json:"anime_watched_episodes"`
-	GameTotalHoursPlayed int64 `bson:"game_total_hours_played" json:"game_total_hours_played"`
-	MovieTotalScore      int64 `bson:"movie_total_score" json:"movie_total_score"`
-	TVTotalScore         int64 `bson:"tv_total_score" json:"tv_total_score"`
-	AnimeTotalScore      int64 `bson:"anime_total_score" json:"anime_total_score"`
-	GameTotalScore       int64 `bson:"game_total_score" json:"game_total_score"`
+	AnimeCount           int        `bson:"anime_count" json:"anime_count"`
+	GameCount            int        `bson:"game_count" json:"game_count"`
+	MovieCount           int        `bson:"movie_count" json:"movie_count"`
+	TVCount              int        `bson:"tv_count" json:"tv_count"`
+	MovieWatchedTime     int64      `bson:"movie_watched_time" json:"movie_watched_time"`
+	TVWatchedEpisodes    int64      `bson:"tv_watched_episodes" json:"tv_watched_episodes"`
+	AnimeWatchedEpisodes int64      `bson:"anime_watched_episodes" json:"anime_watched_episodes"`
+	GameTotalHoursPlayed int64      `bson:"game_total_hours_played" json:"game_total_hours_played"`
+	MovieTotalScore      int64      `bson:"movie_total_score" json:"movie_total_score"`
+	TVTotalScore         int64      `bson:"tv_total_score" json:"tv_total_score"`
+	AnimeTotalScore      int64      `bson:"anime_total_score" json:"anime_total_score"`
+	GameTotalScore       int64      `bson:"game_total_score" json:"game_total_score"`
+	TagCounts            []TagCount `bson:"tag_counts" json:"tag_counts"`
 }
 
 type UserInfo struct {
@@ -91,6 +94,7 @@ type UserInfo struct {
 	TVTotalScore            float64             `bson:"tv_total_score" json:"tv_total_score"`
 	AnimeTotalScore         float64             `bson:"anime_total_score" json:"anime_total_score"`
 	GameTotalScore          float64             `bson:"game_total_score" json:"game_total_score"`
+	TagCounts               []TagCount          `bson:"tag_counts" json:"tag_counts,omitempty"`
 	CreatedAt               time.Time           `bson:"created_at" json:"created_at"`
 	LegendContent           []UserInfoContent   `bson:"legend_content" json:"legend_content"`
 	ConsumeLater            []ConsumeLater      `bson:"consume_later" json:"consume_later"`
@@ -14,6 +14,7 @@ type ConsumeLater struct {
 	ContentExternalIntID *int64              `bson:"content_external_int_id" json:"content_external_int_id"`
 	ContentType          string              `bson:"content_type" json:"content_type"`
 	SelfNote             *string             `bson:"self_note" json:"self_note"`
+	Tags                 []string            `bson:"tags" json:"tags"`
 	CreatedAt            time.Time           `bson:"created_at" json:"created_at"`
 	Content              ConsumeLaterContent `bson:"content" json:"content"`
 }
@@ -33,6 +33,11 @@ type AnimeList struct {
 	TotalEpisodes   *int64             `bson:"total_episodes" json:"total_episodes"`
 	Type            string             `bson:"type" json:"type"`
 	IsAiring        bool               `bson:"is_airing" json:"is_airing"`
+	StartedAt       *time.Time         `bson:"started_at" json:"started_at"`
+	FinishedAt      *time.Time         `bson:"finished_at" json:"finished_at"`
+	Priority        *int               `bson:"priority" json:"priority"`
+	PrivateNote     *string            `bson:"private_note" json:"private_note"`
+	Tags            []string           `bson:"tags" json:"tags"`
 	CreatedAt       time.Time          `bson:"created_at" json:"created_at"`
 }
 
@@ -70,6 +75,11 @@ type GameList struct {
 	TitleOriginal     string             `bson:"title_original" json:"title_original"`
 	ImageURL          *string            `bson:"image_url" json:"image_url"`
 	TBA               bool               `bson:"tba" json:"tba"`
+	StartedAt         *time.Time         `bson:"started_at" json:"started_at"`
+	FinishedAt        *time.Time         `bson:"finished_at" json:"finished_at"`
+	Priority          *int               `bson:"priority" json:"priority"`
+	PrivateNote       *string            `bson:"private_note" json:"private_note"`
+	Tags              []string           `bson:"tags" json:"tags"`
 	CreatedAt         time.Time          `bson:"created_at" json:"created_at"`
 }
 
@@ -85,6 +95,11 @@ type MovieList struct {
 	TitleEn       string             `bson:"title_en" json:"title_en"`
 	TitleOriginal string             `bson:"title_original" json:"title_original"`
 	ImageURL      *string            `bson:"image_url" json:"image_url"`
+	StartedAt     *time.Time         `bson:"started_at" json:"started_at"`
+	FinishedAt    *time.Time         `bson:"finished_at" json:"finished_at"`
+	Priority      *int               `bson:"priority" json:"priority"`
+	PrivateNote   *string            `bson:"private_note" json:"private_note"`
+	Tags          []string           `bson:"tags" json:"tags"`
 	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
 }
 
@@ -104,9 +119,21 @@ type TVSeriesList struct {
 	ImageURL        *string            `bson:"image_url" json:"image_url"`
 	TotalEpisodes   *int64             `bson:"total_episodes" json:"total_episodes"`
 	TotalSeasons    *int64             `bson:"total_seasons" json:"total_seasons"`
+	StartedAt       *time.Time         `bson:"started_at" json:"started_at"`
+	FinishedAt      *time.Time         `bson:"finished_at" json:"finished_at"`
+	Priority        *int               `bson:"priority" json:"priority"`
+	PrivateNote     *string            `bson:"private_note" json:"private_note"`
+	Tags            []string           `bson:"tags" json:"tags"`
 	CreatedAt       time.Time          `bson:"created_at" json:"created_at"`
 }
 
+type BulkListItemResult struct {
+	ID        string  `json:"id"`
+	Type      string  `json:"type"`
+	IsSuccess bool    `json:"is_success"`
+	Error     *string `json:"error"`
+}
+
 type UserListAISuggestion struct {
 	MovieIDList []UserListAISuggestionID `bson:"movie_id_list" json:"movie_id_list"`
 	TVIDList    []UserListAISuggestionID `bson:"tv_id_list" json:"tv_id_list"`
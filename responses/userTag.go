@@ -0,0 +1,11 @@
+package responses
+
+type UserTag struct {
+	Name  string `bson:"name" json:"name"`
+	Count int    `bson:"count" json:"count"`
+}
+
+type TagCount struct {
+	Tag   string `bson:"tag" json:"tag"`
+	Count int    `bson:"count" json:"count"`
+}
@@ -5,10 +5,11 @@ import (
 	"app/db"
 	"app/helpers"
 
+	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
 )
 
-func animeRouter(router *gin.RouterGroup, mongoDB *db.MongoDB) {
+func animeRouter(router *gin.RouterGroup, jwtToken *jwt.GinJWTMiddleware, mongoDB *db.MongoDB) {
 	animeController := controllers.NewAnimeController(mongoDB)
 
 	anime := router.Group("/anime")
@@ -20,7 +21,7 @@ func animeRouter(router *gin.RouterGroup, mongoDB *db.MongoDB) {
 		anime.GET("/popular", animeController.GetPopularAnimes)
 		anime.GET("/popular-streaming-platforms", animeController.GetPopularStreamingPlatforms)
 		anime.GET("/popular-studios", animeController.GetPopularStudios)
-		anime.Use(helpers.OptionalTokenCheck).GET("/details", animeController.GetAnimeDetails)
+		anime.GET("/details", helpers.OptionalAuthMiddleware(jwtToken), animeController.GetAnimeDetails)
 		anime.GET("/search", animeController.SearchAnimeByTitle)
 	}
 }
@@ -25,7 +25,7 @@ func customListRouter(router *gin.RouterGroup, jwtToken *jwt.GinJWTMiddleware, m
 		customList.DELETE("", customListController.DeleteCustomListByID)
 	}
 
-	customListOptional := router.Group("/custom-list").Use(helpers.OptionalTokenCheck)
+	customListOptional := router.Group("/custom-list").Use(helpers.OptionalAuthMiddleware(jwtToken))
 	{
 		customListOptional.GET("", customListController.GetCustomListsByUserID)
 		customListOptional.GET("/details", customListController.GetCustomListDetails)
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"app/controllers"
+	"app/db"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+)
+
+func dataExportRouter(router *gin.RouterGroup, jwtToken *jwt.GinJWTMiddleware, mongoDB *db.MongoDB) {
+	dataExportController := controllers.NewDataExportController(mongoDB)
+	listExportController := controllers.NewListExportController(mongoDB)
+
+	export := router.Group("/export").Use(jwtToken.MiddlewareFunc())
+	{
+		export.GET("", dataExportController.GetDataExport)
+		export.POST("", dataExportController.CreateDataExport)
+		export.GET("/download", dataExportController.DownloadDataExport)
+		export.GET("/mal", listExportController.ExportMALList)
+		export.GET("/letterboxd", listExportController.ExportLetterboxdList)
+		export.GET("/trakt", listExportController.ExportTraktList)
+	}
+}
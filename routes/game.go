@@ -5,17 +5,18 @@ import (
 	"app/db"
 	"app/helpers"
 
+	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
 )
 
-func gameRouter(router *gin.RouterGroup, mongoDB *db.MongoDB) {
+func gameRouter(router *gin.RouterGroup, jwtToken *jwt.GinJWTMiddleware, mongoDB *db.MongoDB) {
 	gameController := controllers.NewGameController(mongoDB)
 
 	game := router.Group("/game")
 	{
 		game.GET("/upcoming", gameController.GetUpcomingGamesBySort)
 		game.GET("", gameController.GetGamesByFilterAndSort)
-		game.Use(helpers.OptionalTokenCheck).GET("/details", gameController.GetGameDetails)
+		game.GET("/details", helpers.OptionalAuthMiddleware(jwtToken), gameController.GetGameDetails)
 		game.GET("/search", gameController.SearchGameByTitle)
 	}
 }
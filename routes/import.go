@@ -15,6 +15,9 @@ func importRouter(router *gin.RouterGroup, jwtToken *jwt.GinJWTMiddleware, mongo
 	tmdbImportController := controllers.NewTMDBImportController(mongoDB)
 	anilistImportController := controllers.NewAniListImportController(mongoDB)
 	traktImportController := controllers.NewTraktImportController(mongoDB)
+	importJobController := controllers.NewImportJobController(mongoDB)
+	fileImportController := controllers.NewFileImportController(mongoDB)
+	unmatchedImportController := controllers.NewUnmatchedImportController(mongoDB)
 
 	importGroup := router.Group("/import")
 	importGroup.Use(jwtToken.MiddlewareFunc())
@@ -25,5 +28,13 @@ func importRouter(router *gin.RouterGroup, jwtToken *jwt.GinJWTMiddleware, mongo
 		importGroup.POST("/tmdb", tmdbImportController.ImportUserData)
 		importGroup.POST("/anilist", anilistImportController.ImportUserLists)
 		importGroup.POST("/trakt", traktImportController.ImportUserData)
+		importGroup.POST("/file", fileImportController.ImportFromFile)
+		importGroup.GET("/history", importJobController.GetImportHistory)
+		importGroup.GET("/jobs/:id", importJobController.GetImportJob)
+		importGroup.POST("/jobs/:id/apply", importJobController.ApplyImportJob)
+		importGroup.POST("/jobs/:id/rollback", importJobController.RollbackImportJob)
+		importGroup.GET("/unmatched", unmatchedImportController.GetUnmatchedItems)
+		importGroup.POST("/unmatched/:id/confirm", unmatchedImportController.ConfirmUnmatchedItem)
+		importGroup.POST("/unmatched/:id/dismiss", unmatchedImportController.DismissUnmatchedItem)
 	}
 }
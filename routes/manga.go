@@ -5,15 +5,16 @@ import (
 	"app/db"
 	"app/helpers"
 
+	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
 )
 
-func mangaRouter(router *gin.RouterGroup, mongoDB *db.MongoDB) {
+func mangaRouter(router *gin.RouterGroup, jwtToken *jwt.GinJWTMiddleware, mongoDB *db.MongoDB) {
 	mangaController := controllers.NewMangaController(mongoDB)
 
 	manga := router.Group("/manga")
 	{
 		manga.GET("", mangaController.GetMangaBySortAndFilter)
-		manga.Use(helpers.OptionalTokenCheck).GET("/details", mangaController.GetMangaDetails)
+		manga.GET("/details", helpers.OptionalAuthMiddleware(jwtToken), mangaController.GetMangaDetails)
 	}
 }
@@ -5,10 +5,11 @@ import (
 	"app/db"
 	"app/helpers"
 
+	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
 )
 
-func movieRouter(router *gin.RouterGroup, mongoDB *db.MongoDB) {
+func movieRouter(router *gin.RouterGroup, jwtToken *jwt.GinJWTMiddleware, mongoDB *db.MongoDB) {
 	movieController := controllers.NewMovieController(mongoDB)
 
 	movie := router.Group("/movie")
@@ -19,7 +20,7 @@ func movieRouter(router *gin.RouterGroup, mongoDB *db.MongoDB) {
 		movie.GET("/streaming-platforms", movieController.GetMoviesByStreamingPlatform)
 		movie.GET("/popular-actors", movieController.GetPopularActors)
 		movie.GET("/popular-streaming-platforms", movieController.GetPopularStreamingPlatforms)
-		movie.Use(helpers.OptionalTokenCheck).GET("/details", movieController.GetMovieDetails)
+		movie.GET("/details", helpers.OptionalAuthMiddleware(jwtToken), movieController.GetMovieDetails)
 		movie.GET("/search", movieController.SearchMovieByTitle)
 		movie.GET("/theaters", movieController.GetMoviesInTheater)
 	}
@@ -6,10 +6,11 @@ import (
 
 	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 )
 
-func oauth2Router(router *gin.RouterGroup, jwtToken *jwt.GinJWTMiddleware, mongoDB *db.MongoDB) {
-	OAuth2Controller := controllers.NewOAuth2Controller(mongoDB)
+func oauth2Router(router *gin.RouterGroup, jwtToken *jwt.GinJWTMiddleware, mongoDB *db.MongoDB, redisClient *redis.Client) {
+	OAuth2Controller := controllers.NewOAuth2Controller(mongoDB, redisClient)
 
 	oauth := router.Group("/oauth")
 	{
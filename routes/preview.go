@@ -5,15 +5,16 @@ import (
 	"app/db"
 	"app/helpers"
 
+	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
 )
 
-func previewRouter(router *gin.RouterGroup, mongoDB *db.MongoDB) {
+func previewRouter(router *gin.RouterGroup, jwtToken *jwt.GinJWTMiddleware, mongoDB *db.MongoDB) {
 	previewController := controllers.NewPreviewController(mongoDB)
 
 	preview := router.Group("/preview")
 	{
-		preview.GET("", helpers.OptionalTokenCheck, previewController.GetHomePreview)
+		preview.GET("", helpers.OptionalAuthMiddleware(jwtToken), previewController.GetHomePreview)
 		preview.GET("/v2", previewController.GetHomePreviewV2)
 	}
 }
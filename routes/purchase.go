@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"app/controllers"
+	"app/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+func purchaseRouter(router *gin.RouterGroup, mongoDB *db.MongoDB) {
+	purchaseController := controllers.NewPurchaseController(mongoDB)
+
+	// Stores authenticate with the token of the webhook url.
+	purchase := router.Group("/purchase")
+	{
+		purchase.POST("/app-store", purchaseController.AppStoreNotification)
+		purchase.POST("/play-store", purchaseController.PlayStoreNotification)
+	}
+}
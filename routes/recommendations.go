@@ -22,7 +22,7 @@ func recommendationRouter(router *gin.RouterGroup, jwtToken *jwt.GinJWTMiddlewar
 		recommendation.PATCH("/like", recommendationController.LikeRecommendation)
 	}
 
-	recommendationOptional := router.Group("/recommendation").Use(helpers.OptionalTokenCheck)
+	recommendationOptional := router.Group("/recommendation").Use(helpers.OptionalAuthMiddleware(jwtToken))
 	{
 		recommendationOptional.GET("", recommendationController.GetRecommendationsByContentID)
 		recommendationOptional.GET("/social", recommendationController.GetRecommendationsForSocial)
@@ -22,7 +22,7 @@ func reviewRouter(router *gin.RouterGroup, jwtToken *jwt.GinJWTMiddleware, mongo
 		review.PATCH("/like", reviewController.VoteReview)
 	}
 
-	reviewOptional := router.Group("/review").Use(helpers.OptionalTokenCheck)
+	reviewOptional := router.Group("/review").Use(helpers.OptionalAuthMiddleware(jwtToken))
 	{
 		reviewOptional.GET("", reviewController.GetReviewsByContentID)
 		reviewOptional.GET("/user", reviewController.GetReviewsByUserID)
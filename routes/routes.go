@@ -20,23 +20,27 @@ func SetupRoutes(
 ) {
 	apiRouter := router.Group("/api/v1")
 
-	previewRouter(apiRouter, mongoDB)
+	previewRouter(apiRouter, jwtToken, mongoDB)
 	socialRouter(apiRouter, jwtToken, mongoDB)
-	userRouter(apiRouter, jwtToken, mongoDB)
-	tvRouter(apiRouter, mongoDB)
-	movieRouter(apiRouter, mongoDB)
-	animeRouter(apiRouter, mongoDB)
-	mangaRouter(apiRouter, mongoDB)
-	gameRouter(apiRouter, mongoDB)
-	oauth2Router(apiRouter, jwtToken, mongoDB)
+	userRouter(apiRouter, jwtToken, mongoDB, redisClient)
+	tvRouter(apiRouter, jwtToken, mongoDB)
+	movieRouter(apiRouter, jwtToken, mongoDB)
+	animeRouter(apiRouter, jwtToken, mongoDB)
+	mangaRouter(apiRouter, jwtToken, mongoDB)
+	gameRouter(apiRouter, jwtToken, mongoDB)
+	oauth2Router(apiRouter, jwtToken, mongoDB, redisClient)
 	userListRouter(apiRouter, jwtToken, mongoDB)
 	userInteractionRouter(apiRouter, jwtToken, mongoDB)
+	userTagRouter(apiRouter, jwtToken, mongoDB)
 	aiSuggestionsRouter(apiRouter, jwtToken, mongoDB, pinecone, pineconeIndex, redisClient)
 	reviewRouter(apiRouter, jwtToken, mongoDB)
 	recommendationRouter(apiRouter, jwtToken, mongoDB)
 	customListRouter(apiRouter, jwtToken, mongoDB)
 	achievementRouter(apiRouter, jwtToken, mongoDB)
 	importRouter(apiRouter, jwtToken, mongoDB)
+	dataExportRouter(apiRouter, jwtToken, mongoDB)
+	scrobbleRouter(apiRouter, jwtToken, mongoDB)
+	purchaseRouter(apiRouter, mongoDB)
 	searchRouter(apiRouter, mongoDB, pinecone, pineconeIndex, redisClient)
 
 	router.NoRoute(func(c *gin.Context) {
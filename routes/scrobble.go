@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"app/controllers"
+	"app/db"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+)
+
+func scrobbleRouter(router *gin.RouterGroup, jwtToken *jwt.GinJWTMiddleware, mongoDB *db.MongoDB) {
+	scrobbleController := controllers.NewScrobbleController(mongoDB)
+
+	scrobble := router.Group("/scrobble")
+	{
+		// Media servers authenticate with the token of the webhook url.
+		scrobble.POST("/:source", scrobbleController.Scrobble)
+
+		scrobble.Use(jwtToken.MiddlewareFunc())
+		{
+			scrobble.POST("/token", scrobbleController.CreateScrobbleToken)
+		}
+	}
+}
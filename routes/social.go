@@ -12,7 +12,7 @@ import (
 func socialRouter(router *gin.RouterGroup, jwtToken *jwt.GinJWTMiddleware, mongoDB *db.MongoDB) {
 	socialController := controllers.NewSocialController(mongoDB)
 
-	preview := router.Group("/social").Use(helpers.OptionalTokenCheck)
+	preview := router.Group("/social").Use(helpers.OptionalAuthMiddleware(jwtToken))
 	{
 		preview.GET("", socialController.GetSocials)
 	}
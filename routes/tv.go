@@ -5,10 +5,11 @@ import (
 	"app/db"
 	"app/helpers"
 
+	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
 )
 
-func tvRouter(router *gin.RouterGroup, mongoDB *db.MongoDB) {
+func tvRouter(router *gin.RouterGroup, jwtToken *jwt.GinJWTMiddleware, mongoDB *db.MongoDB) {
 	tvController := controllers.NewTVController(mongoDB)
 
 	tv := router.Group("/tv")
@@ -19,7 +20,7 @@ func tvRouter(router *gin.RouterGroup, mongoDB *db.MongoDB) {
 		tv.GET("/streaming-platforms", tvController.GetTVSeriesByStreamingPlatform)
 		tv.GET("/popular-actors", tvController.GetPopularActors)
 		tv.GET("/popular-streaming-platforms", tvController.GetPopularStreamingPlatforms)
-		tv.Use(helpers.OptionalTokenCheck).GET("/details", tvController.GetTVSeriesDetails)
+		tv.GET("/details", helpers.OptionalAuthMiddleware(jwtToken), tvController.GetTVSeriesDetails)
 		tv.GET("/search", tvController.SearchTVSeriesByTitle)
 	}
 }
@@ -6,13 +6,20 @@ import (
 
 	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 )
 
-func userRouter(router *gin.RouterGroup, jwtToken *jwt.GinJWTMiddleware, mongoDB *db.MongoDB) {
-	userController := controllers.NewUserController(mongoDB)
+func userRouter(router *gin.RouterGroup, jwtToken *jwt.GinJWTMiddleware, mongoDB *db.MongoDB, redisClient *redis.Client) {
+	userController := controllers.NewUserController(mongoDB, redisClient)
+	sessionController := controllers.NewSessionController(mongoDB, redisClient)
 	feedbackController := controllers.NewFeedbackController(mongoDB)
+	linkedAccountController := controllers.NewLinkedAccountController(mongoDB)
+	traktSyncController := controllers.NewTraktSyncController(mongoDB)
+	purchaseController := controllers.NewPurchaseController(mongoDB)
 
 	router.GET("/confirm-password-reset", userController.ConfirmPasswordReset)
+	router.POST("/confirm-password-reset", userController.ResetPassword)
+	router.GET("/confirm-email", userController.ConfirmEmail)
 
 	feedback := router.Group("/feedback").Use(jwtToken.MiddlewareFunc())
 	{
@@ -23,10 +30,12 @@ func userRouter(router *gin.RouterGroup, jwtToken *jwt.GinJWTMiddleware, mongoDB
 	{
 		auth.POST("/login", jwtToken.LoginHandler)
 		auth.POST("/register", userController.Register)
-		auth.POST("/logout", jwtToken.LogoutHandler)
-		auth.GET("/refresh", jwtToken.RefreshHandler)
+		auth.POST("/logout", sessionController.Logout(jwtToken))
+		auth.POST("/refresh", sessionController.RefreshSession(jwtToken))
+		auth.GET("/refresh", sessionController.RefreshSession(jwtToken))
 
 		auth.GET("/confirm-password-reset", userController.ConfirmPasswordReset)
+		auth.POST("/confirm-password-reset", userController.ResetPassword)
 	}
 
 	user := router.Group("/user")
@@ -43,14 +52,27 @@ func userRouter(router *gin.RouterGroup, jwtToken *jwt.GinJWTMiddleware, mongoDB
 			user.GET("/friends", userController.GetFriends)
 			user.DELETE("/delete", userController.DeleteUser)
 			user.PATCH("/password", userController.ChangePassword)
+			user.POST("/email-verification", userController.ResendEmailVerification)
+			user.GET("/sessions", sessionController.GetSessions)
+			user.DELETE("/sessions", sessionController.RevokeAllSessions)
+			user.DELETE("/sessions/:id", sessionController.RevokeSession)
 			user.PATCH("/image", userController.ChangeUserImage)
 			user.PATCH("/notification/app", userController.ChangeAppNotificationPreference)
 			user.PATCH("/notification/mail", userController.ChangeMailNotificationPreference)
 			user.PATCH("/token", userController.UpdateFCMToken)
-			user.PATCH("/membership", userController.ChangeUserMembership)
+			user.GET("/membership", purchaseController.GetMembership)
+			user.POST("/membership", purchaseController.VerifyPurchase)
 			user.PATCH("/username", userController.ChangeUsername)
 			user.POST("/request-answer", userController.AnswerFriendRequest)
 			user.POST("/friend", userController.SendFriendRequest)
+			user.GET("/linked-accounts", linkedAccountController.GetLinkedAccounts)
+			user.POST("/linked-accounts", linkedAccountController.LinkAccount)
+			user.DELETE("/linked-accounts", linkedAccountController.UnlinkAccount)
+			user.GET("/linked-accounts/history", linkedAccountController.GetLinkedAccountSyncs)
+			user.GET("/trakt", traktSyncController.GetTraktConnection)
+			user.DELETE("/trakt", traktSyncController.Disconnect)
+			user.POST("/trakt/device", traktSyncController.RequestDeviceCode)
+			user.POST("/trakt/device/token", traktSyncController.PollDeviceToken)
 		}
 	}
 }
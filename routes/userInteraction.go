@@ -16,6 +16,7 @@ func userInteractionRouter(router *gin.RouterGroup, jwtToken *jwt.GinJWTMiddlewa
 		consume.POST("/move", userInteractionController.MarkConsumeLaterAsUserList)
 		consume.POST("", userInteractionController.CreateConsumeLater)
 		consume.GET("", userInteractionController.GetConsumeLater)
+		consume.PATCH("/tags", userInteractionController.UpdateConsumeLaterTags)
 		consume.DELETE("", userInteractionController.DeleteConsumeLaterById)
 		consume.DELETE("/all", userInteractionController.DeleteAllConsumeLaterByUserID)
 	}
@@ -10,6 +10,9 @@ import (
 
 func userListRouter(router *gin.RouterGroup, jwtToken *jwt.GinJWTMiddleware, mongoDB *db.MongoDB) {
 	userListController := controllers.NewUserListController(mongoDB)
+	episodeWatchController := controllers.NewEpisodeWatchController(mongoDB)
+	consumptionSessionController := controllers.NewConsumptionSessionController(mongoDB)
+	listChangeController := controllers.NewListChangeController(mongoDB)
 
 	baseRoute := router.Group("/list")
 	{
@@ -19,6 +22,9 @@ func userListRouter(router *gin.RouterGroup, jwtToken *jwt.GinJWTMiddleware, mon
 			userList.GET("", userListController.GetUserListByUserID)
 			userList.GET("/logs", userListController.GetLogsByDateRange)
 			userList.PATCH("", userListController.UpdateUserListPublicVisibility)
+			userList.PATCH("/bulk", userListController.BulkUpdateList)
+			userList.GET("/history", listChangeController.GetListChanges)
+			userList.POST("/undo", listChangeController.UndoListChange)
 		}
 
 		anime := baseRoute.Group("/anime").Use(jwtToken.MiddlewareFunc())
@@ -31,8 +37,8 @@ func userListRouter(router *gin.RouterGroup, jwtToken *jwt.GinJWTMiddleware, mon
 		manga := baseRoute.Group("/manga").Use(jwtToken.MiddlewareFunc())
 		{
 			manga.POST("", userListController.CreateMangaList)
-			// manga.PATCH("", userListController.UpdateAnimeListByID)
-			// manga.PATCH("/inc", userListController.IncrementAnimeListEpisodeByID)
+			manga.PATCH("", userListController.UpdateMangaListByID)
+			manga.PATCH("/inc", userListController.IncrementMangaListChapterVolumeByID)
 		}
 
 		game := baseRoute.Group("/game").Use(jwtToken.MiddlewareFunc())
@@ -48,6 +54,22 @@ func userListRouter(router *gin.RouterGroup, jwtToken *jwt.GinJWTMiddleware, mon
 			movie.PATCH("", userListController.UpdateMovieListByID)
 		}
 
+		episode := baseRoute.Group("/episode").Use(jwtToken.MiddlewareFunc())
+		{
+			episode.GET("", episodeWatchController.GetEpisodeWatches)
+			episode.PATCH("", episodeWatchController.MarkEpisode)
+			episode.PATCH("/season", episodeWatchController.MarkEpisodeSeason)
+			episode.PATCH("/range", episodeWatchController.MarkEpisodeRange)
+		}
+
+		session := baseRoute.Group("/session").Use(jwtToken.MiddlewareFunc())
+		{
+			session.GET("", consumptionSessionController.GetConsumptionSessions)
+			session.POST("", consumptionSessionController.CreateConsumptionSession)
+			session.PATCH("", consumptionSessionController.UpdateConsumptionSession)
+			session.DELETE("", consumptionSessionController.DeleteConsumptionSession)
+		}
+
 		tv := baseRoute.Group("/tv").Use(jwtToken.MiddlewareFunc())
 		{
 			tv.POST("", userListController.CreateTVSeriesWatchList)
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"app/controllers"
+	"app/db"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+)
+
+func userTagRouter(router *gin.RouterGroup, jwtToken *jwt.GinJWTMiddleware, mongoDB *db.MongoDB) {
+	userTagController := controllers.NewUserTagController(mongoDB)
+
+	tag := router.Group("/tag").Use(jwtToken.MiddlewareFunc())
+	{
+		tag.GET("", userTagController.GetUserTags)
+		tag.POST("", userTagController.CreateUserTag)
+		tag.PATCH("/rename", userTagController.RenameUserTag)
+		tag.PATCH("/merge", userTagController.MergeUserTags)
+		tag.DELETE("", userTagController.DeleteUserTag)
+	}
+}
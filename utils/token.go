@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GenerateToken returns a random url safe token, tokens are stored hashed with
+// HashToken.
+func GenerateToken() string {
+	bytes := make([]byte, 32)
+	rand.Read(bytes)
+
+	return hex.EncodeToString(bytes)
+}
+
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// SignToken returns a url safe token of the payload that expires at the given
+// time. Tokens are signed for their purpose, so they don't need to be stored
+// and can't be used for another purpose.
+func SignToken(purpose, payload string, expiresAt time.Time) string {
+	data := base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+
+	return data + "." + signTokenData(purpose, data)
+}
+
+// VerifySignedToken returns the payload of the token, ok is false if the token
+// is tampered or expired.
+func VerifySignedToken(purpose, token string) (string, bool) {
+	index := strings.LastIndex(token, ".")
+	if index < 0 {
+		return "", false
+	}
+
+	data, signature := token[:index], token[index+1:]
+	if !hmac.Equal([]byte(signature), []byte(signTokenData(purpose, data))) {
+		return "", false
+	}
+
+	encodedPayload, expiry, found := strings.Cut(data, ".")
+	if !found {
+		return "", false
+	}
+
+	expiresAt, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return "", false
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return "", false
+	}
+
+	return string(payload), true
+}
+
+func signTokenData(purpose, data string) string {
+	mac := hmac.New(sha256.New, []byte(os.Getenv("JWT_SECRET_KEY")))
+	mac.Write([]byte(purpose + ":" + data))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}